@@ -7,13 +7,18 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/spf13/cast"
 	"golang.org/x/sync/semaphore"
 	"golang.org/x/sync/singleflight"
@@ -34,10 +39,16 @@ func bindFileApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 		maxWait = 60
 	}
 
+	// 0 or negative means that the files are served through the app
+	presignedDownloadExpiry := cast.ToInt64(os.Getenv("PB_S3_PRESIGNED_DOWNLOADS_EXPIRY"))
+
 	api := fileApi{
-		thumbGenPending: new(singleflight.Group),
-		thumbGenSem:     semaphore.NewWeighted(maxWorkers),
-		thumbGenMaxWait: time.Duration(maxWait) * time.Second,
+		thumbGenPending:         new(singleflight.Group),
+		thumbGenSem:             semaphore.NewWeighted(maxWorkers),
+		thumbGenMaxWait:         time.Duration(maxWait) * time.Second,
+		thumbLastAccess:         store.New[string, time.Time](nil),
+		thumbCleanupActive:      new(singleflight.Group),
+		presignedDownloadExpiry: time.Duration(presignedDownloadExpiry) * time.Second,
 	}
 
 	sub := rg.Group("/files")
@@ -57,8 +68,25 @@ type fileApi struct {
 	// thumbGenMaxWait is the maximum waiting time for starting a new
 	// thumb generation process.
 	thumbGenMaxWait time.Duration
+
+	// thumbLastAccess stores the last access time of the served thumbs
+	// (it is used only as an in-memory hint for the thumbs cleanup).
+	thumbLastAccess *store.Store[string, time.Time]
+
+	// thumbCleanupActive represents a group of currently running thumbs cleanups.
+	thumbCleanupActive *singleflight.Group
+
+	// presignedDownloadExpiry is the expiration duration of the presigned
+	// S3 URLs to which the file download requests are redirected.
+	//
+	// If zero (or the storage doesn't support presigned URLs),
+	// the files are streamed through the app.
+	presignedDownloadExpiry time.Duration
 }
 
+// maxTrackedThumbs is the max number of thumbs whose last access time is tracked.
+const maxTrackedThumbs = 10000
+
 func (api *fileApi) fileToken(e *core.RequestEvent) error {
 	if e.Auth == nil {
 		return e.UnauthorizedError("Missing auth context.", nil)
@@ -104,14 +132,23 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 		return e.NotFoundError("", nil)
 	}
 
+	// check for a signed file URL (it grants access to the file regardless of the view rule)
+	query := e.Request.URL.Query()
+	signed := query.Has(core.SignedFileURLSignatureParam)
+	if signed {
+		if err := e.App.ValidateSignedFileURL(record, filename, query); err != nil {
+			return e.NotFoundError("", err)
+		}
+	}
+
 	// check whether the request is authorized to view the protected file
-	if fileField.Protected {
+	if fileField.Protected && !signed {
 		originalRequestInfo, err := e.RequestInfo()
 		if err != nil {
 			return e.InternalServerError("Failed to load request info", err)
 		}
 
-		token := e.Request.URL.Query().Get("token")
+		token := query.Get("token")
 		authRecord, _ := e.App.FindAuthRecordByToken(token, core.TokenTypeFile)
 
 		// create a shallow copy of the cached request data and adjust it to the current auth record (if any)
@@ -124,24 +161,26 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 		}
 	}
 
-	baseFilesPath := record.BaseFilesPath()
+	fileRecord := record
 
 	// fetch the original view file field related record
 	if collection.IsView() {
-		fileRecord, err := e.App.FindRecordByViewFile(collection.Id, fileField.Name, filename)
+		fileRecord, err = e.App.FindRecordByViewFile(collection.Id, fileField.Name, filename)
 		if err != nil {
 			return e.NotFoundError("", fmt.Errorf("failed to fetch view file field record: %w", err))
 		}
-		baseFilesPath = fileRecord.BaseFilesPath()
 	}
 
+	baseFilesPath := fileRecord.BaseFilesPath()
+
 	fsys, err := e.App.NewFilesystem()
 	if err != nil {
 		return e.InternalServerError("Filesystem initialization failure.", err)
 	}
 	defer fsys.Close()
 
-	originalPath := baseFilesPath + "/" + filename
+	// note: for deduplicated files this is the key of their content-addressed blob
+	originalPath := e.App.FindRecordFileKey(fileRecord, filename)
 
 	event := new(core.FileDownloadRequestEvent)
 	event.RequestEvent = e
@@ -152,7 +191,7 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 	event.ServedName = filename
 
 	// check for valid thumb size param
-	thumbSize := e.Request.URL.Query().Get("thumb")
+	thumbSize := query.Get("thumb")
 	if thumbSize != "" && (list.ExistInSlice(thumbSize, defaultThumbSizes) || list.ExistInSlice(thumbSize, fileField.Thumbs)) {
 		// extract the original file meta attributes and check it existence
 		oAttrs, oAttrsErr := fsys.Attributes(originalPath)
@@ -160,15 +199,24 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 			return e.NotFoundError("", err)
 		}
 
+		size, sizeErr := filesystem.ParseThumbSize(thumbSize)
+
 		// check if it is an image
-		if list.ExistInSlice(oAttrs.ContentType, imageContentTypes) {
+		if sizeErr == nil && list.ExistInSlice(oAttrs.ContentType, imageContentTypes) {
+			var focalPoint []filesystem.FocalPoint
+			if size.Mode == filesystem.ThumbModeFocal {
+				if p, ok := fileField.FocalPoint(record, filename); ok {
+					focalPoint = append(focalPoint, p)
+				}
+			}
+
 			// add thumb size as file suffix
-			event.ServedName = thumbSize + "_" + filename
-			event.ServedPath = baseFilesPath + "/thumbs_" + filename + "/" + event.ServedName
+			event.ServedName = thumbServedName(size, thumbSize, filename)
+			event.ServedPath = baseFilesPath + "/thumbs_" + filename + "/" + thumbFileName(thumbSize, filename, focalPoint...)
 
 			// create a new thumb if it doesn't exist
 			if exists, _ := fsys.Exists(event.ServedPath); !exists {
-				if err := api.createThumb(e, fsys, originalPath, event.ServedPath, thumbSize); err != nil {
+				if err := api.createThumb(e, fsys, originalPath, event.ServedPath, thumbSize, focalPoint...); err != nil {
 					e.App.Logger().Warn(
 						"Fallback to original - failed to create thumb "+event.ServedName,
 						slog.Any("error", err),
@@ -180,8 +228,14 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 					event.ThumbError = err
 					event.ServedName = filename
 					event.ServedPath = originalPath
+				} else {
+					api.cleanupThumbs(e, fsys, record, fileField, baseFilesPath, filename, event.ServedPath)
 				}
 			}
+
+			if event.ServedPath != originalPath {
+				api.thumbLastAccess.SetIfLessThanLimit(event.ServedPath, time.Now(), maxTrackedThumbs)
+			}
 		}
 	}
 
@@ -194,8 +248,27 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 	// (note: it is out of the hook to allow users to customize the behavior)
 	e.Response.Header().Del("X-Frame-Options")
 
+	// limit the signed file response caching to the URL expiration time
+	// (note: it is out of the hook to allow users to customize the behavior)
+	if signed {
+		maxAge := max(0, cast.ToInt64(query.Get(core.SignedFileURLExpiresParam))-time.Now().Unix())
+		e.Response.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", maxAge))
+	}
+
 	return e.App.OnFileDownloadRequest().Trigger(event, func(e *core.FileDownloadRequestEvent) error {
 		err = execAfterSuccessTx(true, e.App, func() error {
+			if api.presignedDownloadExpiry > 0 {
+				url, err := fsys.SignedServeURL(e.Request, e.ServedPath, e.ServedName, api.presignedDownloadExpiry)
+				if err == nil {
+					return e.Redirect(http.StatusFound, url)
+				}
+
+				// fallback to serving the file through the app
+				if !errors.Is(err, blob.ErrUnsupported) {
+					e.App.Logger().Warn("Failed to generate presigned download url", slog.Any("error", err))
+				}
+			}
+
 			return fsys.Serve(e.Response, e.Request, e.ServedPath, e.ServedName)
 		})
 		if err != nil {
@@ -212,6 +285,7 @@ func (api *fileApi) createThumb(
 	originalPath string,
 	thumbPath string,
 	thumbSize string,
+	focalPoint ...filesystem.FocalPoint,
 ) error {
 	ch := api.thumbGenPending.DoChan(thumbPath, func() (any, error) {
 		ctx, cancel := context.WithTimeout(e.Request.Context(), api.thumbGenMaxWait)
@@ -222,7 +296,7 @@ func (api *fileApi) createThumb(
 		}
 		defer api.thumbGenSem.Release(1)
 
-		return nil, fsys.CreateThumb(originalPath, thumbPath, thumbSize)
+		return nil, fsys.CreateThumb(originalPath, thumbPath, thumbSize, focalPoint...)
 	})
 
 	res := <-ch
@@ -231,3 +305,111 @@ func (api *fileApi) createThumb(
 
 	return res.Err
 }
+
+// cleanupThumbs deletes the stale and least recently used thumbs
+// of the specified file when their number exceeds the Storage.MaxThumbsPerFile setting.
+//
+// A thumb is considered stale if its size is no longer allowed by the
+// file field or if it was created for a different focal point.
+func (api *fileApi) cleanupThumbs(
+	e *core.RequestEvent,
+	fsys *filesystem.System,
+	record *core.Record,
+	fileField *core.FileField,
+	baseFilesPath string,
+	filename string,
+	keepPath string,
+) {
+	maxPerFile := e.App.Settings().Storage.MaxThumbsPerFile
+	if maxPerFile <= 0 {
+		return
+	}
+
+	thumbsDir := baseFilesPath + "/thumbs_" + filename + "/"
+
+	api.thumbCleanupActive.Do(thumbsDir, func() (any, error) {
+		objects, err := fsys.List(thumbsDir)
+		if err != nil {
+			e.App.Logger().Warn("Failed to list file thumbs", slog.Any("error", err), slog.String("dir", thumbsDir))
+			return nil, nil
+		}
+
+		if len(objects) <= maxPerFile {
+			return nil, nil // nothing to cleanup
+		}
+
+		focalPoint, hasFocalPoint := fileField.FocalPoint(record, filename)
+
+		type thumbInfo struct {
+			key      string
+			stale    bool
+			lastUsed time.Time
+		}
+
+		thumbs := make([]thumbInfo, 0, len(objects))
+		for _, obj := range objects {
+			if obj.Key == keepPath {
+				continue
+			}
+
+			info := thumbInfo{key: obj.Key, lastUsed: obj.ModTime}
+
+			if t, ok := api.thumbLastAccess.GetOk(obj.Key); ok && t.After(info.lastUsed) {
+				info.lastUsed = t
+			}
+
+			spec := strings.TrimSuffix(strings.TrimPrefix(obj.Key, thumbsDir), "_"+filename)
+			thumbSize, focal, _ := strings.Cut(spec, "@")
+			if !list.ExistInSlice(thumbSize, defaultThumbSizes) && !list.ExistInSlice(thumbSize, fileField.Thumbs) {
+				info.stale = true
+			} else if focal != "" && (!hasFocalPoint || focal != focalPoint.String()) {
+				info.stale = true
+			}
+
+			thumbs = append(thumbs, info)
+		}
+
+		sort.SliceStable(thumbs, func(i, j int) bool {
+			if thumbs[i].stale != thumbs[j].stale {
+				return thumbs[i].stale
+			}
+			return thumbs[i].lastUsed.Before(thumbs[j].lastUsed)
+		})
+
+		toDelete := len(objects) - maxPerFile
+		for i := 0; i < toDelete && i < len(thumbs); i++ {
+			err := fsys.Delete(thumbs[i].key)
+			if err != nil && !errors.Is(err, filesystem.ErrNotFound) {
+				e.App.Logger().Warn("Failed to delete file thumb", slog.Any("error", err), slog.String("thumb", thumbs[i].key))
+				continue
+			}
+			api.thumbLastAccess.Remove(thumbs[i].key)
+		}
+
+		return nil, nil
+	})
+}
+
+// thumbFileName returns the stored thumb file name for the specified thumb size.
+//
+// The focal point (if any) is included as part of the name so that
+// a new thumb is generated on focal point change.
+func thumbFileName(thumbSize string, filename string, focalPoint ...filesystem.FocalPoint) string {
+	if len(focalPoint) > 0 {
+		return thumbSize + "@" + focalPoint[0].String() + "_" + filename
+	}
+
+	return thumbSize + "_" + filename
+}
+
+// thumbServedName returns the name of the served thumb file
+// with extension matching the thumb format.
+func thumbServedName(size filesystem.ThumbSize, thumbSize string, filename string) string {
+	name := thumbSize + "_" + filename
+
+	if size.Format == "" {
+		return name
+	}
+
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "." + size.Format
+}
@@ -1,18 +1,22 @@
 package apis_test
 
 import (
+	"bytes"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/types"
 )
 
@@ -492,6 +496,140 @@ func TestFileDownload(t *testing.T) {
 	}
 }
 
+func TestFileDownloadSigned(t *testing.T) {
+	t.Parallel()
+
+	const signingKey = "test_file_signing_key_1234567890"
+
+	signedURL := func(collection string, recordId string, filename string, options core.SignedFileURLOptions) string {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		app.Settings().Storage.FileSigningKey = signingKey
+
+		record, err := app.FindRecordById(collection, recordId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rawURL, err := app.NewSignedFileURL(record, filename, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return strings.TrimPrefix(rawURL, app.Settings().Meta.AppURL)
+	}
+
+	setSigningKey := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		app.Settings().Storage.FileSigningKey = signingKey
+	}
+
+	protectedURL := signedURL("demo1", "al1h9ijdeojtsjy", "300_Jsjq7RdBgA.png", core.SignedFileURLOptions{})
+	protectedThumbURL := signedURL("demo1", "al1h9ijdeojtsjy", "300_Jsjq7RdBgA.png", core.SignedFileURLOptions{Thumb: "100x100", Download: true})
+	publicURL := signedURL("users", "4q1xlclmfloku33", "300_1SEi6Q6U72.png", core.SignedFileURLOptions{})
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "protected file - signed URL with different signing key",
+			Method: http.MethodGet,
+			URL:    protectedURL,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().Storage.FileSigningKey = signingKey + "_new"
+			},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "protected file - signed URL for different file",
+			Method:          http.MethodGet,
+			URL:             strings.Replace(protectedURL, "300_Jsjq7RdBgA.png", "test_d61b33QdDU.txt", 1),
+			BeforeTestFunc:  setSigningKey,
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "protected file - signed URL with changed expiration",
+			Method:          http.MethodGet,
+			URL:             strings.Replace(protectedURL, "expires=", "expires=1", 1),
+			BeforeTestFunc:  setSigningKey,
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "protected file - signed URL with extra thumb param",
+			Method:          http.MethodGet,
+			URL:             protectedURL + "&thumb=100x100",
+			BeforeTestFunc:  setSigningKey,
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "protected file - valid signed URL",
+			Method:          http.MethodGet,
+			URL:             protectedURL,
+			BeforeTestFunc:  setSigningKey,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"PNG"},
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				cacheControl := res.Header.Get("Cache-Control")
+				if cacheControl == "" || !strings.HasPrefix(cacheControl, "max-age=") || cacheControl == "max-age=0" {
+					t.Fatalf("Expected max-age Cache-Control limited to the URL expiration, got %q", cacheControl)
+				}
+			},
+		},
+		{
+			Name:            "protected file - valid signed URL with thumb and download",
+			Method:          http.MethodGet,
+			URL:             protectedThumbURL,
+			BeforeTestFunc:  setSigningKey,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"PNG"},
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				disposition := res.Header.Get("Content-Disposition")
+				if !strings.HasPrefix(disposition, "attachment") || !strings.Contains(disposition, "100x100_300_Jsjq7RdBgA.png") {
+					t.Fatalf("Expected attachment thumb Content-Disposition, got %q", disposition)
+				}
+			},
+		},
+		{
+			Name:            "public file - signed URL with missing signing key",
+			Method:          http.MethodGet,
+			URL:             publicURL,
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "public file - valid signed URL",
+			Method:          http.MethodGet,
+			URL:             publicURL,
+			BeforeTestFunc:  setSigningKey,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"PNG"},
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
 func TestConcurrentThumbsGeneration(t *testing.T) {
 	t.Parallel()
 
@@ -566,3 +704,196 @@ func TestConcurrentThumbsGeneration(t *testing.T) {
 		}
 	}
 }
+
+func TestThumbsFormatFocalPointAndCleanup(t *testing.T) {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	app.Settings().Storage.MaxThumbsPerFile = 2
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileField := demo1.Fields.GetByName("file_one").(*core.FileField)
+	fileField.Protected = false
+	fileField.MaxSelect = 1
+	fileField.MaxSize = 999999
+	fileField.Thumbs = []string{"111x111", "111x222.webp", "111x333p_q80"}
+	fileField.FocalPointField = "json"
+	if err = app.Save(demo1); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.FindRecordById(demo1, "al1h9ijdeojtsjy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Set("json", map[string]any{"x": 0, "y": 0.25})
+	if err = app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	fileKey := "wsmn24bux7wo113/al1h9ijdeojtsjy/300_Jsjq7RdBgA.png"
+
+	pbRouter, _ := apis.NewRouter(app)
+	mux, _ := pbRouter.BuildMux()
+
+	thumbs := []struct {
+		size                string
+		expectedServedName  string
+		expectedContentType string
+	}{
+		{"111x111", "111x111_300_Jsjq7RdBgA.png", "image/png"},
+		{"111x222.webp", "111x222.webp_300_Jsjq7RdBgA.webp", "image/webp"},
+		{"111x333p_q80", "111x333p_q80_300_Jsjq7RdBgA.png", "image/png"},
+	}
+
+	for _, thumb := range thumbs {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/files/"+fileKey+"?thumb="+thumb.size, nil)
+		mux.ServeHTTP(recorder, req)
+
+		if recorder.Code != 200 {
+			t.Fatalf("[%s] Expected status 200, got %d", thumb.size, recorder.Code)
+		}
+
+		if ct := recorder.Header().Get("Content-Type"); ct != thumb.expectedContentType {
+			t.Fatalf("[%s] Expected Content-Type %q, got %q", thumb.size, thumb.expectedContentType, ct)
+		}
+
+		disposition := recorder.Header().Get("Content-Disposition")
+		if !strings.HasSuffix(disposition, "filename="+thumb.expectedServedName) {
+			t.Fatalf("[%s] Expected served name %q, got %q", thumb.size, thumb.expectedServedName, disposition)
+		}
+	}
+
+	thumbsDir := "wsmn24bux7wo113/al1h9ijdeojtsjy/thumbs_300_Jsjq7RdBgA.png/"
+
+	objects, err := fsys.List(thumbsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKeys := []string{
+		thumbsDir + "111x222.webp_300_Jsjq7RdBgA.png",
+		thumbsDir + "111x333p_q80@0,0.25_300_Jsjq7RdBgA.png",
+	}
+
+	if len(objects) != len(expectedKeys) {
+		keys := make([]string, len(objects))
+		for i, obj := range objects {
+			keys[i] = obj.Key
+		}
+		t.Fatalf("Expected thumbs\n%v\ngot\n%v", expectedKeys, keys)
+	}
+
+	for _, k := range expectedKeys {
+		if exists, _ := fsys.Exists(k); !exists {
+			t.Fatalf("Missing thumb %q", k)
+		}
+	}
+}
+
+func TestFileDownloadDeduplicated(t *testing.T) {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileField := demo1.Fields.GetByName("file_many").(*core.FileField)
+	fileField.Deduplicate = true
+	if err = app.Save(demo1); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	// reuse the content of an existing image
+	r, err := fsys.GetReader("_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgContent, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := make([]*core.Record, 2)
+	for i := range records {
+		file, err := filesystem.NewFileFromBytes(imgContent, "image.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records[i] = core.NewRecord(demo1)
+		records[i].Set("file_many", file)
+		if err = app.Save(records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	blobs, err := fsys.List(core.StorageBlobsDirName + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobs) != 1 {
+		t.Fatalf("Expected 1 stored blob, got %d", len(blobs))
+	}
+
+	pbRouter, _ := apis.NewRouter(app)
+	mux, _ := pbRouter.BuildMux()
+
+	for _, record := range records {
+		filename := record.GetStringSlice("file_many")[0]
+		url := "/api/files/" + demo1.Id + "/" + record.Id + "/" + filename
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+
+		if recorder.Code != 200 {
+			t.Fatalf("Expected status 200, got %d", recorder.Code)
+		}
+
+		if !bytes.Equal(recorder.Body.Bytes(), imgContent) {
+			t.Fatal("Expected the original file content to be served")
+		}
+
+		disposition := recorder.Header().Get("Content-Disposition")
+		if !strings.HasSuffix(disposition, "filename="+filename) {
+			t.Fatalf("Expected served name %q, got %q", filename, disposition)
+		}
+
+		// thumbs are still stored per record
+		recorder = httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest("GET", url+"?thumb=100x100", nil))
+
+		if recorder.Code != 200 {
+			t.Fatalf("Expected thumb status 200, got %d", recorder.Code)
+		}
+
+		thumbKey := record.BaseFilesPath() + "/thumbs_" + filename + "/100x100_" + filename
+		if exists, _ := fsys.Exists(thumbKey); !exists {
+			t.Fatalf("Missing thumb %q", thumbKey)
+		}
+	}
+}
@@ -0,0 +1,1054 @@
+package apis
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,expiration,termination"
+
+	// ResumableUploadValuePrefix is the prefix of a file field value
+	// that references a completed resumable upload (eg. "upload:abc...").
+	ResumableUploadValuePrefix = "upload:"
+
+	// ResumableUploadSecretHeader is the name of the header with the
+	// secret of a guest upload.
+	//
+	// The secret is returned only in the upload creation response and
+	// it is required for all subsequent guest upload requests.
+	ResumableUploadSecretHeader = "Upload-Secret"
+
+	// ResumableUploadMaxAge is the max duration after the upload creation
+	// for which the upload can be resumed or referenced.
+	ResumableUploadMaxAge = core.ResumableUploadMaxAge
+
+	resumableUploadInfoName = core.ResumableUploadInfoName
+)
+
+// bindFileUploadApi registers the tus compatible resumable upload api endpoints.
+//
+// The uploads are staged in the app storage (local or S3) and once
+// completed they could be assigned to a record file field by submitting
+// "upload:UPLOAD_ID" as the field value (or as part of its values list).
+//
+// The guest uploads are bound to a random secret that is returned only to
+// their creator (see [ResumableUploadSecretHeader]) and that must be
+// provided with every subsequent upload request and as part of the
+// record field value reference ("upload:UPLOAD_ID:UPLOAD_SECRET").
+//
+// Supported tus extensions: creation, expiration, termination.
+//
+// With S3 storage the file could be also uploaded directly to the bucket
+// with a presigned PUT URL (see [fileUploadApi.presign]).
+func bindFileUploadApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	api := fileUploadApi{}
+
+	sub := rg.Group("/uploads")
+	sub.OPTIONS("", api.options)
+	sub.POST("", api.create)
+	sub.POST("/presigned", api.presign)
+	sub.HEAD("/{id}", api.head)
+	sub.PATCH("/{id}", api.patch).Unbind(DefaultBodyLimitMiddlewareId) // the body limit is inlined
+	sub.DELETE("/{id}", api.delete)
+}
+
+type fileUploadApi struct {
+	// locks prevents concurrent writes to the same upload
+	locks sync.Map
+}
+
+// tryLock acquires the write lock of the specified upload.
+//
+// Returns nil if the upload is already locked by another request.
+func (api *fileUploadApi) tryLock(id string) (unlock func()) {
+	for {
+		v, _ := api.locks.LoadOrStore(id, &sync.Mutex{})
+
+		mu := v.(*sync.Mutex)
+		if !mu.TryLock() {
+			return nil
+		}
+
+		// the mutex could have been already released and removed
+		// by its previous holder after our LoadOrStore call
+		if current, ok := api.locks.Load(id); !ok || current != v {
+			mu.Unlock()
+			continue
+		}
+
+		return func() {
+			// remove the mutex while still holding it so that the requests
+			// that have loaded the same mutex could detect the removal
+			api.locks.Delete(id)
+			mu.Unlock()
+		}
+	}
+}
+
+// resumableUpload holds the state of a single resumable upload.
+type resumableUpload struct {
+	Id               string    `json:"id"`
+	CollectionId     string    `json:"collectionId"`
+	RecordId         string    `json:"recordId"` // empty for new records
+	FieldName        string    `json:"fieldName"`
+	Filename         string    `json:"filename"`
+	AuthCollectionId string    `json:"authCollectionId"`
+	AuthId           string    `json:"authId"`
+	SecretHash       string    `json:"secretHash,omitempty"` // the SHA256 hash of the guest upload secret
+	Size             int64     `json:"size"`
+	Offset           int64     `json:"offset"`
+	Chunks           []int64   `json:"chunks"` // the sizes of the stored chunks in order
+	Created          time.Time `json:"created"`
+
+	// Presigned indicates that the file is uploaded directly to
+	// the storage as a single object (see [resumableUpload.objectKey]).
+	Presigned   bool   `json:"presigned,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+
+	// secret is the plain guest upload secret (available only on creation)
+	secret string
+}
+
+func (u *resumableUpload) dir() string {
+	return resumableUploadDir(u.Id)
+}
+
+func (u *resumableUpload) chunkKey(offset int64) string {
+	return u.dir() + fmt.Sprintf("%020d", offset)
+}
+
+func (u *resumableUpload) objectKey() string {
+	return u.dir() + "file"
+}
+
+func (u *resumableUpload) expires() time.Time {
+	return u.Created.Add(ResumableUploadMaxAge)
+}
+
+func (u *resumableUpload) isCompleted() bool {
+	return u.Offset == u.Size
+}
+
+// canAccess reports whether the request auth state is allowed to access the upload.
+//
+// The guest uploads are accessible only with their creation secret.
+func (u *resumableUpload) canAccess(e *core.RequestEvent, secret string) bool {
+	if e.HasSuperuserAuth() {
+		return true
+	}
+
+	if u.AuthId == "" {
+		return u.SecretHash != "" && secret != "" && security.Equal(u.SecretHash, security.SHA256(secret))
+	}
+
+	return e.Auth != nil && e.Auth.Id == u.AuthId && e.Auth.Collection().Id == u.AuthCollectionId
+}
+
+func resumableUploadDir(id string) string {
+	return core.StorageUploadsDirName + "/" + id + "/"
+}
+
+// -------------------------------------------------------------------
+
+func (api *fileUploadApi) options(e *core.RequestEvent) error {
+	e.Response.Header().Set("Tus-Resumable", tusVersion)
+	e.Response.Header().Set("Tus-Version", tusVersion)
+	e.Response.Header().Set("Tus-Extension", tusExtensions)
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func (api *fileUploadApi) create(e *core.RequestEvent) error {
+	if err := checkTusResumable(e); err != nil {
+		return err
+	}
+
+	size, err := strconv.ParseInt(e.Request.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		return e.BadRequestError("Missing or invalid Upload-Length header.", err)
+	}
+
+	metadata, err := parseTusMetadata(e.Request.Header.Get("Upload-Metadata"))
+	if err != nil {
+		return e.BadRequestError("Invalid Upload-Metadata header.", err)
+	}
+
+	upload, _, err := newResumableUpload(e, metadata["collection"], metadata["record"], metadata["field"], metadata["filename"], size)
+	if err != nil {
+		return err
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Filesystem initialization failure.", err)
+	}
+	defer fsys.Close()
+
+	if err := saveResumableUpload(fsys, upload); err != nil {
+		return e.InternalServerError("Failed to create upload.", err)
+	}
+
+	setTusResponseHeaders(e, upload)
+	e.Response.Header().Set("Location", strings.TrimSuffix(e.Request.URL.Path, "/")+"/"+upload.Id)
+	if upload.secret != "" {
+		e.Response.Header().Set(ResumableUploadSecretHeader, upload.secret)
+	}
+
+	return e.NoContent(http.StatusCreated)
+}
+
+func (api *fileUploadApi) head(e *core.RequestEvent) error {
+	if err := checkTusResumable(e); err != nil {
+		return err
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Filesystem initialization failure.", err)
+	}
+	defer fsys.Close()
+
+	upload, err := findResumableUpload(fsys, e.Request.PathValue("id"))
+	if err != nil || upload.Presigned || !upload.canAccess(e, e.Request.Header.Get(ResumableUploadSecretHeader)) {
+		return e.NotFoundError("", err)
+	}
+
+	setTusResponseHeaders(e, upload)
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	return e.NoContent(http.StatusOK)
+}
+
+func (api *fileUploadApi) patch(e *core.RequestEvent) error {
+	if err := checkTusResumable(e); err != nil {
+		return err
+	}
+
+	if e.Request.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		return e.Error(http.StatusUnsupportedMediaType, "The request Content-Type must be application/offset+octet-stream.", nil)
+	}
+
+	offset, err := strconv.ParseInt(e.Request.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return e.BadRequestError("Missing or invalid Upload-Offset header.", err)
+	}
+
+	id := e.Request.PathValue("id")
+
+	unlock := api.tryLock(id)
+	if unlock == nil {
+		return e.Error(http.StatusLocked, "The upload is currently being written.", nil)
+	}
+	defer unlock()
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Filesystem initialization failure.", err)
+	}
+	defer fsys.Close()
+
+	upload, err := findResumableUpload(fsys, id)
+	if err != nil || upload.Presigned || !upload.canAccess(e, e.Request.Header.Get(ResumableUploadSecretHeader)) {
+		return e.NotFoundError("", err)
+	}
+
+	if offset != upload.Offset {
+		return e.Error(http.StatusConflict, "The Upload-Offset header doesn't match the current upload offset.", nil)
+	}
+
+	if upload.isCompleted() {
+		setTusResponseHeaders(e, upload)
+		return e.NoContent(http.StatusNoContent)
+	}
+
+	remaining := upload.Size - upload.Offset
+	if e.Request.ContentLength > remaining {
+		return ErrRequestEntityTooLarge
+	}
+
+	// stream the chunk to the storage
+	//
+	// note: on client disconnect we still keep the partially received data
+	// so that the upload could be resumed from the last written byte
+	// ---
+	written, writeErr := fsys.UploadReader(io.LimitReader(e.Request.Body, remaining), upload.chunkKey(upload.Offset))
+	if written > 0 {
+		upload.Offset += written
+		upload.Chunks = append(upload.Chunks, written)
+
+		if err := saveResumableUpload(fsys, upload); err != nil {
+			return e.InternalServerError("Failed to update upload.", err)
+		}
+	}
+	if writeErr != nil && written == 0 {
+		return e.BadRequestError("Failed to write the upload chunk.", writeErr)
+	}
+
+	// validate the completed upload against the file field options
+	if upload.isCompleted() {
+		if err := validateCompletedResumableUpload(e.App, upload); err != nil {
+			deleteErr := fsys.DeletePrefix(upload.dir())
+			if len(deleteErr) > 0 {
+				e.App.Logger().Warn("Failed to delete invalid upload", slog.Any("error", errors.Join(deleteErr...)))
+			}
+
+			return e.BadRequestError("The completed upload doesn't satisfy the file field constraints.", validation.Errors{
+				upload.FieldName: err,
+			})
+		}
+	}
+
+	setTusResponseHeaders(e, upload)
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func (api *fileUploadApi) delete(e *core.RequestEvent) error {
+	if err := checkTusResumable(e); err != nil {
+		return err
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Filesystem initialization failure.", err)
+	}
+	defer fsys.Close()
+
+	id := e.Request.PathValue("id")
+
+	unlock := api.tryLock(id)
+	if unlock == nil {
+		return e.Error(http.StatusLocked, "The upload is currently being written.", nil)
+	}
+	defer unlock()
+
+	upload, err := findResumableUpload(fsys, id)
+	if err != nil || !upload.canAccess(e, e.Request.Header.Get(ResumableUploadSecretHeader)) {
+		return e.NotFoundError("", err)
+	}
+
+	if errs := fsys.DeletePrefix(upload.dir()); len(errs) > 0 {
+		return e.InternalServerError("Failed to delete upload.", errors.Join(errs...))
+	}
+
+	e.Response.Header().Set("Tus-Resumable", tusVersion)
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// presignedUploadExpiry is the max duration for starting a presigned upload after the slot creation.
+const presignedUploadExpiry = 15 * time.Minute
+
+type presignForm struct {
+	Collection  string `form:"collection" json:"collection"`
+	Record      string `form:"record" json:"record"`
+	Field       string `form:"field" json:"field"`
+	Filename    string `form:"filename" json:"filename"`
+	ContentType string `form:"contentType" json:"contentType"`
+	Size        int64  `form:"size" json:"size"`
+}
+
+// presign creates a new upload slot for a direct S3 upload.
+//
+// The client is expected to upload the file content with a PUT
+// request to the returned url and headers and then to reference
+// the upload in a record create/update as "upload:UPLOAD_ID".
+//
+// Similar to the tus uploads, the slot creation is checked against the
+// collection CreateRule (or UpdateRule if "record" is specified).
+func (api *fileUploadApi) presign(e *core.RequestEvent) error {
+	form := &presignForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if form.Size <= 0 {
+		return e.BadRequestError("Missing or invalid file size.", nil)
+	}
+
+	upload, fileField, err := newResumableUpload(e, form.Collection, form.Record, form.Field, form.Filename, form.Size)
+	if err != nil {
+		return err
+	}
+
+	contentType, _, _ := strings.Cut(form.ContentType, ";")
+	contentType = strings.TrimSpace(contentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if len(fileField.MimeTypes) > 0 && !slices.Contains(fileField.MimeTypes, contentType) {
+		return e.BadRequestError("", validation.Errors{
+			"contentType": validation.NewError(
+				"validation_invalid_mime_type",
+				fmt.Sprintf("The content type must be one of: %s.", strings.Join(fileField.MimeTypes, ", ")),
+			),
+		})
+	}
+
+	upload.Presigned = true
+	upload.ContentType = contentType
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Filesystem initialization failure.", err)
+	}
+	defer fsys.Close()
+
+	url, err := fsys.SignedURL(upload.objectKey(), &blob.SignedURLOptions{
+		Method:      http.MethodPut,
+		Expiry:      presignedUploadExpiry,
+		ContentType: contentType,
+	})
+	if err != nil {
+		if errors.Is(err, blob.ErrUnsupported) {
+			return e.BadRequestError("Presigned uploads are supported only with S3 storage.", err)
+		}
+		return e.InternalServerError("Failed to generate presigned upload url.", err)
+	}
+
+	if err := saveResumableUpload(fsys, upload); err != nil {
+		return e.InternalServerError("Failed to create upload.", err)
+	}
+
+	value := ResumableUploadValuePrefix + upload.Id
+	if upload.secret != "" {
+		value += ":" + upload.secret
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"id":      upload.Id,
+		"secret":  upload.secret,
+		"value":   value,
+		"method":  http.MethodPut,
+		"url":     url,
+		"headers": map[string]string{"Content-Type": contentType},
+		"expires": types.NowDateTime().Add(presignedUploadExpiry),
+	})
+}
+
+// -------------------------------------------------------------------
+
+// newResumableUpload validates the upload target and returns a new initialized upload state.
+//
+// If recordId is set, the upload could be used only for updating the specified
+// record and it is checked against the collection UpdateRule.
+// Otherwise the upload could be used only for new records and it is checked
+// against the collection CreateRule (evaluated with the request auth state
+// and an empty record data because the record fields are not known yet).
+//
+// The upload creation is also checked against the collection "create"
+// (or "update" if recordId is set) rate limit rules.
+//
+// For guest uploads a new random secret is generated and stored as hash
+// (the plain secret is available only in the returned upload state).
+func newResumableUpload(e *core.RequestEvent, collectionNameOrId, recordId, fieldName, filename string, size int64) (*resumableUpload, *core.FileField, error) {
+	if filename == "" {
+		return nil, nil, e.BadRequestError("Missing filename.", nil)
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(collectionNameOrId)
+	if err != nil || collection.IsView() {
+		return nil, nil, e.BadRequestError("Missing or invalid collection.", err)
+	}
+
+	fileField, ok := collection.Fields.GetByName(fieldName).(*core.FileField)
+	if !ok {
+		return nil, nil, e.BadRequestError("Missing or invalid file field.", nil)
+	}
+
+	rateLimitTag := "create"
+	if recordId != "" {
+		rateLimitTag = "update"
+	}
+	if err := checkCollectionRateLimit(e, collection, rateLimitTag); err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkResumableUploadAccess(e, collection, recordId); err != nil {
+		return nil, nil, err
+	}
+
+	maxSize := fileField.MaxSize
+	if maxSize <= 0 {
+		maxSize = core.DefaultFileFieldMaxSize
+	}
+	if size > maxSize {
+		return nil, nil, e.Error(
+			http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("The upload size must be less than %d bytes.", maxSize),
+			nil,
+		)
+	}
+
+	upload := &resumableUpload{
+		Id:           security.RandomString(40),
+		CollectionId: collection.Id,
+		RecordId:     recordId,
+		FieldName:    fileField.Name,
+		Filename:     filename,
+		Size:         size,
+		Chunks:       []int64{},
+		Created:      time.Now().UTC().Truncate(time.Second),
+	}
+	if e.Auth != nil {
+		upload.AuthId = e.Auth.Id
+		upload.AuthCollectionId = e.Auth.Collection().Id
+	} else {
+		upload.secret = security.RandomString(40)
+		upload.SecretHash = security.SHA256(upload.secret)
+	}
+
+	return upload, fileField, nil
+}
+
+// checkResumableUploadAccess checks whether the request auth state is allowed
+// to upload files for the specified collection record
+// (or for a new record if recordId is empty).
+func checkResumableUploadAccess(e *core.RequestEvent, collection *core.Collection, recordId string) error {
+	if e.HasSuperuserAuth() {
+		return nil
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	// existing record update
+	if recordId != "" {
+		if collection.UpdateRule == nil {
+			return e.ForbiddenError("Only superusers can upload files to the specified collection.", nil)
+		}
+
+		record, err := e.App.FindRecordById(collection, recordId)
+		if err != nil {
+			return e.NotFoundError("Missing upload record.", err)
+		}
+
+		if ok, _ := e.App.CanAccessRecord(record, requestInfo, collection.UpdateRule); !ok {
+			return e.ForbiddenError("The request doesn't satisfy the collection update rule.", nil)
+		}
+
+		return nil
+	}
+
+	// new record
+	if collection.CreateRule == nil {
+		return e.ForbiddenError("Only superusers can upload files to the specified collection.", nil)
+	}
+
+	if *collection.CreateRule == "" {
+		return nil
+	}
+
+	// the request body is not part of the upload request
+	requestInfo.Body = map[string]any{}
+
+	dummyCollection, newDummyQuery, err := newCreateRuleDummyQuery(e.App, collection, core.NewRecord(collection))
+	if err != nil {
+		return e.BadRequestError("Failed to check the collection create rule.", err)
+	}
+
+	ruleQuery := newDummyQuery()
+
+	resolver := core.NewRecordFieldResolver(e.App, dummyCollection, requestInfo, true)
+
+	expr, err := search.FilterData(*dummyCollection.CreateRule).BuildExpr(resolver)
+	if err != nil {
+		return e.ForbiddenError("The request doesn't satisfy the collection create rule.", err)
+	}
+	ruleQuery.AndWhere(expr)
+
+	if err := resolver.UpdateQuery(ruleQuery); err != nil {
+		return e.ForbiddenError("The request doesn't satisfy the collection create rule.", err)
+	}
+
+	var exists int
+	if err := ruleQuery.Limit(1).Row(&exists); err != nil || exists == 0 {
+		return e.ForbiddenError("The request doesn't satisfy the collection create rule.", err)
+	}
+
+	return nil
+}
+
+func checkTusResumable(e *core.RequestEvent) error {
+	if e.Request.Header.Get("Tus-Resumable") != tusVersion {
+		e.Response.Header().Set("Tus-Version", tusVersion)
+		return e.Error(http.StatusPreconditionFailed, "Unsupported or missing Tus-Resumable header.", nil)
+	}
+
+	return nil
+}
+
+func setTusResponseHeaders(e *core.RequestEvent, upload *resumableUpload) {
+	header := e.Response.Header()
+	header.Set("Tus-Resumable", tusVersion)
+	header.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	header.Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	header.Set("Upload-Expires", upload.expires().Format(http.TimeFormat))
+	header.Set("Access-Control-Expose-Headers", "Location,Tus-Resumable,Upload-Offset,Upload-Length,Upload-Expires,"+ResumableUploadSecretHeader)
+}
+
+// parseTusMetadata parses a tus Upload-Metadata header value
+// (comma separated list of "key base64Value" pairs).
+func parseTusMetadata(raw string) (map[string]string, error) {
+	result := map[string]string{}
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, encoded, _ := strings.Cut(pair, " ")
+
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q metadata value: %w", key, err)
+		}
+
+		result[key] = string(value)
+	}
+
+	return result, nil
+}
+
+func findResumableUpload(fsys *filesystem.System, id string) (*resumableUpload, error) {
+	if id == "" || strings.ContainsAny(id, "/\\.") {
+		return nil, errors.New("invalid upload id")
+	}
+
+	r, err := fsys.GetReader(resumableUploadDir(id) + resumableUploadInfoName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	upload := &resumableUpload{}
+	if err := json.NewDecoder(r).Decode(upload); err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(upload.expires()) {
+		return nil, errors.New("the upload has expired")
+	}
+
+	return upload, nil
+}
+
+func saveResumableUpload(fsys *filesystem.System, upload *resumableUpload) error {
+	raw, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	return fsys.Upload(raw, upload.dir()+resumableUploadInfoName)
+}
+
+// validateCompletedResumableUpload checks the completed upload against its file field size and mime type constraints.
+func validateCompletedResumableUpload(app core.App, upload *resumableUpload) error {
+	collection, err := app.FindCachedCollectionByNameOrId(upload.CollectionId)
+	if err != nil {
+		return err
+	}
+
+	fileField, ok := collection.Fields.GetByName(upload.FieldName).(*core.FileField)
+	if !ok {
+		return errors.New("missing upload file field")
+	}
+
+	file, err := newResumableUploadFile(app, upload)
+	if err != nil {
+		return err
+	}
+
+	maxSize := fileField.MaxSize
+	if maxSize <= 0 {
+		maxSize = core.DefaultFileFieldMaxSize
+	}
+
+	if err := validators.UploadedFileSize(maxSize)(file); err != nil {
+		return err
+	}
+
+	if len(fileField.MimeTypes) > 0 {
+		return validators.UploadedFileMimeType(fileField.MimeTypes)(file)
+	}
+
+	return nil
+}
+
+func newResumableUploadFile(app core.App, upload *resumableUpload) (*filesystem.File, error) {
+	reader := &resumableUploadReader{app: app, chunks: make([]resumableUploadChunk, len(upload.Chunks))}
+
+	var offset int64
+	for i, size := range upload.Chunks {
+		reader.chunks[i] = resumableUploadChunk{key: upload.chunkKey(offset), offset: offset, size: size}
+		offset += size
+	}
+
+	return filesystem.NewFileFromReader(reader, upload.Size, upload.Filename)
+}
+
+// newPresignedUploadFile checks the uploaded presigned upload object
+// (its size and content type) and returns a File referencing it.
+//
+// note: the object content is not streamed through the app and
+// on record save it is copied server-side to the record files storage path
+// (unless its content type doesn't match the detected one).
+func newPresignedUploadFile(app core.App, fsys *filesystem.System, upload *resumableUpload, fileField *core.FileField) (*filesystem.File, error) {
+	attrs, err := fsys.Attributes(upload.objectKey())
+	if err != nil {
+		return nil, errors.New("the upload is not completed")
+	}
+
+	maxSize := fileField.MaxSize
+	if maxSize <= 0 {
+		maxSize = core.DefaultFileFieldMaxSize
+	}
+
+	if attrs.Size <= 0 || attrs.Size > maxSize {
+		return nil, fmt.Errorf("the uploaded file size must be between 1 and %d bytes", maxSize)
+	}
+
+	contentType, _, _ := strings.Cut(attrs.ContentType, ";")
+	if contentType != upload.ContentType {
+		return nil, errors.New("the uploaded file content type doesn't match the requested one")
+	}
+
+	return filesystem.NewFileFromReader(
+		&filesystem.ObjectReader{NewSystem: app.NewFilesystem, Key: upload.objectKey()},
+		attrs.Size,
+		upload.Filename,
+	)
+}
+
+// resolveResumableUploads replaces the file field "upload:UPLOAD_ID"
+// (or "upload:UPLOAD_ID:UPLOAD_SECRET" for guest uploads) values
+// in data with the related completed resumable upload files.
+//
+// The resolved uploads are claimed by the current request until
+// [finalizeResumableUploads] is called (aka. an upload cannot
+// be attached to more than one record).
+func resolveResumableUploads(e *core.RequestEvent, record *core.Record, data map[string]any) error {
+	var fsys *filesystem.System
+
+	collection := record.Collection()
+
+	for _, field := range collection.Fields {
+		if field.Type() != core.FieldTypeFile {
+			continue
+		}
+
+		name := field.GetName()
+
+		raw, ok := data[name]
+		if !ok {
+			continue
+		}
+
+		var values []any
+		switch v := raw.(type) {
+		case []any:
+			values = slices.Clone(v)
+		case []string:
+			values = make([]any, len(v))
+			for i, s := range v {
+				values[i] = s
+			}
+		default:
+			values = []any{v}
+		}
+
+		var hasUploads bool
+
+		for i, v := range values {
+			str, ok := v.(string)
+			if !ok || !strings.HasPrefix(str, ResumableUploadValuePrefix) {
+				continue
+			}
+
+			if fsys == nil {
+				var err error
+				fsys, err = e.App.NewFilesystem()
+				if err != nil {
+					return err
+				}
+				defer fsys.Close()
+			}
+
+			// "upload:UPLOAD_ID" or "upload:UPLOAD_ID:UPLOAD_SECRET" (for guest uploads)
+			uploadId, secret, _ := strings.Cut(strings.TrimPrefix(str, ResumableUploadValuePrefix), ":")
+
+			// claim the upload before loading its state so that
+			// an already consumed (aka. deleted) upload is not reused
+			if !claimResumableUpload(e, uploadId) {
+				return fmt.Errorf("the %q upload is already being attached to another record", name)
+			}
+
+			upload, err := findResumableUpload(fsys, uploadId)
+			if err != nil ||
+				!upload.canAccess(e, secret) ||
+				upload.CollectionId != collection.Id ||
+				upload.FieldName != name ||
+				(record.IsNew() && upload.RecordId != "") ||
+				(!record.IsNew() && upload.RecordId != record.Id) {
+				return fmt.Errorf("missing or invalid %q upload reference", name)
+			}
+
+			var file *filesystem.File
+			if upload.Presigned {
+				file, err = newPresignedUploadFile(e.App, fsys, upload, field.(*core.FileField))
+			} else if upload.isCompleted() {
+				file, err = newResumableUploadFile(e.App, upload)
+			} else {
+				err = errors.New("the upload is not completed")
+			}
+			if err != nil {
+				return fmt.Errorf("invalid %q upload reference: %w", name, err)
+			}
+
+			values[i] = file
+			hasUploads = true
+		}
+
+		if hasUploads {
+			data[name] = values
+		}
+	}
+
+	return nil
+}
+
+const (
+	resumableUploadClaimsStoreKey   = "@resumableUploadClaims"
+	resumableUploadClaimsRequestKey = "resumableUploadClaims"
+)
+
+// claimResumableUpload marks the upload as being attached to a record by the current request.
+//
+// Returns false if the upload is already claimed by another request.
+func claimResumableUpload(e *core.RequestEvent, id string) bool {
+	claims := e.App.Store().GetOrSet(resumableUploadClaimsStoreKey, func() any {
+		return &sync.Map{}
+	}).(*sync.Map)
+
+	if _, loaded := claims.LoadOrStore(id, struct{}{}); loaded {
+		return false
+	}
+
+	ids, _ := e.Get(resumableUploadClaimsRequestKey).([]string)
+	e.Set(resumableUploadClaimsRequestKey, append(ids, id))
+
+	return true
+}
+
+// finalizeResumableUploads releases the resumable uploads claimed by the current request.
+//
+// If saved is true, the claimed uploads are deleted because they are
+// already copied as record files (in case of a transaction - after its commit).
+func finalizeResumableUploads(e *core.RequestEvent, saved bool) {
+	ids, _ := e.Get(resumableUploadClaimsRequestKey).([]string)
+	if len(ids) == 0 {
+		return
+	}
+	e.Set(resumableUploadClaimsRequestKey, nil)
+
+	claims, _ := e.App.Store().Get(resumableUploadClaimsStoreKey).(*sync.Map)
+	if claims == nil {
+		return
+	}
+
+	app := e.App
+
+	finalize := func(consumed bool) {
+		if consumed {
+			if err := deleteResumableUploads(app, ids); err != nil {
+				app.Logger().Warn("Failed to delete consumed resumable uploads", slog.Any("error", err))
+			}
+		}
+
+		for _, id := range ids {
+			claims.Delete(id)
+		}
+	}
+
+	if txInfo := app.TxInfo(); txInfo != nil && saved {
+		txInfo.OnComplete(func(txErr error) error {
+			finalize(txErr == nil)
+			return nil
+		})
+		return
+	}
+
+	finalize(saved)
+}
+
+func deleteResumableUploads(app core.App, ids []string) error {
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	var errs []error
+	for _, id := range ids {
+		errs = append(errs, fsys.DeletePrefix(resumableUploadDir(id))...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// -------------------------------------------------------------------
+
+type resumableUploadChunk struct {
+	key    string
+	offset int64
+	size   int64
+}
+
+var _ filesystem.FileReader = (*resumableUploadReader)(nil)
+
+// resumableUploadReader defines a FileReader from the staged resumable upload chunks.
+type resumableUploadReader struct {
+	app    core.App
+	chunks []resumableUploadChunk
+}
+
+// Open implements the [filesystem.FileReader] interface.
+func (r *resumableUploadReader) Open() (io.ReadSeekCloser, error) {
+	fsys, err := r.app.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+
+	var size int64
+	if len(r.chunks) > 0 {
+		last := r.chunks[len(r.chunks)-1]
+		size = last.offset + last.size
+	}
+
+	return &chunksReadSeekCloser{fsys: fsys, chunks: r.chunks, size: size}, nil
+}
+
+// chunksReadSeekCloser reads sequentially the chunks as a single file.
+type chunksReadSeekCloser struct {
+	fsys    *filesystem.System
+	chunks  []resumableUploadChunk
+	size    int64
+	pos     int64
+	current io.ReadSeekCloser
+}
+
+func (r *chunksReadSeekCloser) Read(p []byte) (int, error) {
+	for {
+		if r.pos >= r.size {
+			return 0, io.EOF
+		}
+
+		if r.current == nil {
+			if err := r.openChunkAt(r.pos); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := r.current.Read(p)
+		r.pos += int64(n)
+
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (r *chunksReadSeekCloser) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		pos = r.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if pos < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+
+	r.pos = pos
+
+	return pos, nil
+}
+
+func (r *chunksReadSeekCloser) Close() error {
+	var err error
+	if r.current != nil {
+		err = r.current.Close()
+		r.current = nil
+	}
+
+	return errors.Join(err, r.fsys.Close())
+}
+
+func (r *chunksReadSeekCloser) openChunkAt(pos int64) error {
+	for _, chunk := range r.chunks {
+		if pos >= chunk.offset && pos < chunk.offset+chunk.size {
+			reader, err := r.fsys.GetReader(chunk.key)
+			if err != nil {
+				return err
+			}
+
+			if rel := pos - chunk.offset; rel > 0 {
+				if _, err := reader.Seek(rel, io.SeekStart); err != nil {
+					reader.Close()
+					return err
+				}
+			}
+
+			r.current = reader
+
+			return nil
+		}
+	}
+
+	return io.EOF
+}
@@ -0,0 +1,55 @@
+package apis
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFileUploadApiTryLock(t *testing.T) {
+	t.Parallel()
+
+	api := &fileUploadApi{}
+
+	unlock := api.tryLock("test")
+	if unlock == nil {
+		t.Fatal("Expected the first lock to succeed")
+	}
+
+	if api.tryLock("test") != nil {
+		t.Fatal("Expected the second lock to fail while the first one is held")
+	}
+
+	if other := api.tryLock("other"); other == nil {
+		t.Fatal("Expected a lock for a different upload to succeed")
+	} else {
+		other()
+	}
+
+	unlock()
+
+	// concurrent lock attempts must never result in more than one holder
+	var holders atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				unlock := api.tryLock("test")
+				if unlock == nil {
+					continue
+				}
+
+				if n := holders.Add(1); n > 1 {
+					t.Errorf("Expected at most 1 lock holder, got %d", n)
+				}
+				holders.Add(-1)
+
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -27,10 +27,12 @@ import (
 func bindRecordCrudApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	subGroup := rg.Group("/collections/{collection}/records").Unbind(DefaultRateLimitMiddlewareId)
 	subGroup.GET("", recordsList)
+	subGroup.GET("/aggregate", recordsAggregate)
 	subGroup.GET("/{id}", recordView)
 	subGroup.POST("", recordCreate(true, nil)).Bind(dynamicCollectionBodyLimit(""))
 	subGroup.PATCH("/{id}", recordUpdate(true, nil)).Bind(dynamicCollectionBodyLimit(""))
 	subGroup.DELETE("/{id}", recordDelete(true, nil))
+	subGroup.GET("/{id}/delete-impact", recordDeleteImpact)
 }
 
 func recordsList(e *core.RequestEvent) error {
@@ -49,41 +51,11 @@ func recordsList(e *core.RequestEvent) error {
 		return firstApiError(err, e.BadRequestError("", err))
 	}
 
-	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
-		return e.ForbiddenError("Only superusers can perform this action.", nil)
-	}
-
-	// forbid users and guests to query special filter/sort fields
-	err = checkForSuperuserOnlyRuleFields(requestInfo)
+	searchProvider, err := newRecordsSearchProvider(e, collection, requestInfo)
 	if err != nil {
 		return err
 	}
 
-	query := e.App.RecordQuery(collection)
-
-	fieldsResolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
-
-	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil && *collection.ListRule != "" {
-		expr, err := search.FilterData(*collection.ListRule).BuildExpr(fieldsResolver)
-		if err != nil {
-			return err
-		}
-		query.AndWhere(expr)
-
-		// will be applied by the search provider right before executing the query
-		// fieldsResolver.UpdateQuery(query)
-	}
-
-	// hidden fields are searchable only by superusers
-	fieldsResolver.SetAllowHiddenFields(requestInfo.HasSuperuserAuth())
-
-	searchProvider := search.NewProvider(fieldsResolver).Query(query)
-
-	// use rowid when available to minimize the need of a covering index with the "id" field
-	if !collection.IsView() {
-		searchProvider.CountCol("_rowid_")
-	}
-
 	records := []*core.Record{}
 	result, err := searchProvider.ParseAndExec(e.Request.URL.Query().Encode(), &records)
 	if err != nil {
@@ -127,6 +99,188 @@ func recordsList(e *core.RequestEvent) error {
 	})
 }
 
+// recordsAggregate returns the grouped aggregates (count, sum, avg, min, max)
+// of the collection records matching the list rule and the request filter.
+func recordsAggregate(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	searchProvider, err := newRecordsSearchProvider(e, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	// unlike the filters, the groupBy and aggregate fields return
+	// the field values and they require extra access checks
+	if !requestInfo.HasSuperuserAuth() {
+		query := e.Request.URL.Query()
+
+		fields := strings.Split(query.Get(search.GroupByQueryParam), ",")
+		for _, agg := range search.ParseAggregatesFromString(query.Get(search.AggregateQueryParam)) {
+			fields = append(fields, agg.Field)
+		}
+
+		var hasEmail bool
+		for _, field := range fields {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+
+			isEmail, err := checkAggregateFieldAccess(e.App, collection, field)
+			if err != nil {
+				return e.BadRequestError("", err)
+			}
+			hasEmail = hasEmail || isEmail
+		}
+
+		// the auth record email is returned only if it is public or owned by the requester
+		if hasEmail {
+			if requestInfo.Auth != nil && requestInfo.Auth.Collection().Id == collection.Id {
+				searchProvider.AddFilter(search.FilterData(core.FieldNameEmailVisibility + " = true || id = @request.auth.id"))
+			} else {
+				searchProvider.AddFilter(search.FilterData(core.FieldNameEmailVisibility + " = true"))
+			}
+		}
+	}
+
+	result, err := searchProvider.ParseAndExecAggregate(e.Request.URL.Query().Encode())
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	event := new(core.RecordsAggregateRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Result = result
+
+	return e.App.OnRecordsAggregateRequest().Trigger(event, func(e *core.RecordsAggregateRequestEvent) error {
+		return execAfterSuccessTx(true, e.App, func() error {
+			return e.JSON(http.StatusOK, e.Result)
+		})
+	})
+}
+
+// checkAggregateFieldAccess checks whether the values of the provided
+// groupBy or aggregate field could be returned to a non-superuser.
+//
+// Relation paths are allowed only if all related collections have
+// public view rule and the auth collections email field is allowed only
+// for the main collection (in which case it returns true and it is up to the
+// caller to restrict the aggregated records to the ones with visible email).
+func checkAggregateFieldAccess(app core.App, collection *core.Collection, field string) (bool, error) {
+	props := strings.Split(field, ".")
+
+	current := collection
+
+	for i, prop := range props {
+		name, _, _ := strings.Cut(prop, ":")
+
+		f := current.Fields.GetByName(name)
+
+		// last prop
+		if i == len(props)-1 {
+			if current.IsAuth() && name == core.FieldNameEmail {
+				if current.Id != collection.Id {
+					return false, fmt.Errorf("the related auth collection email field %q is not allowed", field)
+				}
+				return true, nil
+			}
+			return false, nil
+		}
+
+		// the rest of the props are json path
+		if f != nil && (f.Type() == core.FieldTypeJSON || f.Type() == core.FieldTypeGeoPoint) {
+			return false, nil
+		}
+
+		var nextId string
+		if relField, ok := f.(*core.RelationField); ok && !relField.Junction {
+			nextId = relField.CollectionId
+		} else if f == nil {
+			if parts := strings.SplitN(name, "_via_", 2); len(parts) == 2 {
+				nextId = parts[0]
+			}
+		}
+
+		if nextId == "" {
+			return false, fmt.Errorf("unsupported aggregate field path %q", field)
+		}
+
+		next, err := app.FindCachedCollectionByNameOrId(nextId)
+		if err != nil {
+			return false, fmt.Errorf("failed to load the %q related collection", field)
+		}
+
+		if next.ViewRule == nil || *next.ViewRule != "" {
+			return false, fmt.Errorf("the %q related collection records must be publicly viewable", field)
+		}
+
+		current = next
+	}
+
+	return false, nil
+}
+
+// newRecordsSearchProvider checks the collection list rule access and returns
+// a new records search provider with the list rule applied (if any).
+func newRecordsSearchProvider(e *core.RequestEvent, collection *core.Collection, requestInfo *core.RequestInfo) (*search.Provider, error) {
+	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
+		return nil, e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	// forbid users and guests to query special filter/sort fields
+	err := checkForSuperuserOnlyRuleFields(requestInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	query := e.App.RecordQuery(collection)
+
+	fieldsResolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
+
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil && *collection.ListRule != "" {
+		expr, err := search.FilterData(*collection.ListRule).BuildExpr(fieldsResolver)
+		if err != nil {
+			return nil, err
+		}
+		query.AndWhere(expr)
+
+		// will be applied by the search provider right before executing the query
+		// fieldsResolver.UpdateQuery(query)
+	}
+
+	// hidden fields are searchable only by superusers
+	fieldsResolver.SetAllowHiddenFields(requestInfo.HasSuperuserAuth())
+
+	searchProvider := search.NewProvider(fieldsResolver).Query(query)
+
+	// use the persistent settings key so that the issued cursors
+	// remain valid after restart and between multiple app instances
+	if key := core.RecordsCursorKey(e.App); key != "" {
+		searchProvider.CursorKey(key)
+	}
+
+	// use rowid when available to minimize the need of a covering index with the "id" field
+	if !collection.IsView() {
+		searchProvider.CountCol("_rowid_")
+	}
+
+	return searchProvider, nil
+}
+
 var listTimingRateLimitRule = core.RateLimitRule{MaxRequests: 3, Duration: 3}
 
 func randomizedThrottle(softMax int64) {
@@ -234,6 +388,12 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 
 		record := core.NewRecord(collection)
 
+		// release (or delete if the record was saved) the claimed resumable uploads
+		var isSaved bool
+		defer func() {
+			finalizeResumableUploads(e, isSaved)
+		}()
+
 		data, err := recordDataFromRequest(e, record)
 		if err != nil {
 			return firstApiError(err, e.BadRequestError("Failed to read the submitted data.", err))
@@ -268,47 +428,16 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 
 		// check the request and record data against the create and manage rules
 		if !hasSuperuserAuth && collection.CreateRule != nil {
-			dummyRecord := record.Clone()
-
-			dummyRandomPart := "__pb_create__" + security.PseudorandomString(6)
-
-			// set an id if it doesn't have already
-			// (the value doesn't matter; it is used only to minimize the breaking changes with earlier versions)
-			if dummyRecord.Id == "" {
-				dummyRecord.Id = "__temp_id__" + dummyRandomPart
-			}
-
-			// unset the verified field to prevent manage API rule misuse in case the rule relies on it
-			dummyRecord.SetVerified(false)
-
-			// export the dummy record data into db params
-			dummyExport, err := dummyRecord.DBExport(e.App)
+			dummyCollection, newDummyQuery, err := newCreateRuleDummyQuery(e.App, collection, record)
 			if err != nil {
-				return e.BadRequestError("Failed to create record", fmt.Errorf("dummy DBExport error: %w", err))
-			}
-
-			dummyParams := make(dbx.Params, len(dummyExport))
-			selects := make([]string, 0, len(dummyExport))
-			var param string
-			for k, v := range dummyExport {
-				k = inflector.Columnify(k) // columnify is just as extra measure in case of custom fields
-				param = "__pb_create__" + k
-				dummyParams[param] = v
-				selects = append(selects, "{:"+param+"} AS [["+k+"]]")
+				return e.BadRequestError("Failed to create record", err)
 			}
 
-			// shallow clone the current collection
-			dummyCollection := *collection
-			dummyCollection.Id += dummyRandomPart
-			dummyCollection.Name += inflector.Columnify(dummyRandomPart)
-
-			withFrom := fmt.Sprintf("WITH {{%s}} as (SELECT %s)", dummyCollection.Name, strings.Join(selects, ","))
-
 			// check non-empty create rule
 			if *dummyCollection.CreateRule != "" {
-				ruleQuery := e.App.ConcurrentDB().Select("(1)").PreFragment(withFrom).From(dummyCollection.Name).AndBind(dummyParams)
+				ruleQuery := newDummyQuery()
 
-				resolver := core.NewRecordFieldResolver(e.App, &dummyCollection, requestInfo, true)
+				resolver := core.NewRecordFieldResolver(e.App, dummyCollection, requestInfo, true)
 
 				expr, err := search.FilterData(*dummyCollection.CreateRule).BuildExpr(resolver)
 				if err != nil {
@@ -329,9 +458,9 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			}
 
 			// check for manage rule access
-			manageRuleQuery := e.App.ConcurrentDB().Select("(1)").PreFragment(withFrom).From(dummyCollection.Name).AndBind(dummyParams)
+			manageRuleQuery := newDummyQuery()
 			if !form.HasManageAccess() &&
-				hasAuthManageAccess(e.App, requestInfo, &dummyCollection, manageRuleQuery) {
+				hasAuthManageAccess(e.App, requestInfo, dummyCollection, manageRuleQuery) {
 				form.GrantManagerAccess()
 			}
 		}
@@ -351,6 +480,7 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			if err != nil {
 				return firstApiError(err, e.BadRequestError("Failed to create record", err))
 			}
+			isSaved = true
 
 			err = EnrichRecord(e.RequestEvent, e.Record)
 			if err != nil {
@@ -389,6 +519,53 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 	}
 }
 
+// newCreateRuleDummyQuery returns a shallow copy of the collection with
+// a unique name and a factory for queries selecting from a CTE with the
+// provided not persisted record data (used for the create and manage rule checks).
+func newCreateRuleDummyQuery(app core.App, collection *core.Collection, record *core.Record) (*core.Collection, func() *dbx.SelectQuery, error) {
+	dummyRecord := record.Clone()
+
+	dummyRandomPart := "__pb_create__" + security.PseudorandomString(6)
+
+	// set an id if it doesn't have already
+	// (the value doesn't matter; it is used only to minimize the breaking changes with earlier versions)
+	if dummyRecord.Id == "" {
+		dummyRecord.Id = "__temp_id__" + dummyRandomPart
+	}
+
+	// unset the verified field to prevent manage API rule misuse in case the rule relies on it
+	dummyRecord.SetVerified(false)
+
+	// export the dummy record data into db params
+	dummyExport, err := dummyRecord.DBExport(app)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dummy DBExport error: %w", err)
+	}
+
+	dummyParams := make(dbx.Params, len(dummyExport))
+	selects := make([]string, 0, len(dummyExport))
+	var param string
+	for k, v := range dummyExport {
+		k = inflector.Columnify(k) // columnify is just as extra measure in case of custom fields
+		param = "__pb_create__" + k
+		dummyParams[param] = v
+		selects = append(selects, "{:"+param+"} AS [["+k+"]]")
+	}
+
+	// shallow clone the current collection
+	dummyCollection := *collection
+	dummyCollection.Id += dummyRandomPart
+	dummyCollection.Name += inflector.Columnify(dummyRandomPart)
+
+	withFrom := fmt.Sprintf("WITH {{%s}} as (SELECT %s)", dummyCollection.Name, strings.Join(selects, ","))
+
+	newQuery := func() *dbx.SelectQuery {
+		return app.ConcurrentDB().Select("(1)").PreFragment(withFrom).From(dummyCollection.Name).AndBind(dummyParams)
+	}
+
+	return &dummyCollection, newQuery, nil
+}
+
 func recordUpdate(responseWriteAfterTx bool, optFinalizer func(data any) error) func(e *core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
@@ -427,6 +604,12 @@ func recordUpdate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return firstApiError(err, e.NotFoundError("", err))
 		}
 
+		// release (or delete if the record was saved) the claimed resumable uploads
+		var isSaved bool
+		defer func() {
+			finalizeResumableUploads(e, isSaved)
+		}()
+
 		data, err := recordDataFromRequest(e, record)
 		if err != nil {
 			return firstApiError(err, e.BadRequestError("Failed to read the submitted data.", err))
@@ -490,6 +673,7 @@ func recordUpdate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			if err != nil {
 				return firstApiError(err, e.BadRequestError("Failed to update record.", err))
 			}
+			isSaved = true
 
 			err = EnrichRecord(e.RequestEvent, e.Record)
 			if err != nil {
@@ -626,6 +810,153 @@ func recordDelete(responseWriteAfterTx bool, optFinalizer func(data any) error)
 	}
 }
 
+// recordDeleteImpact returns a dry-run report with the records
+// that will be affected by the specified record delete.
+func recordDeleteImpact(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	if collection.IsView() {
+		return e.BadRequestError("Unsupported collection type.", nil)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "delete")
+	if err != nil {
+		return err
+	}
+
+	recordId := e.Request.PathValue("id")
+	if recordId == "" {
+		return e.NotFoundError("", nil)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	if !requestInfo.HasSuperuserAuth() && collection.DeleteRule == nil {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	ruleFunc := func(q *dbx.SelectQuery) error {
+		if !requestInfo.HasSuperuserAuth() && collection.DeleteRule != nil && *collection.DeleteRule != "" {
+			resolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
+
+			expr, err := search.FilterData(*collection.DeleteRule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+
+			q.AndWhere(expr)
+
+			err = resolver.UpdateQuery(q)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	record, err := e.App.FindRecordById(collection, recordId, ruleFunc)
+	if err != nil || record == nil {
+		return e.NotFoundError("", err)
+	}
+
+	impact, err := e.App.FindRecordDeleteImpact(record)
+	if err != nil {
+		return e.BadRequestError("Failed to load the record delete impact.", err)
+	}
+
+	// exclude the affected records that the requester is not allowed to view
+	// (only their total number is returned)
+	if !requestInfo.HasSuperuserAuth() {
+		impact.Deleted, impact.Hidden.Deleted, err = filterDeleteImpactItems(e.App, requestInfo, impact.Deleted)
+		if err != nil {
+			return e.BadRequestError("Failed to load the record delete impact.", err)
+		}
+
+		impact.Updated, impact.Hidden.Updated, err = filterDeleteImpactItems(e.App, requestInfo, impact.Updated)
+		if err != nil {
+			return e.BadRequestError("Failed to load the record delete impact.", err)
+		}
+
+		impact.Blocked, impact.Hidden.Blocked, err = filterDeleteImpactItems(e.App, requestInfo, impact.Blocked)
+		if err != nil {
+			return e.BadRequestError("Failed to load the record delete impact.", err)
+		}
+	}
+
+	return e.JSON(http.StatusOK, impact)
+}
+
+// filterDeleteImpactItems returns only the delete impact items whose
+// records satisfy their collection ViewRule and the number of the excluded ones.
+func filterDeleteImpactItems(
+	app core.App,
+	requestInfo *core.RequestInfo,
+	items []*core.RecordDeleteImpactItem,
+) ([]*core.RecordDeleteImpactItem, int, error) {
+	// group the item ids by their collection
+	collectionIds := map[string][]any{}
+	for _, item := range items {
+		collectionIds[item.CollectionId] = append(collectionIds[item.CollectionId], item.RecordId)
+	}
+
+	visible := map[string]struct{}{}
+
+	for collectionId, ids := range collectionIds {
+		collection, err := app.FindCachedCollectionByNameOrId(collectionId)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if collection.ViewRule == nil {
+			continue // superusers only
+		}
+
+		query := app.RecordQuery(collection).
+			Select(collection.Name + ".id").
+			AndWhere(dbx.In(collection.Name+".id", ids...))
+
+		if *collection.ViewRule != "" {
+			resolver := core.NewRecordFieldResolver(app, collection, requestInfo, true)
+
+			expr, err := search.FilterData(*collection.ViewRule).BuildExpr(resolver)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			query.AndWhere(expr)
+
+			err = resolver.UpdateQuery(query)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+
+		visibleIds := []string{}
+		if err := query.Column(&visibleIds); err != nil {
+			return nil, 0, err
+		}
+
+		for _, id := range visibleIds {
+			visible[collectionId+"/"+id] = struct{}{}
+		}
+	}
+
+	result := make([]*core.RecordDeleteImpactItem, 0, len(items))
+	for _, item := range items {
+		if _, ok := visible[item.CollectionId+"/"+item.RecordId]; ok {
+			result = append(result, item)
+		}
+	}
+
+	return result, len(items) - len(result), nil
+}
+
 // -------------------------------------------------------------------
 
 func recordDataFromRequest(e *core.RequestEvent, record *core.Record) (map[string]any, error) {
@@ -668,6 +999,12 @@ func recordDataFromRequest(e *core.RequestEvent, record *core.Record) (map[strin
 		result = record.ReplaceModifiers(result)
 	}
 
+	// resolve the completed resumable uploads references
+	err = resolveResumableUploads(e, record, result)
+	if err != nil {
+		return nil, err
+	}
+
 	isAuth := record.Collection().IsAuth()
 
 	// unset hidden fields for non-superusers
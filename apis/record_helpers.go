@@ -500,7 +500,7 @@ func autoResolveRecordsFlags(app core.App, records []*core.Record, requestInfo *
 	return nil
 }
 
-var ruleQueryParams = []string{search.FilterQueryParam, search.SortQueryParam}
+var ruleQueryParams = []string{search.FilterQueryParam, search.SortQueryParam, search.GroupByQueryParam, search.AggregateQueryParam}
 var superuserOnlyRuleFields = []string{"@collection.", "@request."}
 
 // checkForSuperuserOnlyRuleFields loosely checks and returns an error if
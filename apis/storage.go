@@ -0,0 +1,48 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// bindStorageApi registers the storage api endpoints and the corresponding handlers.
+func bindStorageApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	api := &storageApi{}
+
+	sub := rg.Group("/storage").Bind(RequireSuperuserAuth())
+	sub.GET("/migrate", api.migrateStatus)
+	sub.POST("/migrate", api.migrate)
+	sub.POST("/orphans", storageOrphans)
+}
+
+func storageOrphans(e *core.RequestEvent) error {
+	if e.App.Store().Has(core.StoreKeyActiveOrphansCleanup) {
+		return e.BadRequestError("Try again later - another orphaned files cleanup has already been started.", nil)
+	}
+
+	form := new(storageOrphansForm)
+
+	err := e.BindBody(form)
+	if err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	result, err := e.App.CleanupOrphanedFiles(e.Request.Context(), core.OrphanedFilesOptions{
+		DryRun: !form.Delete,
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to cleanup the orphaned files.", err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// -------------------------------------------------------------------
+
+type storageOrphansForm struct {
+	// Delete deletes the found orphaned files
+	// (by default they are only reported).
+	Delete bool `form:"delete" json:"delete"`
+}
@@ -0,0 +1,112 @@
+package apis
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+)
+
+type storageApi struct {
+	mu     sync.RWMutex
+	status storageMigrateStatus
+}
+
+// storageMigrateStatus defines the state of the current or last started storage migration.
+type storageMigrateStatus struct {
+	Progress *core.StorageMigrateProgress `json:"progress"`
+	Result   *core.StorageMigrateResult   `json:"result"`
+	Target   string                       `json:"target"`
+	Error    string                       `json:"error"`
+	Active   bool                         `json:"active"`
+}
+
+func (api *storageApi) migrateStatus(e *core.RequestEvent) error {
+	api.mu.RLock()
+	status := api.status
+	if status.Progress != nil {
+		progress := *status.Progress
+		status.Progress = &progress
+	}
+	api.mu.RUnlock()
+
+	return e.JSON(http.StatusOK, status)
+}
+
+func (api *storageApi) migrate(e *core.RequestEvent) error {
+	if e.App.Store().Has(core.StoreKeyActiveStorageMigration) {
+		return e.BadRequestError("Try again later - another storage migration has already been started.", nil)
+	}
+
+	form := new(storageMigrateForm)
+
+	err := e.BindBody(form)
+	if err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	err = form.validate()
+	if err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	api.mu.Lock()
+	if api.status.Active {
+		api.mu.Unlock()
+		return e.BadRequestError("Try again later - another storage migration has already been started.", nil)
+	}
+	api.status = storageMigrateStatus{Active: true, Target: form.To}
+	api.mu.Unlock()
+
+	app := e.App
+
+	routine.FireAndForget(func() {
+		result, err := app.MigrateStorage(context.Background(), core.StorageMigrateOptions{
+			Target:         form.To,
+			IncludeBackups: form.Backups,
+			SwitchSettings: form.Switch,
+			Force:          form.Force,
+			OnProgress: func(p core.StorageMigrateProgress) {
+				api.mu.Lock()
+				api.status.Progress = &p
+				api.mu.Unlock()
+			},
+		})
+
+		api.mu.Lock()
+		api.status.Active = false
+		api.status.Result = result
+		if err != nil {
+			api.status.Error = err.Error()
+		}
+		api.mu.Unlock()
+
+		if err != nil {
+			app.Logger().Error("Failed to migrate storage", "target", form.To, "error", err.Error())
+		}
+	})
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// -------------------------------------------------------------------
+
+type storageMigrateForm struct {
+	To      string `form:"to" json:"to"`
+	Backups bool   `form:"backups" json:"backups"`
+	Switch  bool   `form:"switch" json:"switch"`
+	Force   bool   `form:"force" json:"force"`
+}
+
+func (form *storageMigrateForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.To,
+			validation.Required,
+			validation.In(core.StorageTargetS3, core.StorageTargetLocal),
+		),
+	)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewEncryptionCommand creates and returns new command for managing
+// the encrypted collection fields (eg. key rotation).
+func NewEncryptionCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "encryption",
+		Short: "Manage the encrypted collection fields",
+	}
+
+	command.AddCommand(encryptionRotateCommand(app))
+
+	return command
+}
+
+func encryptionRotateCommand(app core.App) *cobra.Command {
+	var oldKeyEnv string
+	var collectionNameOrId string
+
+	command := &cobra.Command{
+		Use:          "rotate",
+		Example:      "encryption rotate --oldKeyEnv=OLD_ENCRYPTION_KEY",
+		Short:        "Re-encrypts all encrypted fields values from the old key to the current field key",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if oldKeyEnv == "" {
+				return errors.New("missing --oldKeyEnv flag")
+			}
+
+			oldKey := os.Getenv(oldKeyEnv)
+			if len(oldKey) != 32 {
+				return fmt.Errorf("the %q environment variable must be set with the old 32 characters encryption key", oldKeyEnv)
+			}
+
+			var collections []*core.Collection
+			if collectionNameOrId != "" {
+				collection, err := app.FindCollectionByNameOrId(collectionNameOrId)
+				if err != nil {
+					return fmt.Errorf("failed to fetch collection %q: %w", collectionNameOrId, err)
+				}
+				collections = append(collections, collection)
+			} else {
+				var err error
+				collections, err = app.FindAllCollections(core.CollectionTypeBase, core.CollectionTypeAuth)
+				if err != nil {
+					return fmt.Errorf("failed to fetch collections: %w", err)
+				}
+			}
+
+			for _, collection := range collections {
+				for _, field := range collection.Fields {
+					encrypted, ok := field.(*core.EncryptedField)
+					if !ok {
+						continue
+					}
+
+					total, err := encrypted.RotateKey(app, collection, oldKey)
+					if err != nil {
+						return fmt.Errorf("failed to rotate %s.%s: %w", collection.Name, encrypted.Name, err)
+					}
+
+					color.Green("Successfully re-encrypted %d %s.%s value(s)!", total, collection.Name, encrypted.Name)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().StringVar(
+		&oldKeyEnv,
+		"oldKeyEnv",
+		"",
+		"the name of the environment variable that holds the old encryption key",
+	)
+
+	command.PersistentFlags().StringVar(
+		&collectionNameOrId,
+		"collection",
+		"",
+		"optional collection name or id to limit the rotation to",
+	)
+
+	return command
+}
@@ -0,0 +1,95 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestEncryptionRotateCommand(t *testing.T) {
+	oldKey := strings.Repeat("a", 32)
+	newKey := strings.Repeat("b", 32)
+
+	t.Setenv("PB_TEST_FIELD_KEY", oldKey)
+	t.Setenv("PB_TEST_OLD_FIELD_KEY", oldKey)
+	t.Setenv("PB_TEST_SHORT_FIELD_KEY", "abc")
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_encrypted")
+	collection.Fields.Add(&core.EncryptedField{Name: "secret", KeyEnv: "PB_TEST_FIELD_KEY"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("secret", "test")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PB_TEST_FIELD_KEY", newKey)
+
+	scenarios := []struct {
+		name        string
+		args        []string
+		expectError bool
+	}{
+		{
+			"missing old key env flag",
+			[]string{"rotate"},
+			true,
+		},
+		{
+			"invalid old key",
+			[]string{"rotate", "--oldKeyEnv=PB_TEST_SHORT_FIELD_KEY"},
+			true,
+		},
+		{
+			"missing collection",
+			[]string{"rotate", "--oldKeyEnv=PB_TEST_OLD_FIELD_KEY", "--collection=missing"},
+			true,
+		},
+		{
+			"valid old key",
+			[]string{"rotate", "--oldKeyEnv=PB_TEST_OLD_FIELD_KEY"},
+			false,
+		},
+		{
+			"valid old key with collection filter (rerun)",
+			[]string{"rotate", "--oldKeyEnv=PB_TEST_OLD_FIELD_KEY", "--collection=test_encrypted"},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			command := cmd.NewEncryptionCommand(app)
+			command.SetArgs(s.args)
+
+			err := command.Execute()
+
+			hasErr := err != nil
+			if s.expectError != hasErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			// the record should be decryptable with the new key
+			fresh, err := app.FindRecordById(collection, record.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v := fresh.GetString("secret"); v != "test" {
+				t.Fatalf("Expected secret %q, got %q", "test", v)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewStorageCommand creates and returns new command for managing
+// the app storage (eg. migrating files between local and S3,
+// cleaning up the orphaned files, rebuilding the storage usage, etc.).
+func NewStorageCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "storage",
+		Short: "Manage the app files storage",
+	}
+
+	command.AddCommand(storageMigrateCommand(app))
+	command.AddCommand(storageOrphansCommand(app))
+	command.AddCommand(storageUsageCommand(app))
+
+	return command
+}
+
+func storageMigrateCommand(app core.App) *cobra.Command {
+	var target string
+	var includeBackups bool
+	var switchSettings bool
+	var force bool
+
+	command := &cobra.Command{
+		Use:          "migrate",
+		Example:      "storage migrate --to=s3 --backups --switch",
+		Short:        "Copies all stored files between the local filesystem and the configured S3 storage",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if target != core.StorageTargetS3 && target != core.StorageTargetLocal {
+				return errors.New("the --to flag must be either s3 or local")
+			}
+
+			result, err := app.MigrateStorage(command.Context(), core.StorageMigrateOptions{
+				Target:         target,
+				IncludeBackups: includeBackups,
+				SwitchSettings: switchSettings,
+				Force:          force,
+				OnProgress: func(p core.StorageMigrateProgress) {
+					line := fmt.Sprintf("[%s %d/%d] %s %s", p.Section, p.Processed(), p.Total, p.Status, p.Key)
+					switch p.Status {
+					case "failed":
+						color.Red("%s (%v)", line, p.Error)
+					case "skipped":
+						fmt.Println(line)
+					default:
+						color.Green(line)
+					}
+				},
+			})
+
+			if result != nil {
+				printStorageMigrateStats(core.StorageMigrateSectionFiles, result.Files)
+				if result.Backups != nil {
+					printStorageMigrateStats(core.StorageMigrateSectionBackups, *result.Backups)
+				}
+			}
+
+			if err != nil {
+				return fmt.Errorf("storage migration failed (run the command again to resume it): %w", err)
+			}
+
+			if result.SettingsSwitched {
+				color.Green("Successfully migrated the storage and switched the settings to %s!", target)
+			} else {
+				color.Green("Successfully migrated the storage to %s!", target)
+			}
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().StringVar(
+		&target,
+		"to",
+		"",
+		"the target storage (s3 or local)",
+	)
+
+	command.PersistentFlags().BoolVar(
+		&includeBackups,
+		"backups",
+		false,
+		"copy also the app backups",
+	)
+
+	command.PersistentFlags().BoolVar(
+		&switchSettings,
+		"switch",
+		false,
+		"enable/disable the S3 settings after successful migration",
+	)
+
+	command.PersistentFlags().BoolVar(
+		&force,
+		"force",
+		false,
+		"copy again the files that already exist in the target storage",
+	)
+
+	return command
+}
+
+func printStorageMigrateStats(section string, stats core.StorageMigrateStats) {
+	fmt.Printf(
+		"%s: %d total, %d copied (%d bytes), %d skipped, %d failed\n",
+		section,
+		stats.Total,
+		stats.Copied,
+		stats.Bytes,
+		stats.Skipped,
+		stats.Failed,
+	)
+}
+
+func storageOrphansCommand(app core.App) *cobra.Command {
+	var del bool
+	var minAge time.Duration
+
+	command := &cobra.Command{
+		Use:          "orphans",
+		Example:      "storage orphans --delete --minAge=24h",
+		Short:        "Reports (or deletes) the stored files that are not referenced by any record",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if minAge <= 0 {
+				minAge = -1 // check all files
+			}
+
+			result, err := app.CleanupOrphanedFiles(command.Context(), core.OrphanedFilesOptions{
+				DryRun: !del,
+				MinAge: minAge,
+				OnOrphan: func(file core.OrphanedFile) {
+					if file.Deleted {
+						color.Green("deleted %s (%d bytes)", file.Key, file.Size)
+					} else {
+						fmt.Printf("orphan %s (%d bytes)\n", file.Key, file.Size)
+					}
+				},
+			})
+
+			if result != nil {
+				fmt.Printf(
+					"%d scanned, %d orphaned (%d bytes), %d deleted, %d failed\n",
+					result.Scanned,
+					len(result.Orphans),
+					result.Bytes,
+					result.Deleted,
+					len(result.FailedKeys),
+				)
+			}
+
+			if err != nil {
+				return fmt.Errorf("orphaned files cleanup failed: %w", err)
+			}
+
+			if !del && len(result.Orphans) > 0 {
+				color.Yellow("Dry run - rerun the command with the --delete flag to delete the orphaned files.")
+			}
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().BoolVar(
+		&del,
+		"delete",
+		false,
+		"delete the found orphaned files (by default only reports them)",
+	)
+
+	command.PersistentFlags().DurationVar(
+		&minAge,
+		"minAge",
+		core.DefaultOrphanedFilesMinAge,
+		"ignore the files modified more recently than the specified duration (0 to check all files)",
+	)
+
+	return command
+}
+
+func storageUsageCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:          "usage [collections...]",
+		Example:      "storage usage posts documents",
+		Short:        "Rebuilds the storage usage of the specified collections (or of all collections with storage quotas)",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				for _, q := range app.Settings().Storage.Quotas {
+					if !slices.Contains(args, q.Collection) {
+						args = append(args, q.Collection)
+					}
+				}
+
+				if len(args) == 0 {
+					color.Yellow("There are no collections with storage quotas.")
+					return nil
+				}
+			}
+
+			for _, nameOrId := range args {
+				collection, err := app.FindCollectionByNameOrId(nameOrId)
+				if err != nil {
+					return fmt.Errorf("failed to find collection %q: %w", nameOrId, err)
+				}
+
+				err = app.RebuildStorageUsage(command.Context(), collection)
+				if err != nil {
+					return fmt.Errorf("failed to rebuild the %q collection storage usage: %w", collection.Name, err)
+				}
+
+				usage, err := app.FindStorageUsage(collection.Id, "")
+				if err != nil {
+					color.Green("Successfully rebuilt the %q collection storage usage (no tracked files).", collection.Name)
+				} else {
+					color.Green("Successfully rebuilt the %q collection storage usage (%d files, %d bytes).", collection.Name, usage.Files(), usage.Size())
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return command
+}
@@ -0,0 +1,185 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestStorageMigrateCommand(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name        string
+		args        []string
+		expectError bool
+	}{
+		{
+			"missing target flag",
+			[]string{"migrate"},
+			true,
+		},
+		{
+			"invalid target flag",
+			[]string{"migrate", "--to=invalid"},
+			true,
+		},
+		{
+			"missing S3 configuration",
+			[]string{"migrate", "--to=s3"},
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			command := cmd.NewStorageCommand(app)
+			command.SetArgs(s.args)
+
+			err := command.Execute()
+
+			hasErr := err != nil
+			if s.expectError != hasErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestStorageOrphansCommand(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	const orphanKey = "wsmn24bux7wo113/84nmscqy84lsi1t/orphan.txt"
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	if err := fsys.Upload([]byte("test"), orphanKey); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		args         []string
+		expectExists bool
+	}{
+		{
+			"dry run with default min age",
+			[]string{"orphans"},
+			true,
+		},
+		{
+			"delete with default min age",
+			[]string{"orphans", "--delete"},
+			true,
+		},
+		{
+			"dry run without min age",
+			[]string{"orphans", "--minAge=0"},
+			true,
+		},
+		{
+			"delete without min age",
+			[]string{"orphans", "--delete", "--minAge=0"},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			command := cmd.NewStorageCommand(app)
+			command.SetArgs(s.args)
+
+			if err := command.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			exists, err := fsys.Exists(orphanKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if exists != s.expectExists {
+				t.Fatalf("Expected orphan exists %v, got %v", s.expectExists, exists)
+			}
+		})
+	}
+}
+
+func TestStorageUsageCommand(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name          string
+		args          []string
+		quotas        []core.StorageQuota
+		expectError   bool
+		expectedFiles int
+	}{
+		{
+			"missing collection",
+			[]string{"usage", "missing"},
+			nil,
+			true,
+			0,
+		},
+		{
+			"no collections with quotas",
+			[]string{"usage"},
+			nil,
+			false,
+			0,
+		},
+		{
+			"all collections with quotas",
+			[]string{"usage"},
+			[]core.StorageQuota{{Collection: "demo1", MaxSize: 1 << 20}},
+			false,
+			7,
+		},
+		{
+			"explicit collection",
+			[]string{"usage", demo1.Id},
+			[]core.StorageQuota{{Collection: "demo1", MaxSize: 1 << 20}},
+			false,
+			7,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app.Settings().Storage.Quotas = s.quotas
+
+			command := cmd.NewStorageCommand(app)
+			command.SetArgs(s.args)
+
+			err := command.Execute()
+
+			hasErr := err != nil
+			if s.expectError != hasErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			var files int
+			if usage, err := app.FindStorageUsage(demo1.Id, ""); err == nil {
+				files = usage.Files()
+			}
+			if files != s.expectedFiles {
+				t.Fatalf("Expected %d tracked files, got %d", s.expectedFiles, files)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package core
 import (
 	"context"
 	"log/slog"
+	"net/url"
 	"time"
 
 	"github.com/pocketbase/dbx"
@@ -127,6 +128,39 @@ type App interface {
 	// NB! This feature is experimental and currently is expected to work only on UNIX based systems.
 	RestoreBackup(ctx context.Context, name string) error
 
+	// MigrateStorage copies all stored files between the local filesystem
+	// and the S3 storage configured in the app settings (and optionally
+	// the app backups) with checksum verification.
+	//
+	// Please refer to the godoc of the specific core.App implementation
+	// for details on the migration procedures.
+	MigrateStorage(ctx context.Context, options StorageMigrateOptions) (*StorageMigrateResult, error)
+
+	// CleanupOrphanedFiles reports or deletes the storage files
+	// (and their thumbs) that are not referenced by any record.
+	//
+	// Please refer to the godoc of the specific core.App implementation
+	// for details on the checked storage files.
+	CleanupOrphanedFiles(ctx context.Context, options OrphanedFilesOptions) (*OrphanedFilesResult, error)
+
+	// RebuildStorageUsage recomputes the tracked storage files and usage
+	// counters of the specified collection from its current record files.
+	//
+	// Please refer to the godoc of the specific core.App implementation
+	// for details on the rebuild procedure.
+	RebuildStorageUsage(ctx context.Context, collection *Collection) error
+
+	// NewSignedFileURL generates a new HMAC-signed expiring URL for
+	// downloading the specified record file without file token.
+	//
+	// Please refer to the godoc of the specific core.App implementation
+	// for details on the signed URL restrictions.
+	NewSignedFileURL(record *Record, filename string, options SignedFileURLOptions) (string, error)
+
+	// ValidateSignedFileURL checks whether the provided query parameters
+	// contain a valid and unexpired signature for the specified record file.
+	ValidateSignedFileURL(record *Record, filename string, query url.Values) error
+
 	// Restart restarts (aka. replaces) the current running application process.
 	//
 	// NB! It relies on execve which is supported only on UNIX based systems.
@@ -553,6 +587,44 @@ type App interface {
 
 	// ---------------------------------------------------------------
 
+	// FindAllFileScansByRecord returns all FileScan models linked to the provided record (in DESC order).
+	FindAllFileScansByRecord(record *Record) ([]*FileScan, error)
+
+	// FindFileScanByFilename returns a single FileScan model
+	// by its record relation, field name and filename.
+	FindFileScanByFilename(record *Record, field string, filename string) (*FileScan, error)
+
+	// DeleteAllFileScansByRecord deletes all FileScan models associated with the provided record.
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteAllFileScansByRecord(record *Record) error
+
+	// ---------------------------------------------------------------
+
+	// FindAllFileRefsByRecord returns all FileRef models linked to the provided record.
+	FindAllFileRefsByRecord(record *Record) ([]*FileRef, error)
+
+	// FindFileRefByFilename returns a single FileRef model
+	// by its record relation and filename.
+	FindFileRefByFilename(record *Record, filename string) (*FileRef, error)
+
+	// FindRecordFileKey returns the storage key of the specified record file.
+	//
+	// For deduplicated files this is the key of their content-addressed blob,
+	// otherwise it is the regular "{collectionId}/{recordId}/{filename}" key.
+	FindRecordFileKey(record *Record, filename string) string
+
+	// FindAllStorageFilesByRecord returns all tracked StorageFile models of the provided record.
+	FindAllStorageFilesByRecord(record *Record) ([]*StorageFile, error)
+
+	// FindStorageUsage returns the StorageUsage model with the files counters
+	// of the specified collection owner.
+	//
+	// Use an empty ownerId to find the total usage of the collection.
+	FindStorageUsage(collectionId string, ownerId string) (*StorageUsage, error)
+
+	// ---------------------------------------------------------------
+
 	// RecordQuery returns a new Record select query from a collection model, id or name.
 	//
 	// In case a collection id or name is provided and that collection doesn't
@@ -623,6 +695,40 @@ type App interface {
 		params ...dbx.Params,
 	) ([]*Record, error)
 
+	// FindRecordsByCursor returns limit number of records matching the
+	// provided string filter after the specified keyset pagination cursor
+	// together with the cursor of the next page (empty if there are no more records).
+	//
+	// NB! Use the last "params" argument to bind untrusted user variables!
+	//
+	// The filter and sort arguments are optional and have the same format as in
+	// FindRecordsByFilter (random sort is not supported).
+	// The records are always sorted additionally by "id" as tie-breaker.
+	//
+	// The cursor argument should be empty string for the first page
+	// or the cursor returned from the previous call.
+	//
+	// If the limit argument is <= 0, the default search page size is used.
+	//
+	// Example:
+	//
+	//	records, next, err := app.FindRecordsByCursor(
+	//		"posts",
+	//		"visible = {:visible}",
+	//		"-created",
+	//		100,
+	//		next,
+	//		dbx.Params{"visible": true},
+	//	)
+	FindRecordsByCursor(
+		collectionModelOrIdentifier any,
+		filter string,
+		sort string,
+		limit int,
+		cursor string,
+		params ...dbx.Params,
+	) ([]*Record, string, error)
+
 	// FindFirstRecordByFilter returns the first available record matching the provided filter (if any).
 	//
 	// NB! Use the last params argument to bind untrusted user variables!
@@ -691,6 +797,14 @@ type App interface {
 	// Returns a map with the failed expand parameters and their errors.
 	ExpandRecords(records []*Record, expands []string, optFetchFunc ExpandFetchFunc) map[string]error
 
+	// FindRecordDeleteImpact returns information about the records
+	// that will be affected by the provided record delete
+	// (including the nested cascade deletes) without making any changes.
+	//
+	// Note that the result reflects only the relation fields on delete actions
+	// and doesn't include changes made by custom delete hooks.
+	FindRecordDeleteImpact(record *Record) (*RecordDeleteImpact, error)
+
 	// ---------------------------------------------------------------
 	// App event hooks
 	// ---------------------------------------------------------------
@@ -995,6 +1109,33 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordEnrich(tags ...string) *hook.TaggedHook[*RecordEnrichEvent]
 
+	// OnRecordFileScan is triggered for every new record file
+	// right before its upload during the record create/update execution.
+	//
+	// It could be used to scan the uploaded files for malware or
+	// to apply content moderation. For example:
+	//
+	//  app.OnRecordFileScan("posts").BindFunc(func(e *core.RecordFileScanEvent) error {
+	//      if isMalicious(e.File) {
+	//          e.Status = core.FileScanStatusInfected
+	//          e.Signature = "Example.Signature"
+	//          e.Action = core.FileScanActionQuarantine
+	//      } else {
+	//          e.Status = core.FileScanStatusClean
+	//      }
+	//
+	//      return e.Next()
+	//  })
+	//
+	// The infected files are either rejected with a validation error (default)
+	// or moved to a storage quarantine prefix and excluded from the record.
+	// The scan status of the stored files is saved in the "_fileScans" system collection.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordFileScan(tags ...string) *hook.TaggedHook[*RecordFileScanEvent]
+
 	// OnRecordValidate is a Record proxy model hook of [OnModelValidate].
 	//
 	// If the optional "tags" list (Collection ids or names) is specified,
@@ -1452,6 +1593,15 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordsListRequest(tags ...string) *hook.TaggedHook[*RecordsListRequestEvent]
 
+	// OnRecordsAggregateRequest hook is triggered on each API Records aggregate request.
+	//
+	// Could be used to validate or modify the aggregated groups before returning them to the client.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordsAggregateRequest(tags ...string) *hook.TaggedHook[*RecordsAggregateRequestEvent]
+
 	// OnRecordViewRequest hook is triggered on each API Record view request.
 	//
 	// Could be used to validate or modify the response before returning it to the client.
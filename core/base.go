@@ -41,6 +41,12 @@ const (
 	LocalTempDirName          string = ".pb_temp_to_delete" // temp pb_data sub directory that will be deleted on each app.Bootstrap()
 	LocalAutocertCacheDirName string = ".autocert_cache"
 
+	StorageUploadsDirName string = "_pb_uploads_" // app storage sub directory with the staged resumable uploads
+
+	StorageQuarantineDirName string = "_pb_quarantine_" // default app storage sub directory with the quarantined infected files
+
+	StorageBlobsDirName string = "_pb_blobs_" // app storage sub directory with the content-addressed deduplicated files
+
 	// @todo consider removing after backups refactoring
 	lostFoundDirName string = "lost+found"
 )
@@ -108,6 +114,7 @@ type BaseApp struct {
 
 	// db record hooks
 	onRecordEnrich             *hook.Hook[*RecordEnrichEvent]
+	onRecordFileScan           *hook.Hook[*RecordFileScanEvent]
 	onRecordValidate           *hook.Hook[*RecordEvent]
 	onRecordCreate             *hook.Hook[*RecordEvent]
 	onRecordCreateExecute      *hook.Hook[*RecordEvent]
@@ -174,11 +181,12 @@ type BaseApp struct {
 	onRecordAuthWithOTPRequest          *hook.Hook[*RecordAuthWithOTPRequestEvent]
 
 	// record crud API event hooks
-	onRecordsListRequest  *hook.Hook[*RecordsListRequestEvent]
-	onRecordViewRequest   *hook.Hook[*RecordRequestEvent]
-	onRecordCreateRequest *hook.Hook[*RecordRequestEvent]
-	onRecordUpdateRequest *hook.Hook[*RecordRequestEvent]
-	onRecordDeleteRequest *hook.Hook[*RecordRequestEvent]
+	onRecordsListRequest      *hook.Hook[*RecordsListRequestEvent]
+	onRecordsAggregateRequest *hook.Hook[*RecordsAggregateRequestEvent]
+	onRecordViewRequest       *hook.Hook[*RecordRequestEvent]
+	onRecordCreateRequest     *hook.Hook[*RecordRequestEvent]
+	onRecordUpdateRequest     *hook.Hook[*RecordRequestEvent]
+	onRecordDeleteRequest     *hook.Hook[*RecordRequestEvent]
 
 	// collection API event hooks
 	onCollectionsListRequest   *hook.Hook[*CollectionsListRequestEvent]
@@ -256,6 +264,7 @@ func (app *BaseApp) initHooks() {
 
 	// db record hooks
 	app.onRecordEnrich = &hook.Hook[*RecordEnrichEvent]{}
+	app.onRecordFileScan = &hook.Hook[*RecordFileScanEvent]{}
 	app.onRecordValidate = &hook.Hook[*RecordEvent]{}
 	app.onRecordCreate = &hook.Hook[*RecordEvent]{}
 	app.onRecordCreateExecute = &hook.Hook[*RecordEvent]{}
@@ -323,6 +332,7 @@ func (app *BaseApp) initHooks() {
 
 	// record crud API event hooks
 	app.onRecordsListRequest = &hook.Hook[*RecordsListRequestEvent]{}
+	app.onRecordsAggregateRequest = &hook.Hook[*RecordsAggregateRequestEvent]{}
 	app.onRecordViewRequest = &hook.Hook[*RecordRequestEvent]{}
 	app.onRecordCreateRequest = &hook.Hook[*RecordRequestEvent]{}
 	app.onRecordUpdateRequest = &hook.Hook[*RecordRequestEvent]{}
@@ -883,6 +893,10 @@ func (app *BaseApp) OnRecordEnrich(tags ...string) *hook.TaggedHook[*RecordEnric
 	return hook.NewTaggedHook(app.onRecordEnrich, tags...)
 }
 
+func (app *BaseApp) OnRecordFileScan(tags ...string) *hook.TaggedHook[*RecordFileScanEvent] {
+	return hook.NewTaggedHook(app.onRecordFileScan, tags...)
+}
+
 func (app *BaseApp) OnRecordValidate(tags ...string) *hook.TaggedHook[*RecordEvent] {
 	return hook.NewTaggedHook(app.onRecordValidate, tags...)
 }
@@ -1119,6 +1133,10 @@ func (app *BaseApp) OnRecordsListRequest(tags ...string) *hook.TaggedHook[*Recor
 	return hook.NewTaggedHook(app.onRecordsListRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordsAggregateRequest(tags ...string) *hook.TaggedHook[*RecordsAggregateRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordsAggregateRequest, tags...)
+}
+
 func (app *BaseApp) OnRecordViewRequest(tags ...string) *hook.TaggedHook[*RecordRequestEvent] {
 	return hook.NewTaggedHook(app.onRecordViewRequest, tags...)
 }
@@ -1375,13 +1393,23 @@ func (app *BaseApp) registerBaseHooks() {
 
 	app.registerSettingsHooks()
 	app.registerAutobackupHooks()
+	app.registerOrphansCleanupHooks()
 	app.registerCollectionHooks()
 	app.registerRecordHooks()
+	app.registerSequenceHooks()
+	app.registerRelationJunctionHooks()
+	app.registerPolyRelationHooks()
 	app.registerSuperuserHooks()
 	app.registerExternalAuthHooks()
 	app.registerMFAHooks()
 	app.registerOTPHooks()
 	app.registerAuthOriginHooks()
+	app.registerFileScanHooks()
+	app.registerFileRefHooks()
+	app.registerStorageFileHooks()
+	app.registerStorageUsageHooks()
+	app.registerStorageUsageRebuildHooks()
+	app.registerResumableUploadsHooks()
 }
 
 // getLoggerMinLevel returns the logger min level based on the
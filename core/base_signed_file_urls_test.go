@@ -0,0 +1,166 @@
+package core_test
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestNewSignedFileURL(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.FindRecordById("demo1", "al1h9ijdeojtsjy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("missing signing key", func(t *testing.T) {
+		app.Settings().Storage.FileSigningKey = ""
+
+		if _, err := app.NewSignedFileURL(record, "300_Jsjq7RdBgA.png", core.SignedFileURLOptions{}); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+
+	app.Settings().Storage.FileSigningKey = "test_file_signing_key_1234567890"
+
+	t.Run("missing record file", func(t *testing.T) {
+		if _, err := app.NewSignedFileURL(record, "missing.png", core.SignedFileURLOptions{}); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+
+	scenarios := []struct {
+		name            string
+		options         core.SignedFileURLOptions
+		expectedExpires time.Duration
+		expectedQuery   map[string]string
+	}{
+		{
+			"default options",
+			core.SignedFileURLOptions{},
+			core.DefaultSignedFileURLDuration,
+			map[string]string{"thumb": "", "download": ""},
+		},
+		{
+			"custom options",
+			core.SignedFileURLOptions{Duration: 10 * time.Minute, Thumb: "100x100", Download: true},
+			10 * time.Minute,
+			map[string]string{"thumb": "100x100", "download": "1"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			rawURL, err := app.NewSignedFileURL(record, "300_Jsjq7RdBgA.png", s.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedPrefix := app.Settings().Meta.AppURL + "/api/files/" + record.Collection().Id + "/" + record.Id + "/300_Jsjq7RdBgA.png?"
+			if !strings.HasPrefix(rawURL, expectedPrefix) {
+				t.Fatalf("Expected URL with prefix %q, got %q", expectedPrefix, rawURL)
+			}
+
+			u, err := url.Parse(rawURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := u.Query()
+
+			for k, v := range s.expectedQuery {
+				if query.Get(k) != v {
+					t.Fatalf("Expected query param %q to be %q, got %q", k, v, query.Get(k))
+				}
+			}
+
+			expires, _ := strconv.ParseInt(query.Get(core.SignedFileURLExpiresParam), 10, 64)
+			diff := time.Until(time.Unix(expires, 0)) - s.expectedExpires
+			if diff > 0 || diff < -5*time.Second {
+				t.Fatalf("Expected expiration after ~%v, got %d", s.expectedExpires, expires)
+			}
+
+			if err := app.ValidateSignedFileURL(record, "300_Jsjq7RdBgA.png", query); err != nil {
+				t.Fatalf("Expected the generated URL to be valid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateSignedFileURL(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	const signingKey = "test_file_signing_key_1234567890"
+
+	record, err := app.FindRecordById("demo1", "al1h9ijdeojtsjy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(expires int64, thumb string, download string) url.Values {
+		e := strconv.FormatInt(expires, 10)
+
+		query := url.Values{}
+		query.Set("expires", e)
+		query.Set("thumb", thumb)
+		query.Set("download", download)
+		query.Set("signature", security.HS256(strings.Join([]string{record.Collection().Id, record.Id, "300_Jsjq7RdBgA.png", e, thumb, download}, "\n"), signingKey))
+
+		return query
+	}
+
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+
+	scenarios := []struct {
+		name        string
+		key         string
+		filename    string
+		query       url.Values
+		expectError bool
+	}{
+		{"missing signing key", "", "300_Jsjq7RdBgA.png", sign(future, "", ""), true},
+		{"different signing key", signingKey + "_new", "300_Jsjq7RdBgA.png", sign(future, "", ""), true},
+		{"missing signature", signingKey, "300_Jsjq7RdBgA.png", url.Values{"expires": []string{"1"}}, true},
+		{"invalid expiration", signingKey, "300_Jsjq7RdBgA.png", url.Values{"expires": []string{"abc"}, "signature": []string{"abc"}}, true},
+		{"expired signature", signingKey, "300_Jsjq7RdBgA.png", sign(past, "", ""), true},
+		{"different filename", signingKey, "test_d61b33QdDU.txt", sign(future, "", ""), true},
+		{"tampered thumb", signingKey, "300_Jsjq7RdBgA.png", func() url.Values {
+			q := sign(future, "100x100", "")
+			q.Set("thumb", "200x200")
+			return q
+		}(), true},
+		{"tampered download", signingKey, "300_Jsjq7RdBgA.png", func() url.Values {
+			q := sign(future, "", "")
+			q.Set("download", "1")
+			return q
+		}(), true},
+		{"valid signature", signingKey, "300_Jsjq7RdBgA.png", sign(future, "", ""), false},
+		{"valid signature with thumb and download", signingKey, "300_Jsjq7RdBgA.png", sign(future, "100x100", "1"), false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app.Settings().Storage.FileSigningKey = s.key
+
+			err := app.ValidateSignedFileURL(record, s.filename, s.query)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
+)
+
+const (
+	StoreKeyActiveStorageMigration = "@activeStorageMigration"
+)
+
+const (
+	StorageTargetLocal = "local"
+	StorageTargetS3    = "s3"
+)
+
+const (
+	StorageMigrateSectionFiles   = "files"
+	StorageMigrateSectionBackups = "backups"
+)
+
+// StorageMigrateOptions defines the options of a single [App.MigrateStorage] call.
+type StorageMigrateOptions struct {
+	// Target specifies the storage where the files are copied
+	// (StorageTargetS3 or StorageTargetLocal).
+	Target string
+
+	// IncludeBackups copies also the app backups between the
+	// local backups directory and the backups S3 storage.
+	IncludeBackups bool
+
+	// SwitchSettings enables (for StorageTargetS3) or disables (for StorageTargetLocal)
+	// the related S3 settings after all files were successfully copied.
+	SwitchSettings bool
+
+	// Force copies again the files that already exist in the target
+	// storage (by default they are skipped if their checksums match).
+	Force bool
+
+	// OnProgress is an optional callback that is called after each processed file.
+	OnProgress func(p StorageMigrateProgress)
+}
+
+// StorageMigrateStats defines the files counters of a storage migration section.
+type StorageMigrateStats struct {
+	Total   int   `json:"total"`
+	Copied  int   `json:"copied"`
+	Skipped int   `json:"skipped"`
+	Failed  int   `json:"failed"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// Processed returns the total number of the already processed files.
+func (s StorageMigrateStats) Processed() int {
+	return s.Copied + s.Skipped + s.Failed
+}
+
+// StorageMigrateProgress defines the progress state of a storage migration after a processed file.
+type StorageMigrateProgress struct {
+	StorageMigrateStats
+
+	// Section is the currently migrated storage
+	// (StorageMigrateSectionFiles or StorageMigrateSectionBackups).
+	Section string `json:"section"`
+
+	// Key is the last processed file key.
+	Key string `json:"key"`
+
+	// Status is the last processed file status ("copied", "skipped" or "failed").
+	Status string `json:"status"`
+
+	// Error is the last processed file error (if any).
+	Error error `json:"-"`
+}
+
+// StorageMigrateResult defines the result of a storage migration.
+type StorageMigrateResult struct {
+	Files   StorageMigrateStats  `json:"files"`
+	Backups *StorageMigrateStats `json:"backups,omitempty"`
+
+	// FailedKeys lists the keys of the files that failed to copy.
+	FailedKeys []string `json:"failedKeys"`
+
+	// SettingsSwitched indicates whether the S3 settings were updated.
+	SettingsSwitched bool `json:"settingsSwitched"`
+}
+
+// MigrateStorage copies all stored files (record files, thumbs, etc.)
+// between the local filesystem and the S3 storage configured in app.Settings().S3
+// (and optionally the app backups between the local backups directory
+// and the S3 storage configured in app.Settings().Backups.S3).
+//
+// The source files are not deleted and the target files that already
+// exist with matching checksum are skipped, meaning that an interrupted
+// or partially failed migration could be resumed by calling the method again.
+// Each copied file is verified against the source file MD5 checksum.
+//
+// The S3 settings are switched only if options.SwitchSettings is set
+// and all files were copied successfully.
+//
+// Note that the files uploaded while the migration is running may not be
+// copied so it is recommended to run the migration again (or to stop the
+// app) right before switching the settings.
+func (app *BaseApp) MigrateStorage(ctx context.Context, options StorageMigrateOptions) (*StorageMigrateResult, error) {
+	if options.Target != StorageTargetS3 && options.Target != StorageTargetLocal {
+		return nil, fmt.Errorf("invalid storage target %q", options.Target)
+	}
+
+	if app.Store().Has(StoreKeyActiveStorageMigration) {
+		return nil, errors.New("try again later - another storage migration has already been started")
+	}
+
+	app.Store().Set(StoreKeyActiveStorageMigration, options.Target)
+	defer app.Store().Remove(StoreKeyActiveStorageMigration)
+
+	settings := app.Settings()
+	toS3 := options.Target == StorageTargetS3
+
+	result := &StorageMigrateResult{FailedKeys: []string{}}
+
+	err := app.migrateStorageSection(
+		ctx,
+		StorageMigrateSectionFiles,
+		settings.S3,
+		filepath.Join(app.DataDir(), LocalStorageDirName),
+		toS3,
+		options,
+		&result.Files,
+		&result.FailedKeys,
+	)
+	if err != nil {
+		return result, err
+	}
+
+	if options.IncludeBackups {
+		result.Backups = &StorageMigrateStats{}
+
+		err = app.migrateStorageSection(
+			ctx,
+			StorageMigrateSectionBackups,
+			settings.Backups.S3,
+			filepath.Join(app.DataDir(), LocalBackupsDirName),
+			toS3,
+			options,
+			result.Backups,
+			&result.FailedKeys,
+		)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if len(result.FailedKeys) > 0 {
+		return result, fmt.Errorf("failed to copy %d file(s)", len(result.FailedKeys))
+	}
+
+	if options.SwitchSettings {
+		newSettings, err := settings.Clone()
+		if err != nil {
+			return result, err
+		}
+
+		newSettings.S3.Enabled = toS3
+		if options.IncludeBackups {
+			newSettings.Backups.S3.Enabled = toS3
+		}
+
+		if err := app.Save(newSettings); err != nil {
+			return result, fmt.Errorf("failed to switch the S3 settings: %w", err)
+		}
+
+		result.SettingsSwitched = true
+	}
+
+	return result, nil
+}
+
+func (app *BaseApp) migrateStorageSection(
+	ctx context.Context,
+	section string,
+	s3Config S3Config,
+	localDir string,
+	toS3 bool,
+	options StorageMigrateOptions,
+	stats *StorageMigrateStats,
+	failedKeys *[]string,
+) error {
+	if s3Config.Bucket == "" {
+		return fmt.Errorf("missing %s S3 configuration", section)
+	}
+
+	local, err := filesystem.NewLocal(localDir)
+	if err != nil {
+		return err
+	}
+	defer local.Close()
+	local.SetContext(ctx)
+
+	s3, err := filesystem.NewS3(
+		s3Config.Bucket,
+		s3Config.Region,
+		s3Config.Endpoint,
+		s3Config.AccessKey,
+		s3Config.Secret,
+		s3Config.ForcePathStyle,
+	)
+	if err != nil {
+		return err
+	}
+	defer s3.Close()
+	s3.SetContext(ctx)
+
+	src, dst := s3, local
+	if toS3 {
+		src, dst = local, s3
+	}
+
+	objects, err := src.List("")
+	if err != nil {
+		return fmt.Errorf("failed to list the %s source files: %w", section, err)
+	}
+
+	// the staged resumable uploads are bound to the storage they were started in
+	if section == StorageMigrateSectionFiles {
+		objects = slices.DeleteFunc(objects, func(obj *blob.ListObject) bool {
+			return strings.HasPrefix(obj.Key, StorageUploadsDirName+"/")
+		})
+	}
+
+	stats.Total += len(objects)
+
+	for _, obj := range objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		progress := StorageMigrateProgress{Section: section, Key: obj.Key}
+
+		var err error
+
+		skip := !options.Force && isStorageFileMigrated(src, dst, obj.Key, obj.Size)
+		if !skip {
+			err = src.CopyTo(dst, obj.Key, obj.Key)
+		}
+
+		switch {
+		case err != nil:
+			stats.Failed++
+			*failedKeys = append(*failedKeys, obj.Key)
+			progress.Status = "failed"
+			progress.Error = err
+			app.Logger().Warn("Failed to migrate storage file", "section", section, "key", obj.Key, "error", err)
+		case skip:
+			stats.Skipped++
+			progress.Status = "skipped"
+		default:
+			stats.Copied++
+			stats.Bytes += obj.Size
+			progress.Status = "copied"
+		}
+
+		if options.OnProgress != nil {
+			progress.StorageMigrateStats = *stats
+			options.OnProgress(progress)
+		}
+	}
+
+	return nil
+}
+
+// isStorageFileMigrated checks whether the dst file with the specified key
+// has the same size and checksum as the src one.
+func isStorageFileMigrated(src, dst *filesystem.System, key string, size int64) bool {
+	attrs, err := dst.Attributes(key)
+	if err != nil || attrs.Size != size {
+		return false
+	}
+
+	dstChecksum, err := dst.Checksum(key)
+	if err != nil {
+		return false
+	}
+
+	srcChecksum, err := src.Checksum(key)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(srcChecksum, dstChecksum)
+}
@@ -0,0 +1,65 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestMigrateStorageValidation(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name    string
+		options core.StorageMigrateOptions
+		before  func()
+	}{
+		{
+			"invalid target",
+			core.StorageMigrateOptions{Target: "invalid"},
+			nil,
+		},
+		{
+			"another active migration",
+			core.StorageMigrateOptions{Target: core.StorageTargetS3},
+			func() {
+				app.Store().Set(core.StoreKeyActiveStorageMigration, core.StorageTargetS3)
+			},
+		},
+		{
+			"missing S3 configuration",
+			core.StorageMigrateOptions{Target: core.StorageTargetS3},
+			nil,
+		},
+		{
+			"unreachable S3 storage",
+			core.StorageMigrateOptions{Target: core.StorageTargetLocal, IncludeBackups: true},
+			func() {
+				app.Settings().S3.Bucket = "test"
+				app.Settings().S3.Endpoint = "http://127.0.0.1:0"
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app.Store().Remove(core.StoreKeyActiveStorageMigration)
+
+			if s.before != nil {
+				s.before()
+			}
+
+			result, err := app.MigrateStorage(context.Background(), s.options)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+
+			if result != nil && result.SettingsSwitched {
+				t.Fatal("Expected the settings to remain unchanged")
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+const (
+	StoreKeyActiveOrphansCleanup = "@activeOrphansCleanup"
+)
+
+// DefaultOrphanedFilesMinAge is the default min age of the storage files
+// that are checked by the orphaned files cleanup.
+//
+// It prevents deleting the files of the records that are still being saved
+// (the files are uploaded before the record db write).
+const DefaultOrphanedFilesMinAge = time.Hour
+
+// OrphanedFilesOptions defines the options of a single [App.CleanupOrphanedFiles] call.
+type OrphanedFilesOptions struct {
+	// DryRun only reports the found orphaned files without deleting them.
+	DryRun bool
+
+	// MinAge specifies the min age of the storage files to check
+	// (the more recently modified files are ignored).
+	//
+	// Set it to a negative value to check all files regardless of their age.
+	// If zero, fallbacks to DefaultOrphanedFilesMinAge.
+	MinAge time.Duration
+
+	// OnOrphan is an optional callback that is called for each found orphaned file.
+	OnOrphan func(file OrphanedFile)
+}
+
+// OrphanedFile defines a single storage file that is not referenced by any record.
+type OrphanedFile struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+
+	// Deleted indicates whether the file was deleted
+	// (always false for dry runs or on delete error).
+	Deleted bool `json:"deleted"`
+}
+
+// OrphanedFilesResult defines the result of an orphaned files cleanup.
+type OrphanedFilesResult struct {
+	// Scanned is the total number of the checked storage files.
+	Scanned int `json:"scanned"`
+
+	// Orphans lists all found orphaned files.
+	Orphans []OrphanedFile `json:"orphans"`
+
+	// Bytes is the total size of the found orphaned files.
+	Bytes int64 `json:"bytes"`
+
+	// Deleted is the number of the deleted orphaned files.
+	Deleted int `json:"deleted"`
+
+	// FailedKeys lists the keys of the orphaned files that failed to delete.
+	FailedKeys []string `json:"failedKeys"`
+}
+
+// CleanupOrphanedFiles scans the app storage and reports or deletes
+// the files that are no longer referenced by any record, eg. left
+// from a failed transaction, manual db edit or an old backup restore.
+//
+// The following storage files are checked:
+//   - the record files (and their thumbs and kept originals) stored under the prefix of each
+//     non-view collection that are not listed in any of the record file fields
+//   - the record files stored under a top-level directory that doesn't match any
+//     existing collection id (aka. the files of the deleted collections)
+//   - the deduplicated files blobs that are not referenced in the _fileRefs collection
+//   - the quarantined infected files that are not referenced in the _fileScans collection
+//
+// The storage keys with unknown structure are left untouched.
+func (app *BaseApp) CleanupOrphanedFiles(ctx context.Context, options OrphanedFilesOptions) (*OrphanedFilesResult, error) {
+	if app.Store().Has(StoreKeyActiveOrphansCleanup) {
+		return nil, errors.New("try again later - another orphaned files cleanup has already been started")
+	}
+
+	app.Store().Set(StoreKeyActiveOrphansCleanup, struct{}{})
+	defer app.Store().Remove(StoreKeyActiveOrphansCleanup)
+
+	minAge := options.MinAge
+	if minAge == 0 {
+		minAge = DefaultOrphanedFilesMinAge
+	}
+	maxModTime := time.Now().Add(-minAge)
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+	defer fsys.Close()
+	fsys.SetContext(ctx)
+
+	result := &OrphanedFilesResult{
+		Orphans:    []OrphanedFile{},
+		FailedKeys: []string{},
+	}
+
+	// scan checks the listed files with isOrphan and collects
+	// (and optionally deletes) the orphaned ones
+	scan := func(objects []*blob.ListObject, isOrphan func(obj *blob.ListObject) (bool, error)) error {
+		for _, obj := range objects {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if minAge > 0 && obj.ModTime.After(maxModTime) {
+				continue
+			}
+
+			result.Scanned++
+
+			orphan, err := isOrphan(obj)
+			if err != nil {
+				return err
+			}
+			if !orphan {
+				continue
+			}
+
+			file := OrphanedFile{Key: obj.Key, Size: obj.Size, ModTime: obj.ModTime}
+
+			if !options.DryRun {
+				if err := fsys.Delete(obj.Key); err != nil && !errors.Is(err, filesystem.ErrNotFound) {
+					result.FailedKeys = append(result.FailedKeys, obj.Key)
+					app.Logger().Warn("Failed to delete orphaned file", slog.String("key", obj.Key), slog.String("error", err.Error()))
+				} else {
+					file.Deleted = true
+					result.Deleted++
+				}
+			}
+
+			result.Orphans = append(result.Orphans, file)
+			result.Bytes += obj.Size
+
+			if options.OnOrphan != nil {
+				options.OnOrphan(file)
+			}
+		}
+
+		return nil
+	}
+
+	all, err := fsys.List("")
+	if err != nil {
+		return result, fmt.Errorf("failed to list the storage files: %w", err)
+	}
+
+	// group the storage files by their top-level directory
+	dirs := map[string][]*blob.ListObject{}
+	for _, obj := range all {
+		if dir, _, ok := strings.Cut(obj.Key, "/"); ok {
+			dirs[dir] = append(dirs[dir], obj)
+		}
+	}
+
+	collections, err := app.FindAllCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, collection := range collections {
+		objects := dirs[collection.BaseFilesPath()]
+		delete(dirs, collection.BaseFilesPath())
+
+		if collection.IsView() {
+			continue // views don't have their own files
+		}
+
+		refs, err := app.findRecordFilesRefs(collection, objects)
+		if err != nil {
+			return result, err
+		}
+
+		err = scan(objects, func(obj *blob.ListObject) (bool, error) {
+			recordId, filename, ok := parseRecordFileKey(collection.BaseFilesPath(), obj.Key)
+			if !ok {
+				return false, nil
+			}
+
+			return !list.ExistInSlice(filename, refs[recordId]), nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	blobs := dirs[StorageBlobsDirName]
+	delete(dirs, StorageBlobsDirName)
+
+	err = scan(blobs, func(obj *blob.ListObject) (bool, error) {
+		total, err := app.CountRecords(CollectionNameFileRefs, dbx.HashExp{"hash": path.Base(obj.Key)})
+		return total == 0, err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	quarantined := dirs[StorageQuarantineDirName]
+	delete(dirs, StorageQuarantineDirName)
+
+	err = scan(quarantined, func(obj *blob.ListObject) (bool, error) {
+		total, err := app.CountRecords(CollectionNameFileScans, dbx.HashExp{"quarantineKey": obj.Key})
+		return total == 0, err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// the staged resumable uploads expire on their own (see deleteExpiredResumableUploads)
+	delete(dirs, StorageUploadsDirName)
+
+	// the remaining directories don't belong to any existing collection
+	// and all of their record files are orphaned
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		err = scan(dirs[dir], func(obj *blob.ListObject) (bool, error) {
+			_, _, ok := parseRecordFileKey(dir, obj.Key)
+			return ok, nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if len(result.FailedKeys) > 0 {
+		return result, fmt.Errorf("failed to delete %d orphaned file(s)", len(result.FailedKeys))
+	}
+
+	return result, nil
+}
+
+// findRecordFilesRefs returns a map with the file field values of the
+// records referenced in the provided collection storage objects.
+func (app *BaseApp) findRecordFilesRefs(collection *Collection, objects []*blob.ListObject) (map[string][]string, error) {
+	const chunkSize = 200
+
+	refs := map[string][]string{}
+
+	ids := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		if recordId, _, ok := parseRecordFileKey(collection.BaseFilesPath(), obj.Key); ok {
+			ids = append(ids, recordId)
+		}
+	}
+	ids = list.ToUniqueStringSlice(ids)
+
+	for i := 0; i < len(ids); i += chunkSize {
+		records, err := app.FindRecordsByIds(collection, ids[i:min(i+chunkSize, len(ids))])
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the %q collection records: %w", collection.Name, err)
+		}
+
+		for _, record := range records {
+			filenames := []string{}
+			for _, f := range collection.Fields {
+				if f.Type() == FieldTypeFile {
+					filenames = append(filenames, record.GetStringSlice(f.GetName())...)
+				}
+			}
+			refs[record.Id] = filenames
+		}
+	}
+
+	return refs, nil
+}
+
+// parseRecordFileKey extracts the record id and filename from
+// a record file, thumb or kept original storage key, eg.:
+//
+//	{collectionId}/{recordId}/{filename}
+//	{collectionId}/{recordId}/thumbs_{filename}/{thumb}
+//	{collectionId}/{recordId}/originals_{filename}/{original}
+func parseRecordFileKey(basePath string, key string) (string, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(key, basePath+"/"), "/")
+
+	if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+		return parts[0], parts[1], true
+	}
+
+	if len(parts) == 3 && parts[0] != "" {
+		for _, prefix := range []string{"thumbs_", "originals_"} {
+			if filename, ok := strings.CutPrefix(parts[1], prefix); ok && filename != "" {
+				return parts[0], filename, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// registerOrphansCleanupHooks registers the orphaned files cleanup cron app hooks.
+func (app *BaseApp) registerOrphansCleanupHooks() {
+	const jobId = "__pbOrphansCleanup__"
+
+	loadJob := func() {
+		rawSchedule := app.Settings().Storage.OrphansCron
+		if rawSchedule == "" {
+			app.Cron().Remove(jobId)
+			return
+		}
+
+		app.Cron().Add(jobId, rawSchedule, func() {
+			result, err := app.CleanupOrphanedFiles(context.Background(), OrphanedFilesOptions{})
+			if err != nil {
+				app.Logger().Error(
+					"[Orphans cron] Failed to cleanup the orphaned files",
+					slog.String("error", err.Error()),
+				)
+			}
+
+			if result != nil && result.Deleted > 0 {
+				app.Logger().Info(
+					"[Orphans cron] Deleted orphaned files",
+					slog.Int("total", result.Deleted),
+					slog.Int64("bytes", result.Bytes),
+				)
+			}
+		})
+	}
+
+	app.OnBootstrap().BindFunc(func(e *BootstrapEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		loadJob()
+
+		return nil
+	})
+
+	app.OnSettingsReload().BindFunc(func(e *SettingsReloadEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		loadJob()
+
+		return nil
+	})
+}
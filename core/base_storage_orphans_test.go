@@ -0,0 +1,145 @@
+package core_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestCleanupOrphanedFiles(t *testing.T) {
+	orphans := []string{
+		"wsmn24bux7wo113/84nmscqy84lsi1t/orphan.txt",
+		"wsmn24bux7wo113/84nmscqy84lsi1t/thumbs_orphan.png/100x100_orphan.png",
+		"wsmn24bux7wo113/84nmscqy84lsi1t/originals_orphan.webp/orphan.png",
+		"wsmn24bux7wo113/missing/test.txt",
+		core.StorageBlobsDirName + "/ab/abc",
+		core.StorageQuarantineDirName + "/wsmn24bux7wo113/84nmscqy84lsi1t/infected.txt",
+		"missing_collection/84nmscqy84lsi1t/test.txt",
+		"missing_collection/84nmscqy84lsi1t/thumbs_test.png/100x100_test.png",
+	}
+
+	unknown := []string{
+		"wsmn24bux7wo113/84nmscqy84lsi1t/a/b/c.txt",
+		"missing_collection/84nmscqy84lsi1t/a/b/c.txt",
+		"custom_quarantine/wsmn24bux7wo113/84nmscqy84lsi1t/infected.txt",
+		"root.txt",
+		core.StorageUploadsDirName + "/abc/" + core.ResumableUploadInfoName,
+		core.StorageUploadsDirName + "/abc/00000000000000000000",
+		core.StorageUploadsDirName + "/abc/file",
+	}
+
+	seed := func(t *testing.T, app core.App) {
+		fsys, err := app.NewFilesystem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fsys.Close()
+
+		for _, key := range append(slices.Clone(orphans), unknown...) {
+			if err := fsys.Upload([]byte("test"), key); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	exists := func(t *testing.T, app core.App, key string) bool {
+		fsys, err := app.NewFilesystem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fsys.Close()
+
+		ok, err := fsys.Exists(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return ok
+	}
+
+	t.Run("pending cleanup", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		app.Store().Set(core.StoreKeyActiveOrphansCleanup, struct{}{})
+
+		if _, err := app.CleanupOrphanedFiles(context.Background(), core.OrphanedFilesOptions{}); err == nil {
+			t.Fatal("Expected pending error, got nil")
+		}
+	})
+
+	t.Run("default min age", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		seed(t, app)
+
+		result, err := app.CleanupOrphanedFiles(context.Background(), core.OrphanedFilesOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result.Orphans) != 0 {
+			t.Fatalf("Expected the recently uploaded files to be ignored, got %v", result.Orphans)
+		}
+	})
+
+	for _, dryRun := range []bool{true, false} {
+		name := "delete"
+		if dryRun {
+			name = "dry run"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			seed(t, app)
+
+			var reported []string
+
+			result, err := app.CleanupOrphanedFiles(context.Background(), core.OrphanedFilesOptions{
+				DryRun: dryRun,
+				MinAge: -1,
+				OnOrphan: func(file core.OrphanedFile) {
+					reported = append(reported, file.Key)
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Orphans) != len(orphans) {
+				t.Fatalf("Expected %d orphans, got %d: %v", len(orphans), len(result.Orphans), result.Orphans)
+			}
+
+			for _, key := range orphans {
+				if !slices.Contains(reported, key) {
+					t.Errorf("Missing reported orphan %q", key)
+				}
+
+				if exists(t, app, key) != dryRun {
+					t.Errorf("Expected orphan %q to exist: %v", key, dryRun)
+				}
+			}
+
+			expectedDeleted := len(orphans)
+			if dryRun {
+				expectedDeleted = 0
+			}
+			if result.Deleted != expectedDeleted {
+				t.Fatalf("Expected %d deleted files, got %d", expectedDeleted, result.Deleted)
+			}
+
+			// unknown and referenced files must remain untouched
+			for _, key := range append(slices.Clone(unknown), "wsmn24bux7wo113/84nmscqy84lsi1t/300_WlbFWSGmW9.png", "wsmn24bux7wo113/84nmscqy84lsi1t/thumbs_300_WlbFWSGmW9.png/100x100_300_WlbFWSGmW9.png") {
+				if !exists(t, app, key) {
+					t.Errorf("Expected file %q to remain", key)
+				}
+			}
+		})
+	}
+}
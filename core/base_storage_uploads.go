@@ -0,0 +1,86 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// ResumableUploadMaxAge is the max duration after the resumable
+	// upload creation for which the upload can be resumed or referenced.
+	ResumableUploadMaxAge = 24 * time.Hour
+
+	// ResumableUploadInfoName is the name of the file that holds
+	// the state of a single staged resumable upload.
+	ResumableUploadInfoName = "info.json"
+)
+
+// registerResumableUploadsHooks registers the staged resumable uploads cleanup cron app hooks.
+func (app *BaseApp) registerResumableUploadsHooks() {
+	// run on every hour to cleanup the expired staged uploads
+	app.Cron().Add("__pbResumableUploadsCleanup__", "15 * * * *", func() {
+		if err := deleteExpiredResumableUploads(app); err != nil {
+			app.Logger().Warn("Failed to delete expired resumable uploads", "error", err)
+		}
+	})
+}
+
+// deleteExpiredResumableUploads deletes all expired staged resumable uploads
+// from the StorageUploadsDirName app storage directory.
+func deleteExpiredResumableUploads(app App) error {
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	objects, err := fsys.List(StorageUploadsDirName + "/")
+	if err != nil {
+		return err
+	}
+
+	checked := map[string]bool{} // upload id -> expired
+
+	for _, obj := range objects {
+		id, _, _ := strings.Cut(strings.TrimPrefix(obj.Key, StorageUploadsDirName+"/"), "/")
+		if id == "" {
+			continue
+		}
+
+		if _, ok := checked[id]; ok {
+			continue
+		}
+
+		// note: the chunks are never modified after the upload creation
+		// so their modification time is used as a fallback for the broken uploads
+		if time.Since(obj.ModTime) <= ResumableUploadMaxAge {
+			continue
+		}
+
+		expired := true
+
+		r, err := fsys.GetReader(StorageUploadsDirName + "/" + id + "/" + ResumableUploadInfoName)
+		if err == nil {
+			info := struct {
+				Created time.Time `json:"created"`
+			}{}
+			if json.NewDecoder(r).Decode(&info) == nil {
+				expired = time.Since(info.Created) > ResumableUploadMaxAge
+			}
+			r.Close()
+		}
+
+		checked[id] = expired
+	}
+
+	var errs []error
+	for id, expired := range checked {
+		if expired {
+			errs = append(errs, fsys.DeletePrefix(StorageUploadsDirName+"/"+id+"/")...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+package core_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestResumableUploadsCleanupCron(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	old := time.Now().Add(-core.ResumableUploadMaxAge - time.Hour)
+
+	uploads := []struct {
+		id      string
+		created time.Time
+		modTime time.Time
+		info    bool
+		expired bool
+	}{
+		{"new", time.Now(), time.Now(), true, false},
+		{"resumed", time.Now().Add(-time.Hour), old, true, false}, // older chunk of a still valid upload
+		{"expired", old, old, true, true},
+		{"broken_new", time.Time{}, time.Now(), false, false},
+		{"broken_old", time.Time{}, old, false, true},
+	}
+
+	for _, u := range uploads {
+		dir := core.StorageUploadsDirName + "/" + u.id + "/"
+
+		if err := fsys.Upload([]byte("test"), dir+"00000000000000000000"); err != nil {
+			t.Fatal(err)
+		}
+
+		if u.info {
+			raw, _ := json.Marshal(map[string]any{"id": u.id, "created": u.created})
+			if err := fsys.Upload(raw, dir+core.ResumableUploadInfoName); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		files, _ := filepath.Glob(filepath.Join(app.DataDir(), core.LocalStorageDirName, core.StorageUploadsDirName, u.id, "*"))
+		for _, f := range files {
+			if err := os.Chtimes(f, u.modTime, u.modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var found bool
+	for _, job := range app.Cron().Jobs() {
+		if job.Id() == "__pbResumableUploadsCleanup__" {
+			found = true
+			job.Run()
+			break
+		}
+	}
+	if !found {
+		t.Fatal("Missing the resumable uploads cleanup cron job")
+	}
+
+	for _, u := range uploads {
+		exists, err := fsys.Exists(core.StorageUploadsDirName + "/" + u.id + "/00000000000000000000")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if exists == u.expired {
+			t.Errorf("[%s] Expected the upload to be deleted %v", u.id, u.expired)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/routine"
+)
+
+const (
+	StoreKeyActiveStorageUsageRebuild = "@activeStorageUsageRebuild_"
+)
+
+// RebuildStorageUsage recomputes the tracked storage files and usage
+// counters of the specified collection from its current record files,
+// eg. after adding a storage quota to a collection with existing files
+// (the quota tracking otherwise counts only the files uploaded after it).
+//
+// The tracked size of each record file is the size of the stored file
+// (incl. its kept original, if any) and the owner is resolved from the
+// current collection storage quotas settings.
+//
+// The previously tracked files and usage counters of the collection are replaced
+// within a single transaction. If the collection doesn't have any storage quotas,
+// its tracked files and usage counters are only deleted.
+//
+// Note that the stored files sizes are loaded before the transaction
+// and the files uploaded in the meantime are checked on replace.
+func (app *BaseApp) RebuildStorageUsage(ctx context.Context, collection *Collection) error {
+	if collection.IsView() {
+		return errors.New("view collections don't have storage files")
+	}
+
+	storeKey := StoreKeyActiveStorageUsageRebuild + collection.Id
+
+	if app.Store().Has(storeKey) {
+		return errors.New("try again later - another storage usage rebuild of the collection has already been started")
+	}
+
+	app.Store().Set(storeKey, struct{}{})
+	defer app.Store().Remove(storeKey)
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+	fsys.SetContext(ctx)
+
+	_, hasQuotas := storageFilesOwner(app, NewRecord(collection))
+
+	// load the stored files sizes outside of the transaction
+	// to avoid blocking the db writes with the storage requests
+	sizes := map[string]int64{}
+	if hasQuotas {
+		err = eachRecordsBatch(app, collection, func(records []*Record) error {
+			for _, record := range records {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				err := eachRecordFile(record, func(field *FileField, filename string) error {
+					size, err := storedRecordFileSize(app, fsys, record, field, filename)
+					if err != nil {
+						return err
+					}
+
+					sizes[record.Id+"/"+field.Name+"/"+filename] = size
+
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to load the %q collection files: %w", collection.Name, err)
+		}
+	}
+
+	return app.RunInTransaction(func(txApp App) error {
+		// note: the usage counters are deleted explicitly because
+		// the raw delete doesn't trigger the storage files hooks
+		_, err := txApp.DB().Delete(CollectionNameStorageFiles, dbx.HashExp{"collectionRef": collection.Id}).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to delete the tracked storage files: %w", err)
+		}
+
+		_, err = txApp.DB().Delete(CollectionNameStorageUsage, dbx.HashExp{"collectionRef": collection.Id}).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to delete the storage usage: %w", err)
+		}
+
+		if !hasQuotas {
+			return nil
+		}
+
+		return eachRecordsBatch(txApp, collection, func(records []*Record) error {
+			for _, record := range records {
+				owner, _ := storageFilesOwner(txApp, record)
+
+				err := eachRecordFile(record, func(field *FileField, filename string) error {
+					size, ok := sizes[record.Id+"/"+field.Name+"/"+filename]
+					if !ok {
+						// uploaded after the sizes load
+						var err error
+						size, err = storedRecordFileSize(txApp, fsys, record, field, filename)
+						if err != nil {
+							return err
+						}
+					}
+
+					file := NewStorageFile(txApp)
+					file.SetCollectionRef(collection.Id)
+					file.SetRecordRef(record.Id)
+					file.SetOwnerRef(owner)
+					file.SetField(field.Name)
+					file.SetFilename(filename)
+					file.SetSize(size)
+
+					return txApp.SaveNoValidate(file)
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+// eachRecordsBatch loads the collection records in batches ordered by their id
+// and calls fn for each batch.
+func eachRecordsBatch(app App, collection *Collection, fn func(records []*Record) error) error {
+	const batchSize = 500
+
+	var lastId string
+
+	for {
+		records := []*Record{}
+
+		err := app.RecordQuery(collection).
+			AndWhere(dbx.NewExp("[[id]] > {:lastId}", dbx.Params{"lastId": lastId})).
+			OrderBy("id ASC").
+			Limit(batchSize).
+			All(&records)
+		if err != nil {
+			return err
+		}
+
+		if len(records) == 0 {
+			return nil
+		}
+
+		if err := fn(records); err != nil {
+			return err
+		}
+
+		if len(records) < batchSize {
+			return nil
+		}
+
+		lastId = records[len(records)-1].Id
+	}
+}
+
+// eachRecordFile calls fn for each file of the record file fields.
+func eachRecordFile(record *Record, fn func(field *FileField, filename string) error) error {
+	for _, f := range record.Collection().Fields {
+		field, ok := f.(*FileField)
+		if !ok {
+			continue
+		}
+
+		for _, filename := range record.GetStringSlice(field.Name) {
+			if err := fn(field, filename); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// storedRecordFileSize returns the size of the stored record file
+// (incl. its kept original, if any).
+//
+// Missing files are counted with zero size.
+func storedRecordFileSize(app App, fsys *filesystem.System, record *Record, field *FileField, filename string) (int64, error) {
+	var size int64
+
+	attrs, err := fsys.Attributes(app.FindRecordFileKey(record, filename))
+	if err == nil {
+		size = attrs.Size
+	} else if !errors.Is(err, filesystem.ErrNotFound) {
+		return 0, err
+	}
+
+	if field.KeepOriginal {
+		originals, err := fsys.List(record.BaseFilesPath() + "/originals_" + filename + "/")
+		if err != nil {
+			return 0, err
+		}
+
+		for _, obj := range originals {
+			size += obj.Size
+		}
+	}
+
+	return size, nil
+}
+
+// storageQuotasOwnerFields returns a map with the ids of the collections with
+// storage quotas and the owner field of their tracked files.
+func storageQuotasOwnerFields(app App) map[string]string {
+	result := map[string]string{}
+
+	for _, q := range app.Settings().Storage.Quotas {
+		collection, err := app.FindCachedCollectionByNameOrId(q.Collection)
+		if err != nil {
+			continue
+		}
+
+		if result[collection.Id] == "" {
+			result[collection.Id] = q.OwnerField
+		}
+	}
+
+	return result
+}
+
+// registerStorageUsageRebuildHooks registers the app hooks that rebuild
+// in the background the storage usage of the collections with new storage
+// quotas (or with changed owner field).
+func (app *BaseApp) registerStorageUsageRebuildHooks() {
+	var mu sync.Mutex
+	var current map[string]string
+
+	app.OnSettingsReload().BindFunc(func(e *SettingsReloadEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		ownerFields := storageQuotasOwnerFields(e.App)
+
+		mu.Lock()
+		old := current
+		current = ownerFields
+		mu.Unlock()
+
+		if old == nil {
+			return nil // initial load
+		}
+
+		changed := []string{}
+		for id, ownerField := range ownerFields {
+			if oldOwnerField, ok := old[id]; !ok || oldOwnerField != ownerField {
+				changed = append(changed, id)
+			}
+		}
+
+		if len(changed) == 0 {
+			return nil
+		}
+
+		routine.FireAndForget(func() {
+			for _, id := range changed {
+				collection, err := app.FindCollectionByNameOrId(id)
+				if err != nil {
+					continue // deleted in the meantime
+				}
+
+				err = app.RebuildStorageUsage(context.Background(), collection)
+				if err != nil {
+					app.Logger().Error(
+						"Failed to rebuild the collection storage usage",
+						slog.String("collectionId", id),
+						slog.String("error", err.Error()),
+					)
+				}
+			}
+		})
+
+		return nil
+	})
+}
@@ -0,0 +1,113 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRebuildStorageUsage(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const owner = "84nmscqy84lsi1t"
+
+	checkUsage := func(t *testing.T, ownerId string, expectedFiles int, expectedSize int64) {
+		t.Helper()
+
+		usage, err := app.FindStorageUsage(demo1.Id, ownerId)
+		if err != nil {
+			if expectedFiles == 0 && expectedSize == 0 {
+				return
+			}
+			t.Fatalf("Failed to find the %q usage: %v", ownerId, err)
+		}
+
+		if usage.Files() != expectedFiles || usage.Size() != expectedSize {
+			t.Fatalf("Expected %q usage (%d, %d), got (%d, %d)", ownerId, expectedFiles, expectedSize, usage.Files(), usage.Size())
+		}
+	}
+
+	// the existing files are not tracked by default
+	app.Settings().Storage.Quotas = []core.StorageQuota{{Collection: demo1.Name, OwnerField: "rel_one", MaxSize: 1 << 20}}
+	checkUsage(t, "", 0, 0)
+
+	if err := app.RebuildStorageUsage(context.Background(), demo1); err != nil {
+		t.Fatal(err)
+	}
+	checkUsage(t, "", 7, 5456)
+	checkUsage(t, owner, 1, 1132)
+
+	// rebuild again to ensure that the previous state is replaced
+	if err := app.RebuildStorageUsage(context.Background(), demo1); err != nil {
+		t.Fatal(err)
+	}
+	checkUsage(t, "", 7, 5456)
+	checkUsage(t, owner, 1, 1132)
+
+	// without quotas the tracked state should be only deleted
+	app.Settings().Storage.Quotas = nil
+	if err := app.RebuildStorageUsage(context.Background(), demo1); err != nil {
+		t.Fatal(err)
+	}
+	checkUsage(t, "", 0, 0)
+	checkUsage(t, owner, 0, 0)
+
+	total, err := app.CountRecords(core.CollectionNameStorageFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("Expected no tracked files, got %d", total)
+	}
+
+	// view collections
+	view1, err := app.FindCollectionByNameOrId("view1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RebuildStorageUsage(context.Background(), view1); err == nil {
+		t.Fatal("Expected view collection error, got nil")
+	}
+}
+
+func TestRebuildStorageUsageOnQuotasChange(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Settings().Storage.Quotas = []core.StorageQuota{{Collection: demo1.Name, MaxSize: 1 << 20}}
+	if err := app.Save(app.Settings()); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for the background rebuild
+	var usage *core.StorageUsage
+	for i := 0; i < 50; i++ {
+		usage, err = app.FindStorageUsage(demo1.Id, "")
+		if err == nil && !app.Store().Has(core.StoreKeyActiveStorageUsageRebuild+demo1.Id) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if usage == nil {
+		t.Fatal("Expected the storage usage to be rebuilt")
+	}
+
+	if usage.Files() != 7 || usage.Size() != 5456 {
+		t.Fatalf("Expected usage (%d, %d), got (%d, %d)", 7, 5456, usage.Files(), usage.Size())
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -689,7 +690,12 @@ func onCollectionDeleteExecute(e *CollectionEvent) error {
 	if !e.Collection.disableIntegrityChecks {
 		// ensure that there aren't any existing references.
 		// note: the select is outside of the transaction to prevent SQLITE_LOCKED error when mixing read&write in a single transaction
-		references, err := e.App.FindCollectionReferences(e.Collection, e.Collection.Id)
+		// (the collection junctions are excluded since they are deleted together with the collection)
+		excludeIds := []string{e.Collection.Id}
+		for _, f := range collectionJunctionFields(e.Collection) {
+			excludeIds = append(excludeIds, f.JunctionCollectionId(e.Collection))
+		}
+		references, err := e.App.FindCollectionReferences(e.Collection, excludeIds...)
 		if err != nil {
 			return fmt.Errorf("[%s] failed to check collection references: %w", e.Collection.Name, err)
 		}
@@ -716,6 +722,11 @@ func onCollectionDeleteExecute(e *CollectionEvent) error {
 			if err := txApp.DeleteTable(e.Collection.Name); err != nil {
 				return err
 			}
+
+			// the base table triggers are deleted together with the table
+			if err := txApp.DeleteTable(fullTextSearchTableName(e.Collection)); err != nil {
+				return err
+			}
 		}
 
 		if !e.Collection.disableIntegrityChecks {
@@ -726,7 +737,17 @@ func onCollectionDeleteExecute(e *CollectionEvent) error {
 		}
 
 		// delete
-		return e.Next()
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		// delete the stored sequence counters (if any)
+		if err := deleteSequenceCounters(txApp, e.Collection.Id, ""); err != nil {
+			return fmt.Errorf("[%s] failed to delete the sequence counters: %w", e.Collection.Name, err)
+		}
+
+		// delete the auto-managed junction collections (if any)
+		return deleteJunctionCollections(txApp, e.Collection)
 	})
 
 	e.App = originalApp
@@ -807,6 +828,8 @@ func onCollectionSave(e *CollectionEvent) error {
 
 	e.Collection.initDefaultFields()
 
+	e.Collection.initEncryptedFieldsKeyEnv(e.App)
+
 	if e.Collection.IsAuth() {
 		e.Collection.unsetMissingOAuth2MappedFields()
 	}
@@ -887,6 +910,16 @@ func onCollectionSaveExecute(e *CollectionEvent) error {
 				// note: don't wrap to allow propagating indexes validation.Errors
 				return err
 			}
+
+			// create or delete the related junction collections (if any)
+			if err := syncJunctionCollections(e.App, e.Collection, oldCollection); err != nil {
+				return err
+			}
+
+			// delete the stored counters of the removed sequence fields (if any)
+			if err := syncSequenceCounters(e.App, e.Collection, oldCollection); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -908,6 +941,7 @@ func (c *Collection) initDefaultFields() {
 	switch c.Type {
 	case CollectionTypeBase:
 		c.initIdField()
+		c.initSequenceIndexes()
 	case CollectionTypeAuth:
 		c.initIdField()
 		c.initPasswordField()
@@ -915,11 +949,74 @@ func (c *Collection) initDefaultFields() {
 		c.initEmailField()
 		c.initEmailVisibilityField()
 		c.initVerifiedField()
+		c.initSequenceIndexes()
 	case CollectionTypeView:
 		// view fields are autogenerated
 	}
 }
 
+// initEncryptedFieldsKeyEnv sets the app EncryptionEnv as KeyEnv
+// of the encrypted fields that don't have one.
+func (c *Collection) initEncryptedFieldsKeyEnv(app App) {
+	for _, f := range c.Fields {
+		encrypted, ok := f.(*EncryptedField)
+		if ok && encrypted.KeyEnv == "" {
+			encrypted.KeyEnv = app.EncryptionEnv()
+		}
+	}
+}
+
+// initSequenceIndexes ensures that there is a unique index for each
+// sequence field (per scope) and removes the indexes of the deleted ones.
+func (c *Collection) initSequenceIndexes() {
+	const prefix = "idx_sequence_"
+
+	suffix := "_" + c.Id
+
+	// remove the indexes of the deleted sequence fields
+	for i := len(c.Indexes) - 1; i >= 0; i-- {
+		name := dbutils.ParseIndex(c.Indexes[i]).IndexName
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+
+		fieldId := strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+		if _, ok := c.Fields.GetById(fieldId).(*SequenceField); !ok {
+			c.Indexes = append(c.Indexes[:i], c.Indexes[i+1:]...)
+		}
+	}
+
+	for _, field := range c.Fields {
+		seq, ok := field.(*SequenceField)
+		if !ok || seq.Id == "" {
+			continue
+		}
+
+		columns := "`" + seq.Name + "`"
+		if seq.ScopeField != "" {
+			columns = "`" + seq.ScopeField + "`, " + columns
+		}
+
+		name := prefix + seq.Id + suffix
+
+		expected := fmt.Sprintf(
+			"CREATE UNIQUE INDEX `%s` ON `%s` (%s) WHERE `%s` != ''",
+			name,
+			c.Name,
+			columns,
+			seq.Name,
+		)
+
+		// replace in place to preserve the indexes order
+		existing := c.GetIndex(name)
+		if existing == "" {
+			c.Indexes = append(c.Indexes, expected)
+		} else if existing != expected {
+			c.Indexes[slices.Index(c.Indexes, existing)] = expected
+		}
+	}
+}
+
 func (c *Collection) initIdField() {
 	field, _ := c.Fields.GetByName(FieldNameId).(*TextField)
 	if field == nil {
@@ -43,7 +43,11 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 				return err
 			}
 
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
+
+			return createFullTextSearchTable(txApp, newCollection)
 		}
 
 		// update
@@ -69,6 +73,17 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 			}
 		}
 
+		// the full-text search table and its triggers reference the table columns
+		// so they are dropped before the columns change and recreated after that
+		needFullTextSearchUpdate := (needTableRename || oldFields.String() != newFields.String()) &&
+			(len(fullTextSearchFields(oldCollection)) > 0 || len(fullTextSearchFields(newCollection)) > 0)
+
+		if needFullTextSearchUpdate {
+			if err := dropFullTextSearchTable(txApp, oldCollection); err != nil {
+				return err
+			}
+		}
+
 		// check for renamed table
 		if needTableRename {
 			_, err := txApp.DB().RenameTable("{{"+oldTableName+"}}", "{{"+newTableName+"}}").Execute()
@@ -77,15 +92,42 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 			}
 		}
 
-		// check for deleted columns
-		for _, oldField := range oldFields {
-			if f := newFields.GetById(oldField.GetId()); f != nil {
-				continue // exist
+		// check for generated columns with changed definition
+		//
+		// note: sqlite doesn't support altering existing columns so
+		// they are dropped and reinserted with the new definition
+		toRecreate := map[string]struct{}{}
+		for _, field := range newFields {
+			if gc, ok := field.(GeneratedColumn); !ok || !gc.IsGeneratedColumn() {
+				continue
 			}
 
-			_, err := txApp.DB().DropColumn(newTableName, oldField.GetName()).Execute()
-			if err != nil {
-				return fmt.Errorf("failed to drop column %s - %w", oldField.GetName(), err)
+			oldField := oldFields.GetById(field.GetId())
+			if oldField != nil && oldField.ColumnType(txApp) != field.ColumnType(txApp) {
+				toRecreate[field.GetId()] = struct{}{}
+			}
+		}
+
+		// check for deleted columns
+		//
+		// note: the generated columns are dropped first because sqlite
+		// doesn't allow dropping columns used in a generated column expression
+		for _, generatedFirst := range []bool{true, false} {
+			for _, oldField := range oldFields {
+				gc, ok := oldField.(GeneratedColumn)
+				if generatedFirst != (ok && gc.IsGeneratedColumn()) {
+					continue
+				}
+
+				_, recreate := toRecreate[oldField.GetId()]
+				if f := newFields.GetById(oldField.GetId()); f != nil && !recreate {
+					continue // exist
+				}
+
+				_, err := txApp.DB().DropColumn(newTableName, oldField.GetName()).Execute()
+				if err != nil {
+					return fmt.Errorf("failed to drop column %s - %w", oldField.GetName(), err)
+				}
 			}
 		}
 
@@ -93,6 +135,9 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 		toRename := map[string]string{}
 		for _, field := range newFields {
 			oldField := oldFields.GetById(field.GetId())
+			if _, recreate := toRecreate[field.GetId()]; recreate {
+				oldField = nil // was dropped above
+			}
 			// Note:
 			// We are using a temporary column name when adding or renaming columns
 			// to ensure that there are no name collisions in case there is
@@ -133,7 +178,13 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 		}
 
 		if needIndexesUpdate {
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
+		}
+
+		if needFullTextSearchUpdate {
+			return createFullTextSearchTable(txApp, newCollection)
 		}
 
 		return nil
@@ -362,3 +413,135 @@ func createCollectionIndexes(app App, collection *Collection) error {
 		return nil
 	})
 }
+
+// fullTextSearchTableName returns the name of the FTS5 virtual table
+// with the full-text search index of the specified collection.
+func fullTextSearchTableName(collection *Collection) string {
+	return "_fts_" + collection.Id
+}
+
+// fullTextSearchFields returns the names of the collection fields
+// that are part of the collection full-text search index.
+func fullTextSearchFields(collection *Collection) []string {
+	if collection.IsView() {
+		return nil // views don't have records table
+	}
+
+	var result []string
+
+	for _, field := range collection.Fields {
+		if fts, ok := field.(FullTextSearchable); ok && fts.IsFullTextSearchable() {
+			result = append(result, field.GetName())
+		}
+	}
+
+	return result
+}
+
+// checkFullTextSearchField returns a validation rule that checks
+// whether the field could be included in the FTS5 table.
+//
+// Hidden fields are not allowed because the match() filter
+// function would otherwise allow querying their values.
+func checkFullTextSearchField(name string, hidden bool) validation.RuleFunc {
+	return func(value any) error {
+		if strings.EqualFold(name, "rank") || strings.EqualFold(name, "rowid") {
+			return validation.NewError(
+				"validation_invalid_searchable_field_name",
+				"The field name is reserved and the field cannot be searchable.",
+			)
+		}
+
+		if hidden {
+			return validation.NewError(
+				"validation_hidden_searchable_field",
+				"Hidden fields cannot be searchable.",
+			)
+		}
+
+		return nil
+	}
+}
+
+// createFullTextSearchTable creates and populates the full-text search
+// FTS5 table of the specified collection (if it has searchable fields).
+//
+// The FTS5 table uses the records table as external content and it
+// is kept in sync with the records table changes using triggers.
+func createFullTextSearchTable(app App, collection *Collection) error {
+	fields := fullTextSearchFields(collection)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	ftsTable := fullTextSearchTableName(collection)
+
+	cols := make([]string, len(fields))
+	newCols := make([]string, len(fields))
+	oldCols := make([]string, len(fields))
+	for i, name := range fields {
+		cols[i] = "[[" + name + "]]"
+		newCols[i] = "new.[[" + name + "]]"
+		oldCols[i] = "old.[[" + name + "]]"
+	}
+
+	insertSQL := fmt.Sprintf(
+		"INSERT INTO {{%s}}([[rowid]], %s) VALUES (new.[[rowid]], %s);",
+		ftsTable, strings.Join(cols, ", "), strings.Join(newCols, ", "),
+	)
+	deleteSQL := fmt.Sprintf(
+		"INSERT INTO {{%s}}({{%s}}, [[rowid]], %s) VALUES ('delete', old.[[rowid]], %s);",
+		ftsTable, ftsTable, strings.Join(cols, ", "), strings.Join(oldCols, ", "),
+	)
+
+	queries := []string{
+		fmt.Sprintf(
+			"CREATE VIRTUAL TABLE {{%s}} USING fts5(%s, content='%s', content_rowid='rowid', tokenize='unicode61 remove_diacritics 2')",
+			ftsTable, strings.Join(cols, ", "), collection.Name,
+		),
+		fmt.Sprintf("CREATE TRIGGER [[%s_ai]] AFTER INSERT ON {{%s}} BEGIN %s END", ftsTable, collection.Name, insertSQL),
+		fmt.Sprintf("CREATE TRIGGER [[%s_ad]] AFTER DELETE ON {{%s}} BEGIN %s END", ftsTable, collection.Name, deleteSQL),
+		fmt.Sprintf("CREATE TRIGGER [[%s_au]] AFTER UPDATE ON {{%s}} BEGIN %s %s END", ftsTable, collection.Name, deleteSQL, insertSQL),
+	}
+
+	return app.RunInTransaction(func(txApp App) error {
+		for _, query := range queries {
+			if _, err := txApp.DB().NewQuery(query).Execute(); err != nil {
+				return fmt.Errorf("failed to create the full-text search table - %w", err)
+			}
+		}
+
+		return rebuildFullTextSearchTable(txApp, collection)
+	})
+}
+
+// dropFullTextSearchTable drops the full-text search FTS5 table
+// of the specified collection and its triggers (if any).
+func dropFullTextSearchTable(app App, collection *Collection) error {
+	ftsTable := fullTextSearchTableName(collection)
+
+	return app.RunInTransaction(func(txApp App) error {
+		for _, suffix := range []string{"_ai", "_ad", "_au"} {
+			_, err := txApp.DB().NewQuery(fmt.Sprintf("DROP TRIGGER IF EXISTS [[%s]]", ftsTable+suffix)).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return txApp.DeleteTable(ftsTable)
+	})
+}
+
+// rebuildFullTextSearchTable reindexes all records of the specified collection
+// (if it has searchable fields).
+func rebuildFullTextSearchTable(app App, collection *Collection) error {
+	if len(fullTextSearchFields(collection)) == 0 {
+		return nil
+	}
+
+	ftsTable := fullTextSearchTableName(collection)
+
+	_, err := app.DB().NewQuery(fmt.Sprintf("INSERT INTO {{%s}}({{%s}}) VALUES('rebuild')", ftsTable, ftsTable)).Execute()
+
+	return err
+}
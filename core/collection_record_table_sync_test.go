@@ -3,6 +3,7 @@ package core_test
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/dbx"
@@ -294,3 +295,139 @@ func TestSingleVsMultipleValuesNormalization(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncRecordTableSchemaFullTextSearch(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("fts_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "title", Searchable: true},
+		&core.EditorField{Name: "content", Searchable: true},
+		&core.TextField{Name: "note"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	ftsTable := "_fts_" + collection.Id
+
+	if !app.HasTable(ftsTable) {
+		t.Fatalf("Expected table %q to be created", ftsTable)
+	}
+
+	create := func(title, content, note string) *core.Record {
+		record := core.NewRecord(collection)
+		record.Set("title", title)
+		record.Set("content", content)
+		record.Set("note", note)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+
+	r1 := create("Hello world", "<p>lorem ipsum</p>", "secret")
+	r2 := create("Other", "<p>hello hello hello</p>", "")
+	r3 := create("Café", "<p>dolor</p>", "hello")
+
+	assertMatch := func(name string, filter string, sort string, expectedIds ...string) {
+		t.Helper()
+
+		records, err := app.FindRecordsByFilter(collection, filter, sort, 0, 0)
+		if err != nil {
+			t.Fatalf("[%s] %v", name, err)
+		}
+
+		ids := make([]string, len(records))
+		for i, r := range records {
+			ids[i] = r.Id
+		}
+
+		if sort == "" {
+			if len(ids) != len(expectedIds) {
+				t.Fatalf("[%s] Expected ids %v, got %v", name, expectedIds, ids)
+			}
+			for _, id := range expectedIds {
+				if !list.ExistInSlice(id, ids) {
+					t.Fatalf("[%s] Expected ids %v, got %v", name, expectedIds, ids)
+				}
+			}
+		} else if strings.Join(ids, ",") != strings.Join(expectedIds, ",") {
+			t.Fatalf("[%s] Expected ordered ids %v, got %v", name, expectedIds, ids)
+		}
+	}
+
+	assertMatch("single term", "match('hello') = true", "", r1.Id, r2.Id)
+	assertMatch("not matching", "match('hello') = false", "", r3.Id)
+	assertMatch("multiple terms", "match('world hello') = true", "", r1.Id)
+	assertMatch("prefix term", "match('lor*') = true", "", r1.Id)
+	assertMatch("diacritics", "match('cafe') = true", "", r3.Id)
+	assertMatch("fts5 syntax chars", `match('"hello:^') = true`, "", r1.Id, r2.Id)
+	assertMatch("empty query", "match('') = true", "")
+	assertMatch("rank sort", "match('hello') = true", "rank('hello'),id", r2.Id, r1.Id)
+	assertMatch("rank sort without match filter", "", "rank('hello'),title", r2.Id, r1.Id, r3.Id)
+
+	// update
+	r1.Set("title", "Goodbye")
+	if err := app.Save(r1); err != nil {
+		t.Fatal(err)
+	}
+	assertMatch("after update (old term)", "match('world') = true", "")
+	assertMatch("after update (new term)", "match('goodbye') = true", "", r1.Id)
+
+	// delete
+	if err := app.Delete(r2); err != nil {
+		t.Fatal(err)
+	}
+	assertMatch("after delete", "match('hello') = true", "")
+
+	// rename the collection and a searchable field
+	collection.Name = "fts_test_renamed"
+	collection.Fields.GetByName("content").SetName("body")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	assertMatch("after rename", "match('dolor') = true", "", r3.Id)
+
+	r4 := create("New", "", "")
+	assertMatch("after rename create", "match('new') = true", "", r4.Id)
+
+	// vacuum (could change the rowids)
+	if err := app.Vacuum(); err != nil {
+		t.Fatal(err)
+	}
+	assertMatch("after vacuum", "match('new') = true", "", r4.Id)
+
+	// remove all searchable fields
+	collection.Fields.GetByName("title").(*core.TextField).Searchable = false
+	collection.Fields.RemoveByName("body")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.HasTable(ftsTable) {
+		t.Fatalf("Expected table %q to be deleted", ftsTable)
+	}
+
+	if _, err := app.FindRecordsByFilter(collection, "match('new') = true", "", 0, 0); err == nil {
+		t.Fatal("Expected match() error for collection without searchable fields")
+	}
+
+	// re-enable and delete the collection
+	collection.Fields.GetByName("title").(*core.TextField).Searchable = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	assertMatch("after re-enable", "match('new') = true", "", r4.Id)
+
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.HasTable(ftsTable) {
+		t.Fatalf("Expected table %q to be deleted with the collection", ftsTable)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core/validators"
 	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/types"
@@ -123,6 +124,7 @@ func (validator *collectionValidator) run() error {
 				!validator.new.IsView(),
 				validation.By(validator.ensureNoSystemFieldsChange),
 				validation.By(validator.ensureNoFieldsTypeChange),
+				validation.By(validator.checkComputedFields),
 			),
 			validation.When(validator.new.IsAuth(), validation.By(validator.checkReservedAuthKeys)),
 			validation.By(validator.checkFieldValidators),
@@ -308,6 +310,108 @@ func (validator *collectionValidator) checkFieldValidators(value any) error {
 	return nil
 }
 
+var (
+	sqlStringLiteralRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlSubqueryRegex      = regexp.MustCompile(`(?i)\bselect\b|;`)
+	sqlCommentRegex       = regexp.MustCompile(`--|/\*`)
+)
+
+func (validator *collectionValidator) checkComputedFields(value any) error {
+	fields, ok := value.(FieldsList)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	// the computed fields expressions could reference only the regular fields
+	columns := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if gc, ok := f.(GeneratedColumn); ok && gc.IsGeneratedColumn() {
+			continue
+		}
+		columns = append(columns, "[["+inflector.Columnify(f.GetName())+"]]")
+	}
+
+	errs := validation.Errors{}
+
+	for i, field := range fields {
+		computed, ok := field.(*ComputedField)
+		if !ok || computed.Expression == "" {
+			continue // not a computed field or missing expression (handled by the field settings validator)
+		}
+
+		// sqlite doesn't allow adding STORED columns to an existing table
+		if computed.Stored && !validator.original.IsNew() {
+			oldField := validator.original.Fields.GetById(computed.Id)
+			if oldField == nil || oldField.ColumnType(validator.app) != computed.ColumnType(validator.app) {
+				errs[strconv.Itoa(i)] = validation.Errors{"stored": validation.NewError(
+					"validation_computed_stored_change",
+					"Stored computed fields can be added or modified only on collection create.",
+				)}
+				continue
+			}
+		}
+
+		expr := sqlStringLiteralRegex.ReplaceAllString(computed.Expression, "''")
+
+		if sqlSubqueryRegex.MatchString(expr) {
+			errs[strconv.Itoa(i)] = validation.Errors{"expression": validation.NewError(
+				"validation_invalid_computed_expression",
+				"Subqueries and multiple statements are not allowed.",
+			)}
+			continue
+		}
+
+		if sqlCommentRegex.MatchString(expr) {
+			errs[strconv.Itoa(i)] = validation.Errors{"expression": validation.NewError(
+				"validation_invalid_computed_expression",
+				"SQL comments are not allowed.",
+			)}
+			continue
+		}
+
+		err := checkGeneratedColumnExpression(validator.app, columns, computed.Expression)
+		if err != nil {
+			errs[strconv.Itoa(i)] = validation.Errors{"expression": validation.NewError(
+				"validation_invalid_computed_expression",
+				fmt.Sprintf("Invalid expression - %s", err.Error()),
+			)}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// checkGeneratedColumnExpression creates a throwaway temp table with the
+// provided columns and a STORED generated column with the specified expression
+// and inserts a single NULL row in it to ensure that the expression
+// references only existing columns and it is deterministic
+// (sqlite checks some of the non-deterministic functions, eg. datetime('now'), only on evaluation).
+func checkGeneratedColumnExpression(app App, columns []string, expression string) error {
+	return app.RunInTransaction(func(txApp App) error {
+		db := txApp.NonconcurrentDB()
+
+		_, err := db.NewQuery(fmt.Sprintf(
+			"CREATE TEMP TABLE {{_pbComputedCheck}} (%s, [[_pbExpression]] GENERATED ALWAYS AS (%s) STORED)",
+			strings.Join(columns, ", "),
+			expression,
+		)).Execute()
+		if err != nil {
+			return err
+		}
+
+		// explicitly drop the table in case of a nested transaction
+		defer db.NewQuery("DROP TABLE IF EXISTS temp.{{_pbComputedCheck}}").Execute()
+
+		_, err = db.NewQuery("INSERT INTO temp.{{_pbComputedCheck}} DEFAULT VALUES").Execute()
+
+		return err
+	})
+}
+
 func (cv *collectionValidator) checkViewQuery(value any) error {
 	v, _ := value.(string)
 	if v == "" {
@@ -3,10 +3,24 @@
 package core
 
 import (
+	"database/sql/driver"
+
 	"github.com/pocketbase/dbx"
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
 )
 
+func init() {
+	for name, fn := range SQLFunctions {
+		sqlite.MustRegisterDeterministicScalarFunction(
+			name,
+			fn.NArgs,
+			func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+				return fn.Func(args)
+			},
+		)
+	}
+}
+
 func DefaultDBConnect(dbPath string) (*dbx.DB, error) {
 	// Note: the busy_timeout pragma must be first because
 	// the connection needs to be set to block on busy before WAL mode
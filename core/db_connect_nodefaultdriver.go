@@ -4,6 +4,10 @@ package core
 
 import "github.com/pocketbase/dbx"
 
+// DefaultDBConnect is not available when the no_default_driver tag is used.
+//
+// Note that the custom driver is also responsible for registering
+// the custom [SQLFunctions].
 func DefaultDBConnect(dbPath string) (*dbx.DB, error) {
 	panic("DBConnect config option must be set when the no_default_driver tag is used!")
 }
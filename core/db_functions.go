@@ -0,0 +1,135 @@
+package core
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/pocketbase/tools/vector"
+)
+
+// SQLFunction defines a custom deterministic SQLite scalar function.
+type SQLFunction struct {
+	// Func is the function implementation.
+	Func func(args []driver.Value) (driver.Value, error)
+
+	// NArgs is the number of the function arguments (-1 for variadic).
+	NArgs int32
+}
+
+// SQLFunctions is a list with the custom SQLite scalar functions
+// used by some of the builtin filter token functions (eg. vectorDistance, geoWithin, match).
+//
+// They are automatically registered with the default driver.
+// If you are using a custom driver (aka. the no_default_driver build tag)
+// you'll have to register them manually, for example with mattn/go-sqlite3:
+//
+//	sql.Register("pb_sqlite3", &sqlite3.SQLiteDriver{
+//		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+//			for name, fn := range core.SQLFunctions {
+//				err := conn.RegisterFunc(name, func(args ...any) (any, error) {
+//					return fn.Func(args)
+//				}, true)
+//				if err != nil {
+//					return err
+//				}
+//			}
+//			return nil
+//		},
+//	})
+var SQLFunctions = map[string]SQLFunction{
+	vector.SQLFunctionName: {NArgs: 3, Func: vector.SQLFunction},
+	"geo_within":           {NArgs: 2, Func: geoWithinSQLFunction},
+	"fts_query":            {NArgs: 1, Func: ftsQuerySQLFunction},
+}
+
+// geoWithinSQLFunction implements the geo_within(point, polygon) SQLite function.
+//
+// The point argument must be a serialized GeoPoint and the polygon
+// argument must be serialized GeoJSON Polygon or MultiPolygon geometry.
+//
+// Resolves to NULL if any of the arguments is empty or invalid.
+func geoWithinSQLFunction(args []driver.Value) (driver.Value, error) {
+	if len(args) != 2 {
+		return nil, nil
+	}
+
+	point, ok := sqlArgString(args[0])
+	if !ok {
+		return nil, nil
+	}
+
+	rawPolygon, ok := sqlArgString(args[1])
+	if !ok {
+		return nil, nil
+	}
+
+	var p types.GeoPoint
+	if err := p.Scan(point); err != nil {
+		return nil, nil
+	}
+
+	var polygon types.GeoPolygon
+	if err := polygon.Scan(rawPolygon); err != nil || polygon.IsZero() {
+		return nil, nil
+	}
+
+	// fast bbox check
+	minLon, minLat, maxLon, maxLat := polygon.BBox()
+	if p.Lon < minLon || p.Lon > maxLon || p.Lat < minLat || p.Lat > maxLat {
+		return false, nil
+	}
+
+	return polygon.Contains(p), nil
+}
+
+// ftsQuerySQLFunction implements the fts_query(text) SQLite function.
+//
+// It converts a plain text search query into a safe FTS5 MATCH expression
+// where each whitespace separated term is matched as a quoted string
+// (aka. all terms must be present in any order).
+// A term with a trailing "*" (eg. "hel*") is matched as a prefix.
+//
+// Resolves to a query that matches nothing if the text has no terms.
+func ftsQuerySQLFunction(args []driver.Value) (driver.Value, error) {
+	if len(args) != 1 {
+		return `""`, nil
+	}
+
+	text, _ := sqlArgString(args[0])
+
+	terms := strings.Fields(text)
+
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term, isPrefix := strings.CutSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if term == "" {
+			continue
+		}
+
+		part := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if isPrefix {
+			part += "*"
+		}
+
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		return `""`, nil
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+func sqlArgString(arg driver.Value) (string, bool) {
+	switch v := arg.(type) {
+	case string:
+		return v, v != ""
+	case []byte:
+		return string(v), len(v) > 0
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,85 @@
+package core_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestGeoWithinSQLFunction(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	polygon := `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]]}`
+
+	scenarios := []struct {
+		name     string
+		point    any
+		polygon  any
+		expected sql.NullBool
+	}{
+		{"null point", nil, polygon, sql.NullBool{}},
+		{"empty polygon", `{"lon":1,"lat":1}`, "", sql.NullBool{}},
+		{"invalid polygon", `{"lon":1,"lat":1}`, "invalid", sql.NullBool{}},
+		{"invalid point", "invalid", polygon, sql.NullBool{}},
+		{"inside", `{"lon":1,"lat":1}`, polygon, sql.NullBool{Valid: true, Bool: true}},
+		{"in hole", `{"lon":5,"lat":5}`, polygon, sql.NullBool{Valid: true, Bool: false}},
+		{"outside bbox", `{"lon":-1,"lat":5}`, polygon, sql.NullBool{Valid: true, Bool: false}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var result sql.NullBool
+
+			err := app.DB().NewQuery("SELECT geo_within({:point}, {:polygon})").
+				Bind(dbx.Params{"point": s.point, "polygon": s.polygon}).
+				Row(&result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestFTSQuerySQLFunction(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name     string
+		text     any
+		expected string
+	}{
+		{"null", nil, `""`},
+		{"empty", "", `""`},
+		{"only whitespaces", "  \t ", `""`},
+		{"only stars", "* **", `""`},
+		{"single term", "hello", `"hello"`},
+		{"multiple terms", " hello   world ", `"hello" "world"`},
+		{"prefix term", "hel* wor**", `"hel"* "wor"*`},
+		{"fts5 syntax", `a"b OR (c) NEAR:^`, `"a""b" "OR" "(c)" "NEAR:^"`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var result string
+
+			err := app.DB().NewQuery("SELECT fts_query({:text})").
+				Bind(dbx.Params{"text": s.text}).
+				Row(&result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, result)
+			}
+		})
+	}
+}
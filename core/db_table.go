@@ -11,7 +11,9 @@ import (
 func (app *BaseApp) TableColumns(tableName string) ([]string, error) {
 	columns := []string{}
 
-	err := app.ConcurrentDB().NewQuery("SELECT name FROM PRAGMA_TABLE_INFO({:tableName})").
+	// note: table_xinfo is used to include also the generated columns
+	// (hidden=1 are the virtual table hidden columns)
+	err := app.ConcurrentDB().NewQuery("SELECT name FROM PRAGMA_TABLE_XINFO({:tableName}) WHERE [[hidden]] != 1").
 		Bind(dbx.Params{"tableName": tableName}).
 		Column(&columns)
 
@@ -121,8 +123,26 @@ func (app *BaseApp) hasTable(db dbx.Builder, tableName string) bool {
 }
 
 // Vacuum executes VACUUM on the data.db in order to reclaim unused data db disk space.
+//
+// Because VACUUM could change the rowids of the records tables,
+// the collections full-text search indexes are rebuilt after that.
 func (app *BaseApp) Vacuum() error {
-	return app.vacuum(app.NonconcurrentDB())
+	if err := app.vacuum(app.NonconcurrentDB()); err != nil {
+		return err
+	}
+
+	collections, err := app.FindAllCollections(CollectionTypeBase, CollectionTypeAuth)
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range collections {
+		if err := rebuildFullTextSearchTable(app, collection); err != nil {
+			return fmt.Errorf("failed to rebuild the %q full-text search index: %w", collection.Name, err)
+		}
+	}
+
+	return nil
 }
 
 // AuxVacuum executes VACUUM on the auxiliary.db in order to reclaim unused auxiliary db disk space.
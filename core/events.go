@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/router"
@@ -463,6 +464,15 @@ type RecordsListRequestEvent struct {
 	Result  *search.Result
 }
 
+type RecordsAggregateRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	// Result.Items holds the aggregated groups.
+	Result *search.Result
+}
+
 type RecordRequestEvent struct {
 	hook.Event
 	*RequestEvent
@@ -479,6 +489,35 @@ type RecordEnrichEvent struct {
 	RequestInfo *RequestInfo
 }
 
+// RecordFileScanEvent defines the event data of a single new record file scan.
+//
+// The handlers are expected to scan the File content and to set
+// the scan Status (and optionally the detection Signature).
+type RecordFileScanEvent struct {
+	hook.Event
+	App App
+	baseRecordEventData
+	Context context.Context
+
+	FileField *FileField
+	File      *filesystem.File
+
+	// Status is the scan result status (FileScanStatusClean, FileScanStatusInfected
+	// or empty string if the file wasn't scanned).
+	Status string
+
+	// Signature is the optional name of the detected threat or content violation.
+	Signature string
+
+	// Action specifies how to handle an infected file
+	// (FileScanActionReject or FileScanActionQuarantine).
+	Action string
+
+	// QuarantinePrefix is the storage prefix where the infected files
+	// are moved when Action is FileScanActionQuarantine.
+	QuarantinePrefix string
+}
+
 // -------------------------------------------------------------------
 // Auth Record API events data
 // -------------------------------------------------------------------
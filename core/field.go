@@ -166,6 +166,21 @@ type MultiValuer interface {
 	IsMultiple() bool
 }
 
+// GeneratedColumn defines a field interface for fields whose column value
+// is generated by the database (eg. SQLite generated columns) and
+// therefore must be excluded from the record insert/update statements.
+type GeneratedColumn interface {
+	// IsGeneratedColumn reports whether the field column value is generated by the database.
+	IsGeneratedColumn() bool
+}
+
+// FullTextSearchable defines a field interface for fields whose values
+// could be included in the collection FTS5 full-text search index.
+type FullTextSearchable interface {
+	// IsFullTextSearchable reports whether the field is part of the collection full-text search index.
+	IsFullTextSearchable() bool
+}
+
 // RecordInterceptor defines a field interface for reacting to various
 // Record related operations (create, delete, validate, etc.).
 type RecordInterceptor interface {
@@ -0,0 +1,257 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeComputed] = func() Field {
+		return &ComputedField{}
+	}
+}
+
+const FieldTypeComputed = "computed"
+
+// Supported ComputedField value types.
+const (
+	ComputedValueTypeText   = "text"
+	ComputedValueTypeNumber = "number"
+	ComputedValueTypeBool   = "bool"
+)
+
+var (
+	_ Field             = (*ComputedField)(nil)
+	_ SetterFinder      = (*ComputedField)(nil)
+	_ RecordInterceptor = (*ComputedField)(nil)
+	_ GeneratedColumn   = (*ComputedField)(nil)
+)
+
+// ComputedField defines "computed" type field whose value is derived
+// from the other record fields by a SQL expression.
+//
+// The field is backed by a SQLite generated column (GENERATED ALWAYS AS)
+// and its value cannot be changed with record.Set(). The value is refreshed
+// automatically after each successful record create/update.
+//
+// The expression could reference only the regular (non-computed) fields
+// of the same collection, for example:
+//
+//	first_name || ' ' || last_name
+//	price * quantity
+//	lower(trim(title))
+//
+// The respective zero record field value depends on the ValueType option
+// (empty string, 0 or false).
+type ComputedField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Expression (required) is the SQL expression used to generate the field value.
+	//
+	// It must be deterministic and cannot contain subqueries, SQL comments,
+	// aggregate or window functions (see https://www.sqlite.org/gencol.html).
+	Expression string `form:"expression" json:"expression"`
+
+	// ValueType specifies how the expression result should be treated
+	// (ComputedValueTypeText, ComputedValueTypeNumber or ComputedValueTypeBool).
+	//
+	// If empty, defaults to ComputedValueTypeText.
+	ValueType string `form:"valueType" json:"valueType"`
+
+	// Stored specifies whether the generated value should be stored
+	// on disk (STORED) or calculated on read (VIRTUAL).
+	//
+	// Note that because of SQLite limitations stored computed fields
+	// can be added only on collection create and their expression
+	// cannot be changed afterwards.
+	Stored bool `form:"stored" json:"stored"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *ComputedField) Type() string {
+	return FieldTypeComputed
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *ComputedField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *ComputedField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *ComputedField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *ComputedField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *ComputedField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *ComputedField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *ComputedField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *ComputedField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// IsGeneratedColumn implements the [GeneratedColumn] interface.
+func (f *ComputedField) IsGeneratedColumn() bool {
+	return true
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *ComputedField) ColumnType(app App) string {
+	var affinity string
+	switch f.ValueType {
+	case ComputedValueTypeNumber:
+		affinity = "NUMERIC"
+	case ComputedValueTypeBool:
+		affinity = "BOOLEAN"
+	default:
+		affinity = "TEXT"
+	}
+
+	storage := "VIRTUAL"
+	if f.Stored {
+		storage = "STORED"
+	}
+
+	return affinity + " GENERATED ALWAYS AS (" + f.Expression + ") " + storage
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *ComputedField) PrepareValue(record *Record, raw any) (any, error) {
+	if v, ok := raw.([]byte); ok {
+		raw = string(v)
+	}
+
+	switch f.ValueType {
+	case ComputedValueTypeNumber:
+		return cast.ToFloat64(raw), nil
+	case ComputedValueTypeBool:
+		return cast.ToBool(raw), nil
+	default:
+		return cast.ToString(raw), nil
+	}
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *ComputedField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	return nil // the value is always generated by the db
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+//
+// Note that the expression column references are checked as part of
+// the collection validation since they depend on the other collection fields.
+func (f *ComputedField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Expression, validation.Required, validation.Length(1, 1000)),
+		validation.Field(
+			&f.ValueType,
+			validation.In(ComputedValueTypeText, ComputedValueTypeNumber, ComputedValueTypeBool),
+		),
+	)
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *ComputedField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		// return noopSetter to disallow updating the value with record.Set()
+		return noopSetter
+	default:
+		return nil
+	}
+}
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *ComputedField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	switch actionName {
+	case InterceptorActionCreateExecute, InterceptorActionUpdateExecute:
+		if err := actionFunc(); err != nil {
+			return err
+		}
+
+		return f.refreshValue(ctx, app, record)
+	default:
+		return actionFunc()
+	}
+}
+
+// refreshValue reloads the generated column value of the provided record.
+func (f *ComputedField) refreshValue(ctx context.Context, app App, record *Record) error {
+	var raw sql.NullString
+
+	err := app.DB().Select(f.Name).
+		From(record.Collection().Name).
+		Where(dbx.HashExp{FieldNameId: record.Id}).
+		Limit(1).
+		WithContext(ctx).
+		Row(&raw)
+	if err != nil {
+		return err
+	}
+
+	var value any
+	if raw.Valid {
+		value, err = f.PrepareValue(record, raw.String)
+	} else {
+		value, err = f.PrepareValue(record, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	record.SetRaw(f.Name, value)
+
+	return nil
+}
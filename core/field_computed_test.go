@@ -0,0 +1,376 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestComputedFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeComputed)
+}
+
+func TestComputedFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		field    *core.ComputedField
+		expected string
+	}{
+		{
+			&core.ComputedField{Expression: "a || b"},
+			"TEXT GENERATED ALWAYS AS (a || b) VIRTUAL",
+		},
+		{
+			&core.ComputedField{Expression: "a * b", ValueType: core.ComputedValueTypeNumber, Stored: true},
+			"NUMERIC GENERATED ALWAYS AS (a * b) STORED",
+		},
+		{
+			&core.ComputedField{Expression: "a > b", ValueType: core.ComputedValueTypeBool},
+			"BOOLEAN GENERATED ALWAYS AS (a > b) VIRTUAL",
+		},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.field.Expression), func(t *testing.T) {
+			if v := s.field.ColumnType(app); v != s.expected {
+				t.Fatalf("Expected\n%q\ngot\n%q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestComputedFieldPrepareValue(t *testing.T) {
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		valueType string
+		raw       any
+		expected  any
+	}{
+		{"", nil, ""},
+		{"", "abc", "abc"},
+		{"", []byte("abc"), "abc"},
+		{"", 123, "123"},
+		{core.ComputedValueTypeNumber, nil, 0.0},
+		{core.ComputedValueTypeNumber, "", 0.0},
+		{core.ComputedValueTypeNumber, "1.5", 1.5},
+		{core.ComputedValueTypeNumber, 2, 2.0},
+		{core.ComputedValueTypeBool, nil, false},
+		{core.ComputedValueTypeBool, "1", true},
+		{core.ComputedValueTypeBool, "0", false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s_%#v", i, s.valueType, s.raw), func(t *testing.T) {
+			f := &core.ComputedField{ValueType: s.valueType}
+
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != s.expected {
+				t.Fatalf("Expected %#v, got %#v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestComputedFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeComputed)
+	testDefaultFieldNameValidation(t, core.FieldTypeComputed)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.ComputedField
+		expectErrors []string
+	}{
+		{
+			"zero value",
+			func() *core.ComputedField {
+				return &core.ComputedField{Id: "test", Name: "test"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"invalid value type",
+			func() *core.ComputedField {
+				return &core.ComputedField{Id: "test", Name: "test", Expression: "1", ValueType: "abc"}
+			},
+			[]string{"valueType"},
+		},
+		{
+			"valid",
+			func() *core.ComputedField {
+				return &core.ComputedField{Id: "test", Name: "test", Expression: "1", ValueType: core.ComputedValueTypeNumber}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field().ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestComputedFieldFindSetter(t *testing.T) {
+	field := &core.ComputedField{Name: "test"}
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(field)
+
+	record := core.NewRecord(collection)
+	record.Set("test", "abc")
+
+	if v := record.GetRaw("test"); v != "" {
+		t.Fatalf("Expected the value to remain unchanged, got %#v", v)
+	}
+}
+
+func TestComputedFieldRecordSaveAndFilter(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_computed")
+	collection.Fields.Add(
+		&core.TextField{Name: "first"},
+		&core.TextField{Name: "last"},
+		&core.NumberField{Name: "price"},
+		&core.NumberField{Name: "quantity"},
+		&core.ComputedField{Name: "full", Expression: "first || ' ' || last"},
+		&core.ComputedField{Name: "total", Expression: "price * quantity", ValueType: core.ComputedValueTypeNumber, Stored: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []map[string]any{
+		{"first": "John", "last": "Doe", "price": 10, "quantity": 3},
+		{"first": "Jane", "last": "Doe", "price": 2.5, "quantity": 2},
+	}
+	for _, d := range data {
+		record := core.NewRecord(collection)
+		record.Load(d)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedFull := d["first"].(string) + " " + d["last"].(string)
+		if v := record.GetString("full"); v != expectedFull {
+			t.Fatalf("Expected full %q, got %q", expectedFull, v)
+		}
+	}
+
+	// filter and sort
+	records, err := app.FindRecordsByFilter(collection, "total > 1 && full ~ 'Doe'", "-total", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if v := records[0].GetFloat("total"); v != 30 {
+		t.Fatalf("Expected the first record total to be 30, got %v", v)
+	}
+	if v := records[1].GetFloat("total"); v != 5 {
+		t.Fatalf("Expected the second record total to be 5, got %v", v)
+	}
+
+	// update and refresh
+	records[1].Set("quantity", 10)
+	if err := app.Save(records[1]); err != nil {
+		t.Fatal(err)
+	}
+	if v := records[1].GetFloat("total"); v != 25 {
+		t.Fatalf("Expected the updated record total to be 25, got %v", v)
+	}
+
+	// change the virtual column expression
+	collection.Fields.Add(&core.ComputedField{
+		Id:         collection.Fields.GetByName("full").GetId(),
+		Name:       "full_renamed",
+		Expression: "last || ', ' || first",
+	})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.FindFirstRecordByFilter(collection, "full_renamed = 'Doe, John'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := record.GetFloat("total"); v != 30 {
+		t.Fatalf("Expected total 30, got %v", v)
+	}
+}
+
+func TestComputedFieldCollectionValidation(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	existing := core.NewBaseCollection("test_computed")
+	existing.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		collection   func() *core.Collection
+		expectErrors []string
+	}{
+		{
+			"unknown field reference",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "missing || 'a'"})
+				return c
+			},
+			[]string{"fields"},
+		},
+		{
+			"computed field reference",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(
+					&core.ComputedField{Name: "a", Expression: "id"},
+					&core.ComputedField{Name: "b", Expression: "a"},
+				)
+				return c
+			},
+			[]string{"fields"},
+		},
+		{
+			"subquery",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "(SELECT 1)"})
+				return c
+			},
+			[]string{"fields"},
+		},
+		{
+			"select in string literal",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "id || ' select'"})
+				return c
+			},
+			[]string{},
+		},
+		{
+			"line comment",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "id -- comment"})
+				return c
+			},
+			[]string{"fields"},
+		},
+		{
+			"block comment",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "id /* comment */"})
+				return c
+			},
+			[]string{"fields"},
+		},
+		{
+			"comment markers in string literal",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "id || ' -- /* '"})
+				return c
+			},
+			[]string{},
+		},
+		{
+			"non-deterministic random()",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "random()", ValueType: core.ComputedValueTypeNumber})
+				return c
+			},
+			[]string{"fields"},
+		},
+		{
+			"non-deterministic datetime('now')",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "datetime('now')"})
+				return c
+			},
+			[]string{"fields"},
+		},
+		{
+			"deterministic datetime()",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "datetime(created)"})
+				c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+				return c
+			},
+			[]string{},
+		},
+		{
+			"stored field on create",
+			func() *core.Collection {
+				c := core.NewBaseCollection("new_computed")
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "upper(id)", Stored: true})
+				return c
+			},
+			[]string{},
+		},
+		{
+			"new virtual field on update",
+			func() *core.Collection {
+				c, _ := app.FindCollectionByNameOrId(existing.Id)
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "upper(title)"})
+				return c
+			},
+			[]string{},
+		},
+		{
+			"new stored field on update",
+			func() *core.Collection {
+				c, _ := app.FindCollectionByNameOrId(existing.Id)
+				c.Fields.Add(&core.ComputedField{Name: "test", Expression: "upper(title)", Stored: true})
+				return c
+			},
+			[]string{"fields"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.collection())
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+
+			// the throwaway check table shouldn't be left behind
+			var total int
+			err := app.DB().NewQuery("SELECT count(*) FROM sqlite_temp_master WHERE name = '_pbComputedCheck'").Row(&total)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 0 {
+				t.Fatal("Expected the temp check table to be dropped")
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeDecimal] = func() Field {
+		return &DecimalField{}
+	}
+}
+
+const FieldTypeDecimal = "decimal"
+
+const (
+	// DefaultDecimalPrecision is the default and max allowed DecimalField.Precision
+	// (aka. the max number of digits that could be stored in int64).
+	DefaultDecimalPrecision = 18
+)
+
+var (
+	_ Field        = (*DecimalField)(nil)
+	_ SetterFinder = (*DecimalField)(nil)
+	_ DriverValuer = (*DecimalField)(nil)
+)
+
+var (
+	errDecimalInvalid   = validation.NewError("validation_invalid_decimal", "Must be a valid decimal number.")
+	errDecimalScale     = validation.NewError("validation_decimal_scale_constraint", "Too many decimal places.")
+	errDecimalPrecision = validation.NewError("validation_decimal_precision_constraint", "Too many digits.")
+)
+
+// DecimalField defines "decimal" type field for storing exact fixed-point
+// numbers (eg. prices and other money amounts).
+//
+// The value is stored in the database as scaled INTEGER (eg. "12.34" with Scale 2 is stored as 1234)
+// which allows exact comparisons, sorting and aggregations
+// (the sum, min and max aggregates are returned as decimal strings,
+// while the avg aggregate is calculated with the REAL value).
+// In filter expressions the stored integer is compared directly with the
+// literal operands scaled to the same number of decimal places.
+// Note that with other operands (eg. another field) the value is compared
+// as REAL number, which is exact for values with up to 15 significant digits.
+//
+// The respective zero record field value is the canonical "0" string
+// with Scale number of decimal places (eg. "0.00").
+// The value is always serialized as string to prevent the JS float precision loss.
+//
+// The following additional setter keys are available:
+//
+//   - "fieldName+" - adds to the existing record value. For example:
+//     record.Set("total+", "5.25")
+//   - "fieldName-" - subtracts from the existing record value. For example:
+//     record.Set("total-", "5.25")
+type DecimalField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Precision specifies the max total number of digits (both before and after the decimal point).
+	//
+	// If zero, fallbacks to DefaultDecimalPrecision.
+	Precision int `form:"precision" json:"precision"`
+
+	// Scale specifies the number of digits after the decimal point.
+	//
+	// Scale cannot be changed after the field creation because it
+	// determines how the existing values are stored.
+	Scale int `form:"scale" json:"scale"`
+
+	// Min specifies the min allowed field value (eg. "0.01").
+	//
+	// Leave it empty to skip the validator.
+	Min string `form:"min" json:"min"`
+
+	// Max specifies the max allowed field value (eg. "999.99").
+	//
+	// Leave it empty to skip the validator.
+	Max string `form:"max" json:"max"`
+
+	// Required will require the field value to be non-zero.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *DecimalField) Type() string {
+	return FieldTypeDecimal
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *DecimalField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *DecimalField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *DecimalField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *DecimalField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *DecimalField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *DecimalField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *DecimalField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *DecimalField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *DecimalField) ColumnType(app App) string {
+	return "INTEGER DEFAULT 0 NOT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+//
+// The raw value is expected to be the scaled db integer.
+func (f *DecimalField) PrepareValue(record *Record, raw any) (any, error) {
+	return f.Format(cast.ToInt64(raw)), nil
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *DecimalField) DriverValue(record *Record) (driver.Value, error) {
+	return f.Parse(record.GetRaw(f.Name))
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *DecimalField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	raw, ok := record.GetRaw(f.Name).(string)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	val, err := f.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if val == 0 {
+		if f.Required {
+			if err := validation.Required.Validate(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if f.Min != "" {
+		if min, err := f.Parse(f.Min); err == nil && val < min {
+			return validation.NewError("validation_min_number_constraint", "Must be larger than "+f.Format(min)).
+				SetParams(map[string]any{"min": f.Format(min)})
+		}
+	}
+
+	if f.Max != "" {
+		if max, err := f.Parse(f.Max); err == nil && val > max {
+			return validation.NewError("validation_max_number_constraint", "Must be less than "+f.Format(max)).
+				SetParams(map[string]any{"max": f.Format(max)})
+		}
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *DecimalField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	maxRules := []validation.Rule{validation.By(f.checkBoundary)}
+	if f.Min != "" && f.Max != "" {
+		maxRules = append(maxRules, validation.By(f.checkMaxGreaterThanMin))
+	}
+
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Precision, validation.Min(0), validation.Max(DefaultDecimalPrecision)),
+		validation.Field(
+			&f.Scale,
+			validation.Min(0),
+			validation.Max(f.precision()),
+			validation.By(f.checkScaleChange(app, collection)),
+		),
+		validation.Field(&f.Min, validation.By(f.checkBoundary)),
+		validation.Field(&f.Max, maxRules...),
+	)
+}
+
+func (f *DecimalField) checkBoundary(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	_, err := f.Parse(v)
+
+	return err
+}
+
+func (f *DecimalField) checkMaxGreaterThanMin(value any) error {
+	min, minErr := f.Parse(f.Min)
+	max, maxErr := f.Parse(f.Max)
+	if minErr != nil || maxErr != nil {
+		return nil // already checked by checkBoundary
+	}
+
+	if max < min {
+		return validation.NewError("validation_min_greater_equal_than_required", "Must be no less than "+f.Format(min))
+	}
+
+	return nil
+}
+
+func (f *DecimalField) checkScaleChange(app App, collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		if collection.IsNew() {
+			return nil // nothing to check
+		}
+
+		oldCollection, err := app.FindCachedCollectionByNameOrId(collection.Id)
+		if err != nil {
+			return nil // new or missing collection
+		}
+
+		oldField, ok := oldCollection.Fields.GetById(f.Id).(*DecimalField)
+		if ok && oldField != nil && oldField.Scale != f.Scale {
+			return validation.NewError("validation_decimal_scale_change", "The scale of an existing decimal field cannot be changed.")
+		}
+
+		return nil
+	}
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *DecimalField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		return f.setValue
+	case f.Name + "+":
+		return f.addValue
+	case f.Name + "-":
+		return f.subtractValue
+	default:
+		return nil
+	}
+}
+
+func (f *DecimalField) setValue(record *Record, raw any) {
+	val, err := f.Parse(raw)
+	if err != nil {
+		// store the raw value as it is to trigger the validation error
+		record.SetRaw(f.Name, f.rawString(raw))
+		return
+	}
+
+	record.SetRaw(f.Name, f.Format(val))
+}
+
+func (f *DecimalField) addValue(record *Record, raw any) {
+	f.sumValue(record, raw, 1)
+}
+
+func (f *DecimalField) subtractValue(record *Record, raw any) {
+	f.sumValue(record, raw, -1)
+}
+
+func (f *DecimalField) sumValue(record *Record, raw any, sign int64) {
+	current, err := f.Parse(record.GetRaw(f.Name))
+	if err != nil {
+		return // the current value is already invalid
+	}
+
+	val, err := f.Parse(raw)
+	if err != nil {
+		record.SetRaw(f.Name, f.rawString(raw))
+		return
+	}
+
+	// note: the sum can't overflow because both values are limited to 18 digits
+	// and the result will be validated against the field precision
+	record.SetRaw(f.Name, f.Format(current+sign*val))
+}
+
+// Format returns the canonical string representation of the
+// provided scaled integer (eg. 1234 with Scale 2 -> "12.34").
+func (f *DecimalField) Format(scaled int64) string {
+	str := strconv.FormatInt(scaled, 10)
+	if f.Scale <= 0 {
+		return str
+	}
+
+	var sign string
+	if scaled < 0 {
+		sign = "-"
+		str = str[1:]
+	}
+
+	if len(str) <= f.Scale {
+		str = strings.Repeat("0", f.Scale-len(str)+1) + str
+	}
+
+	return sign + str[:len(str)-f.Scale] + "." + str[len(str)-f.Scale:]
+}
+
+// Parse parses the provided raw decimal value and returns its
+// scaled integer representation (eg. "12.34" with Scale 2 -> 1234).
+//
+// Returns an error if the value is not a valid decimal number or
+// it doesn't fit in the field Precision and Scale.
+func (f *DecimalField) Parse(raw any) (int64, error) {
+	str := strings.TrimSpace(f.rawString(raw))
+	if str == "" {
+		return 0, nil
+	}
+
+	var negative bool
+	switch str[0] {
+	case '-':
+		negative = true
+		str = str[1:]
+	case '+':
+		str = str[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if (intPart == "" && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, errDecimalInvalid
+	}
+
+	intPart = strings.TrimLeft(intPart, "0")
+	fracPart = strings.TrimRight(fracPart, "0")
+
+	if len(fracPart) > f.Scale {
+		return 0, errDecimalScale
+	}
+
+	if len(intPart)+f.Scale > f.precision() {
+		return 0, errDecimalPrecision
+	}
+
+	digits := intPart + fracPart + strings.Repeat("0", f.Scale-len(fracPart))
+
+	var scaled int64
+	if digits != "" {
+		var err error
+		scaled, err = strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return 0, errDecimalPrecision
+		}
+	}
+
+	if negative {
+		scaled = -scaled
+	}
+
+	return scaled, nil
+}
+
+func (f *DecimalField) precision() int {
+	if f.Precision <= 0 {
+		return DefaultDecimalPrecision
+	}
+
+	return f.Precision
+}
+
+func (f *DecimalField) rawString(raw any) string {
+	switch v := raw.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return cast.ToString(v)
+	}
+}
+
+// scaleDivisor returns the SQL numeric literal that could be used
+// to convert the stored scaled integer to its real value.
+func (f *DecimalField) scaleDivisor() string {
+	return "1" + strings.Repeat("0", f.Scale) + ".0"
+}
+
+func isDigits(str string) bool {
+	for _, c := range str {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,505 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func TestDecimalFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeDecimal)
+}
+
+func TestDecimalFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.DecimalField{}
+
+	expected := "INTEGER DEFAULT 0 NOT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestDecimalFieldParseAndFormat(t *testing.T) {
+	scenarios := []struct {
+		field       *core.DecimalField
+		raw         any
+		expected    int64
+		expectError bool
+		formatted   string
+	}{
+		{&core.DecimalField{Scale: 2}, nil, 0, false, "0.00"},
+		{&core.DecimalField{Scale: 2}, "", 0, false, "0.00"},
+		{&core.DecimalField{Scale: 2}, "abc", 0, true, ""},
+		{&core.DecimalField{Scale: 2}, "1e5", 0, true, ""},
+		{&core.DecimalField{Scale: 2}, ".", 0, true, ""},
+		{&core.DecimalField{Scale: 2}, "1.234", 0, true, ""},
+		{&core.DecimalField{Scale: 2}, "1.230", 123, false, "1.23"},
+		{&core.DecimalField{Scale: 2}, " 12.3 ", 1230, false, "12.30"},
+		{&core.DecimalField{Scale: 2}, "-0.05", -5, false, "-0.05"},
+		{&core.DecimalField{Scale: 2}, "+.5", 50, false, "0.50"},
+		{&core.DecimalField{Scale: 2}, 0.1, 10, false, "0.10"},
+		{&core.DecimalField{Scale: 2}, 7, 700, false, "7.00"},
+		{&core.DecimalField{Scale: 2, Precision: 4}, "99.99", 9999, false, "99.99"},
+		{&core.DecimalField{Scale: 2, Precision: 4}, "100", 0, true, ""},
+		{&core.DecimalField{Scale: 2, Precision: 4}, "00099", 9900, false, "99.00"},
+		{&core.DecimalField{}, "123456789012345678", 123456789012345678, false, "123456789012345678"},
+		{&core.DecimalField{}, "1234567890123456789", 0, true, ""},
+		{&core.DecimalField{}, "-12", -12, false, "-12"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := s.field.Parse(s.raw)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if v != s.expected {
+				t.Fatalf("Expected %d, got %d", s.expected, v)
+			}
+
+			if f := s.field.Format(v); f != s.formatted {
+				t.Fatalf("Expected formatted %q, got %q", s.formatted, f)
+			}
+		})
+	}
+}
+
+func TestDecimalFieldPrepareValue(t *testing.T) {
+	f := &core.DecimalField{Scale: 2}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		raw      any
+		expected string
+	}{
+		{nil, "0.00"},
+		{"", "0.00"},
+		{"1234", "12.34"},
+		{int64(-5), "-0.05"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestDecimalFieldSetters(t *testing.T) {
+	collection := core.NewBaseCollection("test")
+	collection.Fields.Add(&core.DecimalField{Name: "test", Scale: 2})
+
+	record := core.NewRecord(collection)
+
+	record.Set("test", "10.5")
+	if v := record.GetString("test"); v != "10.50" {
+		t.Fatalf("Expected %q, got %q", "10.50", v)
+	}
+
+	record.Set("test+", "0.1")
+	record.Set("test+", 0.2)
+	if v := record.GetString("test"); v != "10.80" {
+		t.Fatalf("Expected %q, got %q", "10.80", v)
+	}
+
+	record.Set("test-", "11")
+	if v := record.GetString("test"); v != "-0.20" {
+		t.Fatalf("Expected %q, got %q", "-0.20", v)
+	}
+
+	record.Set("test", "invalid")
+	if v := record.GetString("test"); v != "invalid" {
+		t.Fatalf("Expected the invalid value to be stored as it is, got %q", v)
+	}
+}
+
+func TestDecimalFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name        string
+		field       *core.DecimalField
+		record      func() *core.Record
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.DecimalField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", 123)
+				return record
+			},
+			true,
+		},
+		{
+			"invalid decimal string",
+			&core.DecimalField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "abc")
+				return record
+			},
+			true,
+		},
+		{
+			"zero field value (not required)",
+			&core.DecimalField{Name: "test", Scale: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "0.00")
+				return record
+			},
+			false,
+		},
+		{
+			"zero field value (required)",
+			&core.DecimalField{Name: "test", Scale: 2, Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "0.00")
+				return record
+			},
+			true,
+		},
+		{
+			"> scale",
+			&core.DecimalField{Name: "test", Scale: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "1.001")
+				return record
+			},
+			true,
+		},
+		{
+			"< min",
+			&core.DecimalField{Name: "test", Scale: 2, Min: "0.10"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "0.09")
+				return record
+			},
+			true,
+		},
+		{
+			">= min",
+			&core.DecimalField{Name: "test", Scale: 2, Min: "0.10"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "0.10")
+				return record
+			},
+			false,
+		},
+		{
+			"> max",
+			&core.DecimalField{Name: "test", Scale: 2, Max: "0.30"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "0.31")
+				return record
+			},
+			true,
+		},
+		{
+			"<= max",
+			&core.DecimalField{Name: "test", Scale: 2, Max: "0.30"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "0.30")
+				return record
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.field.ValidateValue(context.Background(), app, s.record())
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestDecimalFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeDecimal)
+	testDefaultFieldNameValidation(t, core.FieldTypeDecimal)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	existing := core.NewBaseCollection("test_decimal")
+	existing.Fields.Add(&core.DecimalField{Id: "price_id", Name: "price", Scale: 2})
+	if err := app.Save(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		collection   *core.Collection
+		field        func() *core.DecimalField
+		expectErrors []string
+	}{
+		{
+			"zero minimal",
+			core.NewBaseCollection("new_collection"),
+			func() *core.DecimalField {
+				return &core.DecimalField{Id: "test", Name: "test"}
+			},
+			[]string{},
+		},
+		{
+			"invalid precision and scale",
+			core.NewBaseCollection("new_collection"),
+			func() *core.DecimalField {
+				return &core.DecimalField{Id: "test", Name: "test", Precision: 19, Scale: -1}
+			},
+			[]string{"precision", "scale"},
+		},
+		{
+			"scale > precision",
+			core.NewBaseCollection("new_collection"),
+			func() *core.DecimalField {
+				return &core.DecimalField{Id: "test", Name: "test", Precision: 2, Scale: 3}
+			},
+			[]string{"scale"},
+		},
+		{
+			"invalid min and max",
+			core.NewBaseCollection("new_collection"),
+			func() *core.DecimalField {
+				return &core.DecimalField{Id: "test", Name: "test", Scale: 1, Min: "0.01", Max: "abc"}
+			},
+			[]string{"min", "max"},
+		},
+		{
+			"max < min",
+			core.NewBaseCollection("new_collection"),
+			func() *core.DecimalField {
+				return &core.DecimalField{Id: "test", Name: "test", Scale: 1, Min: "1.1", Max: "1"}
+			},
+			[]string{"max"},
+		},
+		{
+			"existing field scale change",
+			existing,
+			func() *core.DecimalField {
+				return &core.DecimalField{Id: "price_id", Name: "price", Scale: 3}
+			},
+			[]string{"scale"},
+		},
+		{
+			"valid",
+			existing,
+			func() *core.DecimalField {
+				return &core.DecimalField{Id: "price_id", Name: "price", Precision: 10, Scale: 2, Min: "0.01", Max: "999.99"}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field().ValidateSettings(context.Background(), app, s.collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestDecimalFieldRecordSave(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_decimal")
+	collection.Fields.Add(&core.DecimalField{Name: "price", Scale: 2})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, price := range []string{"0.10", "0.20", "0.30", "12.50"} {
+		record := core.NewRecord(collection)
+		record.Set("price", price)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the stored value must be the scaled integer
+	var total int64
+	if err := app.DB().Select("SUM(price)").From(collection.Name).Row(&total); err != nil {
+		t.Fatal(err)
+	}
+	if total != 1310 {
+		t.Fatalf("Expected exact scaled sum %d, got %d", 1310, total)
+	}
+
+	scenarios := []struct {
+		filter   string
+		sort     string
+		expected []string
+	}{
+		{"price = 0.3", "price", []string{"0.30"}},
+		{"price > 0.1 && price <= 0.3", "-price", []string{"0.30", "0.20"}},
+		{"price >= '12.5'", "", []string{"12.50"}},
+		{"price < 0.105", "", []string{"0.10"}},
+		{"price in [0.1, '12.50']", "price", []string{"0.10", "12.50"}},
+		{"price ~ '2.5'", "", []string{"12.50"}},
+		{"price > 'abc'", "", []string{}},
+		{"", "-price", []string{"12.50", "0.30", "0.20", "0.10"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter+"_"+s.sort, func(t *testing.T) {
+			records, err := app.FindRecordsByFilter(collection, s.filter, s.sort, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prices := make([]string, len(records))
+			for i, r := range records {
+				prices[i] = r.GetString("price")
+			}
+
+			if strings.Join(prices, ",") != strings.Join(s.expected, ",") {
+				t.Fatalf("Expected prices %v, got %v", s.expected, prices)
+			}
+		})
+	}
+
+	// json serialization
+	record, err := app.FindFirstRecordByFilter(collection, "price = 12.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `"price":"12.50"`) {
+		t.Fatalf("Expected the price to be serialized as string, got %s", raw)
+	}
+}
+
+func TestDecimalFieldAggregate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_decimal")
+	collection.Fields.Add(
+		&core.TextField{Name: "group"},
+		&core.DecimalField{Name: "price", Scale: 2},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		group string
+		price string
+	}{
+		{"a", "0.10"},
+		{"a", "0.20"},
+		{"b", "1.10"},
+		{"b", "2.20"},
+		{"b", "-0.05"},
+		{"c", "0.01"},
+	}
+	for _, d := range data {
+		record := core.NewRecord(collection)
+		record.Set("group", d.group)
+		record.Set("price", d.price)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resolver := core.NewRecordFieldResolver(app, collection, nil, true)
+
+	result, err := search.NewProvider(resolver).
+		Query(app.RecordQuery(collection)).
+		GroupBy([]string{"group"}).
+		Aggregates([]search.AggregateField{
+			{Func: search.AggregateSum, Field: "price"},
+			{Func: search.AggregateMin, Field: "price"},
+			{Func: search.AggregateMax, Field: "price"},
+		}).
+		ExecAggregate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(result.Items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[` +
+		`{"group":"a","max(price)":"0.20","min(price)":"0.10","sum(price)":"0.30"},` +
+		`{"group":"b","max(price)":"2.20","min(price)":"-0.05","sum(price)":"3.25"},` +
+		`{"group":"c","max(price)":"0.01","min(price)":"0.01","sum(price)":"0.01"}` +
+		`]`
+	if string(raw) != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, raw)
+	}
+
+	// group by the decimal field
+	result, err = search.NewProvider(resolver).
+		Query(app.RecordQuery(collection)).
+		GroupBy([]string{"price"}).
+		Sort([]search.SortField{{Name: "price", Direction: search.SortDesc}}).
+		ExecAggregate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err = json.Marshal(result.Items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `[` +
+		`{"count()":1,"price":"2.20"},` +
+		`{"count()":1,"price":"1.10"},` +
+		`{"count()":1,"price":"0.20"},` +
+		`{"count()":1,"price":"0.10"},` +
+		`{"count()":1,"price":"0.01"},` +
+		`{"count()":1,"price":"-0.05"}` +
+		`]`
+	if string(raw) != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, raw)
+	}
+}
@@ -2,9 +2,11 @@ package core
 
 import (
 	"context"
+	"regexp"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/spf13/cast"
 )
 
@@ -18,13 +20,36 @@ const FieldTypeEditor = "editor"
 
 const DefaultEditorFieldMaxSize int64 = 5 << 20
 
+var (
+	editorTagRegex         = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*$`)
+	editorAttributeRegex   = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*:)?[a-zA-Z_][\w.:-]*\*?$`)
+	editorURLSchemeRegex   = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*$`)
+	editorCSSPropertyRegex = regexp.MustCompile(`^-?[a-zA-Z][a-zA-Z0-9-]*$`)
+)
+
 var (
 	_ Field                 = (*EditorField)(nil)
 	_ MaxBodySizeCalculator = (*EditorField)(nil)
+	_ RecordInterceptor     = (*EditorField)(nil)
+	_ FullTextSearchable    = (*EditorField)(nil)
 )
 
 // EditorField defines "editor" type field to store HTML formatted text.
 //
+// By default the field value is sanitized before persisting using the
+// field allow-lists (see [security.SanitizeHTML]), aka. not allowed tags,
+// attributes and URL schemes (eg. scripts, event handlers, "javascript:" links)
+// are removed from the stored HTML.
+// The default allow-lists include the "style" attribute limited to the common
+// text formatting CSS properties but not embeds like iframe and video
+// (they could be enabled with the AllowedTags and AllowedAttributes options).
+//
+// Note that the sanitizer is enabled for all editor fields, including the ones
+// created before its introduction. The already stored values are left untouched
+// and they are sanitized only when changed. If your existing editor fields
+// rely on markup that is not allowed by default (eg. iframe and video embeds),
+// as an upgrade step either extend their allow-lists or opt out with DisableSanitize.
+//
 // The respective zero record field value is empty string.
 type EditorField struct {
 	// Name (required) is the unique name of the field.
@@ -61,6 +86,45 @@ type EditorField struct {
 
 	// Required will require the field value to be non-empty string.
 	Required bool `form:"required" json:"required"`
+
+	// AllowedTags specifies the HTML tags allowed by the field sanitizer (eg. "p", "a").
+	//
+	// If empty, [security.DefaultHTMLAllowedTags] is used.
+	AllowedTags []string `form:"allowedTags" json:"allowedTags"`
+
+	// AllowedAttributes specifies the HTML attributes allowed by the field sanitizer.
+	//
+	// Each item could be either a plain attribute name allowed for all tags (eg. "class")
+	// or a tag specific attribute in the format "tag:attribute" (eg. "a:href").
+	//
+	// If empty, [security.DefaultHTMLAllowedAttributes] is used.
+	AllowedAttributes []string `form:"allowedAttributes" json:"allowedAttributes"`
+
+	// AllowedURLSchemes specifies the URL schemes allowed by the field
+	// sanitizer for the URL attributes like href and src (eg. "https", "mailto").
+	//
+	// If empty, [security.DefaultHTMLAllowedURLSchemes] is used.
+	AllowedURLSchemes []string `form:"allowedURLSchemes" json:"allowedURLSchemes"`
+
+	// AllowedCSSProperties specifies the CSS properties allowed by the
+	// field sanitizer in the "style" attribute (eg. "color", "text-align").
+	//
+	// If empty, [security.DefaultHTMLAllowedCSSProperties] is used.
+	AllowedCSSProperties []string `form:"allowedCSSProperties" json:"allowedCSSProperties"`
+
+	// DisableSanitize disables the field HTML sanitizer and stores the field value as it is.
+	//
+	// Use with caution and only if the field value comes from a trusted source
+	// or you are sanitizing it manually.
+	DisableSanitize bool `form:"disableSanitize" json:"disableSanitize"`
+
+	// Searchable includes the field in the collection full-text search
+	// index allowing it to be queried with the match() filter function.
+	//
+	// Hidden fields cannot be searchable.
+	//
+	// Note that the raw field HTML is indexed.
+	Searchable bool `form:"searchable" json:"searchable"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -143,15 +207,64 @@ func (f *EditorField) ValidateValue(ctx context.Context, app App, record *Record
 	return nil
 }
 
+// IsFullTextSearchable implements the [FullTextSearchable] interface.
+func (f *EditorField) IsFullTextSearchable() bool {
+	return f.Searchable
+}
+
 // ValidateSettings implements [Field.ValidateSettings] interface method.
 func (f *EditorField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
 	return validation.ValidateStruct(f,
 		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
 		validation.Field(&f.MaxSize, validation.Min(0), validation.Max(maxSafeJSONInt)),
+		validation.Field(&f.AllowedTags, validation.Each(
+			validation.NotIn("script"),
+			validation.Match(editorTagRegex),
+		)),
+		validation.Field(&f.AllowedAttributes, validation.Each(validation.Match(editorAttributeRegex))),
+		validation.Field(&f.AllowedURLSchemes, validation.Each(
+			validation.NotIn("javascript", "vbscript"),
+			validation.Match(editorURLSchemeRegex),
+		)),
+		validation.Field(&f.AllowedCSSProperties, validation.Each(validation.Match(editorCSSPropertyRegex))),
+		validation.Field(&f.Searchable, validation.When(f.Searchable, validation.By(checkFullTextSearchField(f.Name, f.Hidden)))),
 	)
 }
 
+// Intercept implements the [RecordInterceptor] interface.
+func (f *EditorField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	// sanitize the field value before validation and persistence
+	// (the create/update actions are for the case when the validations are skipped)
+	switch actionName {
+	case InterceptorActionValidate, InterceptorActionCreate, InterceptorActionUpdate:
+		if !f.DisableSanitize {
+			val, ok := record.GetRaw(f.Name).(string)
+			if ok && val != "" && (record.IsNew() || val != record.Original().GetString(f.Name)) {
+				record.SetRaw(f.Name, f.Sanitize(val))
+			}
+		}
+	}
+
+	return actionFunc()
+}
+
+// Sanitize sanitizes the provided HTML string using the field allow-lists.
+func (f *EditorField) Sanitize(rawHTML string) string {
+	return security.SanitizeHTML(rawHTML, &security.HTMLPolicy{
+		AllowedTags:          f.AllowedTags,
+		AllowedAttributes:    f.AllowedAttributes,
+		AllowedURLSchemes:    f.AllowedURLSchemes,
+		AllowedCSSProperties: f.AllowedCSSProperties,
+	})
+}
+
 // CalculateMaxBodySize implements the [MaxBodySizeCalculator] interface.
 func (f *EditorField) CalculateMaxBodySize() int64 {
 	if f.MaxSize <= 0 {
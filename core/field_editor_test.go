@@ -217,6 +217,66 @@ func TestEditorFieldValidateSettings(t *testing.T) {
 			},
 			[]string{"maxSize"},
 		},
+		{
+			"searchable with reserved name",
+			func() *core.EditorField {
+				return &core.EditorField{
+					Id:         "test",
+					Name:       "rowid",
+					Searchable: true,
+				}
+			},
+			[]string{"searchable"},
+		},
+		{
+			"hidden searchable",
+			func() *core.EditorField {
+				return &core.EditorField{
+					Id:         "test",
+					Name:       "test",
+					Hidden:     true,
+					Searchable: true,
+				}
+			},
+			[]string{"searchable"},
+		},
+		{
+			"searchable with valid name",
+			func() *core.EditorField {
+				return &core.EditorField{
+					Id:         "test",
+					Name:       "test",
+					Searchable: true,
+				}
+			},
+			[]string{},
+		},
+		{
+			"invalid sanitizer allow-lists",
+			func() *core.EditorField {
+				return &core.EditorField{
+					Id:                "test",
+					Name:              "test",
+					AllowedTags:       []string{"p", "script"},
+					AllowedAttributes: []string{"class", "a:href", "in valid"},
+					AllowedURLSchemes: []string{"https", "javascript"},
+				}
+			},
+			[]string{"allowedTags", "allowedAttributes", "allowedURLSchemes"},
+		},
+		{
+			"valid sanitizer allow-lists",
+			func() *core.EditorField {
+				return &core.EditorField{
+					Id:                "test",
+					Name:              "test",
+					AllowedTags:       []string{"p", "a", "custom-tag"},
+					AllowedAttributes: []string{"class", "a:href", "data-*", "xlink:href"},
+					AllowedURLSchemes: []string{"https", "web+app"},
+				}
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -250,3 +310,134 @@ func TestEditorFieldCalculateMaxBodySize(t *testing.T) {
 		})
 	}
 }
+
+func TestEditorFieldIntercept(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(&core.EditorField{Name: "test"})
+
+	unsafeHTML := `<p onclick="alert(1)">a<script>alert(2)</script><a href="javascript:alert(3)" title="b">b</a></p>`
+
+	scenarios := []struct {
+		name       string
+		actionName string
+		field      *core.EditorField
+		record     func() *core.Record
+		expected   string
+	}{
+		{
+			"non-matching action",
+			core.InterceptorActionDelete,
+			&core.EditorField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", unsafeHTML)
+				return record
+			},
+			unsafeHTML,
+		},
+		{
+			"matching action (validate)",
+			core.InterceptorActionValidate,
+			&core.EditorField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", unsafeHTML)
+				return record
+			},
+			`<p>a<a title="b">b</a></p>`,
+		},
+		{
+			"matching action (update) with custom allow-lists",
+			core.InterceptorActionUpdate,
+			&core.EditorField{Name: "test", AllowedTags: []string{"a"}, AllowedAttributes: []string{"a:href"}},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Id = "test"
+				record.PostScan()
+				record.SetRaw("test", unsafeHTML)
+				return record
+			},
+			`a<a>b</a>`,
+		},
+		{
+			"unchanged value of existing record",
+			core.InterceptorActionUpdate,
+			&core.EditorField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Id = "test"
+				record.SetRaw("test", unsafeHTML)
+				record.PostScan()
+				return record
+			},
+			unsafeHTML,
+		},
+		{
+			"disabled sanitizer",
+			core.InterceptorActionCreate,
+			&core.EditorField{Name: "test", DisableSanitize: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", unsafeHTML)
+				return record
+			},
+			unsafeHTML,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actionCalls := 0
+			record := s.record()
+
+			err := s.field.Intercept(context.Background(), app, record, s.actionName, func() error {
+				actionCalls++
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actionCalls != 1 {
+				t.Fatalf("Expected actionCalls %d, got %d", 1, actionCalls)
+			}
+
+			v := record.GetString(s.field.GetName())
+			if v != s.expected {
+				t.Fatalf("Expected value\n%s\ngot\n%s", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestEditorFieldRecordSave(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_editor")
+	collection.Fields.Add(&core.EditorField{Name: "content"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("content", `<p>a<img src="x" onerror="alert(1)"></p>`)
+
+	// should be sanitized even when the validations are skipped
+	if err := app.SaveNoValidate(record); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<p>a<img src="x"></p>`
+	if v := stored.GetString("content"); v != expected {
+		t.Fatalf("Expected the stored value to be\n%s\ngot\n%s", expected, v)
+	}
+}
@@ -0,0 +1,373 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"os"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeEncrypted] = func() Field {
+		return &EncryptedField{}
+	}
+}
+
+const FieldTypeEncrypted = "encrypted"
+
+const undecryptedValuePrefix = internalCustomFieldKeyPrefix + "_undecrypted_"
+
+var (
+	_ Field             = (*EncryptedField)(nil)
+	_ SetterFinder      = (*EncryptedField)(nil)
+	_ DriverValuer      = (*EncryptedField)(nil)
+	_ RecordInterceptor = (*EncryptedField)(nil)
+)
+
+// EncryptedField defines "encrypted" type field for storing sensitive
+// string values (API tokens, PII, etc.) encrypted at rest.
+//
+// The record field value is always the plain string and it is
+// transparently encrypted with AES-256-GCM (see [security.Encrypt])
+// when persisted and decrypted when loaded from the database.
+//
+// The encryption key is read from the environment variable specified
+// with the KeyEnv option (or the app EncryptionEnv if not set).
+//
+// Note that because the stored value is encrypted, the field cannot
+// be used in API rules, filters and sort expressions.
+//
+// The respective zero record field value is empty string.
+type EncryptedField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// KeyEnv specifies the name of the environment variable that
+	// holds the 32 characters encryption key.
+	//
+	// If empty, on collection save it is set to the app EncryptionEnv
+	// (aka. the same key used for the settings encryption).
+	KeyEnv string `form:"keyEnv" json:"keyEnv"`
+
+	// Max specifies the maximum allowed plain string characters.
+	//
+	// If zero, a default limit of 5000 is applied.
+	Max int `form:"max" json:"max"`
+
+	// Required will require the field value to be non-empty string.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *EncryptedField) Type() string {
+	return FieldTypeEncrypted
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *EncryptedField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *EncryptedField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *EncryptedField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *EncryptedField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *EncryptedField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *EncryptedField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *EncryptedField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *EncryptedField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *EncryptedField) ColumnType(app App) string {
+	return "TEXT DEFAULT '' NOT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+//
+// The raw value is expected to be the encrypted db value
+// (the plain value is set with the custom field setter).
+//
+// A value that cannot be decrypted (eg. because of a missing or rotated key)
+// is loaded as empty string so that it doesn't fail the entire query.
+// The original encrypted value is kept with the record and it is persisted
+// unchanged on save unless a new field value is explicitly set.
+func (f *EncryptedField) PrepareValue(record *Record, raw any) (any, error) {
+	cipherText := cast.ToString(raw)
+
+	plain, err := f.Decrypt(cipherText)
+	if err != nil {
+		if record != nil {
+			record.SetRaw(undecryptedValuePrefix+f.Name, cipherText)
+		}
+
+		return "", nil
+	}
+
+	return plain, nil
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *EncryptedField) DriverValue(record *Record) (driver.Value, error) {
+	if cipherText := f.undecryptedValue(record); cipherText != "" {
+		return cipherText, nil
+	}
+
+	return f.Encrypt(record.GetString(f.Name))
+}
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *EncryptedField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	if actionName == InterceptorActionUpdateExecute && f.undecryptedValue(record) != "" {
+		app.Logger().Warn(
+			"Failed to decrypt the encrypted field value; keeping the stored value unchanged",
+			slog.String("collectionName", record.Collection().Name),
+			slog.String("field", f.Name),
+			slog.String("recordId", record.Id),
+		)
+	}
+
+	return actionFunc()
+}
+
+// undecryptedValue returns the stored encrypted value of a record field
+// that couldn't be decrypted on load and hasn't been changed since.
+func (f *EncryptedField) undecryptedValue(record *Record) string {
+	cipherText, _ := record.GetRaw(undecryptedValuePrefix + f.Name).(string)
+	if cipherText == "" || record.GetString(f.Name) != "" {
+		return ""
+	}
+
+	return cipherText
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *EncryptedField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	val, ok := record.GetRaw(f.Name).(string)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	if f.Required {
+		if err := validation.Required.Validate(val); err != nil {
+			return err
+		}
+	}
+
+	max := f.Max
+	if max == 0 {
+		max = 5000
+	}
+
+	// note: casted to []rune to count multi-byte chars as one
+	if len([]rune(val)) > max {
+		return validation.NewError("validation_max_text_constraint", "Must be no more than {{.max}} character(s).").
+			SetParams(map[string]any{"max": max})
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *EncryptedField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.KeyEnv, validation.Required, validation.By(f.checkKeyEnv)),
+		validation.Field(&f.Max, validation.Min(0), validation.Max(maxSafeJSONInt)),
+	)
+}
+
+func (f *EncryptedField) checkKeyEnv(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if len(os.Getenv(v)) != 32 {
+		return validation.NewError(
+			"validation_invalid_encryption_key_env",
+			"The environment variable {{.env}} must be set with a 32 characters encryption key.",
+		).SetParams(map[string]any{"env": v})
+	}
+
+	return nil
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *EncryptedField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		return func(record *Record, raw any) {
+			// explicitly set value (even empty) replaces the undecryptable one
+			record.SetRaw(undecryptedValuePrefix+f.Name, "")
+			record.SetRaw(f.Name, cast.ToString(raw))
+		}
+	default:
+		return nil
+	}
+}
+
+// Encrypt encrypts the provided plain value with the field encryption key.
+//
+// Empty string is returned as it is.
+func (f *EncryptedField) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+
+	key, err := f.encryptionKey()
+	if err != nil {
+		return "", err
+	}
+
+	return security.Encrypt([]byte(plain), key)
+}
+
+// Decrypt decrypts the provided encrypted value with the field encryption key.
+//
+// Empty string is returned as it is.
+func (f *EncryptedField) Decrypt(cipherText string) (string, error) {
+	if cipherText == "" {
+		return "", nil
+	}
+
+	key, err := f.encryptionKey()
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := security.Decrypt(cipherText, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field %q value: %w", f.Name, err)
+	}
+
+	return string(plain), nil
+}
+
+func (f *EncryptedField) encryptionKey() (string, error) {
+	key := os.Getenv(f.KeyEnv)
+	if f.KeyEnv == "" || len(key) != 32 {
+		return "", fmt.Errorf("missing or invalid encryption key env %q for field %q", f.KeyEnv, f.Name)
+	}
+
+	return key, nil
+}
+
+// RotateKey re-encrypts all stored collection field values
+// from oldKey to the current field encryption key.
+//
+// Values that are already encrypted with the current key are skipped
+// so it is safe to rerun the rotation in case of an error.
+//
+// Returns the number of the re-encrypted values.
+func (f *EncryptedField) RotateKey(app App, collection *Collection, oldKey string) (int, error) {
+	newKey, err := f.encryptionKey()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+
+	txErr := app.RunInTransaction(func(txApp App) error {
+		rows := []struct {
+			Id    string `db:"id"`
+			Value string `db:"value"`
+		}{}
+
+		err := txApp.DB().Select("[["+FieldNameId+"]]", "[["+f.Name+"]] as [[value]]").
+			From(collection.Name).
+			AndWhere(dbx.NewExp("[[" + f.Name + "]] != ''")).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			plain, err := security.Decrypt(row.Value, oldKey)
+			if err != nil {
+				// already rotated
+				if _, newErr := security.Decrypt(row.Value, newKey); newErr == nil {
+					continue
+				}
+
+				return fmt.Errorf("failed to decrypt %s.%s value of record %q: %w", collection.Name, f.Name, row.Id, err)
+			}
+
+			encrypted, err := security.Encrypt(plain, newKey)
+			if err != nil {
+				return err
+			}
+
+			_, err = txApp.DB().Update(
+				collection.Name,
+				dbx.Params{f.Name: encrypted},
+				dbx.HashExp{FieldNameId: row.Id},
+			).Execute()
+			if err != nil {
+				return err
+			}
+
+			total++
+		}
+
+		return nil
+	})
+
+	return total, txErr
+}
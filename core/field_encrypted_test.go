@@ -0,0 +1,458 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+const testEncryptionKeyEnv = "PB_TEST_ENCRYPTED_FIELD_KEY"
+
+func TestEncryptedFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeEncrypted)
+}
+
+func TestEncryptedFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.EncryptedField{}
+
+	expected := "TEXT DEFAULT '' NOT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestEncryptedFieldEncryptDecrypt(t *testing.T) {
+	t.Setenv(testEncryptionKeyEnv, strings.Repeat("a", 32))
+
+	f := &core.EncryptedField{Name: "test", KeyEnv: testEncryptionKeyEnv}
+
+	empty, err := f.Encrypt("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty != "" {
+		t.Fatalf("Expected empty string to remain unencrypted, got %q", empty)
+	}
+
+	encrypted, err := f.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted == "" || encrypted == "secret" {
+		t.Fatalf("Expected encrypted value, got %q", encrypted)
+	}
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	plain, err := f.PrepareValue(record, encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "secret" {
+		t.Fatalf("Expected decrypted value %q, got %q", "secret", plain)
+	}
+
+	// invalid key
+	invalidKeyField := &core.EncryptedField{Name: "test", KeyEnv: "PB_TEST_MISSING_KEY_ENV"}
+	if _, err := invalidKeyField.Encrypt("secret"); err == nil {
+		t.Fatal("Expected encrypt error due to missing key")
+	}
+	if _, err := invalidKeyField.Decrypt(encrypted); err == nil {
+		t.Fatal("Expected decrypt error due to missing key")
+	}
+
+	// PrepareValue should fallback to empty string instead of failing
+	masked, err := invalidKeyField.PrepareValue(record, encrypted)
+	if err != nil {
+		t.Fatalf("Expected no PrepareValue error, got %v", err)
+	}
+	if masked != "" {
+		t.Fatalf("Expected empty value, got %q", masked)
+	}
+
+	// the undecryptable value should be persisted as it is
+	driverValue, err := invalidKeyField.DriverValue(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if driverValue != encrypted {
+		t.Fatalf("Expected the original encrypted driver value %q, got %q", encrypted, driverValue)
+	}
+}
+
+func TestEncryptedFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name        string
+		field       *core.EncryptedField
+		record      func() *core.Record
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.EncryptedField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", 123)
+				return record
+			},
+			true,
+		},
+		{
+			"zero field value (not required)",
+			&core.EncryptedField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "")
+				return record
+			},
+			false,
+		},
+		{
+			"zero field value (required)",
+			&core.EncryptedField{Name: "test", Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "")
+				return record
+			},
+			true,
+		},
+		{
+			"> default max",
+			&core.EncryptedField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", strings.Repeat("a", 5001))
+				return record
+			},
+			true,
+		},
+		{
+			"> explicit max",
+			&core.EncryptedField{Name: "test", Max: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "abc")
+				return record
+			},
+			true,
+		},
+		{
+			"valid value",
+			&core.EncryptedField{Name: "test", Required: true, Max: 3},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "abc")
+				return record
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.field.ValidateValue(context.Background(), app, s.record())
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestEncryptedFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeEncrypted)
+	testDefaultFieldNameValidation(t, core.FieldTypeEncrypted)
+
+	t.Setenv(testEncryptionKeyEnv, strings.Repeat("a", 32))
+	t.Setenv("PB_TEST_SHORT_KEY", "abc")
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.EncryptedField
+		expectErrors []string
+	}{
+		{
+			"empty key env",
+			func() *core.EncryptedField {
+				return &core.EncryptedField{Id: "test", Name: "test"}
+			},
+			[]string{"keyEnv"},
+		},
+		{
+			"missing key env",
+			func() *core.EncryptedField {
+				return &core.EncryptedField{Id: "test", Name: "test", KeyEnv: "PB_TEST_MISSING_KEY_ENV"}
+			},
+			[]string{"keyEnv"},
+		},
+		{
+			"invalid key length",
+			func() *core.EncryptedField {
+				return &core.EncryptedField{Id: "test", Name: "test", KeyEnv: "PB_TEST_SHORT_KEY"}
+			},
+			[]string{"keyEnv"},
+		},
+		{
+			"negative max",
+			func() *core.EncryptedField {
+				return &core.EncryptedField{Id: "test", Name: "test", KeyEnv: testEncryptionKeyEnv, Max: -1}
+			},
+			[]string{"max"},
+		},
+		{
+			"valid",
+			func() *core.EncryptedField {
+				return &core.EncryptedField{Id: "test", Name: "test", KeyEnv: testEncryptionKeyEnv, Max: 10}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			field := s.field()
+			keyEnv := field.KeyEnv
+
+			errs := field.ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+
+			if field.KeyEnv != keyEnv {
+				t.Fatalf("Expected KeyEnv to remain %q, got %q", keyEnv, field.KeyEnv)
+			}
+		})
+	}
+}
+
+func TestEncryptedFieldDefaultKeyEnv(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	t.Setenv(app.EncryptionEnv(), strings.Repeat("b", 32))
+
+	collection := core.NewBaseCollection("test_encrypted")
+	collection.Fields.Add(&core.EncryptedField{Name: "secret"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	field, _ := collection.Fields.GetByName("secret").(*core.EncryptedField)
+	if field == nil || field.KeyEnv != app.EncryptionEnv() {
+		t.Fatalf("Expected KeyEnv %q, got %v", app.EncryptionEnv(), field)
+	}
+}
+
+func TestEncryptedFieldRecordSave(t *testing.T) {
+	t.Setenv(testEncryptionKeyEnv, strings.Repeat("a", 32))
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_encrypted")
+	collection.Fields.Add(&core.EncryptedField{Name: "secret", KeyEnv: testEncryptionKeyEnv})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("secret", "abc")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// check the raw db value
+	var raw string
+	err := app.DB().Select("secret").From(collection.Name).Where(dbx.HashExp{"id": record.Id}).Row(&raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw == "" || strings.Contains(raw, "abc") {
+		t.Fatalf("Expected the db value to be encrypted, got %q", raw)
+	}
+
+	fresh, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := fresh.GetString("secret"); v != "abc" {
+		t.Fatalf("Expected decrypted value %q, got %q", "abc", v)
+	}
+
+	// filter and sort
+	if _, err := app.FindRecordsByFilter(collection, "secret = 'abc'", "", 0, 0); err == nil {
+		t.Fatal("Expected filter error")
+	}
+	if _, err := app.FindRecordsByFilter(collection, "", "secret", 0, 0); err == nil {
+		t.Fatal("Expected sort error")
+	}
+}
+
+func TestEncryptedFieldRotateKey(t *testing.T) {
+	oldKey := strings.Repeat("a", 32)
+	newKey := strings.Repeat("b", 32)
+
+	t.Setenv(testEncryptionKeyEnv, oldKey)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	field := &core.EncryptedField{Name: "secret", KeyEnv: testEncryptionKeyEnv}
+
+	collection := core.NewBaseCollection("test_encrypted")
+	collection.Fields.Add(field)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		record := core.NewRecord(collection)
+		if i > 0 {
+			record.Set("secret", fmt.Sprintf("value%d", i))
+		}
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv(testEncryptionKeyEnv, newKey)
+
+	// wrong old key
+	if _, err := field.RotateKey(app, collection, strings.Repeat("c", 32)); err == nil {
+		t.Fatal("Expected error due to invalid old key")
+	}
+
+	total, err := field.RotateKey(app, collection, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Fatalf("Expected %d re-encrypted values, got %d", 2, total)
+	}
+
+	// rerun
+	total, err = field.RotateKey(app, collection, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("Expected no re-encrypted values on rerun, got %d", total)
+	}
+
+	// the records should be decryptable with the new key
+	records, err := app.FindRecordsByFilter(collection, "", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]bool{}
+	for _, r := range records {
+		values[r.GetString("secret")] = true
+	}
+	for _, v := range []string{"", "value1", "value2"} {
+		if !values[v] {
+			t.Fatalf("Missing expected value %q in %v", v, values)
+		}
+	}
+}
+
+func TestEncryptedFieldUndecryptableRecordSave(t *testing.T) {
+	t.Setenv(testEncryptionKeyEnv, strings.Repeat("a", 32))
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_encrypted")
+	collection.Fields.Add(&core.EncryptedField{Name: "secret", KeyEnv: testEncryptionKeyEnv})
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("secret", "abc")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	storedCipherText := func() string {
+		var raw string
+		err := app.DB().Select("secret").From(collection.Name).Where(dbx.HashExp{"id": record.Id}).Row(&raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	original := storedCipherText()
+
+	// wrong key
+	t.Setenv(testEncryptionKeyEnv, strings.Repeat("b", 32))
+
+	loaded, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := loaded.GetString("secret"); v != "" {
+		t.Fatalf("Expected empty undecrypted value, got %q", v)
+	}
+
+	loaded.Set("title", "test")
+	if err := app.Save(loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := storedCipherText(); v != original {
+		t.Fatalf("Expected the stored encrypted value to remain\n%q\ngot\n%q", original, v)
+	}
+
+	// the value should be still decryptable with the correct key
+	t.Setenv(testEncryptionKeyEnv, strings.Repeat("a", 32))
+
+	fresh, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := fresh.GetString("secret"); v != "abc" {
+		t.Fatalf("Expected decrypted value %q, got %q", "abc", v)
+	}
+
+	// explicitly set value should replace the undecryptable one
+	t.Setenv(testEncryptionKeyEnv, strings.Repeat("b", 32))
+
+	loaded, err = app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Set("secret", "new")
+	if err := app.Save(loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, err = app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := fresh.GetString("secret"); v != "new" {
+		t.Fatalf("Expected decrypted value %q, got %q", "new", v)
+	}
+}
@@ -3,12 +3,15 @@ package core
 import (
 	"context"
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/gabriel-vasile/mimetype"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/core/validators"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
@@ -27,11 +30,15 @@ const FieldTypeFile = "file"
 
 const DefaultFileFieldMaxSize int64 = 5 << 20
 
+const maxImageTransformSize = 10000
+
 var looseFilenameRegex = regexp.MustCompile(`^[^\./\\][^/\\]+$`)
 
 const (
 	deletedFilesPrefix  = internalCustomFieldKeyPrefix + "_deletedFilesPrefix_"
 	uploadedFilesPrefix = internalCustomFieldKeyPrefix + "_uploadedFilesPrefix_"
+	scannedFilesPrefix  = internalCustomFieldKeyPrefix + "_scannedFilesPrefix_"
+	dedupedFilesPrefix  = internalCustomFieldKeyPrefix + "_dedupedFilesPrefix_"
 )
 
 var (
@@ -114,13 +121,27 @@ type FileField struct {
 	// Each entry must be in one of the following formats:
 	//
 	//   - WxH  (eg. 100x300) - crop to WxH viewbox (from center)
+	//   - WxHc (eg. 100x300c) - crop to WxH viewbox (from center)
 	//   - WxHt (eg. 100x300t) - crop to WxH viewbox (from top)
 	//   - WxHb (eg. 100x300b) - crop to WxH viewbox (from bottom)
 	//   - WxHf (eg. 100x300f) - fit inside a WxH viewbox (without cropping)
+	//   - WxHs (eg. 100x300s) - crop to WxH viewbox (around the area with the most details)
+	//   - WxHp (eg. 100x300p) - crop to WxH viewbox (around the FocalPointField value)
 	//   - 0xH  (eg. 0x300)    - resize to H height preserving the aspect ratio
 	//   - Wx0  (eg. 100x0)    - resize to W width preserving the aspect ratio
+	//
+	// Each format could be optionally followed by a JPEG quality
+	// and/or output format suffix (eg. 100x300_q80.jpg, 100x300s.webp).
 	Thumbs []string `form:"thumbs" json:"thumbs"`
 
+	// FocalPointField specifies an optional name of a sibling "json" field
+	// holding the focal point used for cropping the "p" mode thumbs.
+	//
+	// The json field value could be either a single {"x":0.5, "y":0.5} object
+	// with relative coordinates (0-1) applied to all field files or an object
+	// with the file names as keys, eg. {"example_abc.png":{"x":0.5, "y":0.5}}.
+	FocalPointField string `form:"focalPointField" json:"focalPointField"`
+
 	// Protected will require the users to provide a special file token to access the file.
 	//
 	// Note that by default all files are publicly accessible.
@@ -132,6 +153,48 @@ type FileField struct {
 
 	// Required will require the field value to have at least one file.
 	Required bool `form:"required" json:"required"`
+
+	// ImageMaxWidth specifies an optional max width of the uploaded images.
+	//
+	// Larger images are downscaled preserving their aspect ratio.
+	ImageMaxWidth int `form:"imageMaxWidth" json:"imageMaxWidth"`
+
+	// ImageMaxHeight specifies an optional max height of the uploaded images.
+	//
+	// Larger images are downscaled preserving their aspect ratio.
+	ImageMaxHeight int `form:"imageMaxHeight" json:"imageMaxHeight"`
+
+	// ImageFormat specifies an optional format ("jpeg" or "png")
+	// to which the uploaded images will be converted.
+	//
+	// The extension of the stored file name is changed accordingly.
+	ImageFormat string `form:"imageFormat" json:"imageFormat"`
+
+	// ImageQuality specifies the JPEG encoding quality (1-100) of the transformed images.
+	ImageQuality int `form:"imageQuality" json:"imageQuality"`
+
+	// ImageStripMetadata removes the metadata (EXIF, GPS location, etc.) of the uploaded images.
+	ImageStripMetadata bool `form:"imageStripMetadata" json:"imageStripMetadata"`
+
+	// ImageAutoOrient rotates/flips the uploaded images based on their EXIF orientation.
+	ImageAutoOrient bool `form:"imageAutoOrient" json:"imageAutoOrient"`
+
+	// KeepOriginal stores also a copy of the original uploaded image
+	// when the above image transform options are applicable to it.
+	//
+	// The original is stored in the "originals_{filename}/" record files subdirectory
+	// and it is deleted together with the transformed file.
+	KeepOriginal bool `form:"keepOriginal" json:"keepOriginal"`
+
+	// Deduplicate stores the uploaded files by their content hash so that
+	// identical files are stored only once (see [FileRef]).
+	//
+	// The file names and URLs of the deduplicated files are the same as
+	// of the regular ones and the stored content is deleted only when
+	// there are no longer any records referencing it.
+	//
+	// Files with applicable image transform options are always stored regularly.
+	Deduplicate bool `form:"deduplicate" json:"deduplicate"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -225,13 +288,87 @@ func (f *FileField) ValidateSettings(ctx context.Context, app App, collection *C
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
 		validation.Field(&f.MaxSelect, validation.Min(0), validation.Max(maxSafeJSONInt)),
 		validation.Field(&f.MaxSize, validation.Min(0), validation.Max(maxSafeJSONInt)),
-		validation.Field(&f.Thumbs, validation.Each(
-			validation.NotIn("0x0", "0x0t", "0x0b", "0x0f"),
-			validation.Match(filesystem.ThumbSizeRegex),
-		)),
+		validation.Field(&f.Thumbs, validation.Each(validation.By(f.checkThumbSize))),
+		validation.Field(&f.FocalPointField, validation.By(f.checkFocalPointField(collection))),
+		validation.Field(&f.ImageMaxWidth, validation.Min(0), validation.Max(maxImageTransformSize)),
+		validation.Field(&f.ImageMaxHeight, validation.Min(0), validation.Max(maxImageTransformSize)),
+		validation.Field(&f.ImageFormat, validation.In(filesystem.ImageFormatJPEG, filesystem.ImageFormatPNG)),
+		validation.Field(&f.ImageQuality, validation.Min(0), validation.Max(100)),
 	)
 }
 
+func (f *FileField) checkThumbSize(value any) error {
+	v, _ := value.(string)
+
+	if _, err := filesystem.ParseThumbSize(v); err != nil {
+		return validation.NewError("validation_invalid_thumb_size", err.Error())
+	}
+
+	return nil
+}
+
+func (f *FileField) checkFocalPointField(collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		if _, ok := collection.Fields.GetByName(v).(*JSONField); !ok {
+			return validation.NewError("validation_invalid_focal_point_field", "The focal point field must be an existing json field.")
+		}
+
+		return nil
+	}
+}
+
+// FocalPoint returns the focal point of the specified record file
+// extracted from the FocalPointField value (if any).
+func (f *FileField) FocalPoint(record *Record, filename string) (filesystem.FocalPoint, bool) {
+	if f.FocalPointField == "" {
+		return filesystem.FocalPoint{}, false
+	}
+
+	raw, _ := record.GetRaw(f.FocalPointField).(types.JSONRaw)
+	if len(raw) == 0 {
+		return filesystem.FocalPoint{}, false
+	}
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return filesystem.FocalPoint{}, false
+	}
+
+	// file specific focal point
+	if fileRaw, ok := data[filename]; ok {
+		raw = types.JSONRaw(fileRaw)
+	} else if _, ok := data["x"]; !ok {
+		return filesystem.FocalPoint{}, false
+	}
+
+	point := struct {
+		X *float64 `json:"x"`
+		Y *float64 `json:"y"`
+	}{}
+	if err := json.Unmarshal(raw, &point); err != nil || point.X == nil || point.Y == nil {
+		return filesystem.FocalPoint{}, false
+	}
+
+	return filesystem.FocalPoint{X: *point.X, Y: *point.Y}.Clamp(), true
+}
+
+// ImageTransform returns the upload image transform options of the field.
+func (f *FileField) ImageTransform() filesystem.ImageTransform {
+	return filesystem.ImageTransform{
+		MaxWidth:      f.ImageMaxWidth,
+		MaxHeight:     f.ImageMaxHeight,
+		Format:        f.ImageFormat,
+		Quality:       f.ImageQuality,
+		StripMetadata: f.ImageStripMetadata,
+		AutoOrient:    f.ImageAutoOrient,
+	}
+}
+
 // ValidateValue implements [Field.ValidateValue] interface method.
 func (f *FileField) ValidateValue(ctx context.Context, app App, record *Record) error {
 	files := f.toSliceValue(record.GetRaw(f.Name))
@@ -295,12 +432,49 @@ func (f *FileField) ValidateValue(ctx context.Context, app App, record *Record)
 			if err != nil {
 				return err
 			}
+
+			err = f.validateTransformedMimeType(upload)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
+	// check the collection storage quotas (if any)
+	if len(uploads) > 0 {
+		return checkStorageQuotas(app, record)
+	}
+
 	return nil
 }
 
+// validateTransformedMimeType checks whether the content type of the
+// transformed upload image (if any) is also one of the allowed MimeTypes.
+func (f *FileField) validateTransformedMimeType(upload *filesystem.File) error {
+	transform := f.ImageTransform()
+	if transform.IsZero() {
+		return nil
+	}
+
+	contentType, err := detectFileContentType(upload)
+	if err != nil {
+		return err
+	}
+
+	transformedType := transform.ContentType(contentType)
+	if transformedType == contentType || list.ExistInSlice(transformedType, f.MimeTypes) {
+		return nil
+	}
+
+	return validation.NewError(
+		"validation_invalid_transformed_mime_type",
+		"The transformed image {{.mimeType}} mime type must be one of: {{.mimeTypes}}.",
+	).SetParams(map[string]any{
+		"mimeType":  transformedType,
+		"mimeTypes": strings.Join(f.MimeTypes, ", "),
+	})
+}
+
 func (f *FileField) maxSize() int64 {
 	if f.MaxSize <= 0 {
 		return DefaultFileFieldMaxSize
@@ -345,6 +519,9 @@ func (f *FileField) Intercept(
 		}
 
 		err = actionFunc()
+		if err == nil {
+			err = f.saveStorageFiles(app, record)
+		}
 		if err != nil {
 			return errors.Join(err, f.afterRecordExecuteFailure(newContextIfInvalid(ctx), app, record))
 		}
@@ -353,6 +530,8 @@ func (f *FileField) Intercept(
 
 		f.afterRecordExecuteSuccess(newContextIfInvalid(ctx), app, record)
 
+		f.saveFileScans(app, record)
+
 		return nil
 	case InterceptorActionAfterCreateError, InterceptorActionAfterUpdateError:
 		// when in transaction we assume that the error was handled by afterRecordExecuteFailure
@@ -370,6 +549,7 @@ func (f *FileField) Intercept(
 		}
 
 		record.SetRaw(deletedFilesPrefix+f.Name, nil)
+		record.SetRaw(dedupedFilesPrefix+f.Name, nil)
 
 		if record.IsNew() {
 			// try to delete the record directory if there are no other files
@@ -385,7 +565,10 @@ func (f *FileField) Intercept(
 
 		return actionFunc()
 	case InterceptorActionAfterCreate, InterceptorActionAfterUpdate:
+		f.restoreMissingFileBlobs(newContextIfInvalid(ctx), app, record)
+
 		record.SetRaw(uploadedFilesPrefix+f.Name, nil)
+		record.SetRaw(dedupedFilesPrefix+f.Name, nil)
 
 		err := f.processFilesToDelete(ctx, app, record)
 		if err != nil {
@@ -443,6 +626,18 @@ func (f *FileField) afterRecordExecuteFailure(ctx context.Context, app App, reco
 		)
 	}
 
+	// delete the quarantined files since there will be no scan records pointing to them
+	quarantineErr := f.deleteQuarantinedFiles(ctx, app, record)
+	if quarantineErr != nil {
+		app.Logger().Warn(
+			"Failed to cleanup the quarantined files after record db write failure",
+			"error", quarantineErr,
+		)
+	}
+
+	record.SetRaw(scannedFilesPrefix+f.Name, nil)
+	record.SetRaw(dedupedFilesPrefix+f.Name, nil)
+
 	return deleteErr
 }
 
@@ -483,10 +678,14 @@ func (f *FileField) processFilesToDelete(ctx context.Context, app App, record *R
 		toDelete[i] = f.getFileName(del)
 	}
 
-	failedToDelete, err := f.deleteFilesByNamesList(ctx, app, record, list.ToUniqueStringSlice(toDelete))
+	toDelete = list.ToUniqueStringSlice(toDelete)
+
+	failedToDelete, err := f.deleteFilesByNamesList(ctx, app, record, toDelete)
 
 	record.SetRaw(deletedFilesPrefix+f.Name, failedToDelete)
 
+	f.deleteFileScans(app, record, list.SubtractSlice(toDelete, failedToDelete))
+
 	return err
 }
 
@@ -521,12 +720,18 @@ func (f *FileField) processFilesToUpload(ctx context.Context, app App, record *R
 	defer fsys.Close()
 	fsys.SetContext(ctx)
 
+	uploads, err = f.scanFilesToUpload(ctx, app, fsys, record, uploads)
+	if err != nil {
+		return err
+	}
+
 	var failed []error     // list of upload errors
 	var succeeded []string // list of uploaded file names
 
+	transform := f.ImageTransform()
+
 	for _, upload := range uploads {
-		path := record.BaseFilesPath() + "/" + upload.Name
-		if err := fsys.UploadFile(upload, path); err == nil {
+		if err := f.uploadFile(app, fsys, record, upload, transform); err == nil {
 			succeeded = append(succeeded, upload.Name)
 		} else {
 			failed = append(failed, fmt.Errorf("%q: %w", upload.Name, err))
@@ -546,6 +751,502 @@ func (f *FileField) processFilesToUpload(ctx context.Context, app App, record *R
 	return nil
 }
 
+// scanFilesToUpload triggers the OnRecordFileScan hook for each of the
+// new record files and returns the files that should be uploaded.
+//
+// The infected files are either rejected with a validation error or
+// moved to the scan quarantine prefix and removed from the record value.
+func (f *FileField) scanFilesToUpload(
+	ctx context.Context,
+	app App,
+	fsys *filesystem.System,
+	record *Record,
+	uploads []*filesystem.File,
+) ([]*filesystem.File, error) {
+	scans := make([]*RecordFileScanEvent, 0, len(uploads))
+
+	for _, upload := range uploads {
+		event := new(RecordFileScanEvent)
+		event.App = app
+		event.Context = ctx
+		event.Record = record
+		event.FileField = f
+		event.File = upload
+		event.Action = FileScanActionReject
+		event.QuarantinePrefix = StorageQuarantineDirName
+
+		err := app.OnRecordFileScan().Trigger(event, func(e *RecordFileScanEvent) error {
+			return e.Next()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%q scan failure: %w", upload.Name, err)
+		}
+
+		if event.Status == "" {
+			continue // not scanned
+		}
+
+		if event.Status == FileScanStatusInfected && event.Action != FileScanActionQuarantine {
+			return nil, validation.Errors{
+				f.Name: validation.NewError(
+					"validation_file_infected",
+					"The file {{.name}} failed the security scan.",
+				).SetParams(map[string]any{"name": upload.OriginalName}),
+			}
+		}
+
+		scans = append(scans, event)
+	}
+
+	result := make([]*filesystem.File, 0, len(uploads))
+	quarantined := make([]*filesystem.File, 0, len(scans))
+
+	for _, upload := range uploads {
+		i := slices.IndexFunc(scans, func(e *RecordFileScanEvent) bool { return e.File == upload })
+		if i < 0 || scans[i].Status != FileScanStatusInfected {
+			result = append(result, upload)
+			continue
+		}
+
+		key := quarantineFileKey(scans[i].QuarantinePrefix, record, upload.Name)
+
+		err := fsys.UploadFile(upload, key)
+		if err != nil {
+			return nil, errors.Join(
+				fmt.Errorf("%q quarantine failure: %w", upload.Name, err),
+				f.deleteQuarantinedFilesList(fsys, record, scans, quarantined),
+			)
+		}
+
+		quarantined = append(quarantined, upload)
+	}
+
+	// exclude the quarantined files from the record value
+	if len(quarantined) > 0 {
+		newValue := f.toSliceValue(record.GetRaw(f.Name))
+		newValue = slices.DeleteFunc(newValue, func(v any) bool {
+			file, ok := v.(*filesystem.File)
+			return ok && slices.Contains(quarantined, file)
+		})
+		f.setValue(record, newValue)
+	}
+
+	record.SetRaw(scannedFilesPrefix+f.Name, scans)
+
+	return result, nil
+}
+
+// saveFileScans persists the scan records of the last uploaded record files.
+//
+// note: a failure to save the scan record is not critical and it is only logged.
+func (f *FileField) saveFileScans(app App, record *Record) {
+	scans, _ := record.GetRaw(scannedFilesPrefix + f.Name).([]*RecordFileScanEvent)
+	if len(scans) == 0 {
+		return
+	}
+
+	record.SetRaw(scannedFilesPrefix+f.Name, nil)
+
+	for _, e := range scans {
+		scan := NewFileScan(app)
+		scan.SetCollectionRef(record.Collection().Id)
+		scan.SetRecordRef(record.Id)
+		scan.SetField(f.Name)
+		scan.SetFilename(e.File.Name)
+		scan.SetStatus(e.Status)
+		scan.SetSignature(e.Signature)
+		if e.Status == FileScanStatusInfected {
+			scan.SetQuarantineKey(quarantineFileKey(e.QuarantinePrefix, record, e.File.Name))
+		}
+
+		if err := app.Save(scan); err != nil {
+			app.Logger().Warn(
+				"Failed to save file scan record",
+				"error", err,
+				"recordId", record.Id,
+				"collectionId", record.Collection().Id,
+				"filename", e.File.Name,
+			)
+		}
+	}
+}
+
+// deleteFileScans deletes the scan records of the specified record files.
+//
+// note: a failure to delete the scan record is not critical and it is only logged.
+func (f *FileField) deleteFileScans(app App, record *Record, filenames []string) {
+	if len(filenames) == 0 {
+		return
+	}
+
+	scans, err := app.FindAllFileScansByRecord(record)
+	if err != nil {
+		app.Logger().Warn("Failed to load the record file scans", "error", err, "recordId", record.Id)
+		return
+	}
+
+	for _, scan := range scans {
+		if scan.Field() != f.Name || !slices.Contains(filenames, scan.Filename()) {
+			continue
+		}
+
+		if err := app.Delete(scan); err != nil {
+			app.Logger().Warn(
+				"Failed to delete file scan record",
+				"error", err,
+				"recordId", record.Id,
+				"fileScanId", scan.Id,
+			)
+		}
+	}
+}
+
+func (f *FileField) deleteQuarantinedFiles(ctx context.Context, app App, record *Record) error {
+	scans, _ := record.GetRaw(scannedFilesPrefix + f.Name).([]*RecordFileScanEvent)
+	if len(scans) == 0 {
+		return nil
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+	fsys.SetContext(ctx)
+
+	files := make([]*filesystem.File, 0, len(scans))
+	for _, e := range scans {
+		if e.Status == FileScanStatusInfected {
+			files = append(files, e.File)
+		}
+	}
+
+	return f.deleteQuarantinedFilesList(fsys, record, scans, files)
+}
+
+func (f *FileField) deleteQuarantinedFilesList(
+	fsys *filesystem.System,
+	record *Record,
+	scans []*RecordFileScanEvent,
+	files []*filesystem.File,
+) error {
+	var errs []error
+
+	for _, e := range scans {
+		if !slices.Contains(files, e.File) {
+			continue
+		}
+
+		err := fsys.Delete(quarantineFileKey(e.QuarantinePrefix, record, e.File.Name))
+		if err != nil && !errors.Is(err, filesystem.ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteFileRefs deletes the blob references of the specified record files
+// and releases their content-addressed blobs (if no longer used).
+//
+// note: a failure to delete the reference is not critical and it is only logged.
+func (f *FileField) deleteFileRefs(ctx context.Context, app App, record *Record, filenames []string) {
+	if len(filenames) == 0 {
+		return
+	}
+
+	// the hashes of the new deduplicated files are also checked in case
+	// their references were already rolled back with the failed transaction
+	deduped, _ := record.GetRaw(dedupedFilesPrefix + f.Name).(map[string]string)
+
+	hashes := make([]string, 0, len(filenames))
+	for _, name := range filenames {
+		if hash, ok := deduped[name]; ok {
+			hashes = append(hashes, hash)
+		}
+	}
+
+	refs, err := app.FindAllFileRefsByRecord(record)
+	if err != nil {
+		app.Logger().Warn("Failed to load the record file refs", "error", err, "recordId", record.Id)
+	}
+
+	for _, ref := range refs {
+		if !slices.Contains(filenames, ref.Filename()) {
+			continue
+		}
+
+		if err := app.Delete(ref); err != nil {
+			app.Logger().Warn(
+				"Failed to delete file ref",
+				"error", err,
+				"recordId", record.Id,
+				"fileRefId", ref.Id,
+			)
+			continue
+		}
+
+		hashes = append(hashes, ref.Hash())
+	}
+
+	for _, hash := range list.ToUniqueStringSlice(hashes) {
+		if err := releaseFileBlob(ctx, app, hash); err != nil {
+			app.Logger().Warn("Failed to release file blob", "error", err, "hash", hash)
+		}
+	}
+}
+
+// saveStorageFiles tracks the size of the new uploaded record files
+// and enforces the storage quotas if the record collection has any.
+//
+// note: it is expected to be called in the same transaction as the record
+// save so that on error both the record and the storage usage changes are reverted.
+func (f *FileField) saveStorageFiles(app App, record *Record) error {
+	uploaded := f.extractUploadableFiles(f.toSliceValue(record.GetRaw(f.Name)))
+	if len(uploaded) == 0 {
+		return nil
+	}
+
+	owner, ok := storageFilesOwner(app, record)
+	if !ok {
+		return nil
+	}
+
+	tracked, err := app.FindAllStorageFilesByRecord(record)
+	if err != nil {
+		return fmt.Errorf("failed to load the record storage files: %w", err)
+	}
+
+	for _, upload := range uploaded {
+		if slices.ContainsFunc(tracked, func(t *StorageFile) bool { return t.Filename() == upload.Name }) {
+			continue // already tracked
+		}
+
+		file := NewStorageFile(app)
+		file.SetCollectionRef(record.Collection().Id)
+		file.SetRecordRef(record.Id)
+		file.SetOwnerRef(owner)
+		file.SetField(f.Name)
+		file.SetFilename(upload.Name)
+		file.SetSize(upload.Size)
+
+		if err := app.Save(file); err != nil {
+			return fmt.Errorf("failed to save storage file record %q: %w", upload.Name, err)
+		}
+	}
+
+	return validation.Errors{f.Name: enforceStorageQuotas(app, record)}.Filter()
+}
+
+// deleteStorageFiles deletes the tracked storage file records of the specified record files.
+//
+// note: a failure to delete the storage file record is not critical and it is only logged.
+func (f *FileField) deleteStorageFiles(app App, record *Record, filenames []string) {
+	if len(filenames) == 0 {
+		return
+	}
+
+	tracked, err := app.FindAllStorageFilesByRecord(record)
+	if err != nil {
+		app.Logger().Warn("Failed to load the record storage files", "error", err, "recordId", record.Id)
+		return
+	}
+
+	for _, file := range tracked {
+		if file.Field() != f.Name || !slices.Contains(filenames, file.Filename()) {
+			continue
+		}
+
+		if err := app.Delete(file); err != nil {
+			app.Logger().Warn(
+				"Failed to delete storage file record",
+				"error", err,
+				"recordId", record.Id,
+				"storageFileId", file.Id,
+			)
+		}
+	}
+}
+
+// quarantineFileKey returns the storage key of a quarantined record file.
+func quarantineFileKey(prefix string, record *Record, filename string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		prefix = StorageQuarantineDirName
+	}
+
+	return prefix + "/" + record.Collection().Id + "/" + record.Id + "/" + filename
+}
+
+// uploadFile uploads a single new record file applying the field image transform (if any).
+//
+// The transformed image is stored as any other plain file (including the deduplication).
+// On success the upload is updated to reflect the stored transformed file name, content
+// and size (note: the size includes also the kept original in order to be tracked by the
+// storage quotas).
+func (f *FileField) uploadFile(app App, fsys *filesystem.System, record *Record, upload *filesystem.File, transform filesystem.ImageTransform) error {
+	if transform.IsZero() {
+		return f.uploadPlainFile(app, fsys, record, upload)
+	}
+
+	transformed, err := transform.TransformFile(upload)
+	if err != nil {
+		return err
+	}
+
+	if transformed == upload {
+		return f.uploadPlainFile(app, fsys, record, upload)
+	}
+
+	var originalKey string
+	if f.KeepOriginal {
+		originalKey = record.BaseFilesPath() + "/originals_" + transformed.Name + "/" + upload.Name
+
+		err = fsys.UploadFile(upload, originalKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = f.uploadPlainFile(app, fsys, record, transformed)
+	if err != nil {
+		if originalKey != "" {
+			return errors.Join(err, fsys.Delete(originalKey))
+		}
+		return err
+	}
+
+	size := transformed.Size
+	if f.KeepOriginal {
+		size += upload.Size
+	}
+
+	upload.Name = transformed.Name
+	upload.Reader = transformed.Reader
+	upload.Size = size
+
+	return nil
+}
+
+// uploadPlainFile uploads a single new record file as it is
+// (or as content-addressed blob if the field deduplication is enabled).
+//
+// note: the blob reference is created before the blob upload and the blob
+// existence check is executed under the blob lock to prevent the blob being
+// released by a concurrent delete. When in transaction, the reference is not
+// visible to the concurrent releases until commit so the blob is rechecked
+// after it (see [FileField.restoreMissingFileBlobs]).
+func (f *FileField) uploadPlainFile(app App, fsys *filesystem.System, record *Record, upload *filesystem.File) error {
+	if !f.Deduplicate {
+		return fsys.UploadFile(upload, record.BaseFilesPath()+"/"+upload.Name)
+	}
+
+	hash, err := fileContentHash(upload)
+	if err != nil {
+		return err
+	}
+
+	ref := NewFileRef(app)
+	ref.SetCollectionRef(record.Collection().Id)
+	ref.SetRecordRef(record.Id)
+	ref.SetField(f.Name)
+	ref.SetFilename(upload.Name)
+	ref.SetHash(hash)
+
+	// the record may not exist yet so skip the recordRef validation
+	err = app.SaveNoValidate(ref)
+	if err != nil {
+		return err
+	}
+
+	deduped, _ := record.GetRaw(dedupedFilesPrefix + f.Name).(map[string]string)
+	if deduped == nil {
+		deduped = map[string]string{}
+	}
+	deduped[upload.Name] = hash
+	record.SetRaw(dedupedFilesPrefix+f.Name, deduped)
+
+	unlock := lockFileBlob(hash)
+
+	if exists, _ := fsys.Exists(ref.BlobKey()); exists {
+		unlock()
+		return nil // already stored
+	}
+
+	err = fsys.UploadFile(upload, ref.BlobKey())
+	unlock()
+	if err != nil {
+		return errors.Join(err, app.Delete(ref))
+	}
+
+	return nil
+}
+
+// restoreMissingFileBlobs re-uploads the blobs of the new deduplicated
+// record files that were released by a concurrent delete before the
+// record commit (the releases count only the committed references).
+//
+// note: a failure to restore the blob is not critical and it is only logged.
+func (f *FileField) restoreMissingFileBlobs(ctx context.Context, app App, record *Record) {
+	deduped, _ := record.GetRaw(dedupedFilesPrefix + f.Name).(map[string]string)
+	if len(deduped) == 0 {
+		return
+	}
+
+	uploaded, _ := record.GetRaw(uploadedFilesPrefix + f.Name).([]*filesystem.File)
+	if len(uploaded) == 0 {
+		return
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		app.Logger().Warn("Failed to initialize the filesystem for the file blobs check", "error", err)
+		return
+	}
+	defer fsys.Close()
+	fsys.SetContext(ctx)
+
+	for _, file := range uploaded {
+		hash, ok := deduped[file.Name]
+		if !ok {
+			continue
+		}
+
+		unlock := lockFileBlob(hash)
+
+		exists, err := fsys.Exists(FileBlobKey(hash))
+		if err == nil && !exists {
+			err = fsys.UploadFile(file, FileBlobKey(hash))
+		}
+
+		unlock()
+
+		if err != nil {
+			app.Logger().Warn(
+				"Failed to restore the released file blob",
+				"error", err,
+				"recordId", record.Id,
+				"hash", hash,
+			)
+		}
+	}
+}
+
+func detectFileContentType(file *filesystem.File) (string, error) {
+	r, err := file.Reader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	mt, err := mimetype.DetectReader(r)
+	if err != nil {
+		return "", err
+	}
+
+	return mt.String(), nil
+}
+
 func (f *FileField) deleteNewlyUploadedFiles(ctx context.Context, app App, record *Record) ([]string, error) {
 	uploaded, _ := record.GetRaw(uploadedFilesPrefix + f.Name).([]*filesystem.File)
 	if len(uploaded) == 0 {
@@ -587,6 +1288,8 @@ func (f *FileField) deleteFilesByNamesList(ctx context.Context, app App, record
 
 	var failures []error
 
+	deleted := slices.Clone(filenames)
+
 	for i := len(filenames) - 1; i >= 0; i-- {
 		filename := filenames[i]
 		if filename == "" || strings.ContainsAny(filename, "/\\") {
@@ -608,9 +1311,19 @@ func (f *FileField) deleteFilesByNamesList(ctx context.Context, app App, record
 			if len(thumbsErr) > 0 {
 				app.Logger().Warn("Failed to delete file thumbs", "error", errors.Join(thumbsErr...))
 			}
+
+			// try to delete the related kept original (if any)
+			originalsErr := fsys.DeletePrefix(record.BaseFilesPath() + "/originals_" + filename + "/")
+			if len(originalsErr) > 0 {
+				app.Logger().Warn("Failed to delete file originals", "error", errors.Join(originalsErr...))
+			}
 		}
 	}
 
+	f.deleteFileRefs(ctx, app, record, list.SubtractSlice(deleted, filenames))
+
+	f.deleteStorageFiles(app, record, list.SubtractSlice(deleted, filenames))
+
 	if len(failures) > 0 {
 		return filenames, fmt.Errorf("failed to delete all files: %w", errors.Join(failures...))
 	}
@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
+	"image/png"
+	"io"
 	"slices"
 	"strings"
 	"testing"
@@ -583,6 +586,70 @@ func TestFileFieldValidateSettings(t *testing.T) {
 			},
 			[]string{"maxSelect"},
 		},
+		{
+			"valid extended thumbs",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:     "test",
+					Name:   "test",
+					Thumbs: []string{"100x200c", "100x200s.webp", "100x200p_q80", "0x100.jpg"},
+				}
+			},
+			[]string{},
+		},
+		{
+			"invalid thumb quality",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:     "test",
+					Name:   "test",
+					Thumbs: []string{"100x200_q0"},
+				}
+			},
+			[]string{"thumbs"},
+		},
+		{
+			"nonexisting focal point field",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:              "test",
+					Name:            "test",
+					FocalPointField: "missing",
+				}
+			},
+			[]string{"focalPointField"},
+		},
+		{
+			"invalid image transform options",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:             "test",
+					Name:           "test",
+					ImageMaxWidth:  -1,
+					ImageMaxHeight: 100000,
+					ImageFormat:    "gif",
+					ImageQuality:   101,
+				}
+			},
+			[]string{"imageMaxWidth", "imageMaxHeight", "imageFormat", "imageQuality"},
+		},
+		{
+			"valid image transform options",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:                 "test",
+					Name:               "test",
+					ImageMaxWidth:      1000,
+					ImageMaxHeight:     1000,
+					ImageFormat:        "jpeg",
+					ImageQuality:       80,
+					ImageStripMetadata: true,
+					ImageAutoOrient:    true,
+					KeepOriginal:       true,
+				}
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -961,6 +1028,432 @@ func TestFileFieldIntercept(t *testing.T) {
 	})
 }
 
+func TestFileFieldInterceptScan(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	demo1, err := testApp.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testApp.OnRecordFileScan("demo1").BindFunc(func(e *core.RecordFileScanEvent) error {
+		switch e.File.OriginalName {
+		case "reject.txt":
+			e.Status = core.FileScanStatusInfected
+			e.Signature = "Test.Reject"
+		case "quarantine.txt":
+			e.Status = core.FileScanStatusInfected
+			e.Signature = "Test.Quarantine"
+			e.Action = core.FileScanActionQuarantine
+		case "clean.txt":
+			e.Status = core.FileScanStatusClean
+		}
+		return e.Next()
+	})
+
+	newFile := func(name string) *filesystem.File {
+		f, err := filesystem.NewFileFromBytes([]byte("test"), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	record := core.NewRecord(demo1)
+
+	t.Run("reject", func(t *testing.T) {
+		record.Set("file_many", []any{newFile("clean.txt"), newFile("reject.txt")})
+
+		err := testApp.Save(record)
+		tests.TestValidationErrors(t, err, []string{"file_many"})
+
+		if !record.IsNew() {
+			t.Fatal("Expected the record to remain new")
+		}
+	})
+
+	clean := newFile("clean.txt")
+	unscanned := newFile("unscanned.txt")
+	quarantined := newFile("quarantine.txt")
+
+	t.Run("quarantine", func(t *testing.T) {
+		record.Set("file_many", []any{clean, unscanned, quarantined})
+
+		err := testApp.Save(record)
+		if err != nil {
+			t.Fatalf("Expected save to succeed, got %v", err)
+		}
+
+		raw, _ := json.Marshal(record.GetRaw("file_many"))
+		expectedRaw, _ := json.Marshal([]any{clean.Name, unscanned.Name})
+		if !bytes.Equal(expectedRaw, raw) {
+			t.Fatalf("Expected file field value\n%s\ngot\n%s", expectedRaw, raw)
+		}
+
+		checkRecordFiles(t, testApp, record, []string{clean.Name, unscanned.Name})
+
+		fsys, err := testApp.NewFilesystem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fsys.Close()
+
+		quarantineKey := core.StorageQuarantineDirName + "/" + demo1.Id + "/" + record.Id + "/" + quarantined.Name
+		if exists, _ := fsys.Exists(quarantineKey); !exists {
+			t.Fatalf("Expected quarantined file %q to exist", quarantineKey)
+		}
+
+		scans, err := testApp.FindAllFileScansByRecord(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(scans) != 2 {
+			t.Fatalf("Expected 2 scan records, got %d", len(scans))
+		}
+
+		cleanScan, err := testApp.FindFileScanByFilename(record, "file_many", clean.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cleanScan.Status() != core.FileScanStatusClean || cleanScan.QuarantineKey() != "" {
+			t.Fatalf("Unexpected clean scan record %v", cleanScan)
+		}
+
+		infectedScan, err := testApp.FindFileScanByFilename(record, "file_many", quarantined.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if infectedScan.Status() != core.FileScanStatusInfected ||
+			infectedScan.Signature() != "Test.Quarantine" ||
+			infectedScan.QuarantineKey() != quarantineKey {
+			t.Fatalf("Unexpected infected scan record %v", infectedScan)
+		}
+	})
+
+	t.Run("delete scanned file", func(t *testing.T) {
+		record.Set("file_many-", clean.Name)
+
+		err := testApp.Save(record)
+		if err != nil {
+			t.Fatalf("Expected save to succeed, got %v", err)
+		}
+
+		if _, err := testApp.FindFileScanByFilename(record, "file_many", clean.Name); err == nil {
+			t.Fatal("Expected the clean file scan record to be deleted")
+		}
+
+		if _, err := testApp.FindFileScanByFilename(record, "file_many", quarantined.Name); err != nil {
+			t.Fatalf("Expected the quarantined file scan record to remain, got %v", err)
+		}
+	})
+
+	t.Run("delete record", func(t *testing.T) {
+		err := testApp.Delete(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		scans, err := testApp.FindAllFileScansByRecord(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(scans) != 0 {
+			t.Fatalf("Expected all scan records to be deleted, got %d", len(scans))
+		}
+	})
+}
+
+func TestFileFieldInterceptDeduplicate(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	demo1, err := testApp.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	demo1.Fields.GetByName("file_many").(*core.FileField).Deduplicate = true
+
+	fsys, err := testApp.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	countBlobs := func() int {
+		objects, err := fsys.List(core.StorageBlobsDirName + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(objects)
+	}
+
+	newFile := func(content string, name string) *filesystem.File {
+		f, err := filesystem.NewFileFromBytes([]byte(content), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	record1 := core.NewRecord(demo1)
+	record1.Set("file_many", []any{newFile("same", "a.txt"), newFile("other", "b.txt")})
+	if err := testApp.Save(record1); err != nil {
+		t.Fatal(err)
+	}
+
+	record2 := core.NewRecord(demo1)
+	record2.Set("file_many", []any{newFile("same", "c.txt")})
+	if err := testApp.Save(record2); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := countBlobs(); total != 2 {
+		t.Fatalf("Expected 2 stored blobs, got %d", total)
+	}
+
+	// no regular record files should be stored
+	checkRecordFiles(t, testApp, record1, nil)
+	checkRecordFiles(t, testApp, record2, nil)
+
+	file1 := record1.GetStringSlice("file_many")[0]
+	file2 := record2.GetStringSlice("file_many")[0]
+
+	key1 := testApp.FindRecordFileKey(record1, file1)
+	key2 := testApp.FindRecordFileKey(record2, file2)
+	if key1 != key2 || !strings.HasPrefix(key1, core.StorageBlobsDirName+"/") {
+		t.Fatalf("Expected the same blob key, got %q and %q", key1, key2)
+	}
+
+	content, err := fsys.GetReader(key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(content)
+	content.Close()
+	if string(raw) != "same" {
+		t.Fatalf("Expected blob content %q, got %q", "same", raw)
+	}
+
+	t.Run("remove one of the references", func(t *testing.T) {
+		record1.Set("file_many-", file1)
+		if err := testApp.Save(record1); err != nil {
+			t.Fatal(err)
+		}
+
+		if exists, _ := fsys.Exists(key1); !exists {
+			t.Fatal("Expected the blob to remain while still referenced")
+		}
+
+		if total := countBlobs(); total != 2 {
+			t.Fatalf("Expected 2 stored blobs, got %d", total)
+		}
+	})
+
+	t.Run("remove the last reference", func(t *testing.T) {
+		if err := testApp.Delete(record2); err != nil {
+			t.Fatal(err)
+		}
+
+		if exists, _ := fsys.Exists(key1); exists {
+			t.Fatal("Expected the blob to be deleted")
+		}
+
+		if total := countBlobs(); total != 1 {
+			t.Fatalf("Expected 1 stored blob, got %d", total)
+		}
+	})
+
+	t.Run("blob released by a concurrent delete before the transaction commit", func(t *testing.T) {
+		var record *core.Record
+
+		err := testApp.RunInTransaction(func(txApp core.App) error {
+			record = core.NewRecord(demo1)
+			record.Set("file_many", []any{newFile("other", "e.txt")})
+			if err := txApp.Save(record); err != nil {
+				return err
+			}
+
+			// simulate a concurrent release that doesn't see the uncommitted reference
+			return fsys.Delete(txApp.FindRecordFileKey(record, record.GetStringSlice("file_many")[0]))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if exists, _ := fsys.Exists(testApp.FindRecordFileKey(record, record.GetStringSlice("file_many")[0])); !exists {
+			t.Fatal("Expected the released blob to be restored after commit")
+		}
+	})
+
+	t.Run("db write failure", func(t *testing.T) {
+		record := core.NewRecord(demo1)
+		record.Id = record1.Id // trigger primary key constraint error
+		record.Set("file_many", []any{newFile("new", "d.txt")})
+
+		if err := testApp.SaveNoValidate(record); err == nil {
+			t.Fatal("Expected save error")
+		}
+
+		if total := countBlobs(); total != 1 {
+			t.Fatalf("Expected 1 stored blob, got %d", total)
+		}
+	})
+}
+
+func TestFileFieldStorageQuotas(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	demo1, err := testApp.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testApp.Settings().Storage.Quotas = []core.StorageQuota{
+		{Collection: demo1.Name, MaxSize: 12},
+		{Collection: demo1.Id, OwnerField: "rel_one", MaxSize: 8},
+	}
+
+	const owner1 = "84nmscqy84lsi1t"
+	const owner2 = "al1h9ijdeojtsjy"
+
+	newFile := func(content string, name string) *filesystem.File {
+		f, err := filesystem.NewFileFromBytes([]byte(content), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	checkUsage := func(t *testing.T, ownerId string, expectedFiles int, expectedSize int64) {
+		t.Helper()
+
+		usage, err := testApp.FindStorageUsage(demo1.Id, ownerId)
+		if err != nil {
+			t.Fatalf("Failed to find the %q usage: %v", ownerId, err)
+		}
+
+		if usage.Files() != expectedFiles || usage.Size() != expectedSize {
+			t.Fatalf("Expected %q usage (%d, %d), got (%d, %d)", ownerId, expectedFiles, expectedSize, usage.Files(), usage.Size())
+		}
+	}
+
+	record1 := core.NewRecord(demo1)
+	record1.Set("rel_one", owner1)
+	record1.Set("file_one", newFile("1234", "a.txt"))
+	record1.Set("file_many", []any{newFile("1234", "b.txt")})
+	if err := testApp.Save(record1); err != nil {
+		t.Fatal(err)
+	}
+
+	checkUsage(t, "", 2, 8)
+	checkUsage(t, owner1, 2, 8)
+
+	files, err := testApp.FindAllStorageFilesByRecord(record1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 tracked files, got %d", len(files))
+	}
+
+	t.Run("exceed the owner quota", func(t *testing.T) {
+		record1.Set("file_many+", newFile("1", "c.txt"))
+
+		err := testApp.Save(record1)
+		tests.TestValidationErrors(t, err, []string{"file_many"})
+
+		checkUsage(t, owner1, 2, 8)
+	})
+
+	t.Run("replace a file within the owner quota", func(t *testing.T) {
+		record1.Set("file_many", []any{newFile("12", "d.txt")})
+		if err := testApp.Save(record1); err != nil {
+			t.Fatal(err)
+		}
+
+		checkUsage(t, "", 2, 6)
+		checkUsage(t, owner1, 2, 6)
+	})
+
+	t.Run("exceed the collection quota", func(t *testing.T) {
+		record2 := core.NewRecord(demo1)
+		record2.Set("rel_one", owner2)
+		record2.Set("file_many", []any{newFile("1234567", "e.txt")})
+
+		err := testApp.Save(record2)
+		tests.TestValidationErrors(t, err, []string{"file_many"})
+
+		checkUsage(t, "", 2, 6)
+	})
+
+	t.Run("exceed the quota with skipped validation", func(t *testing.T) {
+		// simulates a concurrent upload that passed the validation check
+		record2 := core.NewRecord(demo1)
+		record2.Set("rel_one", owner2)
+		record2.Set("file_many", []any{newFile("1234567", "f.txt")})
+
+		err := testApp.SaveNoValidate(record2)
+		tests.TestValidationErrors(t, err, []string{"file_many"})
+
+		checkUsage(t, "", 2, 6)
+
+		if _, err := testApp.FindRecordById(demo1, record2.Id); err == nil {
+			t.Fatal("Expected the record create to be rolled back")
+		}
+
+		fsys, err := testApp.NewFilesystem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fsys.Close()
+
+		if exists, _ := fsys.Exists(record2.BaseFilesPath() + "/f.txt"); exists {
+			t.Fatal("Expected the uploaded file to be deleted")
+		}
+	})
+
+	t.Run("change the owner", func(t *testing.T) {
+		record1.Set("rel_one", owner2)
+		if err := testApp.Save(record1); err != nil {
+			t.Fatal(err)
+		}
+
+		checkUsage(t, "", 2, 6)
+		checkUsage(t, owner1, 0, 0)
+		checkUsage(t, owner2, 2, 6)
+	})
+
+	t.Run("change back the owner", func(t *testing.T) {
+		record1.Set("rel_one", owner1)
+		if err := testApp.Save(record1); err != nil {
+			t.Fatal(err)
+		}
+
+		checkUsage(t, "", 2, 6)
+		checkUsage(t, owner1, 2, 6)
+		checkUsage(t, owner2, 0, 0)
+	})
+
+	t.Run("delete the record", func(t *testing.T) {
+		if err := testApp.Delete(record1); err != nil {
+			t.Fatal(err)
+		}
+
+		checkUsage(t, "", 0, 0)
+		checkUsage(t, owner1, 0, 0)
+
+		files, err := testApp.FindAllStorageFilesByRecord(record1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 0 {
+			t.Fatalf("Expected no tracked files, got %d", len(files))
+		}
+	})
+}
+
 func TestFileFieldInterceptTx(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -1121,6 +1614,162 @@ func TestFileFieldInterceptTx(t *testing.T) {
 
 // -------------------------------------------------------------------
 
+func TestFileFieldFocalPoint(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(&core.JSONField{Name: "focal"})
+
+	scenarios := []struct {
+		name            string
+		focalPointField string
+		value           any
+		filename        string
+		expectedOk      bool
+		expected        filesystem.FocalPoint
+	}{
+		{"no focal point field", "", map[string]any{"x": 0.1, "y": 0.2}, "a.png", false, filesystem.FocalPoint{}},
+		{"empty value", "focal", nil, "a.png", false, filesystem.FocalPoint{}},
+		{"non-object value", "focal", []int{1, 2}, "a.png", false, filesystem.FocalPoint{}},
+		{"missing coordinate", "focal", map[string]any{"x": 0.1}, "a.png", false, filesystem.FocalPoint{}},
+		{"single point", "focal", map[string]any{"x": 0.1, "y": 0.2}, "a.png", true, filesystem.FocalPoint{X: 0.1, Y: 0.2}},
+		{"out of range point", "focal", map[string]any{"x": -1, "y": 2}, "a.png", true, filesystem.FocalPoint{X: 0, Y: 1}},
+		{"per file point", "focal", map[string]any{"a.png": map[string]any{"x": 0.3, "y": 0.4}}, "a.png", true, filesystem.FocalPoint{X: 0.3, Y: 0.4}},
+		{"per file point (missing file)", "focal", map[string]any{"a.png": map[string]any{"x": 0.3, "y": 0.4}}, "b.png", false, filesystem.FocalPoint{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			field := &core.FileField{Name: "file", FocalPointField: s.focalPointField}
+
+			record := core.NewRecord(collection)
+			record.Set("focal", s.value)
+
+			point, ok := field.FocalPoint(record, s.filename)
+
+			if ok != s.expectedOk {
+				t.Fatalf("Expected ok %v, got %v", s.expectedOk, ok)
+			}
+
+			if point != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, point)
+			}
+		})
+	}
+}
+
+func TestFileFieldImageTransform(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	demo1, err := testApp.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	field := demo1.Fields.GetByName("file_many").(*core.FileField)
+	field.ImageMaxWidth = 50
+	field.ImageFormat = "jpeg"
+	field.KeepOriginal = true
+
+	testApp.Settings().Storage.Quotas = []core.StorageQuota{{Collection: demo1.Name, MaxSize: 1 << 20}}
+
+	pngBuf := new(bytes.Buffer)
+	if err := png.Encode(pngBuf, image.NewRGBA(image.Rect(0, 0, 200, 100))); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := filesystem.NewFileFromBytes(pngBuf.Bytes(), "image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalName := img.Name
+
+	txt, err := filesystem.NewFileFromBytes([]byte("test"), "new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(demo1)
+	record.Set("text", "test")
+	record.Set("file_many", []any{img, txt})
+
+	if err := testApp.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	files := record.GetStringSlice("file_many")
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %v", files)
+	}
+
+	expectedName := strings.TrimSuffix(originalName, ".png") + ".jpg"
+	if files[0] != expectedName {
+		t.Fatalf("Expected the converted image name %q, got %q", expectedName, files[0])
+	}
+	if files[1] != txt.Name {
+		t.Fatalf("Expected the unchanged text file name %q, got %q", txt.Name, files[1])
+	}
+
+	checkRecordFiles(t, testApp, record, []string{
+		files[0],
+		files[1],
+		"originals_" + files[0] + "/" + originalName,
+	})
+
+	fsys, err := testApp.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	r, err := fsys.GetReader(record.BaseFilesPath() + "/" + files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	config, format, err := image.DecodeConfig(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || config.Width != 50 || config.Height != 25 {
+		t.Fatalf("Expected 50x25 jpeg image, got %dx%d %s", config.Width, config.Height, format)
+	}
+
+	// the tracked size should be the size of the stored transformed image and its original
+	transformedAttrs, err := fsys.Attributes(record.BaseFilesPath() + "/" + files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedSize := transformedAttrs.Size + int64(pngBuf.Len())
+
+	tracked, err := testApp.FindAllStorageFilesByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range tracked {
+		if file.Filename() == files[0] && file.Size() != expectedSize {
+			t.Fatalf("Expected the tracked image size %d, got %d", expectedSize, file.Size())
+		}
+		if file.Filename() == files[1] && file.Size() != 4 {
+			t.Fatalf("Expected the tracked text file size %d, got %d", 4, file.Size())
+		}
+	}
+	if len(tracked) != 2 {
+		t.Fatalf("Expected 2 tracked files, got %d", len(tracked))
+	}
+
+	// deleting the transformed file should delete also its original
+	record.Set("file_many-", files[0])
+	if err := testApp.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRecordFiles(t, testApp, record, []string{files[1]})
+}
+
 func checkRecordFiles(t *testing.T, testApp core.App, record *core.Record, expectedKeys []string) {
 	fsys, err := testApp.NewFilesystem()
 	if err != nil {
@@ -1150,3 +1799,123 @@ func checkRecordFiles(t *testing.T, testApp core.App, record *core.Record, expec
 		}
 	}
 }
+
+func TestFileFieldImageTransformDeduplicate(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	demo1, err := testApp.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	field := demo1.Fields.GetByName("file_many").(*core.FileField)
+	field.ImageFormat = "jpeg"
+	field.Deduplicate = true
+
+	pngBuf := new(bytes.Buffer)
+	if err := png.Encode(pngBuf, image.NewRGBA(image.Rect(0, 0, 20, 10))); err != nil {
+		t.Fatal(err)
+	}
+
+	newImage := func() *filesystem.File {
+		f, err := filesystem.NewFileFromBytes(pngBuf.Bytes(), "image.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	record1 := core.NewRecord(demo1)
+	record1.Set("file_many", []any{newImage()})
+	if err := testApp.Save(record1); err != nil {
+		t.Fatal(err)
+	}
+
+	record2 := core.NewRecord(demo1)
+	record2.Set("file_many", []any{newImage()})
+	if err := testApp.Save(record2); err != nil {
+		t.Fatal(err)
+	}
+
+	// no regular record files should be stored
+	checkRecordFiles(t, testApp, record1, nil)
+	checkRecordFiles(t, testApp, record2, nil)
+
+	file1 := record1.GetStringSlice("file_many")[0]
+	file2 := record2.GetStringSlice("file_many")[0]
+	if !strings.HasSuffix(file1, ".jpg") || !strings.HasSuffix(file2, ".jpg") {
+		t.Fatalf("Expected converted jpg images, got %q and %q", file1, file2)
+	}
+
+	key1 := testApp.FindRecordFileKey(record1, file1)
+	key2 := testApp.FindRecordFileKey(record2, file2)
+	if key1 != key2 || !strings.HasPrefix(key1, core.StorageBlobsDirName+"/") {
+		t.Fatalf("Expected the same blob key, got %q and %q", key1, key2)
+	}
+
+	fsys, err := testApp.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	attrs, err := fsys.Attributes(key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.ContentType != "image/jpeg" {
+		t.Fatalf("Expected the blob to be the transformed image, got %q", attrs.ContentType)
+	}
+}
+
+func TestFileFieldImageTransformMimeTypes(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	pngBuf := new(bytes.Buffer)
+	if err := png.Encode(pngBuf, image.NewRGBA(image.Rect(0, 0, 20, 10))); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		format      string
+		mimeTypes   []string
+		expectError bool
+	}{
+		{"no transform", "", []string{"image/png"}, false},
+		{"preserved format", "png", []string{"image/png"}, false},
+		{"not allowed transformed format", "jpeg", []string{"image/png"}, true},
+		{"allowed transformed format", "jpeg", []string{"image/png", "image/jpeg"}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			field := &core.FileField{
+				Name:        "test",
+				MaxSelect:   1,
+				MimeTypes:   s.mimeTypes,
+				ImageFormat: s.format,
+			}
+
+			collection := core.NewBaseCollection("test_collection")
+			collection.Fields.Add(field)
+
+			img, err := filesystem.NewFileFromBytes(pngBuf.Bytes(), "image.png")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record := core.NewRecord(collection)
+			record.Set("test", img)
+
+			err = field.ValidateValue(context.Background(), testApp, record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	Fields[FieldTypeGeoPolygon] = func() Field {
+		return &GeoPolygonField{}
+	}
+}
+
+const FieldTypeGeoPolygon = "geoPolygon"
+
+var (
+	_ Field = (*GeoPolygonField)(nil)
+)
+
+// GeoPolygonField defines "geoPolygon" type field for storing GeoJSON Polygon or MultiPolygon geometry
+// (eg. delivery zones, regions, etc.).
+//
+// You can set the record field value as [types.GeoPolygon], map or serialized GeoJSON geometry object.
+// The stored value is always converted to [types.GeoPolygon].
+// Nil, empty map, empty bytes slice, etc. results in zero [types.GeoPolygon].
+//
+// The field value could be used in filter expressions with the "geoWithin" function, eg.:
+//
+//	geoWithin(@request.body.address, deliveryZone) = true
+//
+// Examples of updating a record's GeoPolygonField value programmatically:
+//
+//	record.Set("zone", types.GeoPolygon{Type: "Polygon", Polygons: [][][][2]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}})
+//	record.Set("zone", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`)
+type GeoPolygonField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Required will require the field value to have at least one polygon.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *GeoPolygonField) Type() string {
+	return FieldTypeGeoPolygon
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *GeoPolygonField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *GeoPolygonField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *GeoPolygonField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *GeoPolygonField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *GeoPolygonField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *GeoPolygonField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *GeoPolygonField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *GeoPolygonField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *GeoPolygonField) ColumnType(app App) string {
+	return "JSON DEFAULT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *GeoPolygonField) PrepareValue(record *Record, raw any) (any, error) {
+	polygon := types.GeoPolygon{}
+	err := polygon.Scan(raw)
+	return polygon, err
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *GeoPolygonField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	val, ok := record.GetRaw(f.Name).(types.GeoPolygon)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	if val.IsZero() {
+		if f.Required {
+			return validation.ErrRequired
+		}
+		return nil
+	}
+
+	if err := val.Validate(); err != nil {
+		return validation.NewError("validation_invalid_geo_polygon", err.Error())
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *GeoPolygonField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+	)
+}
@@ -0,0 +1,253 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestGeoPolygonFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeGeoPolygon)
+}
+
+func TestGeoPolygonFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.GeoPolygonField{}
+
+	expected := "JSON DEFAULT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestGeoPolygonFieldPrepareValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.GeoPolygonField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	polygon := `{"coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"type":"Polygon"}`
+
+	scenarios := []struct {
+		raw      any
+		expected string
+	}{
+		{nil, `null`},
+		{"", `null`},
+		{[]byte{}, `null`},
+		{map[string]any{}, `null`},
+		{polygon, polygon},
+		{[]byte(polygon), polygon},
+		{map[string]any{"type": "Polygon", "coordinates": [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, polygon},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(raw) != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, raw)
+			}
+		})
+	}
+}
+
+func TestGeoPolygonFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	validPolygon := types.GeoPolygon{Type: types.GeoPolygonTypePolygon, Polygons: [][][][2]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}}
+
+	scenarios := []struct {
+		name        string
+		field       *core.GeoPolygonField
+		record      func() *core.Record
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.GeoPolygonField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", `{"type":"Polygon"}`)
+				return record
+			},
+			true,
+		},
+		{
+			"zero field value (not required)",
+			&core.GeoPolygonField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", types.GeoPolygon{})
+				return record
+			},
+			false,
+		},
+		{
+			"zero field value (required)",
+			&core.GeoPolygonField{Name: "test", Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", types.GeoPolygon{})
+				return record
+			},
+			true,
+		},
+		{
+			"unclosed ring",
+			&core.GeoPolygonField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", types.GeoPolygon{Polygons: [][][][2]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}}})
+				return record
+			},
+			true,
+		},
+		{
+			"invalid coordinates",
+			&core.GeoPolygonField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", types.GeoPolygon{Polygons: [][][][2]float64{{{{0, 0}, {200, 0}, {1, 1}, {0, 0}}}}})
+				return record
+			},
+			true,
+		},
+		{
+			"valid polygon (required)",
+			&core.GeoPolygonField{Name: "test", Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", validPolygon)
+				return record
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.field.ValidateValue(context.Background(), app, s.record())
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestGeoPolygonFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeGeoPolygon)
+	testDefaultFieldNameValidation(t, core.FieldTypeGeoPolygon)
+}
+
+func TestGeoPolygonFieldRecordSave(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	zones := core.NewBaseCollection("test_zones")
+	zones.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.GeoPolygonField{Name: "area"},
+	)
+	if err := app.Save(zones); err != nil {
+		t.Fatal(err)
+	}
+
+	zone := core.NewRecord(zones)
+	zone.Set("title", "center")
+	zone.Set("area", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]]}`)
+	if err := app.Save(zone); err != nil {
+		t.Fatal(err)
+	}
+
+	places := core.NewBaseCollection("test_places")
+	places.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.GeoPointField{Name: "location"},
+		&core.RelationField{Name: "zone", CollectionId: zones.Id, MaxSelect: 1},
+	)
+	if err := app.Save(places); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]types.GeoPoint{
+		"a": {Lon: 1, Lat: 1},
+		"b": {Lon: 5, Lat: 5}, // in the hole
+		"c": {Lon: 9, Lat: 2},
+		"d": {Lon: 20, Lat: 20},
+	}
+	for title, location := range data {
+		record := core.NewRecord(places)
+		record.Set("title", title)
+		record.Set("location", location)
+		record.Set("zone", zone.Id)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the stored value must be the GeoJSON geometry
+	var raw string
+	err := app.DB().Select("area").From(zones.Name).Where(dbx.HashExp{"id": zone.Id}).Row(&raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(raw, `"type":"Polygon"`) {
+		t.Fatalf("Expected the stored value to be a GeoJSON Polygon, got %s", raw)
+	}
+
+	scenarios := []struct {
+		filter   string
+		expected []string
+	}{
+		{"geoWithin(location, zone.area) = true", []string{"a", "c"}},
+		{"geoWithin(location, zone.area) = false", []string{"b", "d"}},
+		{`geoWithin(location, '{"type":"Polygon","coordinates":[[[0,0],[6,0],[6,6],[0,0]]]}') = true`, []string{"a", "b"}},
+		{"geoWithin(location, 'invalid') = true", []string{}},
+		{"geoBBox(location, 0, 0, 10, 10) = true", []string{"a", "b", "c"}},
+		{"geoBBox(location, 4, 1, 21, 21) = true", []string{"b", "c", "d"}},
+		{"geoBBox(location, 9, 0, 2, 10) = true", []string{"a", "c"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			records, err := app.FindRecordsByFilter(places, s.filter, "title", 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			titles := make([]string, len(records))
+			for i, r := range records {
+				titles[i] = r.GetString("title")
+			}
+
+			if strings.Join(titles, ",") != strings.Join(s.expected, ",") {
+				t.Fatalf("Expected %v, got %v", s.expected, titles)
+			}
+		})
+	}
+}
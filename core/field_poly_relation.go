@@ -0,0 +1,527 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypePolyRelation] = func() Field {
+		return &PolyRelationField{}
+	}
+}
+
+const FieldTypePolyRelation = "polyRelation"
+
+var (
+	_ Field        = (*PolyRelationField)(nil)
+	_ MultiValuer  = (*PolyRelationField)(nil)
+	_ DriverValuer = (*PolyRelationField)(nil)
+	_ SetterFinder = (*PolyRelationField)(nil)
+)
+
+// PolyRef defines a single polymorphic relation reference.
+type PolyRef struct {
+	CollectionId string `json:"collectionId"`
+	RecordId     string `json:"recordId"`
+}
+
+// IsZero checks whether the current reference has missing collection or record id.
+func (ref PolyRef) IsZero() bool {
+	return ref.CollectionId == "" || ref.RecordId == ""
+}
+
+// String returns the "collectionId:recordId" representation of the current reference.
+func (ref PolyRef) String() string {
+	return ref.CollectionId + ":" + ref.RecordId
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+//
+// The zero PolyRef value is serialized as null.
+func (ref PolyRef) MarshalJSON() ([]byte, error) {
+	if ref.IsZero() {
+		return []byte("null"), nil
+	}
+
+	type alias PolyRef // prevent recursion
+
+	return json.Marshal(alias(ref))
+}
+
+// PolyRelationField defines "polyRelation" type field for storing single or
+// multiple polymorphic record references, aka. references to records
+// from different collections (eg. a comment that could belong to a post, task or user).
+//
+// Requires the CollectionIds option to be set with the allowed target collections.
+//
+// If MaxSelect is not set or <= 1, then the field value is expected to be a single [PolyRef].
+//
+// If MaxSelect is > 1, then the field value is expected to be a slice of [PolyRef].
+//
+// The value is always stored as JSON array of {"collectionId":"...", "recordId":"..."} objects.
+//
+// You can set the record field value as [PolyRef], []PolyRef, map, slice of maps,
+// serialized JSON object or array, or "collectionId:recordId" string(s).
+//
+// In filter expressions the references could be accessed with the
+// "collectionId", "collectionName" and "recordId" field props, eg.:
+//
+//	target.collectionName = 'posts' && target.recordId = 'abc'
+//
+// ---
+//
+// The following additional setter keys are available:
+//
+//   - "fieldName+" - append one or more values to the existing record one. For example:
+//
+//     record.Set("targets+", "pbc_123:abc") // []PolyRef{old1, old2, {"pbc_123", "abc"}}
+//
+//   - "+fieldName" - prepend one or more values to the existing record one. For example:
+//
+//     record.Set("+targets", "pbc_123:abc") // []PolyRef{{"pbc_123", "abc"}, old1, old2}
+//
+//   - "fieldName-" - subtract one or more values from the existing record one. For example:
+//
+//     record.Set("targets-", "pbc_123:abc") // []PolyRef{old1, old2}
+type PolyRelationField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// CollectionIds is the allow-list with the ids of the collections
+	// whose records could be referenced.
+	CollectionIds []string `form:"collectionIds" json:"collectionIds"`
+
+	// MaxSelect indicates the max number of allowed references.
+	//
+	// For multiple select the value must be > 1, otherwise fallbacks to single (default).
+	MaxSelect int `form:"maxSelect" json:"maxSelect"`
+
+	// Required will require the field value to be non-empty.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *PolyRelationField) Type() string {
+	return FieldTypePolyRelation
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *PolyRelationField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *PolyRelationField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *PolyRelationField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *PolyRelationField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *PolyRelationField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *PolyRelationField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *PolyRelationField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *PolyRelationField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// IsMultiple implements [MultiValuer] interface and checks whether the
+// current field options support multiple values.
+func (f *PolyRelationField) IsMultiple() bool {
+	return f.MaxSelect > 1
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *PolyRelationField) ColumnType(app App) string {
+	return "JSON DEFAULT '[]' NOT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *PolyRelationField) PrepareValue(record *Record, raw any) (any, error) {
+	return f.normalizeValue(raw), nil
+}
+
+func (f *PolyRelationField) normalizeValue(raw any) any {
+	refs := ParsePolyRefs(raw)
+
+	if !f.IsMultiple() {
+		if len(refs) > 0 {
+			return refs[len(refs)-1] // the last selected
+		}
+		return PolyRef{}
+	}
+
+	return refs
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *PolyRelationField) DriverValue(record *Record) (driver.Value, error) {
+	refs := ParsePolyRefs(record.GetRaw(f.Name))
+
+	if !f.IsMultiple() && len(refs) > 1 {
+		refs = refs[len(refs)-1:] // the last selected
+	}
+
+	// serialize as json array
+	return append(types.JSONArray[PolyRef]{}, refs...), nil
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *PolyRelationField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	refs := ParsePolyRefs(record.GetRaw(f.Name))
+	if len(refs) == 0 {
+		if f.Required {
+			return validation.ErrRequired
+		}
+		return nil // nothing to check
+	}
+
+	maxSelect := max(f.MaxSelect, 1)
+	if len(refs) > maxSelect {
+		return validation.NewError("validation_too_many_values", "Select no more than {{.maxSelect}}").
+			SetParams(map[string]any{"maxSelect": maxSelect})
+	}
+
+	// group the record ids by their collection
+	grouped := map[string][]string{}
+	for _, ref := range refs {
+		if !slices.Contains(f.CollectionIds, ref.CollectionId) {
+			return validation.NewError("validation_invalid_poly_rel_collection", "Collection {{.collectionId}} is not allowed").
+				SetParams(map[string]any{"collectionId": ref.CollectionId})
+		}
+		grouped[ref.CollectionId] = append(grouped[ref.CollectionId], ref.RecordId)
+	}
+
+	// check if the related records exist
+	// ---
+	for collectionId, ids := range grouped {
+		relCollection, err := app.FindCachedCollectionByNameOrId(collectionId)
+		if err != nil {
+			return validation.NewError("validation_missing_rel_collection", "Relation connection is missing or cannot be accessed")
+		}
+
+		var total int
+		_ = app.ConcurrentDB().
+			Select("count(*)").
+			From(relCollection.Name).
+			AndWhere(dbx.In("id", list.ToInterfaceSlice(ids)...)).
+			Row(&total)
+		if total != len(ids) {
+			return validation.NewError("validation_missing_rel_records", "Failed to find all relation records with the provided ids")
+		}
+	}
+	// ---
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *PolyRelationField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.CollectionIds, validation.Required, validation.By(f.checkCollectionIds(app, collection))),
+		validation.Field(&f.MaxSelect, validation.Min(0)),
+	)
+}
+
+func (f *PolyRelationField) checkCollectionIds(app App, collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.([]string)
+
+		for _, id := range v {
+			relCollection, _ := app.FindCachedCollectionByNameOrId(id)
+
+			if relCollection == nil || relCollection.Id != id {
+				return validation.NewError(
+					"validation_field_poly_relation_missing_collection",
+					"The relation collection {{.collectionId}} doesn't exist.",
+				).SetParams(map[string]any{"collectionId": id})
+			}
+
+			// allow only views to have relations to other views
+			if !collection.IsView() && relCollection.IsView() {
+				return validation.NewError(
+					"validation_relation_field_non_view_base_collection",
+					"Only view collections are allowed to have relations to other views.",
+				)
+			}
+		}
+
+		if len(list.ToUniqueStringSlice(v)) != len(v) {
+			return validation.NewError("validation_duplicated_collection_ids", "The relation collections must be unique.")
+		}
+
+		return nil
+	}
+}
+
+// ---
+
+// FindSetter implements [SetterFinder] interface method.
+func (f *PolyRelationField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		return f.setValue
+	case "+" + f.Name:
+		return f.prependValue
+	case f.Name + "+":
+		return f.appendValue
+	case f.Name + "-":
+		return f.subtractValue
+	default:
+		return nil
+	}
+}
+
+func (f *PolyRelationField) setValue(record *Record, raw any) {
+	record.SetRaw(f.Name, f.normalizeValue(raw))
+}
+
+func (f *PolyRelationField) appendValue(record *Record, modifierValue any) {
+	f.setValue(record, append(
+		ParsePolyRefs(record.GetRaw(f.Name)),
+		ParsePolyRefs(modifierValue)...,
+	))
+}
+
+func (f *PolyRelationField) prependValue(record *Record, modifierValue any) {
+	f.setValue(record, append(
+		ParsePolyRefs(modifierValue),
+		ParsePolyRefs(record.GetRaw(f.Name))...,
+	))
+}
+
+func (f *PolyRelationField) subtractValue(record *Record, modifierValue any) {
+	subtract := ParsePolyRefs(modifierValue)
+
+	val := slices.DeleteFunc(ParsePolyRefs(record.GetRaw(f.Name)), func(ref PolyRef) bool {
+		return slices.Contains(subtract, ref)
+	})
+
+	f.setValue(record, val)
+}
+
+// -------------------------------------------------------------------
+
+// ParsePolyRefs converts the provided raw value into a slice of unique non-zero [PolyRef] items.
+//
+// The raw value could be a [PolyRef], []PolyRef, map, slice of maps,
+// serialized JSON object or array, or "collectionId:recordId" string(s).
+func ParsePolyRefs(raw any) []PolyRef {
+	result := []PolyRef{}
+
+	appendPolyRefs(&result, raw)
+
+	return result
+}
+
+func appendPolyRefs(result *[]PolyRef, raw any) {
+	add := func(ref PolyRef) {
+		if !ref.IsZero() && !slices.Contains(*result, ref) {
+			*result = append(*result, ref)
+		}
+	}
+
+	switch v := raw.(type) {
+	case nil:
+		// nothing to add
+	case PolyRef:
+		add(v)
+	case *PolyRef:
+		if v != nil {
+			add(*v)
+		}
+	case []PolyRef:
+		for _, ref := range v {
+			add(ref)
+		}
+	case types.JSONArray[PolyRef]:
+		for _, ref := range v {
+			add(ref)
+		}
+	case map[string]any:
+		add(PolyRef{
+			CollectionId: cast.ToString(v["collectionId"]),
+			RecordId:     cast.ToString(v["recordId"]),
+		})
+	case map[string]string:
+		add(PolyRef{
+			CollectionId: v["collectionId"],
+			RecordId:     v["recordId"],
+		})
+	case types.JSONRaw:
+		appendPolyRefs(result, string(v))
+	case []byte:
+		appendPolyRefs(result, string(v))
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return
+		}
+
+		if v[0] == '[' || v[0] == '{' {
+			var decoded any
+			if err := json.Unmarshal([]byte(v), &decoded); err == nil {
+				appendPolyRefs(result, decoded)
+			}
+			return
+		}
+
+		collectionId, recordId, _ := strings.Cut(v, ":")
+		add(PolyRef{CollectionId: collectionId, RecordId: recordId})
+	case []string:
+		for _, item := range v {
+			appendPolyRefs(result, item)
+		}
+	case []any:
+		for _, item := range v {
+			appendPolyRefs(result, item)
+		}
+	case []map[string]any:
+		for _, item := range v {
+			appendPolyRefs(result, item)
+		}
+	default:
+		// fallback to json serialization
+		raw, err := json.Marshal(v)
+		if err == nil {
+			appendPolyRefs(result, raw)
+		}
+	}
+}
+
+// -------------------------------------------------------------------
+
+// findCachedPolyRelationReferences returns all polymorphic relation fields
+// that allow references to the provided collection.
+func findCachedPolyRelationReferences(app App, collection *Collection) map[*Collection][]*PolyRelationField {
+	collections, _ := app.Store().Get(StoreKeyCachedCollections).([]*Collection)
+	if collections == nil {
+		// cache is not initialized yet (eg. run in a system migration)
+		collections, _ = app.FindAllCollections()
+	}
+
+	result := map[*Collection][]*PolyRelationField{}
+
+	for _, c := range collections {
+		if c.IsView() {
+			continue
+		}
+
+		for _, rawField := range c.Fields {
+			f, ok := rawField.(*PolyRelationField)
+			if ok && slices.Contains(f.CollectionIds, collection.Id) {
+				result[c] = append(result[c], f)
+			}
+		}
+	}
+
+	return result
+}
+
+func (app *BaseApp) registerPolyRelationHooks() {
+	// unset the deleted record references from the polymorphic relation fields
+	app.OnRecordDeleteExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			refs := findCachedPolyRelationReferences(e.App, e.Record.Collection())
+			if len(refs) == 0 {
+				return e.Next()
+			}
+
+			deletedRef := PolyRef{CollectionId: e.Record.Collection().Id, RecordId: e.Record.Id}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				for refCollection, fields := range refs {
+					for _, field := range fields {
+						prefixedFieldName := inflector.Columnify(refCollection.Name) + "." + inflector.Columnify(field.Name)
+
+						records, err := txApp.FindAllRecords(refCollection, dbx.Exists(dbx.NewExp(fmt.Sprintf(
+							"SELECT 1 FROM %s {{__je__}} WHERE json_extract([[__je__.value]], '$.collectionId') = {:collectionId} AND json_extract([[__je__.value]], '$.recordId') = {:recordId}",
+							dbutils.JSONEach(prefixedFieldName),
+						), dbx.Params{
+							"collectionId": deletedRef.CollectionId,
+							"recordId":     deletedRef.RecordId,
+						})))
+						if err != nil {
+							return err
+						}
+
+						for _, record := range records {
+							record.Set(field.Name+"-", deletedRef)
+
+							// similar to the relation field references, save without validations
+							if err := txApp.SaveNoValidate(record); err != nil {
+								return err
+							}
+						}
+					}
+				}
+
+				return nil
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+}
@@ -0,0 +1,653 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestPolyRelationFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypePolyRelation)
+}
+
+func TestPolyRelationFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name     string
+		field    *core.PolyRelationField
+		expected string
+	}{
+		{
+			"single (zero)",
+			&core.PolyRelationField{},
+			"JSON DEFAULT '[]' NOT NULL",
+		},
+		{
+			"multiple",
+			&core.PolyRelationField{MaxSelect: 2},
+			"JSON DEFAULT '[]' NOT NULL",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := s.field.ColumnType(app); v != s.expected {
+				t.Fatalf("Expected\n%q\ngot\n%q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestPolyRelationFieldIsMultiple(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		field    *core.PolyRelationField
+		expected bool
+	}{
+		{"zero", &core.PolyRelationField{}, false},
+		{"single", &core.PolyRelationField{MaxSelect: 1}, false},
+		{"multiple", &core.PolyRelationField{MaxSelect: 2}, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := s.field.IsMultiple(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestParsePolyRefs(t *testing.T) {
+	scenarios := []struct {
+		raw      any
+		expected string
+	}{
+		{nil, `[]`},
+		{"", `[]`},
+		{"invalid", `[]`},
+		{"a:", `[]`},
+		{"a:1", `[{"collectionId":"a","recordId":"1"}]`},
+		{[]string{"a:1", "b:2", "a:1"}, `[{"collectionId":"a","recordId":"1"},{"collectionId":"b","recordId":"2"}]`},
+		{core.PolyRef{CollectionId: "a", RecordId: "1"}, `[{"collectionId":"a","recordId":"1"}]`},
+		{&core.PolyRef{CollectionId: "a", RecordId: "1"}, `[{"collectionId":"a","recordId":"1"}]`},
+		{[]core.PolyRef{{"a", "1"}, {"", "2"}, {"b", "2"}}, `[{"collectionId":"a","recordId":"1"},{"collectionId":"b","recordId":"2"}]`},
+		{map[string]any{"collectionId": "a", "recordId": "1"}, `[{"collectionId":"a","recordId":"1"}]`},
+		{[]any{map[string]any{"collectionId": "a", "recordId": "1"}, "b:2"}, `[{"collectionId":"a","recordId":"1"},{"collectionId":"b","recordId":"2"}]`},
+		{`{"collectionId":"a","recordId":"1"}`, `[{"collectionId":"a","recordId":"1"}]`},
+		{`[{"collectionId":"a","recordId":"1"},{"collectionId":"b","recordId":"2"}]`, `[{"collectionId":"a","recordId":"1"},{"collectionId":"b","recordId":"2"}]`},
+		{types.JSONRaw(`[{"collectionId":"a","recordId":"1"}]`), `[{"collectionId":"a","recordId":"1"}]`},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			raw, err := json.Marshal(core.ParsePolyRefs(s.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := string(raw); v != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestPolyRelationFieldPrepareValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		raw      any
+		field    *core.PolyRelationField
+		expected string
+	}{
+		// single
+		{nil, &core.PolyRelationField{}, `null`},
+		{"", &core.PolyRelationField{}, `null`},
+		{"a:1", &core.PolyRelationField{}, `{"collectionId":"a","recordId":"1"}`},
+		{[]string{"a:1", "b:2"}, &core.PolyRelationField{}, `{"collectionId":"b","recordId":"2"}`},
+		{`[{"collectionId":"a","recordId":"1"}]`, &core.PolyRelationField{}, `{"collectionId":"a","recordId":"1"}`},
+
+		// multiple
+		{nil, &core.PolyRelationField{MaxSelect: 2}, `[]`},
+		{"a:1", &core.PolyRelationField{MaxSelect: 2}, `[{"collectionId":"a","recordId":"1"}]`},
+		{[]string{"a:1", "b:2"}, &core.PolyRelationField{MaxSelect: 2}, `[{"collectionId":"a","recordId":"1"},{"collectionId":"b","recordId":"2"}]`},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v_%v", i, s.raw, s.field.IsMultiple()), func(t *testing.T) {
+			v, err := s.field.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if str := string(raw); str != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestPolyRelationFieldDriverValue(t *testing.T) {
+	scenarios := []struct {
+		raw      any
+		field    *core.PolyRelationField
+		expected string
+	}{
+		{nil, &core.PolyRelationField{Name: "test"}, `[]`},
+		{"a:1", &core.PolyRelationField{Name: "test"}, `[{"collectionId":"a","recordId":"1"}]`},
+		{[]string{"a:1", "b:2"}, &core.PolyRelationField{Name: "test"}, `[{"collectionId":"b","recordId":"2"}]`},
+		{[]string{"a:1", "b:2"}, &core.PolyRelationField{Name: "test", MaxSelect: 2}, `[{"collectionId":"a","recordId":"1"},{"collectionId":"b","recordId":"2"}]`},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v_%v", i, s.raw, s.field.IsMultiple()), func(t *testing.T) {
+			record := core.NewRecord(core.NewBaseCollection("test"))
+			record.SetRaw(s.field.Name, s.raw)
+
+			v, err := s.field.DriverValue(record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if str := string(raw); str != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestPolyRelationFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo2, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collectionIds := []string{demo1.Id, demo2.Id}
+
+	scenarios := []struct {
+		name        string
+		field       *core.PolyRelationField
+		record      func() *core.Record
+		expectError bool
+	}{
+		{
+			"[single] zero field value (not required)",
+			&core.PolyRelationField{Name: "test", CollectionIds: collectionIds},
+			func() *core.Record {
+				return core.NewRecord(core.NewBaseCollection("test_collection"))
+			},
+			false,
+		},
+		{
+			"[single] zero field value (required)",
+			&core.PolyRelationField{Name: "test", CollectionIds: collectionIds, Required: true},
+			func() *core.Record {
+				return core.NewRecord(core.NewBaseCollection("test_collection"))
+			},
+			true,
+		},
+		{
+			"[single] not allowed collection",
+			&core.PolyRelationField{Name: "test", CollectionIds: []string{demo1.Id}},
+			func() *core.Record {
+				record := core.NewRecord(core.NewBaseCollection("test_collection"))
+				record.SetRaw("test", demo2.Id+":llvuca81nly1qls")
+				return record
+			},
+			true,
+		},
+		{
+			"[single] missing record",
+			&core.PolyRelationField{Name: "test", CollectionIds: collectionIds},
+			func() *core.Record {
+				record := core.NewRecord(core.NewBaseCollection("test_collection"))
+				record.SetRaw("test", demo2.Id+":84nmscqy84lsi1t")
+				return record
+			},
+			true,
+		},
+		{
+			"[single] existing record",
+			&core.PolyRelationField{Name: "test", CollectionIds: collectionIds},
+			func() *core.Record {
+				record := core.NewRecord(core.NewBaseCollection("test_collection"))
+				record.SetRaw("test", demo2.Id+":llvuca81nly1qls")
+				return record
+			},
+			false,
+		},
+		{
+			"[multiple] > MaxSelect",
+			&core.PolyRelationField{Name: "test", CollectionIds: collectionIds, MaxSelect: 2},
+			func() *core.Record {
+				record := core.NewRecord(core.NewBaseCollection("test_collection"))
+				record.SetRaw("test", []string{demo2.Id + ":llvuca81nly1qls", demo2.Id + ":achvryl401bhse3", demo1.Id + ":84nmscqy84lsi1t"})
+				return record
+			},
+			true,
+		},
+		{
+			"[multiple] existing records from different collections",
+			&core.PolyRelationField{Name: "test", CollectionIds: collectionIds, MaxSelect: 3},
+			func() *core.Record {
+				record := core.NewRecord(core.NewBaseCollection("test_collection"))
+				record.SetRaw("test", []string{demo2.Id + ":llvuca81nly1qls", demo2.Id + ":achvryl401bhse3", demo1.Id + ":84nmscqy84lsi1t"})
+				return record
+			},
+			false,
+		},
+		{
+			"[multiple] one missing record",
+			&core.PolyRelationField{Name: "test", CollectionIds: collectionIds, MaxSelect: 3},
+			func() *core.Record {
+				record := core.NewRecord(core.NewBaseCollection("test_collection"))
+				record.SetRaw("test", []string{demo2.Id + ":llvuca81nly1qls", demo1.Id + ":achvryl401bhse3"})
+				return record
+			},
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.field.ValidateValue(context.Background(), app, s.record())
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestPolyRelationFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypePolyRelation)
+	testDefaultFieldNameValidation(t, core.FieldTypePolyRelation)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view1, err := app.FindCollectionByNameOrId("view1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.PolyRelationField
+		expectErrors []string
+	}{
+		{
+			"zero minimal",
+			func() *core.PolyRelationField {
+				return &core.PolyRelationField{Id: "test", Name: "test"}
+			},
+			[]string{"collectionIds"},
+		},
+		{
+			"missing collection",
+			func() *core.PolyRelationField {
+				return &core.PolyRelationField{Id: "test", Name: "test", CollectionIds: []string{demo1.Id, "missing"}}
+			},
+			[]string{"collectionIds"},
+		},
+		{
+			"collection name instead of id",
+			func() *core.PolyRelationField {
+				return &core.PolyRelationField{Id: "test", Name: "test", CollectionIds: []string{demo1.Name}}
+			},
+			[]string{"collectionIds"},
+		},
+		{
+			"base->view",
+			func() *core.PolyRelationField {
+				return &core.PolyRelationField{Id: "test", Name: "test", CollectionIds: []string{view1.Id}}
+			},
+			[]string{"collectionIds"},
+		},
+		{
+			"duplicated collections",
+			func() *core.PolyRelationField {
+				return &core.PolyRelationField{Id: "test", Name: "test", CollectionIds: []string{demo1.Id, demo1.Id}}
+			},
+			[]string{"collectionIds"},
+		},
+		{
+			"negative MaxSelect",
+			func() *core.PolyRelationField {
+				return &core.PolyRelationField{Id: "test", Name: "test", CollectionIds: []string{demo1.Id}, MaxSelect: -1}
+			},
+			[]string{"maxSelect"},
+		},
+		{
+			"valid",
+			func() *core.PolyRelationField {
+				return &core.PolyRelationField{Id: "test", Name: "test", CollectionIds: []string{demo1.Id, "_pb_users_auth_"}, MaxSelect: 2}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection := core.NewBaseCollection("test_collection")
+
+			field := s.field()
+
+			collection.Fields.Add(field)
+
+			errs := field.ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestPolyRelationFieldFindSetter(t *testing.T) {
+	scenarios := []struct {
+		name      string
+		key       string
+		value     any
+		field     *core.PolyRelationField
+		hasSetter bool
+		expected  string
+	}{
+		{
+			"no match",
+			"example",
+			"b:2",
+			&core.PolyRelationField{Name: "test", MaxSelect: 3},
+			false,
+			"",
+		},
+		{
+			"exact match",
+			"test",
+			"b:2",
+			&core.PolyRelationField{Name: "test", MaxSelect: 3},
+			true,
+			`[{"collectionId":"b","recordId":"2"}]`,
+		},
+		{
+			"append",
+			"test+",
+			"b:2",
+			&core.PolyRelationField{Name: "test", MaxSelect: 3},
+			true,
+			`[{"collectionId":"a","recordId":"1"},{"collectionId":"a","recordId":"2"},{"collectionId":"b","recordId":"2"}]`,
+		},
+		{
+			"prepend",
+			"+test",
+			"b:2",
+			&core.PolyRelationField{Name: "test", MaxSelect: 3},
+			true,
+			`[{"collectionId":"b","recordId":"2"},{"collectionId":"a","recordId":"1"},{"collectionId":"a","recordId":"2"}]`,
+		},
+		{
+			"subtract",
+			"test-",
+			core.PolyRef{CollectionId: "a", RecordId: "1"},
+			&core.PolyRelationField{Name: "test", MaxSelect: 3},
+			true,
+			`[{"collectionId":"a","recordId":"2"}]`,
+		},
+		{
+			"subtract (single)",
+			"test-",
+			"a:2",
+			&core.PolyRelationField{Name: "test"},
+			true,
+			`{"collectionId":"a","recordId":"1"}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection := core.NewBaseCollection("test_collection")
+			collection.Fields.Add(s.field)
+
+			setter := s.field.FindSetter(s.key)
+
+			hasSetter := setter != nil
+			if hasSetter != s.hasSetter {
+				t.Fatalf("Expected hasSetter %v, got %v", s.hasSetter, hasSetter)
+			}
+
+			if !hasSetter {
+				return
+			}
+
+			record := core.NewRecord(collection)
+			record.SetRaw(s.field.GetName(), []string{"a:1", "a:2"})
+
+			setter(record, s.value)
+
+			raw, err := json.Marshal(record.Get(s.field.GetName()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if str := string(raw); str != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, str)
+			}
+		})
+	}
+}
+
+func preparePolyRelationCollection(t testing.TB, app core.App) *core.Collection {
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo2, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comments := core.NewBaseCollection("poly_comments")
+	comments.Fields.Add(
+		&core.PolyRelationField{Name: "target", CollectionIds: []string{demo1.Id, demo2.Id}},
+		&core.PolyRelationField{Name: "targets", CollectionIds: []string{demo1.Id, demo2.Id}, MaxSelect: 5},
+	)
+	if err := app.Save(comments); err != nil {
+		t.Fatal(err)
+	}
+
+	return comments
+}
+
+func TestPolyRelationFieldRecordSave(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	comments := preparePolyRelationCollection(t, app)
+
+	record := core.NewRecord(comments)
+	record.Set("target", "sz5l5z67tg7gku0:llvuca81nly1qls")
+	record.Set("targets", []string{"sz5l5z67tg7gku0:achvryl401bhse3", "wsmn24bux7wo113:84nmscqy84lsi1t"})
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := app.FindRecordById(comments, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedParts := []string{
+		`"target":{"collectionId":"sz5l5z67tg7gku0","recordId":"llvuca81nly1qls"}`,
+		`"targets":[{"collectionId":"sz5l5z67tg7gku0","recordId":"achvryl401bhse3"},{"collectionId":"wsmn24bux7wo113","recordId":"84nmscqy84lsi1t"}]`,
+	}
+	for _, part := range expectedParts {
+		if !strings.Contains(string(raw), part) {
+			t.Fatalf("Expected %s in\n%s", part, raw)
+		}
+	}
+
+	// delete one of the referenced records
+	target, err := app.FindRecordById("demo2", "achvryl401bhse3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(target); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err = app.FindRecordById(comments, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs := core.ParsePolyRefs(stored.Get("targets"))
+	expectedRefs := []core.PolyRef{{CollectionId: "wsmn24bux7wo113", RecordId: "84nmscqy84lsi1t"}}
+	if !slices.Equal(refs, expectedRefs) {
+		t.Fatalf("Expected the deleted record reference to be removed, got %v", refs)
+	}
+}
+
+func TestPolyRelationFieldExpand(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	comments := preparePolyRelationCollection(t, app)
+
+	record := core.NewRecord(comments)
+	record.Set("target", "sz5l5z67tg7gku0:llvuca81nly1qls")
+	record.Set("targets", []string{"wsmn24bux7wo113:84nmscqy84lsi1t", "sz5l5z67tg7gku0:achvryl401bhse3"})
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := app.ExpandRecord(record, []string{"target", "targets"}, nil)
+	if len(failed) > 0 {
+		t.Fatalf("Expected no failed expands, got %v", failed)
+	}
+
+	target := record.ExpandedOne("target")
+	if target == nil || target.Id != "llvuca81nly1qls" || target.Collection().Name != "demo2" {
+		t.Fatalf("Expected the target to be expanded with demo2 llvuca81nly1qls, got %v", target)
+	}
+
+	targets := record.ExpandedAll("targets")
+	if len(targets) != 2 {
+		t.Fatalf("Expected 2 expanded targets, got %d", len(targets))
+	}
+	if targets[0].Id != "84nmscqy84lsi1t" || targets[0].Collection().Name != "demo1" {
+		t.Fatalf("Expected targets[0] to be demo1 84nmscqy84lsi1t, got %s %s", targets[0].Collection().Name, targets[0].Id)
+	}
+	if targets[1].Id != "achvryl401bhse3" || targets[1].Collection().Name != "demo2" {
+		t.Fatalf("Expected targets[1] to be demo2 achvryl401bhse3, got %s %s", targets[1].Collection().Name, targets[1].Id)
+	}
+}
+
+func TestPolyRelationFieldFilter(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	comments := preparePolyRelationCollection(t, app)
+
+	record1 := core.NewRecord(comments)
+	record1.Set("target", "sz5l5z67tg7gku0:llvuca81nly1qls")
+	record1.Set("targets", []string{"wsmn24bux7wo113:84nmscqy84lsi1t", "sz5l5z67tg7gku0:achvryl401bhse3"})
+	if err := app.Save(record1); err != nil {
+		t.Fatal(err)
+	}
+
+	record2 := core.NewRecord(comments)
+	record2.Set("target", "wsmn24bux7wo113:84nmscqy84lsi1t")
+	if err := app.Save(record2); err != nil {
+		t.Fatal(err)
+	}
+
+	record3 := core.NewRecord(comments)
+	if err := app.Save(record3); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		filter      string
+		expected    []string
+		expectError bool
+	}{
+		{"target.collectionName = 'demo2'", []string{record1.Id}, false},
+		{"target.collectionName != 'demo2'", []string{record2.Id, record3.Id}, false},
+		{"target.collectionId = 'wsmn24bux7wo113'", []string{record2.Id}, false},
+		{"target.recordId = 'llvuca81nly1qls'", []string{record1.Id}, false},
+		{"targets.collectionName ?= 'demo1'", []string{record1.Id}, false},
+		{"targets.collectionName = 'demo1'", []string{}, false},
+		{"targets.recordId ?= 'achvryl401bhse3'", []string{record1.Id}, false},
+		{"target.missing = 'demo2'", nil, true},
+		{"target.collectionName.name = 'demo2'", nil, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			records, err := app.FindRecordsByFilter(comments, s.filter, "", 0, 0)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			result := make([]string, 0, len(records))
+			for _, r := range records {
+				result = append(result, r.Id)
+			}
+
+			slices.Sort(result)
+			expected := slices.Clone(s.expected)
+			slices.Sort(expected)
+
+			if !slices.Equal(result, expected) {
+				t.Fatalf("Expected %v, got %v", expected, result)
+			}
+		})
+	}
+}
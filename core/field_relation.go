@@ -25,6 +25,20 @@ var (
 	_ SetterFinder = (*RelationField)(nil)
 )
 
+// Supported [RelationField.OnDelete] actions.
+const (
+	// RelationOnDeleteCascade deletes the referencing record
+	// (for multiple relations only when there are no other references left).
+	RelationOnDeleteCascade = "cascade"
+
+	// RelationOnDeleteRestrict prevents the deletion of a referenced record.
+	RelationOnDeleteRestrict = "restrict"
+
+	// RelationOnDeleteSetNull unsets the deleted record id from the referencing record field
+	// (fails for required relations when there are no other references left).
+	RelationOnDeleteSetNull = "setNull"
+)
+
 // RelationField defines "relation" type field for storing single or
 // multiple collection record references.
 //
@@ -77,8 +91,17 @@ type RelationField struct {
 
 	// CascadeDelete indicates whether the root model should be deleted
 	// in case of delete of all linked relations.
+	//
+	// It is used only when OnDelete is not set.
 	CascadeDelete bool `form:"cascadeDelete" json:"cascadeDelete"`
 
+	// OnDelete specifies the action to perform when a linked relation record is deleted
+	// (see [RelationOnDeleteCascade], [RelationOnDeleteRestrict] and [RelationOnDeleteSetNull]).
+	//
+	// If empty, fallbacks to [RelationOnDeleteCascade] or [RelationOnDeleteSetNull]
+	// based on the CascadeDelete option.
+	OnDelete string `form:"onDelete" json:"onDelete"`
+
 	// MinSelect indicates the min number of allowed relation records
 	// that could be linked to the main model.
 	//
@@ -95,6 +118,23 @@ type RelationField struct {
 
 	// Required will require the field value to be non-empty.
 	Required bool `form:"required" json:"required"`
+
+	// Junction enables the many-to-many junction mode for a multiple relation field.
+	//
+	// When enabled, an auto-managed "{collection}_{field}" junction collection
+	// is created with a single link record per related record
+	// (see [JunctionFieldSource], [JunctionFieldTarget] and [JunctionFieldPosition]).
+	// Additional fields could be added to the junction collection to store per-link metadata.
+	// When enabled for an existing field, the link records of the already
+	// existing records are created from their current field value.
+	//
+	// The field value remains the source of truth for the linked records and their order
+	// and the junction link records are kept in sync on both sides.
+	//
+	// The field expand returns only the related records. The ordered link records
+	// (aka. the per-link metadata) could be expanded explicitly with the
+	// "{junction}_via_source" back-relation, eg. "posts_tags_via_source.target".
+	Junction bool `form:"junction" json:"junction"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -148,6 +188,20 @@ func (f *RelationField) IsMultiple() bool {
 	return f.MaxSelect > 1
 }
 
+// OnDeleteAction returns the effective on delete action of the field
+// (aka. OnDelete with fallback to the CascadeDelete option).
+func (f *RelationField) OnDeleteAction() string {
+	if f.OnDelete != "" {
+		return f.OnDelete
+	}
+
+	if f.CascadeDelete {
+		return RelationOnDeleteCascade
+	}
+
+	return RelationOnDeleteSetNull
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *RelationField) ColumnType(app App) string {
 	if f.IsMultiple() {
@@ -240,6 +294,8 @@ func (f *RelationField) ValidateSettings(ctx context.Context, app App, collectio
 		validation.Field(&f.CollectionId, validation.Required, validation.By(f.checkCollectionId(app, collection))),
 		validation.Field(&f.MinSelect, validation.Min(0)),
 		validation.Field(&f.MaxSelect, validation.When(f.MinSelect > 0, validation.Required), validation.Min(f.MinSelect)),
+		validation.Field(&f.OnDelete, validation.In(RelationOnDeleteCascade, RelationOnDeleteRestrict, RelationOnDeleteSetNull)),
+		validation.Field(&f.Junction, validation.By(f.checkJunction(collection))),
 	)
 }
 
@@ -0,0 +1,509 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// Relation junction collection system field names.
+const (
+	JunctionFieldSource   = "source"
+	JunctionFieldTarget   = "target"
+	JunctionFieldPosition = "position"
+)
+
+const junctionCollectionIdPrefix = "pbj_"
+
+// JunctionCollectionId returns the id of the auto-managed junction
+// collection of the current relation field (see [RelationField.Junction]).
+//
+// Returns empty string if the field is not a junction relation.
+func (f *RelationField) JunctionCollectionId(collection *Collection) string {
+	if !f.Junction || collection == nil {
+		return ""
+	}
+
+	return junctionCollectionIdPrefix + crc32Checksum(collection.Id+"_"+f.Id)
+}
+
+func (f *RelationField) checkJunction(collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(bool)
+		if !v {
+			return nil // nothing to check
+		}
+
+		if collection.IsView() {
+			return validation.NewError(
+				"validation_relation_junction_view_collection",
+				"View collections cannot have junction relations.",
+			)
+		}
+
+		if !f.IsMultiple() {
+			return validation.NewError(
+				"validation_relation_junction_single",
+				"Junction relations must allow multiple values (maxSelect > 1).",
+			)
+		}
+
+		if strings.HasPrefix(collection.Id, junctionCollectionIdPrefix) {
+			return validation.NewError(
+				"validation_relation_junction_nested",
+				"Junction collections cannot have junction relations.",
+			)
+		}
+
+		return nil
+	}
+}
+
+// collectionJunctionFields returns all junction relation fields of the provided collection.
+func collectionJunctionFields(collection *Collection) []*RelationField {
+	var result []*RelationField
+
+	for _, field := range collection.Fields {
+		if f, ok := field.(*RelationField); ok && f.Junction {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
+
+// findJunctionOwner returns the owner collection and relation field of the
+// provided junction collection (or nil if the collection is not a junction).
+func findJunctionOwner(app App, junction *Collection) (*Collection, *RelationField) {
+	if !strings.HasPrefix(junction.Id, junctionCollectionIdPrefix) {
+		return nil, nil
+	}
+
+	source, _ := junction.Fields.GetByName(JunctionFieldSource).(*RelationField)
+	if source == nil {
+		return nil, nil
+	}
+
+	owner, _ := app.FindCachedCollectionByNameOrId(source.CollectionId)
+	if owner == nil {
+		return nil, nil
+	}
+
+	for _, f := range collectionJunctionFields(owner) {
+		if f.JunctionCollectionId(owner) == junction.Id {
+			return owner, f
+		}
+	}
+
+	return nil, nil
+}
+
+// syncJunctionCollections creates, updates or deletes the auto-managed
+// junction collections of the provided collection junction relation fields.
+//
+// NB! This method is expected to be called from inside of a transaction.
+func syncJunctionCollections(app App, newCollection *Collection, oldCollection *Collection) error {
+	activeIds := map[string]struct{}{}
+
+	for _, f := range collectionJunctionFields(newCollection) {
+		junctionId := f.JunctionCollectionId(newCollection)
+		activeIds[junctionId] = struct{}{}
+
+		junction, _ := app.FindCollectionByNameOrId(junctionId)
+		if junction != nil {
+			continue // already created
+		}
+
+		name, err := uniqueCollectionName(app, newCollection.Name+"_"+f.Name)
+		if err != nil {
+			return err
+		}
+
+		junction = NewBaseCollection(name, junctionId)
+		junction.Fields.Add(
+			&RelationField{
+				Name:          JunctionFieldSource,
+				System:        true,
+				Required:      true,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				CollectionId:  newCollection.Id,
+			},
+			&RelationField{
+				Name:          JunctionFieldTarget,
+				System:        true,
+				Required:      true,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				CollectionId:  f.CollectionId,
+			},
+			&NumberField{
+				Name:    JunctionFieldPosition,
+				System:  true,
+				OnlyInt: true,
+			},
+		)
+		junction.AddIndex("idx_"+junctionId+"_link", true, "`"+JunctionFieldSource+"`, `"+JunctionFieldTarget+"`", "")
+
+		// note: save without validations because the owner collection
+		// could be still not available in the collections cache
+		if err := app.SaveNoValidate(junction); err != nil {
+			return fmt.Errorf("failed to create the %q junction collection: %w", f.Name, err)
+		}
+
+		if err := backfillJunctionRecords(app, newCollection, f, junction); err != nil {
+			return fmt.Errorf("failed to create the %q junction links of the existing records: %w", f.Name, err)
+		}
+	}
+
+	// delete the junction collections of the removed or disabled junction relations
+	if oldCollection != nil {
+		for _, f := range collectionJunctionFields(oldCollection) {
+			junctionId := f.JunctionCollectionId(oldCollection)
+			if _, ok := activeIds[junctionId]; ok {
+				continue
+			}
+
+			junction, _ := app.FindCollectionByNameOrId(junctionId)
+			if junction == nil {
+				continue // already deleted
+			}
+
+			if err := app.Delete(junction); err != nil {
+				return fmt.Errorf("failed to delete the %q junction collection: %w", f.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// deleteJunctionCollections deletes the auto-managed junction collections of the provided collection.
+//
+// NB! This method is expected to be called from inside of a transaction
+// and after the owner collection deletion.
+func deleteJunctionCollections(app App, collection *Collection) error {
+	for _, f := range collectionJunctionFields(collection) {
+		junction, _ := app.FindCollectionByNameOrId(f.JunctionCollectionId(collection))
+		if junction == nil {
+			continue
+		}
+
+		if err := app.Delete(junction); err != nil {
+			return fmt.Errorf("failed to delete the %q junction collection: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// backfillJunctionRecords creates the junction link records of the
+// already existing owner records from their relation field column value.
+//
+// Links to no longer existing targets are skipped.
+//
+// NB! This method is expected to be called from inside of a transaction.
+func backfillJunctionRecords(app App, owner *Collection, field *RelationField, junction *Collection) error {
+	target, err := app.FindCollectionByNameOrId(field.CollectionId)
+	if err != nil {
+		return err
+	}
+
+	_, err = app.DB().NewQuery(fmt.Sprintf(
+		"INSERT OR IGNORE INTO {{%s}} ([[%s]], [[%s]], [[%s]]) "+
+			"SELECT [[o.id]], [[je.value]], COALESCE([[je.key]], 0) "+
+			"FROM {{%s}} o, json_each(CASE WHEN json_valid([[o.%s]]) THEN [[o.%s]] ELSE json_array([[o.%s]]) END) je "+
+			"WHERE [[je.value]] != '' AND EXISTS (SELECT 1 FROM {{%s}} t WHERE [[t.id]] = [[je.value]])",
+		junction.Name,
+		JunctionFieldSource,
+		JunctionFieldTarget,
+		JunctionFieldPosition,
+		owner.Name,
+		field.Name,
+		field.Name,
+		field.Name,
+		target.Name,
+	)).Execute()
+
+	return err
+}
+
+func uniqueCollectionName(app App, name string) (string, error) {
+	result := name
+
+	for i := 2; i < 1000; i++ {
+		existing, _ := app.FindCollectionByNameOrId(result)
+		if existing == nil {
+			return result, nil
+		}
+		result = name + strconv.Itoa(i)
+	}
+
+	return "", fmt.Errorf("failed to generate unique collection name for %q", name)
+}
+
+// syncJunctionRecords creates, updates or deletes the junction link records
+// of the provided record so that they match with the record relation field value.
+//
+// NB! This method is expected to be called from inside of a transaction.
+func syncJunctionRecords(app App, record *Record, field *RelationField) error {
+	junction, err := app.FindCollectionByNameOrId(field.JunctionCollectionId(record.Collection()))
+	if err != nil {
+		return fmt.Errorf("failed to load the %q junction collection: %w", field.Name, err)
+	}
+
+	ids := record.GetStringSlice(field.Name)
+
+	links, err := app.FindAllRecords(junction, dbx.HashExp{JunctionFieldSource: record.Id})
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*Record, len(links))
+	for _, link := range links {
+		target := link.GetString(JunctionFieldTarget)
+		if _, ok := existing[target]; ok || !slices.Contains(ids, target) {
+			if err := app.Delete(link); err != nil {
+				return fmt.Errorf("failed to delete %q junction link %q: %w", field.Name, link.Id, err)
+			}
+			continue
+		}
+		existing[target] = link
+	}
+
+	for i, id := range ids {
+		link := existing[id]
+		if link == nil {
+			link = NewRecord(junction)
+			link.Set(JunctionFieldSource, record.Id)
+			link.Set(JunctionFieldTarget, id)
+		} else if link.GetInt(JunctionFieldPosition) == i {
+			continue // no changes
+		}
+
+		link.Set(JunctionFieldPosition, i)
+
+		if err := app.Save(link); err != nil {
+			return fmt.Errorf("failed to save %q junction link to %q: %w", field.Name, id, err)
+		}
+	}
+
+	return nil
+}
+
+// findJunctionLinkOwner returns the owner record of the provided junction link record (if exists).
+func findJunctionLinkOwner(app App, link *Record, ownerCollection *Collection) *Record {
+	owner, _ := app.FindRecordById(ownerCollection, link.GetString(JunctionFieldSource))
+
+	return owner
+}
+
+func (app *BaseApp) registerRelationJunctionHooks() {
+	// owner record create/update -> sync the junction links
+	ownerSaveHandler := func(e *RecordEvent) error {
+		fields := collectionJunctionFields(e.Record.Collection())
+		if len(fields) == 0 {
+			return e.Next()
+		}
+
+		var changed []*RelationField
+		if e.Record.IsNew() {
+			for _, f := range fields {
+				if len(e.Record.GetStringSlice(f.Name)) > 0 {
+					changed = append(changed, f)
+				}
+			}
+		} else {
+			original := e.Record.Original()
+			for _, f := range fields {
+				if !slices.Equal(original.GetStringSlice(f.Name), e.Record.GetStringSlice(f.Name)) {
+					changed = append(changed, f)
+				}
+			}
+		}
+
+		if len(changed) == 0 {
+			return e.Next()
+		}
+
+		originalApp := e.App
+		txErr := e.App.RunInTransaction(func(txApp App) error {
+			e.App = txApp
+
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			for _, f := range changed {
+				if err := syncJunctionRecords(txApp, e.Record, f); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		e.App = originalApp
+
+		return txErr
+	}
+
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     ownerSaveHandler,
+		Priority: 99,
+	})
+
+	app.OnRecordUpdateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     ownerSaveHandler,
+		Priority: 99,
+	})
+
+	// junction link create -> set its position and add the target to the owner relation field (if missing)
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			ownerCollection, field := findJunctionOwner(e.App, e.Record.Collection())
+			if field == nil {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				owner := findJunctionLinkOwner(txApp, e.Record, ownerCollection)
+				if owner == nil {
+					return errors.New("missing junction link source record")
+				}
+
+				ids := owner.GetStringSlice(field.Name)
+				target := e.Record.GetString(JunctionFieldTarget)
+
+				position := slices.Index(ids, target)
+				if position < 0 {
+					position = len(ids)
+				}
+				e.Record.Set(JunctionFieldPosition, position)
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				if slices.Contains(ids, target) {
+					return nil // already linked
+				}
+
+				owner.Set(field.Name+"+", target)
+
+				return txApp.Save(owner)
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	// junction link update -> prevent source/target change and normalize its position
+	app.OnRecordUpdateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			ownerCollection, field := findJunctionOwner(e.App, e.Record.Collection())
+			if field == nil {
+				return e.Next()
+			}
+
+			original := e.Record.Original()
+			if original.GetString(JunctionFieldSource) != e.Record.GetString(JunctionFieldSource) ||
+				original.GetString(JunctionFieldTarget) != e.Record.GetString(JunctionFieldTarget) {
+				return validation.Errors{
+					JunctionFieldTarget: validation.NewError(
+						"validation_junction_link_change",
+						"The junction link source and target cannot be changed.",
+					),
+				}
+			}
+
+			owner := findJunctionLinkOwner(e.App, e.Record, ownerCollection)
+			if owner != nil {
+				position := slices.Index(owner.GetStringSlice(field.Name), e.Record.GetString(JunctionFieldTarget))
+				if position >= 0 {
+					e.Record.Set(JunctionFieldPosition, position)
+				}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	// junction link delete -> remove the target from the owner relation field (if still linked)
+	app.OnRecordDeleteExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			ownerCollection, field := findJunctionOwner(e.App, e.Record.Collection())
+			if field == nil {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				// the owner could be missing in case of a cascade delete
+				owner := findJunctionLinkOwner(txApp, e.Record, ownerCollection)
+				if owner == nil {
+					return nil
+				}
+
+				target := e.Record.GetString(JunctionFieldTarget)
+				if !list.ExistInSlice(target, owner.GetStringSlice(field.Name)) {
+					return nil // already unlinked
+				}
+
+				owner.Set(field.Name+"-", target)
+
+				// similar to the cascade delete, save without validations
+				return txApp.SaveNoValidate(owner)
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	// prevent manual deletion of the junction collections
+	app.OnCollectionDeleteExecute().Bind(&hook.Handler[*CollectionEvent]{
+		Func: func(e *CollectionEvent) error {
+			if !strings.HasPrefix(e.Collection.Id, junctionCollectionIdPrefix) {
+				return e.Next()
+			}
+
+			source, _ := e.Collection.Fields.GetByName(JunctionFieldSource).(*RelationField)
+			if source != nil {
+				// note: non-cached lookup to ensure that the owner wasn't deleted in the current transaction
+				owner, _ := e.App.FindCollectionByNameOrId(source.CollectionId)
+				if owner != nil {
+					for _, f := range collectionJunctionFields(owner) {
+						if f.JunctionCollectionId(owner) == e.Collection.Id {
+							return fmt.Errorf(
+								"[%s] junction collections cannot be deleted manually, disable the %s.%s junction option instead",
+								e.Collection.Name, owner.Name, f.Name,
+							)
+						}
+					}
+				}
+			}
+
+			return e.Next()
+		},
+	})
+}
@@ -0,0 +1,518 @@
+package core_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func prepareJunctionCollections(t testing.TB, app core.App) (*core.Collection, *core.Collection, *core.Collection) {
+	tags := core.NewBaseCollection("jtags")
+	tags.Fields.Add(&core.TextField{Name: "name"})
+	if err := app.Save(tags); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := core.NewBaseCollection("jposts")
+	posts.Fields.Add(&core.RelationField{
+		Name:         "tags",
+		CollectionId: tags.Id,
+		MaxSelect:    99,
+		Junction:     true,
+	})
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	junction, err := app.FindCollectionByNameOrId(posts.Fields.GetByName("tags").(*core.RelationField).JunctionCollectionId(posts))
+	if err != nil {
+		t.Fatalf("Expected the junction collection to be created, got %v", err)
+	}
+
+	junction.Fields.Add(&core.TextField{Name: "role"})
+	if err := app.Save(junction); err != nil {
+		t.Fatal(err)
+	}
+
+	return tags, posts, junction
+}
+
+func createJunctionTags(t testing.TB, app core.App, tags *core.Collection, names ...string) []string {
+	ids := make([]string, 0, len(names))
+
+	for _, name := range names {
+		tag := core.NewRecord(tags)
+		tag.Set("name", name)
+		if err := app.Save(tag); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, tag.Id)
+	}
+
+	return ids
+}
+
+func junctionLinkTargets(t testing.TB, app core.App, junction *core.Collection, sourceId string) []string {
+	links, err := app.FindRecordsByFilter(junction, "source = {:id}", "position", 0, 0, dbx.Params{"id": sourceId})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := make([]string, 0, len(links))
+	for i, link := range links {
+		if link.GetInt(core.JunctionFieldPosition) != i {
+			t.Fatalf("Expected link %q position %d, got %d", link.Id, i, link.GetInt(core.JunctionFieldPosition))
+		}
+		result = append(result, link.GetString(core.JunctionFieldTarget))
+	}
+
+	return result
+}
+
+func TestRelationFieldJunctionValidateSettings(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name        string
+		field       *core.RelationField
+		expectError bool
+	}{
+		{
+			"single relation",
+			&core.RelationField{Name: "test", CollectionId: "_pb_users_auth_", MaxSelect: 1, Junction: true},
+			true,
+		},
+		{
+			"multiple relation",
+			&core.RelationField{Name: "test", CollectionId: "_pb_users_auth_", MaxSelect: 2, Junction: true},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection := core.NewBaseCollection("test_junction_settings")
+			collection.Fields.Add(s.field)
+
+			err := s.field.ValidateSettings(t.Context(), app, collection)
+
+			hasErr := err != nil && strings.Contains(err.Error(), "junction")
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestRelationFieldJunctionCollection(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	_, posts, junction := prepareJunctionCollections(t, app)
+
+	if junction.Name != "jposts_tags" {
+		t.Fatalf("Expected junction name %q, got %q", "jposts_tags", junction.Name)
+	}
+
+	for _, name := range []string{core.JunctionFieldSource, core.JunctionFieldTarget, core.JunctionFieldPosition} {
+		if f := junction.Fields.GetByName(name); f == nil || !f.GetSystem() {
+			t.Fatalf("Expected system field %q, got %v", name, f)
+		}
+	}
+
+	// manual delete should fail
+	if err := app.Delete(junction); err == nil {
+		t.Fatal("Expected the manual junction delete to fail")
+	}
+
+	// disabling the junction option should delete the junction collection
+	posts.Fields.GetByName("tags").(*core.RelationField).Junction = false
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.FindCollectionByNameOrId(junction.Id); err == nil {
+		t.Fatal("Expected the junction collection to be deleted")
+	}
+
+	// reenable and delete the owner collection
+	posts.Fields.GetByName("tags").(*core.RelationField).Junction = true
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.FindCollectionByNameOrId(junction.Id); err != nil {
+		t.Fatalf("Expected the junction collection to be recreated, got %v", err)
+	}
+	if err := app.Delete(posts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.FindCollectionByNameOrId(junction.Id); err == nil {
+		t.Fatal("Expected the junction collection to be deleted together with its owner")
+	}
+}
+
+func TestRelationFieldJunctionOwnerSync(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tags, posts, junction := prepareJunctionCollections(t, app)
+
+	ids := createJunctionTags(t, app, tags, "a", "b", "c")
+
+	post := core.NewRecord(posts)
+	post.Set("tags", []string{ids[0], ids[1]})
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	if targets := junctionLinkTargets(t, app, junction, post.Id); !slices.Equal(targets, []string{ids[0], ids[1]}) {
+		t.Fatalf("Expected links %v after create, got %v", ids[:2], targets)
+	}
+
+	// store link metadata that should be preserved on reorder
+	link, err := app.FindFirstRecordByFilter(junction, "source = {:source} && target = {:target}", dbx.Params{"source": post.Id, "target": ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	link.Set("role", "admin")
+	if err := app.Save(link); err != nil {
+		t.Fatal(err)
+	}
+
+	// reorder, add and remove
+	post, err = app.FindRecordById(posts, post.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post.Set("tags", []string{ids[2], ids[1]})
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	if targets := junctionLinkTargets(t, app, junction, post.Id); !slices.Equal(targets, []string{ids[2], ids[1]}) {
+		t.Fatalf("Expected links %v after update, got %v", []string{ids[2], ids[1]}, targets)
+	}
+
+	link, err = app.FindRecordById(junction, link.Id)
+	if err != nil {
+		t.Fatalf("Expected the existing link to be preserved, got %v", err)
+	}
+	if v := link.GetString("role"); v != "admin" {
+		t.Fatalf("Expected the link role to be preserved, got %q", v)
+	}
+
+	// delete a target record
+	tag, err := app.FindRecordById(tags, ids[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(tag); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err = app.FindRecordById(posts, post.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := post.GetStringSlice("tags"); !slices.Equal(v, []string{ids[1]}) {
+		t.Fatalf("Expected tags %v after target delete, got %v", ids[1:2], v)
+	}
+	if targets := junctionLinkTargets(t, app, junction, post.Id); !slices.Equal(targets, []string{ids[1]}) {
+		t.Fatalf("Expected links %v after target delete, got %v", ids[1:2], targets)
+	}
+
+	// delete the owner record
+	if err := app.Delete(post); err != nil {
+		t.Fatal(err)
+	}
+	if total, _ := app.CountRecords(junction); total != 0 {
+		t.Fatalf("Expected all links to be deleted, found %d", total)
+	}
+}
+
+func TestRelationFieldJunctionLinkSync(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tags, posts, junction := prepareJunctionCollections(t, app)
+
+	ids := createJunctionTags(t, app, tags, "a", "b")
+
+	post := core.NewRecord(posts)
+	post.Set("tags", []string{ids[0]})
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	// create a link directly
+	link := core.NewRecord(junction)
+	link.Set(core.JunctionFieldSource, post.Id)
+	link.Set(core.JunctionFieldTarget, ids[1])
+	link.Set(core.JunctionFieldPosition, 100)
+	link.Set("role", "editor")
+	if err := app.Save(link); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := link.GetInt(core.JunctionFieldPosition); v != 1 {
+		t.Fatalf("Expected the link position to be normalized to 1, got %d", v)
+	}
+
+	post, err := app.FindRecordById(posts, post.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := post.GetStringSlice("tags"); !slices.Equal(v, ids) {
+		t.Fatalf("Expected tags %v after link create, got %v", ids, v)
+	}
+
+	// changing the link target is not allowed
+	link.Set(core.JunctionFieldTarget, ids[0])
+	if err := app.Save(link); err == nil {
+		t.Fatal("Expected the link target change to fail")
+	}
+	link.Set(core.JunctionFieldTarget, ids[1])
+
+	// delete the link directly
+	if err := app.Delete(link); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err = app.FindRecordById(posts, post.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := post.GetStringSlice("tags"); !slices.Equal(v, ids[:1]) {
+		t.Fatalf("Expected tags %v after link delete, got %v", ids[:1], v)
+	}
+}
+
+func TestRelationFieldJunctionExpand(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tags, posts, junction := prepareJunctionCollections(t, app)
+
+	ids := createJunctionTags(t, app, tags, "a", "b", "c")
+
+	post := core.NewRecord(posts)
+	post.Set("tags", []string{ids[2], ids[0], ids[1]})
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := app.ExpandRecord(post, []string{"tags"}, nil)
+	if len(failed) > 0 {
+		t.Fatalf("Expected no failed expands, got %v", failed)
+	}
+
+	expanded := post.ExpandedAll("tags")
+	if len(expanded) != 3 {
+		t.Fatalf("Expected 3 expanded tags, got %d", len(expanded))
+	}
+
+	if expand := post.Expand(); len(expand) != 1 {
+		t.Fatalf("Expected only the tags expand, got %v", expand)
+	}
+
+	// explicit junction links expand
+	linksExpand := junction.Name + "_via_source"
+
+	failed = app.ExpandRecord(post, []string{linksExpand + ".target"}, nil)
+	if len(failed) > 0 {
+		t.Fatalf("Expected no failed links expands, got %v", failed)
+	}
+
+	links := post.ExpandedAll(linksExpand)
+	if len(links) != 3 {
+		t.Fatalf("Expected 3 expanded links, got %d", len(links))
+	}
+
+	for i, link := range links {
+		if link.GetString(core.JunctionFieldTarget) != expanded[i].Id {
+			t.Fatalf("Expected link %d target %q, got %q", i, expanded[i].Id, link.GetString(core.JunctionFieldTarget))
+		}
+
+		target := link.ExpandedOne(core.JunctionFieldTarget)
+		if target == nil || target.Id != expanded[i].Id {
+			t.Fatalf("Expected link %d expanded target %q, got %v", i, expanded[i].Id, target)
+		}
+	}
+}
+
+func TestRelationFieldJunctionFilter(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tags, posts, junction := prepareJunctionCollections(t, app)
+
+	ids := createJunctionTags(t, app, tags, "a", "b")
+
+	post1 := core.NewRecord(posts)
+	post1.Set("tags", ids)
+	if err := app.Save(post1); err != nil {
+		t.Fatal(err)
+	}
+
+	post2 := core.NewRecord(posts)
+	post2.Set("tags", ids[:1])
+	if err := app.Save(post2); err != nil {
+		t.Fatal(err)
+	}
+
+	links, err := app.FindAllRecords(junction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range links {
+		if link.GetString(core.JunctionFieldSource) == post1.Id {
+			link.Set("role", "admin")
+		} else {
+			link.Set("role", "viewer")
+		}
+		if err := app.Save(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		filter   string
+		expected []string
+	}{
+		{"tags.role = 'admin'", []string{post1.Id}},
+		{"tags.role ?= 'viewer'", []string{post2.Id}},
+		{"tags.role:lower ?= 'viewer'", []string{post2.Id}},
+		{"tags.position = 1", []string{}},
+		{"tags.position ?= 1", []string{post1.Id}},
+		{"tags.name ?= 'b'", []string{post1.Id}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			records, err := app.FindRecordsByFilter(posts, s.filter, "", 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := make([]string, 0, len(records))
+			for _, r := range records {
+				result = append(result, r.Id)
+			}
+
+			if !slices.Equal(result, s.expected) {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestRelationFieldJunctionBackfill(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tags := core.NewBaseCollection("jtags")
+	tags.Fields.Add(&core.TextField{Name: "name"})
+	if err := app.Save(tags); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := core.NewBaseCollection("jposts")
+	posts.Fields.Add(&core.RelationField{
+		Name:         "tags",
+		CollectionId: tags.Id,
+		MaxSelect:    99,
+	})
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := createJunctionTags(t, app, tags, "a", "b", "c")
+
+	post1 := core.NewRecord(posts)
+	post1.Set("tags", []string{ids[2], ids[0]})
+	if err := app.Save(post1); err != nil {
+		t.Fatal(err)
+	}
+
+	post2 := core.NewRecord(posts)
+	post2.Set("tags", ids[1:2])
+	if err := app.Save(post2); err != nil {
+		t.Fatal(err)
+	}
+
+	post3 := core.NewRecord(posts)
+	if err := app.Save(post3); err != nil {
+		t.Fatal(err)
+	}
+
+	// enable the junction on the already populated collection
+	field := posts.Fields.GetByName("tags").(*core.RelationField)
+	field.Junction = true
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	junction, err := app.FindCollectionByNameOrId(field.JunctionCollectionId(posts))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets := map[string][]string{
+		post1.Id: {ids[2], ids[0]},
+		post2.Id: {ids[1]},
+		post3.Id: {},
+	}
+	for sourceId, expected := range targets {
+		if result := junctionLinkTargets(t, app, junction, sourceId); !slices.Equal(result, expected) {
+			t.Fatalf("Expected %q link targets %v, got %v", sourceId, expected, result)
+		}
+	}
+
+	// filter by the link metadata
+	junction.Fields.Add(&core.TextField{Name: "role"})
+	if err := app.Save(junction); err != nil {
+		t.Fatal(err)
+	}
+
+	links, err := app.FindAllRecords(junction, dbx.HashExp{core.JunctionFieldSource: post2.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range links {
+		link.Set("role", "admin")
+		if err := app.Save(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		filter   string
+		expected []string
+	}{
+		{"tags.role ?= 'admin'", []string{post2.Id}},
+		{"tags.position ?= 1", []string{post1.Id}},
+		{"tags.name ?= 'c'", []string{post1.Id}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			records, err := app.FindRecordsByFilter(posts, s.filter, "", 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := make([]string, 0, len(records))
+			for _, r := range records {
+				result = append(result, r.Id)
+			}
+
+			if !slices.Equal(result, s.expected) {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
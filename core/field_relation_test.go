@@ -467,6 +467,30 @@ func TestRelationFieldValidateSettings(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"invalid OnDelete",
+			func(col *core.Collection) *core.RelationField {
+				return &core.RelationField{
+					Id:           "test",
+					Name:         "test",
+					CollectionId: demo1.Id,
+					OnDelete:     "invalid",
+				}
+			},
+			[]string{"onDelete"},
+		},
+		{
+			"valid OnDelete",
+			func(col *core.Collection) *core.RelationField {
+				return &core.RelationField{
+					Id:           "test",
+					Name:         "test",
+					CollectionId: demo1.Id,
+					OnDelete:     core.RelationOnDeleteRestrict,
+				}
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -601,3 +625,93 @@ func TestRelationFieldFindSetter(t *testing.T) {
 		})
 	}
 }
+
+func TestRelationFieldOnDeleteAction(t *testing.T) {
+	scenarios := []struct {
+		field    *core.RelationField
+		expected string
+	}{
+		{&core.RelationField{}, core.RelationOnDeleteSetNull},
+		{&core.RelationField{CascadeDelete: true}, core.RelationOnDeleteCascade},
+		{&core.RelationField{OnDelete: core.RelationOnDeleteRestrict}, core.RelationOnDeleteRestrict},
+		{&core.RelationField{CascadeDelete: true, OnDelete: core.RelationOnDeleteSetNull}, core.RelationOnDeleteSetNull},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%v_%s", i, s.field.CascadeDelete, s.field.OnDelete), func(t *testing.T) {
+			if v := s.field.OnDeleteAction(); v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestRelationFieldOnDeleteRecordDelete(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		onDelete      string
+		required      bool
+		expectError   bool
+		expectDeleted bool
+	}{
+		{"cascade", core.RelationOnDeleteCascade, false, false, true},
+		{"restrict", core.RelationOnDeleteRestrict, false, true, false},
+		{"setNull", core.RelationOnDeleteSetNull, false, false, false},
+		{"setNull required", core.RelationOnDeleteSetNull, true, true, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			target := core.NewBaseCollection("test_target")
+			if err := app.Save(target); err != nil {
+				t.Fatal(err)
+			}
+
+			owner := core.NewBaseCollection("test_owner")
+			owner.Fields.Add(&core.RelationField{
+				Name:         "rel",
+				CollectionId: target.Id,
+				OnDelete:     s.onDelete,
+				Required:     s.required,
+			})
+			if err := app.Save(owner); err != nil {
+				t.Fatal(err)
+			}
+
+			targetRecord := core.NewRecord(target)
+			if err := app.Save(targetRecord); err != nil {
+				t.Fatal(err)
+			}
+
+			ownerRecord := core.NewRecord(owner)
+			ownerRecord.Set("rel", targetRecord.Id)
+			if err := app.Save(ownerRecord); err != nil {
+				t.Fatal(err)
+			}
+
+			err := app.Delete(targetRecord)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			_, err = app.FindRecordById(target, targetRecord.Id)
+			if targetExists := err == nil; targetExists != s.expectError {
+				t.Fatalf("Expected the target record to exist %v, got %v", s.expectError, targetExists)
+			}
+
+			refreshed, err := app.FindRecordById(owner, ownerRecord.Id)
+			if deleted := err != nil; deleted != s.expectDeleted {
+				t.Fatalf("Expected the owner record to be deleted %v, got %v", s.expectDeleted, deleted)
+			}
+
+			if refreshed != nil && !s.expectError && refreshed.GetString("rel") != "" {
+				t.Fatalf("Expected the owner relation to be unset, got %q", refreshed.GetString("rel"))
+			}
+		})
+	}
+}
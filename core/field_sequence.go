@@ -0,0 +1,440 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeSequence] = func() Field {
+		return &SequenceField{}
+	}
+}
+
+const FieldTypeSequence = "sequence"
+
+const maxSequencePadding = 20
+
+// sequenceParamsKeyPrefix is the prefix of the _params keys
+// that store the last allocated sequence numbers.
+const sequenceParamsKeyPrefix = "sequence_"
+
+var (
+	_ Field             = (*SequenceField)(nil)
+	_ SetterFinder      = (*SequenceField)(nil)
+	_ RecordInterceptor = (*SequenceField)(nil)
+)
+
+// SequenceField defines "sequence" type field for storing human-readable
+// sequential record numbers (eg. "INV-000123").
+//
+// The next sequence value is allocated on record create from a monotonic
+// counter stored in the _params table (one per Prefix and ScopeField value).
+// The counter update and the record INSERT statement are always executed in
+// a single transaction (aka. a failed create doesn't leave a gap in the sequence)
+// and the numbers of the deleted records are never reused.
+// The field column is also guarded with a UNIQUE index (per scope).
+//
+// The counters are deleted together with the field or its collection.
+//
+// The field value cannot be changed with record.Set() and it is
+// immutable after the record creation.
+// If you want to assign an explicit value (eg. when importing existing
+// records) you can use record.SetRaw() before the record create.
+//
+// The respective zero record field value is empty string.
+type SequenceField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Prefix is an optional static string that is prepended to the sequence number (eg. "INV-").
+	//
+	// Changing the prefix starts a new sequence.
+	Prefix string `form:"prefix" json:"prefix"`
+
+	// Padding specifies the min number of digits of the sequence number
+	// (the number is left padded with zeros, eg. 6 -> "000123").
+	//
+	// If zero, no padding is applied.
+	Padding int `form:"padding" json:"padding"`
+
+	// ScopeField is an optional name of a single value collection field
+	// (eg. "organization" relation) whose value is used to maintain
+	// a separate counter per each distinct field value.
+	ScopeField string `form:"scopeField" json:"scopeField"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *SequenceField) Type() string {
+	return FieldTypeSequence
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *SequenceField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *SequenceField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *SequenceField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *SequenceField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *SequenceField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *SequenceField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *SequenceField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *SequenceField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *SequenceField) ColumnType(app App) string {
+	return "TEXT DEFAULT '' NOT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *SequenceField) PrepareValue(record *Record, raw any) (any, error) {
+	return cast.ToString(raw), nil
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *SequenceField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	val, ok := record.GetRaw(f.Name).(string)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	// note: the value can be changed only with record.SetRaw()
+	// (the original value is synced on create, see [SequenceField.Intercept])
+	if !record.IsNew() && val != record.Original().GetString(f.Name) {
+		return validation.NewError("validation_sequence_immutable", "The sequence value cannot be changed.")
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *SequenceField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Prefix, validation.Length(0, 100)),
+		validation.Field(&f.Padding, validation.Min(0), validation.Max(maxSequencePadding)),
+		validation.Field(&f.ScopeField, validation.By(f.checkScopeField(collection))),
+	)
+}
+
+func (f *SequenceField) checkScopeField(collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		scope := collection.Fields.GetByName(v)
+		if scope == nil || scope.GetName() == f.Name {
+			return validation.NewError("validation_invalid_scope_field", "Missing or invalid scope field.")
+		}
+
+		if mv, ok := scope.(MultiValuer); ok && mv.IsMultiple() {
+			return validation.NewError("validation_invalid_scope_field", "The scope field must be a single value field.")
+		}
+
+		if gc, ok := scope.(GeneratedColumn); ok && gc.IsGeneratedColumn() {
+			return validation.NewError("validation_invalid_scope_field", "The scope field cannot be a db generated field.")
+		}
+
+		return nil
+	}
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *SequenceField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		// return noopSetter to disallow updating the value with record.Set()
+		return noopSetter
+	default:
+		return nil
+	}
+}
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *SequenceField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	switch actionName {
+	case InterceptorActionCreateExecute:
+		val := record.GetString(f.Name)
+
+		if val == "" {
+			number, err := f.allocateNumber(ctx, app, record, 0)
+			if err != nil {
+				return fmt.Errorf("failed to allocate %q sequence number: %w", f.Name, err)
+			}
+
+			val = f.FormatValue(number)
+			record.SetRaw(f.Name, val)
+
+			if err := actionFunc(); err != nil {
+				record.SetRaw(f.Name, "") // reset so that a retry could allocate a new number
+				return err
+			}
+		} else {
+			if err := actionFunc(); err != nil {
+				return err
+			}
+
+			// ensure that the counter is not behind the explicitly set number
+			if number, ok := f.parseNumber(val); ok {
+				if _, err := f.allocateNumber(ctx, app, record, number); err != nil {
+					return fmt.Errorf("failed to update %q sequence counter: %w", f.Name, err)
+				}
+			}
+		}
+
+		// sync the original value so that the immutability check doesn't require a db query
+		record.originalData[f.Name] = val
+
+		return nil
+	default:
+		return actionFunc()
+	}
+}
+
+// FormatValue returns the formatted sequence value for the provided number
+// (eg. 123 -> "INV-000123").
+func (f *SequenceField) FormatValue(number int) string {
+	return f.Prefix + fmt.Sprintf(f.numberFormat(), number)
+}
+
+func (f *SequenceField) numberFormat() string {
+	if f.Padding <= 0 {
+		return "%d"
+	}
+
+	return "%0" + strconv.Itoa(f.Padding) + "d"
+}
+
+// parseNumber extracts the sequence number from a formatted sequence value.
+func (f *SequenceField) parseNumber(val string) (int, bool) {
+	rest, ok := strings.CutPrefix(val, f.Prefix)
+	if !ok {
+		return 0, false
+	}
+
+	number, err := strconv.Atoi(rest)
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+
+	return number, true
+}
+
+// counterKey returns the _params key of the record sequence counter.
+func (f *SequenceField) counterKey(record *Record) string {
+	parts := []string{f.Prefix}
+	if f.ScopeField != "" {
+		parts = append(parts, record.GetString(f.ScopeField))
+	}
+
+	return sequenceCountersKeyPrefix(record.Collection().Id, f.Id) + security.SHA256(strings.Join(parts, "\x00"))
+}
+
+// sequenceCountersKeyPrefix returns the common _params key prefix of
+// the collection sequence field counters (or of all collection
+// sequence counters if fieldId is empty).
+func sequenceCountersKeyPrefix(collectionId string, fieldId string) string {
+	if fieldId == "" {
+		return sequenceParamsKeyPrefix + collectionId + "_"
+	}
+
+	return sequenceParamsKeyPrefix + collectionId + "_" + fieldId + "_"
+}
+
+// deleteSequenceCounters deletes the stored _params counters of the
+// specified collection sequence field (or of all collection sequence
+// fields if fieldId is empty).
+func deleteSequenceCounters(app App, collectionId string, fieldId string) error {
+	prefix := sequenceCountersKeyPrefix(collectionId, fieldId)
+
+	_, err := app.NonconcurrentDB().Delete(
+		paramsTable,
+		dbx.NewExp(
+			fmt.Sprintf("substr([[id]], 1, %d) = {:prefix}", len(prefix)),
+			dbx.Params{"prefix": prefix},
+		),
+	).Execute()
+
+	return err
+}
+
+// syncSequenceCounters deletes the stored _params counters of the
+// sequence fields that were removed from the collection.
+func syncSequenceCounters(app App, newCollection *Collection, oldCollection *Collection) error {
+	if oldCollection == nil {
+		return nil
+	}
+
+	for _, field := range oldCollection.Fields {
+		if field.Type() != FieldTypeSequence {
+			continue
+		}
+
+		if newField := newCollection.Fields.GetById(field.GetId()); newField != nil && newField.Type() == FieldTypeSequence {
+			continue
+		}
+
+		if err := deleteSequenceCounters(app, newCollection.Id, field.GetId()); err != nil {
+			return fmt.Errorf("failed to delete the %q sequence counters: %w", field.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+func (app *BaseApp) registerSequenceHooks() {
+	// execute the counter allocation and the record INSERT in a single transaction
+	// (the counter is allocated in the create execute interceptor, see [SequenceField.Intercept])
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			if e.App.IsTransactional() || !hasSequenceFields(e.Record.Collection()) {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+				return e.Next()
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 98, // before the system record create execute hook
+	})
+}
+
+func hasSequenceFields(collection *Collection) bool {
+	for _, field := range collection.Fields {
+		if field.Type() == FieldTypeSequence {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allocateNumber increments and returns the record sequence counter.
+//
+// If min is positive, the counter is only moved forward to min (if behind)
+// and no new number is allocated.
+//
+// A missing counter is initialized from the max existing record number
+// (eg. for fields added to a collection with existing records).
+func (f *SequenceField) allocateNumber(ctx context.Context, app App, record *Record, min int) (int, error) {
+	key := f.counterKey(record)
+
+	increment := "1"
+	if min > 0 {
+		increment = "0"
+	}
+
+	var number int
+
+	// the counter already exists
+	err := app.NonconcurrentDB().NewQuery(fmt.Sprintf(
+		"UPDATE {{%s}} SET [[value]] = MAX(CAST([[value]] AS INTEGER) + %s, {:min}), [[updated]] = strftime('%%Y-%%m-%%d %%H:%%M:%%fZ') WHERE [[id]] = {:key} RETURNING CAST([[value]] AS INTEGER)",
+		paramsTable,
+		increment,
+	)).Bind(dbx.Params{"key": key, "min": min}).WithContext(ctx).Row(&number)
+	if err == nil {
+		return number, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	// initialize the counter
+	// (the conflict clause handles a concurrent initialization outside of a transaction)
+	prefixLen := utf8.RuneCountInString(f.Prefix)
+
+	params := dbx.Params{"key": key, "min": min, "prefix": f.Prefix}
+
+	where := fmt.Sprintf("substr([[%s]], 1, %d) = {:prefix}", f.Name, prefixLen)
+	if f.ScopeField != "" {
+		where += fmt.Sprintf(" AND [[%s]] = {:scope}", f.ScopeField)
+		params["scope"] = record.GetString(f.ScopeField)
+	}
+
+	err = app.NonconcurrentDB().NewQuery(fmt.Sprintf(
+		"INSERT INTO {{%s}} ([[id]], [[value]]) VALUES ({:key}, MAX((SELECT COALESCE(MAX(CAST(substr([[%s]], %d) AS INTEGER)), 0) + %s FROM {{%s}} WHERE %s), {:min})) ON CONFLICT ([[id]]) DO UPDATE SET [[value]] = MAX(CAST([[value]] AS INTEGER) + %s, excluded.[[value]]) RETURNING CAST([[value]] AS INTEGER)",
+		paramsTable,
+		f.Name,
+		prefixLen+1,
+		increment,
+		record.Collection().Name,
+		where,
+		increment,
+	)).Bind(params).WithContext(ctx).Row(&number)
+	if err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
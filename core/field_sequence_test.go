@@ -0,0 +1,457 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+func TestSequenceFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeSequence)
+}
+
+func TestSequenceFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.SequenceField{}
+
+	expected := "TEXT DEFAULT '' NOT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestSequenceFieldFormatValue(t *testing.T) {
+	scenarios := []struct {
+		field    *core.SequenceField
+		number   int
+		expected string
+	}{
+		{&core.SequenceField{}, 12, "12"},
+		{&core.SequenceField{Prefix: "INV-"}, 12, "INV-12"},
+		{&core.SequenceField{Padding: 4}, 12, "0012"},
+		{&core.SequenceField{Prefix: "INV-", Padding: 6}, 123, "INV-000123"},
+		{&core.SequenceField{Prefix: "INV-", Padding: 2}, 123, "INV-123"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expected, func(t *testing.T) {
+			if v := s.field.FormatValue(s.number); v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestSequenceFieldSetter(t *testing.T) {
+	f := &core.SequenceField{Name: "test"}
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Collection().Fields.Add(f)
+	record.Set("test", "abc")
+
+	if v := record.GetString("test"); v != "" {
+		t.Fatalf("Expected the value to remain unset, got %q", v)
+	}
+}
+
+func TestSequenceFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(&core.SequenceField{Name: "test"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := core.NewRecord(collection)
+	existing.SetRaw("test", "INV-1")
+	if err := app.Save(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	existingRecord := func() *core.Record {
+		record, err := app.FindRecordById(collection, existing.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+
+	scenarios := []struct {
+		name        string
+		field       *core.SequenceField
+		record      func() *core.Record
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.SequenceField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", 123)
+				return record
+			},
+			true,
+		},
+		{
+			"new record",
+			&core.SequenceField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "")
+				return record
+			},
+			false,
+		},
+		{
+			"existing record with unchanged value",
+			&core.SequenceField{Name: "test"},
+			existingRecord,
+			false,
+		},
+		{
+			"existing record with changed value",
+			&core.SequenceField{Name: "test"},
+			func() *core.Record {
+				record := existingRecord()
+				record.SetRaw("test", "INV-2")
+				return record
+			},
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.field.ValidateValue(context.Background(), app, s.record())
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestSequenceFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeSequence)
+	testDefaultFieldNameValidation(t, core.FieldTypeSequence)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(
+		&core.SequenceField{Name: "seq"},
+		&core.TextField{Name: "single"},
+		&core.SelectField{Name: "multiple", MaxSelect: 2, Values: []string{"a", "b"}},
+	)
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.SequenceField
+		expectErrors []string
+	}{
+		{
+			"zero minimal",
+			func() *core.SequenceField {
+				return &core.SequenceField{Id: "test", Name: "seq"}
+			},
+			[]string{},
+		},
+		{
+			"invalid padding",
+			func() *core.SequenceField {
+				return &core.SequenceField{Id: "test", Name: "seq", Padding: 21}
+			},
+			[]string{"padding"},
+		},
+		{
+			"missing scope field",
+			func() *core.SequenceField {
+				return &core.SequenceField{Id: "test", Name: "seq", ScopeField: "missing"}
+			},
+			[]string{"scopeField"},
+		},
+		{
+			"self scope field",
+			func() *core.SequenceField {
+				return &core.SequenceField{Id: "test", Name: "seq", ScopeField: "seq"}
+			},
+			[]string{"scopeField"},
+		},
+		{
+			"multiple scope field",
+			func() *core.SequenceField {
+				return &core.SequenceField{Id: "test", Name: "seq", ScopeField: "multiple"}
+			},
+			[]string{"scopeField"},
+		},
+		{
+			"valid",
+			func() *core.SequenceField {
+				return &core.SequenceField{Id: "test", Name: "seq", Prefix: "INV-", Padding: 6, ScopeField: "single"}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field().ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestSequenceFieldRecordSave(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_sequence")
+	collection.Fields.Add(
+		&core.TextField{Name: "org"},
+		&core.SequenceField{Name: "number", Prefix: "INV-", Padding: 3, ScopeField: "org"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(txApp core.App, org string) *core.Record {
+		record := core.NewRecord(collection)
+		record.Set("org", org)
+		if err := txApp.Save(record); err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+
+	r1 := create(app, "a")
+	r2 := create(app, "a")
+	r3 := create(app, "b")
+
+	// multiple creates in a single transaction
+	var r4, r5 *core.Record
+	err := app.RunInTransaction(func(txApp core.App) error {
+		r4 = create(txApp, "a")
+		r5 = create(txApp, "a")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// explicit raw value
+	r6 := core.NewRecord(collection)
+	r6.Set("org", "b")
+	r6.SetRaw("number", "INV-100")
+	if err := app.Save(r6); err != nil {
+		t.Fatal(err)
+	}
+	r7 := create(app, "b")
+
+	expectations := []struct {
+		record   *core.Record
+		expected string
+	}{
+		{r1, "INV-001"},
+		{r2, "INV-002"},
+		{r3, "INV-001"},
+		{r4, "INV-003"},
+		{r5, "INV-004"},
+		{r6, "INV-100"},
+		{r7, "INV-101"},
+	}
+
+	for i, e := range expectations {
+		if v := e.record.GetString("number"); v != e.expected {
+			t.Fatalf("[%d] Expected record number %q, got %q", i, e.expected, v)
+		}
+
+		fresh, err := app.FindRecordById(collection, e.record.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := fresh.GetString("number"); v != e.expected {
+			t.Fatalf("[%d] Expected stored record number %q, got %q", i, e.expected, v)
+		}
+	}
+
+	// update shouldn't change the number
+	r1.Set("org", "c")
+	if err := app.Save(r1); err != nil {
+		t.Fatal(err)
+	}
+	if v := r1.GetString("number"); v != "INV-001" {
+		t.Fatalf("Expected the number to remain unchanged, got %q", v)
+	}
+
+	// immutable
+	r1.SetRaw("number", "INV-999")
+	if err := app.Save(r1); err == nil {
+		t.Fatal("Expected validation error on number change")
+	}
+
+	// the number of the deleted last record shouldn't be reused
+	if err := app.Delete(r7); err != nil {
+		t.Fatal(err)
+	}
+	if v := create(app, "b").GetString("number"); v != "INV-102" {
+		t.Fatalf("Expected the next number to be %q, got %q", "INV-102", v)
+	}
+
+	// rolled back transaction shouldn't advance the counter
+	err = app.RunInTransaction(func(txApp core.App) error {
+		create(txApp, "b")
+		return errors.New("rollback")
+	})
+	if err == nil {
+		t.Fatal("Expected transaction error")
+	}
+	if v := create(app, "b").GetString("number"); v != "INV-103" {
+		t.Fatalf("Expected the next number to be %q, got %q", "INV-103", v)
+	}
+
+	// duplicated explicit value
+	dup := core.NewRecord(collection)
+	dup.Set("org", "b")
+	dup.SetRaw("number", "INV-100")
+	if err := app.Save(dup); err == nil {
+		t.Fatal("Expected unique constraint error for the duplicated number")
+	}
+}
+
+func TestSequenceFieldUniqueIndex(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_sequence")
+	collection.Fields.Add(
+		&core.TextField{Name: "org"},
+		&core.SequenceField{Id: "seq1", Name: "number", ScopeField: "org"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "CREATE UNIQUE INDEX `idx_sequence_seq1_" + collection.Id + "` ON `test_sequence` (`org`, `number`) WHERE `number` != ''"
+	if idx := collection.GetIndex("idx_sequence_seq1_" + collection.Id); idx != expected {
+		t.Fatalf("Expected index\n%s\ngot\n%s", expected, idx)
+	}
+
+	collection.Fields.RemoveById("seq1")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if idx := collection.GetIndex("idx_sequence_seq1_" + collection.Id); idx != "" {
+		t.Fatalf("Expected the index to be removed, got %s", idx)
+	}
+}
+
+func TestSequenceFieldFailedCreate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_sequence")
+	collection.Fields.Add(&core.SequenceField{Name: "number"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	fail := true
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*core.RecordEvent]{
+		Func: func(e *core.RecordEvent) error {
+			if fail {
+				return errors.New("test_error")
+			}
+			return e.Next()
+		},
+		Priority: 100, // after the record INSERT
+	})
+
+	if err := app.Save(core.NewRecord(collection)); err == nil {
+		t.Fatal("Expected the create to fail")
+	}
+
+	total, err := app.CountRecords(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("Expected the failed record INSERT to be rolled back, found %d records", total)
+	}
+
+	fail = false
+
+	record := core.NewRecord(collection)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// the failed create outside of a transaction shouldn't leave a gap
+	if v := record.GetString("number"); v != "1" {
+		t.Fatalf("Expected number %q, got %q", "1", v)
+	}
+}
+
+func TestSequenceFieldCountersCleanup(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	countCounters := func() int {
+		var total int
+		err := app.DB().Select("count(*)").
+			From("_params").
+			Where(dbx.NewExp("[[id]] LIKE 'sequence\\_%' ESCAPE '\\'")).
+			Row(&total)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return total
+	}
+
+	collection := core.NewBaseCollection("test_sequence")
+	collection.Fields.Add(
+		&core.SequenceField{Id: "seq1", Name: "a"},
+		&core.SequenceField{Id: "seq2", Name: "b"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Save(core.NewRecord(collection)); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := countCounters(); total != 2 {
+		t.Fatalf("Expected 2 counters, got %d", total)
+	}
+
+	// field removal
+	collection.Fields.RemoveById("seq1")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if total := countCounters(); total != 1 {
+		t.Fatalf("Expected 1 counter after the field removal, got %d", total)
+	}
+
+	// collection delete
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+	if total := countCounters(); total != 0 {
+		t.Fatalf("Expected 0 counters after the collection delete, got %d", total)
+	}
+}
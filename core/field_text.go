@@ -26,9 +26,10 @@ const FieldTypeText = "text"
 const autogenerateModifier = ":autogenerate"
 
 var (
-	_ Field             = (*TextField)(nil)
-	_ SetterFinder      = (*TextField)(nil)
-	_ RecordInterceptor = (*TextField)(nil)
+	_ Field              = (*TextField)(nil)
+	_ SetterFinder       = (*TextField)(nil)
+	_ RecordInterceptor  = (*TextField)(nil)
+	_ FullTextSearchable = (*TextField)(nil)
 )
 
 var forbiddenPKCharacters = []string{
@@ -107,6 +108,12 @@ type TextField struct {
 	//
 	// A single collection can have only 1 field marked as primary key.
 	PrimaryKey bool `form:"primaryKey" json:"primaryKey"`
+
+	// Searchable includes the field in the collection full-text search
+	// index allowing it to be queried with the match() filter function.
+	//
+	// Hidden fields cannot be searchable.
+	Searchable bool `form:"searchable" json:"searchable"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -166,6 +173,11 @@ func (f *TextField) ColumnType(app App) string {
 	return "TEXT DEFAULT '' NOT NULL"
 }
 
+// IsFullTextSearchable implements the [FullTextSearchable] interface.
+func (f *TextField) IsFullTextSearchable() bool {
+	return f.Searchable
+}
+
 // PrepareValue implements [Field.PrepareValue] interface method.
 func (f *TextField) PrepareValue(record *Record, raw any) (any, error) {
 	return cast.ToString(raw), nil
@@ -290,6 +302,7 @@ func (f *TextField) ValidateSettings(ctx context.Context, app App, collection *C
 		validation.Field(&f.Hidden, validation.When(f.PrimaryKey, validation.Empty)),
 		validation.Field(&f.Required, validation.When(f.PrimaryKey, validation.Required)),
 		validation.Field(&f.AutogeneratePattern, validation.By(validators.IsRegex), validation.By(f.checkAutogeneratePattern)),
+		validation.Field(&f.Searchable, validation.When(f.Searchable, validation.By(checkFullTextSearchField(f.Name, f.Hidden)))),
 	)
 }
 
@@ -486,6 +486,40 @@ func TestTextFieldValidateSettings(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"searchable with reserved name",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:         "test2",
+					Name:       "Rank",
+					Searchable: true,
+				}
+			},
+			[]string{"searchable"},
+		},
+		{
+			"hidden searchable",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:         "test2",
+					Name:       "title",
+					Hidden:     true,
+					Searchable: true,
+				}
+			},
+			[]string{"searchable"},
+		},
+		{
+			"searchable with valid name",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:         "test2",
+					Name:       "title",
+					Searchable: true,
+				}
+			},
+			[]string{},
+		},
 		{
 			"invalid autogeneratePattern",
 			func() *core.TextField {
@@ -0,0 +1,219 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/vector"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeVector] = func() Field {
+		return &VectorField{}
+	}
+}
+
+const FieldTypeVector = "vector"
+
+const maxVectorDimensions = 16000
+
+// VectorIndexes is a registry with the optional nearest neighbour indexes
+// of the vector fields, keyed by "collectionNameOrId.fieldName".
+//
+// When the vectorDistance filter function compares a base collection vector field
+// with a literal vector, only the candidates of the registered field index are checked.
+// The vector fields without a registered index are checked by brute-force (see [vector.BruteForce]).
+//
+// Note that the registered index is expected to be kept in sync with
+// the stored records by the caller (eg. with the record model hooks).
+var VectorIndexes = map[string]vector.Index{}
+
+// findVectorIndex returns the registered index of the specified collection vector field (if any).
+func findVectorIndex(collection *Collection, fieldName string) vector.Index {
+	if idx, ok := VectorIndexes[collection.Id+"."+fieldName]; ok {
+		return idx
+	}
+
+	return VectorIndexes[collection.Name+"."+fieldName]
+}
+
+var (
+	_ Field        = (*VectorField)(nil)
+	_ SetterFinder = (*VectorField)(nil)
+	_ DriverValuer = (*VectorField)(nil)
+)
+
+// VectorField defines "vector" type field for storing fixed
+// dimensions float32 vector embeddings.
+//
+// The vector is stored in the database as BLOB (see [vector.Encode])
+// and could be compared in filter and sort expressions with the
+// "vectorDistance" function, eg.:
+//
+//	vectorDistance(embedding, @request.query.q) < 0.3
+//
+// The respective zero record field value is empty []float32.
+type VectorField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Dimensions (required) specifies the exact number of the vector items.
+	Dimensions int `form:"dimensions" json:"dimensions"`
+
+	// Required will require the field value to be non-empty vector.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *VectorField) Type() string {
+	return FieldTypeVector
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *VectorField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *VectorField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *VectorField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *VectorField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *VectorField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *VectorField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *VectorField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *VectorField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *VectorField) ColumnType(app App) string {
+	return "BLOB DEFAULT x'' NOT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+//
+// The raw value is expected to be the encoded db BLOB.
+func (f *VectorField) PrepareValue(record *Record, raw any) (any, error) {
+	v, err := vector.Decode([]byte(cast.ToString(raw)))
+	if err != nil {
+		return []float32{}, nil // invalid or non-vector blob
+	}
+
+	return v, nil
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *VectorField) DriverValue(record *Record) (driver.Value, error) {
+	v, ok := record.GetRaw(f.Name).([]float32)
+	if !ok {
+		return nil, fmt.Errorf("invalid %q vector value", f.Name)
+	}
+
+	return vector.Encode(v), nil
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *VectorField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	val, ok := record.GetRaw(f.Name).([]float32)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	if len(val) == 0 {
+		if f.Required {
+			return validation.ErrRequired
+		}
+		return nil
+	}
+
+	if len(val) != f.Dimensions {
+		return validation.NewError(
+			"validation_vector_dimensions",
+			fmt.Sprintf("The vector must have exactly %d dimensions.", f.Dimensions),
+		).SetParams(map[string]any{"dimensions": f.Dimensions})
+	}
+
+	for _, v := range val {
+		if math.IsInf(float64(v), 0) || math.IsNaN(float64(v)) {
+			return validation.NewError("validation_not_a_number", "The vector must contain only finite numbers.")
+		}
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *VectorField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Dimensions, validation.Required, validation.Min(1), validation.Max(maxVectorDimensions)),
+	)
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *VectorField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		return f.setValue
+	default:
+		return nil
+	}
+}
+
+func (f *VectorField) setValue(record *Record, raw any) {
+	v, err := vector.Parse(raw)
+	if err != nil {
+		// store the raw value as it is to trigger the validation error
+		record.SetRaw(f.Name, raw)
+		return
+	}
+
+	record.SetRaw(f.Name, v)
+}
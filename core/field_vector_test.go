@@ -0,0 +1,376 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/vector"
+)
+
+func TestVectorFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeVector)
+}
+
+func TestVectorFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.VectorField{}
+
+	expected := "BLOB DEFAULT x'' NOT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestVectorFieldPrepareValue(t *testing.T) {
+	f := &core.VectorField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		raw      any
+		expected []float32
+	}{
+		{nil, []float32{}},
+		{"", []float32{}},
+		{"abc", []float32{}},
+		{string(vector.Encode([]float32{1, 2.5})), []float32{1, 2.5}},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			vv, ok := v.([]float32)
+			if !ok {
+				t.Fatalf("Expected []float32 instance, got %T", v)
+			}
+
+			if !slices.Equal(vv, s.expected) {
+				t.Fatalf("Expected %v, got %v", s.expected, vv)
+			}
+		})
+	}
+}
+
+func TestVectorFieldSetter(t *testing.T) {
+	collection := core.NewBaseCollection("test")
+	collection.Fields.Add(&core.VectorField{Name: "test", Dimensions: 2})
+
+	record := core.NewRecord(collection)
+
+	record.Set("test", "[1, 2.5]")
+	if v, _ := record.GetRaw("test").([]float32); !slices.Equal(v, []float32{1, 2.5}) {
+		t.Fatalf("Expected %v, got %v", []float32{1, 2.5}, record.GetRaw("test"))
+	}
+
+	record.Set("test", []any{3, 4})
+	if v, _ := record.GetRaw("test").([]float32); !slices.Equal(v, []float32{3, 4}) {
+		t.Fatalf("Expected %v, got %v", []float32{3, 4}, record.GetRaw("test"))
+	}
+
+	record.Set("test", "invalid")
+	if v := record.GetRaw("test"); v != "invalid" {
+		t.Fatalf("Expected the invalid value to be stored as it is, got %v", v)
+	}
+}
+
+func TestVectorFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name        string
+		field       *core.VectorField
+		record      func() *core.Record
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "[1,2]")
+				return record
+			},
+			true,
+		},
+		{
+			"zero field value (not required)",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", []float32{})
+				return record
+			},
+			false,
+		},
+		{
+			"zero field value (required)",
+			&core.VectorField{Name: "test", Dimensions: 2, Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", []float32{})
+				return record
+			},
+			true,
+		},
+		{
+			"dimensions mismatch",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", []float32{1, 2, 3})
+				return record
+			},
+			true,
+		},
+		{
+			"NaN item",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", []float32{1, float32(math.NaN())})
+				return record
+			},
+			true,
+		},
+		{
+			"valid vector",
+			&core.VectorField{Name: "test", Dimensions: 2, Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", []float32{1, 2})
+				return record
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.field.ValidateValue(context.Background(), app, s.record())
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestVectorFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeVector)
+	testDefaultFieldNameValidation(t, core.FieldTypeVector)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.VectorField
+		expectErrors []string
+	}{
+		{
+			"zero minimal",
+			func() *core.VectorField {
+				return &core.VectorField{Id: "test", Name: "test"}
+			},
+			[]string{"dimensions"},
+		},
+		{
+			"> max dimensions",
+			func() *core.VectorField {
+				return &core.VectorField{Id: "test", Name: "test", Dimensions: 16001}
+			},
+			[]string{"dimensions"},
+		},
+		{
+			"valid",
+			func() *core.VectorField {
+				return &core.VectorField{Id: "test", Name: "test", Dimensions: 1536}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field().ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestVectorFieldRecordSave(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_vector")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.VectorField{Name: "embedding", Dimensions: 2},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]string{
+		"east":  "[1, 0]",
+		"north": "[0, 1]",
+		"west":  "[-1, 0]",
+		"ne":    "[1, 1]",
+		"empty": "",
+	}
+	for title, embedding := range data {
+		record := core.NewRecord(collection)
+		record.Set("title", title)
+		record.Set("embedding", embedding)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the stored value must be the encoded blob
+	var raw []byte
+	err := app.DB().Select("embedding").From(collection.Name).Where(dbx.HashExp{"title": "east"}).Row(&raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := vector.Decode(raw); !slices.Equal(v, []float32{1, 0}) {
+		t.Fatalf("Expected the stored blob to be decoded as %v, got %v", []float32{1, 0}, v)
+	}
+
+	scenarios := []struct {
+		filter   string
+		sort     string
+		expected []string
+	}{
+		{"vectorDistance(embedding, '[2, 0]') < 0.1", "", []string{"east"}},
+		{"vectorDistance(embedding, '[1, 0]') < 0.5", "vectorDistance(embedding, '[1, 0]')", []string{"east", "ne"}},
+		{"vectorDistance(embedding, '[1, 0]', 'l2') <= 1.5", "-vectorDistance(embedding, '[1, 0]', 'l2')", []string{"north", "ne", "east"}},
+		{"title != 'empty'", "vectorDistance(embedding, '[1, 0]', 'dot'),title", []string{"east", "ne", "north", "west"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter+"_"+s.sort, func(t *testing.T) {
+			records, err := app.FindRecordsByFilter(collection, s.filter, s.sort, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			titles := make([]string, len(records))
+			for i, r := range records {
+				titles[i] = r.GetString("title")
+			}
+
+			if strings.Join(titles, ",") != strings.Join(s.expected, ",") {
+				t.Fatalf("Expected %v, got %v", s.expected, titles)
+			}
+		})
+	}
+
+	// json serialization
+	record, err := app.FindFirstRecordByData(collection, "title", "ne")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"embedding":[1,1]`) {
+		t.Fatalf("Expected the embedding to be serialized as JSON array, got %s", encoded)
+	}
+}
+
+type testCandidatesIndex struct {
+	ids []string
+}
+
+func (idx *testCandidatesIndex) Candidates(metric string, query []float32, k int) ([]string, error) {
+	return idx.ids, nil
+}
+
+func TestVectorFieldIndex(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_vector_index")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.VectorField{Name: "embedding", Dimensions: 2},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[string]string{}
+	for _, title := range []string{"east", "north", "ne"} {
+		record := core.NewRecord(collection)
+		record.Set("title", title)
+		record.Set("embedding", map[string]string{"east": "[1, 0]", "north": "[0, 1]", "ne": "[1, 1]"}[title])
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = record.Id
+	}
+
+	scenarios := []struct {
+		name     string
+		index    vector.Index
+		expected []string
+	}{
+		{"no index", nil, []string{"east", "ne", "north"}},
+		{"brute-force index", vector.BruteForce{}, []string{"east", "ne", "north"}},
+		{"candidates index", &testCandidatesIndex{ids: []string{ids["north"], ids["east"]}}, []string{"east", "north"}},
+		{"no candidates index", &testCandidatesIndex{ids: []string{}}, []string{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if s.index != nil {
+				core.VectorIndexes[collection.Name+".embedding"] = s.index
+				defer delete(core.VectorIndexes, collection.Name+".embedding")
+			}
+
+			records, err := app.FindRecordsByFilter(
+				collection,
+				"vectorDistance(embedding, '[1, 0]', 'l2') <= 1.5",
+				"vectorDistance(embedding, '[1, 0]', 'l2')",
+				0,
+				0,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			titles := make([]string, len(records))
+			for i, r := range records {
+				titles[i] = r.GetString("title")
+			}
+
+			if !slices.Equal(titles, s.expected) {
+				t.Fatalf("Expected %v, got %v", s.expected, titles)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash/fnv"
+	"io"
+	"sync"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameFileRefs = "_fileRefs"
+
+var (
+	_ Model        = (*FileRef)(nil)
+	_ PreValidator = (*FileRef)(nil)
+	_ RecordProxy  = (*FileRef)(nil)
+)
+
+// FileRef defines a Record proxy for working with the fileRefs collection.
+//
+// A FileRef links a deduplicated record file to its content-addressed blob
+// (see [FileField.Deduplicate]). The blob is deleted when its last FileRef is deleted.
+type FileRef struct {
+	*Record
+}
+
+// NewFileRef instantiates and returns a new blank *FileRef model.
+//
+// Example usage:
+//
+//	ref := core.NewFileRef(app)
+//	ref.SetCollectionRef(record.Collection().Id)
+//	ref.SetRecordRef(record.Id)
+//	ref.SetField("documents")
+//	ref.SetFilename("example_52iwbgds7l.pdf")
+//	ref.SetHash("2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae")
+//	app.Save(ref)
+func NewFileRef(app App) *FileRef {
+	m := &FileRef{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameFileRefs)
+	if err != nil {
+		// this is just to make tests easier since fileRefs is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on FileRef.PreValidate())
+		c = NewBaseCollection("@___invalid___")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *FileRef) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameFileRefs {
+		return errors.New("missing or invalid FileRef ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *FileRef) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *FileRef) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *FileRef) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *FileRef) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *FileRef) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *FileRef) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Field returns the "field" record field value.
+func (m *FileRef) Field() string {
+	return m.GetString("field")
+}
+
+// SetField updates the "field" record field value.
+func (m *FileRef) SetField(field string) {
+	m.Set("field", field)
+}
+
+// Filename returns the "filename" record field value.
+func (m *FileRef) Filename() string {
+	return m.GetString("filename")
+}
+
+// SetFilename updates the "filename" record field value.
+func (m *FileRef) SetFilename(filename string) {
+	m.Set("filename", filename)
+}
+
+// Hash returns the "hash" record field value.
+func (m *FileRef) Hash() string {
+	return m.GetString("hash")
+}
+
+// SetHash updates the "hash" record field value.
+func (m *FileRef) SetHash(hash string) {
+	m.Set("hash", hash)
+}
+
+// BlobKey returns the storage key of the referenced content-addressed blob.
+func (m *FileRef) BlobKey() string {
+	return FileBlobKey(m.Hash())
+}
+
+// Created returns the "created" record field value.
+func (m *FileRef) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *FileRef) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// FileBlobKey returns the storage key of the content-addressed blob with the specified hash.
+func FileBlobKey(hash string) string {
+	if len(hash) < 2 {
+		return StorageBlobsDirName + "/" + hash
+	}
+
+	return StorageBlobsDirName + "/" + hash[:2] + "/" + hash
+}
+
+// fileContentHash returns the hex encoded SHA-256 checksum of the file content.
+func fileContentHash(file *filesystem.File) (string, error) {
+	r, err := file.Reader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileBlobLocks serializes the blob existence checks and uploads
+// with the blob releases of the same hash (see [lockFileBlob]).
+var fileBlobLocks [64]sync.Mutex
+
+// lockFileBlob locks the content-addressed blob with the specified hash
+// and returns its unlock function.
+func lockFileBlob(hash string) (unlock func()) {
+	h := fnv.New32a()
+	h.Write([]byte(hash))
+
+	mu := &fileBlobLocks[h.Sum32()%uint32(len(fileBlobLocks))]
+	mu.Lock()
+
+	return mu.Unlock
+}
+
+// releaseFileBlob deletes the content-addressed blob with the specified hash
+// if there are no longer any FileRef models pointing to it.
+//
+// note: the references count and the blob delete are executed under the blob
+// lock to prevent deleting a blob that a concurrent upload has just checked.
+func releaseFileBlob(ctx context.Context, app App, hash string) error {
+	if hash == "" {
+		return nil
+	}
+
+	unlock := lockFileBlob(hash)
+	defer unlock()
+
+	total, err := app.CountRecords(CollectionNameFileRefs, dbx.HashExp{"hash": hash})
+	if err != nil {
+		return err
+	}
+
+	if total > 0 {
+		return nil // still in use
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+	fsys.SetContext(newContextIfInvalid(ctx))
+
+	err = fsys.Delete(FileBlobKey(hash))
+	if err != nil && !errors.Is(err, filesystem.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (app *BaseApp) registerFileRefHooks() {
+	recordRefHooks[*FileRef](app, CollectionNameFileRefs)
+
+	// release the blob once its last reference is deleted
+	// (eg. on record or collection cascade delete)
+	app.OnRecordAfterDeleteSuccess(CollectionNameFileRefs).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			err := releaseFileBlob(e.Context, e.App, e.Record.GetString("hash"))
+			if err != nil {
+				e.App.Logger().Warn(
+					"Failed to release file blob",
+					"error", err,
+					"hash", e.Record.GetString("hash"),
+				)
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+}
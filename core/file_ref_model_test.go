@@ -0,0 +1,90 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewFileRef(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	ref := core.NewFileRef(app)
+
+	if ref.Collection().Name != core.CollectionNameFileRefs {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameFileRefs, ref.Collection().Name)
+	}
+
+	if err := ref.PreValidate(context.Background(), app); err != nil {
+		t.Fatalf("Expected no PreValidate error, got %v", err)
+	}
+}
+
+func TestFileRefFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	ref := core.NewFileRef(app)
+
+	scenarios := []struct {
+		field  string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", ref.SetCollectionRef, ref.CollectionRef},
+		{"recordRef", ref.SetRecordRef, ref.RecordRef},
+		{"field", ref.SetField, ref.Field},
+		{"filename", ref.SetFilename, ref.Filename},
+		{"hash", ref.SetHash, ref.Hash},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.field, func(t *testing.T) {
+			for _, testValue := range []string{"test_1", ""} {
+				s.setter(testValue)
+
+				if v := s.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := ref.GetString(s.field); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			}
+		})
+	}
+}
+
+func TestFileBlobKey(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		hash     string
+		expected string
+	}{
+		{"", core.StorageBlobsDirName + "/"},
+		{"a", core.StorageBlobsDirName + "/a"},
+		{"abcdef", core.StorageBlobsDirName + "/ab/abcdef"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.hash, func(t *testing.T) {
+			if v := core.FileBlobKey(s.hash); v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+
+			ref := core.FileRef{Record: core.NewRecord(core.NewBaseCollection("test"))}
+			ref.SetHash(s.hash)
+			if v := ref.BlobKey(); v != s.expected {
+				t.Fatalf("Expected BlobKey %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// FindAllFileRefsByRecord returns all FileRef models linked to the provided record.
+func (app *BaseApp) FindAllFileRefsByRecord(record *Record) ([]*FileRef, error) {
+	result := []*FileRef{}
+
+	err := app.RecordQuery(CollectionNameFileRefs).
+		AndWhere(dbx.HashExp{
+			"collectionRef": record.Collection().Id,
+			"recordRef":     record.Id,
+		}).
+		OrderBy("created ASC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindFileRefByFilename returns a single FileRef model
+// by its record relation and filename.
+func (app *BaseApp) FindFileRefByFilename(record *Record, filename string) (*FileRef, error) {
+	result := &FileRef{}
+
+	err := app.RecordQuery(CollectionNameFileRefs).
+		AndWhere(dbx.HashExp{
+			"collectionRef": record.Collection().Id,
+			"recordRef":     record.Id,
+			"filename":      filename,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindRecordFileKey returns the storage key of the specified record file.
+//
+// For deduplicated files this is the key of their content-addressed blob,
+// otherwise it is the regular "{collectionId}/{recordId}/{filename}" key.
+func (app *BaseApp) FindRecordFileKey(record *Record, filename string) string {
+	ref, err := app.FindFileRefByFilename(record, filename)
+	if err == nil {
+		return ref.BlobKey()
+	}
+
+	return record.BaseFilesPath() + "/" + filename
+}
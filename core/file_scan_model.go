@@ -0,0 +1,161 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameFileScans = "_fileScans"
+
+const (
+	FileScanStatusClean    = "clean"
+	FileScanStatusInfected = "infected"
+)
+
+const (
+	FileScanActionReject     = "reject"
+	FileScanActionQuarantine = "quarantine"
+)
+
+var (
+	_ Model        = (*FileScan)(nil)
+	_ PreValidator = (*FileScan)(nil)
+	_ RecordProxy  = (*FileScan)(nil)
+)
+
+// FileScan defines a Record proxy for working with the fileScans collection.
+type FileScan struct {
+	*Record
+}
+
+// NewFileScan instantiates and returns a new blank *FileScan model.
+//
+// Example usage:
+//
+//	scan := core.NewFileScan(app)
+//	scan.SetCollectionRef(record.Collection().Id)
+//	scan.SetRecordRef(record.Id)
+//	scan.SetField("documents")
+//	scan.SetFilename("example_52iwbgds7l.pdf")
+//	scan.SetStatus(core.FileScanStatusClean)
+//	app.Save(scan)
+func NewFileScan(app App) *FileScan {
+	m := &FileScan{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameFileScans)
+	if err != nil {
+		// this is just to make tests easier since fileScans is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on FileScan.PreValidate())
+		c = NewBaseCollection("@___invalid___")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *FileScan) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameFileScans {
+		return errors.New("missing or invalid FileScan ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *FileScan) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *FileScan) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *FileScan) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *FileScan) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *FileScan) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *FileScan) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Field returns the "field" record field value.
+func (m *FileScan) Field() string {
+	return m.GetString("field")
+}
+
+// SetField updates the "field" record field value.
+func (m *FileScan) SetField(field string) {
+	m.Set("field", field)
+}
+
+// Filename returns the "filename" record field value.
+func (m *FileScan) Filename() string {
+	return m.GetString("filename")
+}
+
+// SetFilename updates the "filename" record field value.
+func (m *FileScan) SetFilename(filename string) {
+	m.Set("filename", filename)
+}
+
+// Status returns the "status" record field value.
+func (m *FileScan) Status() string {
+	return m.GetString("status")
+}
+
+// SetStatus updates the "status" record field value.
+func (m *FileScan) SetStatus(status string) {
+	m.Set("status", status)
+}
+
+// Signature returns the "signature" record field value.
+func (m *FileScan) Signature() string {
+	return m.GetString("signature")
+}
+
+// SetSignature updates the "signature" record field value.
+func (m *FileScan) SetSignature(signature string) {
+	m.Set("signature", signature)
+}
+
+// QuarantineKey returns the "quarantineKey" record field value.
+func (m *FileScan) QuarantineKey() string {
+	return m.GetString("quarantineKey")
+}
+
+// SetQuarantineKey updates the "quarantineKey" record field value.
+func (m *FileScan) SetQuarantineKey(key string) {
+	m.Set("quarantineKey", key)
+}
+
+// Created returns the "created" record field value.
+func (m *FileScan) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *FileScan) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerFileScanHooks() {
+	recordRefHooks[*FileScan](app, CollectionNameFileScans)
+}
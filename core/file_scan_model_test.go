@@ -0,0 +1,107 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewFileScan(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scan := core.NewFileScan(app)
+
+	if scan.Collection().Name != core.CollectionNameFileScans {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameFileScans, scan.Collection().Name)
+	}
+}
+
+func TestFileScanPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	t.Run("invalid proxy record", func(t *testing.T) {
+		scan := &core.FileScan{}
+		scan.SetProxyRecord(core.NewRecord(core.NewBaseCollection("test")))
+
+		if err := scan.PreValidate(context.Background(), app); err == nil {
+			t.Fatal("Expected PreValidate error")
+		}
+	})
+
+	t.Run("valid proxy record", func(t *testing.T) {
+		scan := core.NewFileScan(app)
+
+		if err := scan.PreValidate(context.Background(), app); err != nil {
+			t.Fatalf("Expected no PreValidate error, got %v", err)
+		}
+	})
+}
+
+func TestFileScanFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scan := core.NewFileScan(app)
+
+	scenarios := []struct {
+		field  string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", scan.SetCollectionRef, scan.CollectionRef},
+		{"recordRef", scan.SetRecordRef, scan.RecordRef},
+		{"field", scan.SetField, scan.Field},
+		{"filename", scan.SetFilename, scan.Filename},
+		{"status", scan.SetStatus, scan.Status},
+		{"signature", scan.SetSignature, scan.Signature},
+		{"quarantineKey", scan.SetQuarantineKey, scan.QuarantineKey},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.field, func(t *testing.T) {
+			for _, testValue := range []string{"test_1", ""} {
+				s.setter(testValue)
+
+				if v := s.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := scan.GetString(s.field); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			}
+		})
+	}
+}
+
+func TestFileScanValidateRecordRef(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scan := core.NewFileScan(app)
+	scan.SetCollectionRef("wsmn24bux7wo113")
+	scan.SetRecordRef("missing")
+	scan.SetField("file_many")
+	scan.SetFilename("test.txt")
+	scan.SetStatus(core.FileScanStatusClean)
+
+	tests.TestValidationErrors(t, app.Save(scan), []string{"recordRef"})
+
+	scan.SetRecordRef("84nmscqy84lsi1t")
+
+	if err := app.Save(scan); err != nil {
+		t.Fatalf("Expected save to succeed, got %v", err)
+	}
+}
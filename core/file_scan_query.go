@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+)
+
+// FindAllFileScansByRecord returns all FileScan models linked to the provided record (in DESC order).
+func (app *BaseApp) FindAllFileScansByRecord(record *Record) ([]*FileScan, error) {
+	result := []*FileScan{}
+
+	err := app.RecordQuery(CollectionNameFileScans).
+		AndWhere(dbx.HashExp{
+			"collectionRef": record.Collection().Id,
+			"recordRef":     record.Id,
+		}).
+		OrderBy("created DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindFileScanByFilename returns a single FileScan model
+// by its record relation, field name and filename.
+func (app *BaseApp) FindFileScanByFilename(record *Record, field string, filename string) (*FileScan, error) {
+	result := &FileScan{}
+
+	err := app.RecordQuery(CollectionNameFileScans).
+		AndWhere(dbx.HashExp{
+			"collectionRef": record.Collection().Id,
+			"recordRef":     record.Id,
+			"field":         field,
+			"filename":      filename,
+		}).
+		OrderBy("created DESC").
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteAllFileScansByRecord deletes all FileScan models associated with the provided record.
+//
+// Returns a combined error with the failed deletes.
+func (app *BaseApp) DeleteAllFileScansByRecord(record *Record) error {
+	models, err := app.FindAllFileScansByRecord(record)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range models {
+		if err := app.Delete(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
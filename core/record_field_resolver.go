@@ -98,6 +98,8 @@ func NewRecordFieldResolver(
 			`^\@request\.query\.[\w\.\:]*\w+$`,
 			`^\@request\.headers\.[\w\.\:]*\w+$`,
 			`^\@collection\.\w+(\:\w+)?\.[\w\.\:]*\w+$`,
+			`^\@rowid$`,
+			`^\@fts$`,
 		},
 	}
 
@@ -316,7 +318,7 @@ func (r *RecordFieldResolver) resolveStaticRequestField(path ...string) (*search
 		// float in case of a numeric string value was used
 		// (this usually the case when the data is from a multipart/form-data request)
 		field := r.baseCollection.Fields.GetByName(path[len(path)-1])
-		if field != nil && field.Type() == FieldTypeNumber {
+		if field != nil && (field.Type() == FieldTypeNumber || field.Type() == FieldTypeDecimal) {
 			if nv, err := strconv.ParseFloat(v, 64); err == nil {
 				resultVal = nv
 			}
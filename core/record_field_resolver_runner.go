@@ -73,6 +73,24 @@ func (r *runner) run() (*search.ResolverResult, error) {
 
 	r.prepare()
 
+	// full-text search token functions identifiers
+	switch r.fieldName {
+	case search.RowidIdentifier:
+		return &search.ResolverResult{
+			NullFallback: search.NullFallbackDisabled,
+			Identifier:   "[[" + r.activeTableAlias + "._rowid_]]",
+		}, nil
+	case search.FullTextSearchIdentifier:
+		if len(fullTextSearchFields(r.resolver.baseCollection)) == 0 {
+			return nil, fmt.Errorf("collection %q doesn't have searchable fields", r.resolver.baseCollection.Name)
+		}
+
+		return &search.ResolverResult{
+			NullFallback: search.NullFallbackDisabled,
+			Identifier:   "{{" + fullTextSearchTableName(r.resolver.baseCollection) + "}}",
+		}, nil
+	}
+
 	// check for @collection field (aka. non-relational join)
 	// must be in the format "@collection.COLLECTION_NAME.FIELD[.FIELD2....]"
 	if r.activeProps[0] == "@collection" {
@@ -129,15 +147,20 @@ func (r *runner) run() (*search.ResolverResult, error) {
 	return r.processActiveProps()
 }
 
+// baseTableAlias returns the table alias of the resolver base collection.
+func (r *runner) baseTableAlias() string {
+	if r.resolver.baseCollectionAlias == "" {
+		return inflector.Columnify(r.resolver.baseCollection.Name)
+	}
+
+	return r.resolver.baseCollectionAlias
+}
+
 func (r *runner) prepare() {
 	r.activeProps = strings.Split(r.fieldName, ".")
 
 	r.activeCollectionName = r.resolver.baseCollection.Name
-	if r.resolver.baseCollectionAlias == "" {
-		r.activeTableAlias = inflector.Columnify(r.activeCollectionName)
-	} else {
-		r.activeTableAlias = r.resolver.baseCollectionAlias
-	}
+	r.activeTableAlias = r.baseTableAlias()
 
 	// enable the ignore flag for missing @request.* fields for backward
 	// compatibility and consistency with all @request.* filter fields and types
@@ -458,6 +481,15 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 			return nil, fmt.Errorf("non-filterable field %q", prop)
 		}
 
+		// junction relation field with a junction link field (eg. members.role)
+		// -> resolve the rest of the props through the junction back relation
+		if relField, ok := field.(*RelationField); ok && relField.Junction {
+			if junctionProp := r.junctionLinkProp(collection, relField, r.activeProps[i+1]); junctionProp != "" {
+				prop = junctionProp
+				field = nil
+			}
+		}
+
 		// @todo consider moving to the finalizer and converting to "JSONExtractable" interface with optional extra validation for the remaining props?
 		// json or geoPoint field -> treat the rest of the props as json path
 		if field != nil && (field.Type() == FieldTypeJSON || field.Type() == FieldTypeGeoPoint) {
@@ -489,6 +521,11 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 			return result, nil
 		}
 
+		// polymorphic relation field -> resolve the reference prop (eg. target.collectionName)
+		if polyField, ok := field.(*PolyRelationField); ok {
+			return r.processPolyRelationField(polyField, i)
+		}
+
 		if i >= maxNestedRels {
 			return nil, fmt.Errorf("max nested relations reached for field %q", prop)
 		}
@@ -751,6 +788,11 @@ func (r *runner) finalizeActivePropsProcessing(collection *Collection, prop stri
 		return nil, fmt.Errorf("non-filterable field %q", name)
 	}
 
+	// the stored value is encrypted and cannot be compared or sorted
+	if field.Type() == FieldTypeEncrypted {
+		return nil, fmt.Errorf("encrypted field %q cannot be used in filter or sort expressions", name)
+	}
+
 	multvaluer, isMultivaluer := field.(MultiValuer)
 
 	cleanFieldName := inflector.Columnify(field.GetName())
@@ -841,6 +883,29 @@ func (r *runner) finalizeActivePropsProcessing(collection *Collection, prop stri
 		}
 	}
 
+	// attach the registered vector index only for the base collection fields
+	// (aka. so that the index candidates could be matched with the base record id)
+	if field.Type() == FieldTypeVector && !r.withMultiMatch &&
+		collection.Id == r.resolver.baseCollection.Id && r.activeTableAlias == r.baseTableAlias() {
+		result.VectorIndex = findVectorIndex(collection, field.GetName())
+	}
+
+	// compare the stored scaled integer directly with the scaled literal operands
+	// (for the other operands it is converted to its real decimal value on build)
+	//
+	// note: with the ":lower" modifier the value is always converted
+	// to its real decimal value to have the expected text representation
+	if decimal, ok := field.(*DecimalField); ok && decimal.Scale > 0 {
+		if modifier != lowerModifier {
+			result.Scale = decimal.Scale
+		} else {
+			result.Identifier = "CAST(" + result.Identifier + " / " + decimal.scaleDivisor() + " AS REAL)"
+			if r.withMultiMatch {
+				r.multiMatch.ValueIdentifier = "CAST(" + r.multiMatch.ValueIdentifier + " / " + decimal.scaleDivisor() + " AS REAL)"
+			}
+		}
+	}
+
 	// account for the ":lower" modifier
 	if modifier == lowerModifier {
 		result.Identifier = "LOWER(" + result.Identifier + ")"
@@ -851,3 +916,84 @@ func (r *runner) finalizeActivePropsProcessing(collection *Collection, prop stri
 
 	return result, nil
 }
+
+// junctionLinkProp returns the back relation prop (eg. "posts_members_via_source")
+// of the provided junction relation field if nextProp is a junction link field
+// and it is not a field of the related collection.
+//
+// Returns empty string if nextProp should be resolved as regular relation field prop.
+func (r *runner) junctionLinkProp(collection *Collection, relField *RelationField, nextProp string) string {
+	nextFieldName, _, err := splitModifier(nextProp)
+	if err != nil {
+		return ""
+	}
+
+	relCollection, err := r.resolver.loadCollection(relField.CollectionId)
+	if err != nil || relCollection.Fields.GetByName(nextFieldName) != nil {
+		return ""
+	}
+
+	junction, err := r.resolver.loadCollection(relField.JunctionCollectionId(collection))
+	if err != nil || junction.Fields.GetByName(nextFieldName) == nil {
+		return ""
+	}
+
+	return junction.Name + "_via_" + JunctionFieldSource
+}
+
+// processPolyRelationField resolves the reference prop of a polymorphic
+// relation field ("collectionId", "collectionName" or "recordId").
+func (r *runner) processPolyRelationField(field *PolyRelationField, propDepth int) (*search.ResolverResult, error) {
+	if propDepth != len(r.activeProps)-2 {
+		return nil, fmt.Errorf("invalid polymorphic relation field path %q", r.fieldName)
+	}
+
+	refProp := r.activeProps[propDepth+1]
+
+	var refIdentifier func(jeAlias string) string
+	switch refProp {
+	case "collectionId", "recordId":
+		refIdentifier = func(jeAlias string) string {
+			return fmt.Sprintf("json_extract([[%s.value]], '$.%s')", jeAlias, refProp)
+		}
+	case "collectionName":
+		refIdentifier = func(jeAlias string) string {
+			return fmt.Sprintf(
+				"(SELECT [[__pc.name]] FROM {{_collections}} [[__pc]] WHERE [[__pc.id]] = json_extract([[%s.value]], '$.collectionId'))",
+				jeAlias,
+			)
+		}
+	default:
+		return nil, fmt.Errorf("unknown polymorphic relation field prop %q", refProp)
+	}
+
+	cleanFieldName := inflector.Columnify(field.Name)
+	jeAlias := "__je_" + r.activeTableAlias + "_" + cleanFieldName + r.resolver.joinAliasSuffix
+
+	err := r.resolver.registerJoin(dbutils.JSONEach(r.activeTableAlias+"."+cleanFieldName), jeAlias, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &search.ResolverResult{
+		Identifier: refIdentifier(jeAlias),
+	}
+
+	if field.IsMultiple() {
+		r.withMultiMatch = true
+	}
+
+	if r.withMultiMatch {
+		jeAlias2 := "__je_" + r.multiMatchActiveTableAlias + "_" + cleanFieldName + r.resolver.joinAliasSuffix
+
+		r.multiMatch.Joins = append(r.multiMatch.Joins, &search.Join{
+			TableName:  dbutils.JSONEach(r.multiMatchActiveTableAlias + "." + cleanFieldName),
+			TableAlias: jeAlias2,
+		})
+		r.multiMatch.ValueIdentifier = refIdentifier(jeAlias2)
+
+		result.MultiMatchSubQuery = r.multiMatch
+	}
+
+	return result, nil
+}
@@ -28,8 +28,8 @@ func TestRecordFieldResolverAllowedFields(t *testing.T) {
 	r := core.NewRecordFieldResolver(app, collection, nil, false)
 
 	fields := r.AllowedFields()
-	if len(fields) != 8 {
-		t.Fatalf("Expected %d original allowed fields, got %d", 8, len(fields))
+	if len(fields) != 10 {
+		t.Fatalf("Expected %d original allowed fields, got %d", 10, len(fields))
 	}
 
 	// change the allowed fields
@@ -644,6 +644,27 @@ func TestRecordFieldResolverUpdateQuery(t *testing.T) {
 			false,
 			"SELECT `view1`.* FROM `view1` WHERE (([[view1.point]] = '' OR [[view1.point]] IS NULL) OR (CASE WHEN json_valid([[view1.point]]) THEN JSON_EXTRACT([[view1.point]], '$.lat') ELSE JSON_EXTRACT(json_object('pb', [[view1.point]]), '$.pb.lat') END) > {:TEST} OR (CASE WHEN json_valid([[view1.point]]) THEN JSON_EXTRACT([[view1.point]], '$.lon') ELSE JSON_EXTRACT(json_object('pb', [[view1.point]]), '$.pb.lon') END) < {:TEST} OR (CASE WHEN json_valid([[view1.point]]) THEN JSON_EXTRACT([[view1.point]], '$.something') ELSE JSON_EXTRACT(json_object('pb', [[view1.point]]), '$.pb.something') END) > {:TEST})",
 		},
+		{
+			"match() on collection without searchable fields",
+			"demo4",
+			"match('test') = true",
+			true,
+			"",
+		},
+		{
+			"@rowid identifier",
+			"demo4",
+			"@rowid > 1",
+			true,
+			"",
+		},
+		{
+			"@fts identifier",
+			"demo4",
+			"@fts = 'test'",
+			true,
+			"",
+		},
 		{
 			"strftime with fixed string as time-value against known empty value (null normalizations)",
 			"demo5",
@@ -665,6 +686,48 @@ func TestRecordFieldResolverUpdateQuery(t *testing.T) {
 			false,
 			"SELECT DISTINCT `demo5`.* FROM `demo5` LEFT JOIN json_each(CASE WHEN iif(json_valid([[demo5.rel_many]]), json_type([[demo5.rel_many]])='array', FALSE) THEN [[demo5.rel_many]] ELSE json_array([[demo5.rel_many]]) END) `__je_demo5_rel_many` LEFT JOIN `demo4` `demo5_rel_many` ON [[demo5_rel_many.id]] = [[__je_demo5_rel_many.value]] WHERE (((strftime({:TEST},[[demo5_rel_many.created]]) = 1) AND (NOT EXISTS (SELECT 1 FROM (SELECT strftime({:TEST},[[__mm_demo5_rel_many.created]]) as [[multiMatchValue]] FROM `demo5` `__mm_demo5` LEFT JOIN json_each(CASE WHEN iif(json_valid([[__mm_demo5.rel_many]]), json_type([[__mm_demo5.rel_many]])='array', FALSE) THEN [[__mm_demo5.rel_many]] ELSE json_array([[__mm_demo5.rel_many]]) END) `__mm_demo5_rel_many_je` LEFT JOIN `demo4` `__mm_demo5_rel_many` ON [[__mm_demo5_rel_many.id]] = [[__mm_demo5_rel_many_je.value]] WHERE `__mm_demo5`.`id` = `demo5`.`id`) {{__smTEST}} WHERE NOT ([[__smTEST.multiMatchValue]] = 1)))))",
 		},
+		{
+			"string and date functions without multi-match",
+			"demo5",
+			"lower(trim(rel_one.title)) = 'a' && dateSub(rel_one.created, 1, 'days') < coalesce(rel_one.updated, @now)",
+			false,
+			"SELECT DISTINCT `demo5`.* FROM `demo5` LEFT JOIN `demo4` `demo5_rel_one` ON [[demo5_rel_one.id]] = [[demo5.rel_one]] WHERE (LOWER(TRIM([[demo5_rel_one.title]])) = {:TEST} AND strftime('%Y-%m-%d %H:%M:%fZ',[[demo5_rel_one.created]],(-CAST({:TEST} AS REAL) || ' days')) < COALESCE(NULLIF([[demo5_rel_one.updated]], ''),{:TEST}))",
+		},
+		{
+			"string function with multi-match",
+			"demo5",
+			"length(rel_many.title) > 1",
+			false,
+			"SELECT DISTINCT `demo5`.* FROM `demo5` LEFT JOIN json_each(CASE WHEN iif(json_valid([[demo5.rel_many]]), json_type([[demo5.rel_many]])='array', FALSE) THEN [[demo5.rel_many]] ELSE json_array([[demo5.rel_many]]) END) `__je_demo5_rel_many` LEFT JOIN `demo4` `demo5_rel_many` ON [[demo5_rel_many.id]] = [[__je_demo5_rel_many.value]] WHERE (((LENGTH(COALESCE([[demo5_rel_many.title]], '')) > {:TEST}) AND (NOT EXISTS (SELECT 1 FROM (SELECT LENGTH(COALESCE([[__mm_demo5_rel_many.title]], '')) as [[multiMatchValue]] FROM `demo5` `__mm_demo5` LEFT JOIN json_each(CASE WHEN iif(json_valid([[__mm_demo5.rel_many]]), json_type([[__mm_demo5.rel_many]])='array', FALSE) THEN [[__mm_demo5.rel_many]] ELSE json_array([[__mm_demo5.rel_many]]) END) `__mm_demo5_rel_many_je` LEFT JOIN `demo4` `__mm_demo5_rel_many` ON [[__mm_demo5_rel_many.id]] = [[__mm_demo5_rel_many_je.value]] WHERE `__mm_demo5`.`id` = `demo5`.`id`) {{__smTEST}} WHERE NOT ([[__smTEST.multiMatchValue]] > {:TEST})))))",
+		},
+		{
+			"coalesce with multi-match argument",
+			"demo5",
+			"coalesce(rel_many.title, 'a') = 'a'",
+			false,
+			"",
+		},
+		{
+			"IN operators with array literal and @request.body array",
+			"demo5",
+			"rel_one IN ['a', @request.body.rel_one] && rel_one NOT IN @request.body.rel_many",
+			false,
+			"SELECT `demo5`.* FROM `demo5` WHERE ([[demo5.rel_one]] IN ({:TEST}, {:TEST}) AND ([[demo5.rel_one]] IS NULL OR [[demo5.rel_one]] NOT IN (SELECT [[value]] FROM json_each(CASE WHEN iif(json_valid({:TEST}), json_type({:TEST})='array', FALSE) THEN {:TEST} ELSE json_array({:TEST}) END))))",
+		},
+		{
+			"IN operators with multi-match",
+			"demo5",
+			"rel_many.title IN ['a', 'b'] || rel_many.id ?IN @request.body.rel_many",
+			false,
+			"SELECT DISTINCT `demo5`.* FROM `demo5` LEFT JOIN json_each(CASE WHEN iif(json_valid([[demo5.rel_many]]), json_type([[demo5.rel_many]])='array', FALSE) THEN [[demo5.rel_many]] ELSE json_array([[demo5.rel_many]]) END) `__je_demo5_rel_many` LEFT JOIN `demo4` `demo5_rel_many` ON [[demo5_rel_many.id]] = [[__je_demo5_rel_many.value]] WHERE ((([[demo5_rel_many.title]] IN ({:TEST}, {:TEST})) AND (NOT EXISTS (SELECT 1 FROM (SELECT [[__mm_demo5_rel_many.title]] as [[multiMatchValue]] FROM `demo5` `__mm_demo5` LEFT JOIN json_each(CASE WHEN iif(json_valid([[__mm_demo5.rel_many]]), json_type([[__mm_demo5.rel_many]])='array', FALSE) THEN [[__mm_demo5.rel_many]] ELSE json_array([[__mm_demo5.rel_many]]) END) `__mm_demo5_rel_many_je` LEFT JOIN `demo4` `__mm_demo5_rel_many` ON [[__mm_demo5_rel_many.id]] = [[__mm_demo5_rel_many_je.value]] WHERE `__mm_demo5`.`id` = `demo5`.`id`) {{__smTEST}} WHERE NOT ([[__smTEST.multiMatchValue]] IN ({:TEST}, {:TEST}))))) OR [[demo5_rel_many.id]] IN (SELECT [[value]] FROM json_each(CASE WHEN iif(json_valid({:TEST}), json_type({:TEST})='array', FALSE) THEN {:TEST} ELSE json_array({:TEST}) END)))",
+		},
+		{
+			"IN operator with multi-match right operand",
+			"demo5",
+			"rel_one IN [rel_many.title]",
+			false,
+			"",
+		},
 	}
 
 	for _, s := range scenarios {
@@ -977,6 +977,13 @@ func (m *Record) GetGeoPoint(key string) types.GeoPoint {
 	return point
 }
 
+// GetGeoPolygon returns the data value for "key" as a GeoPolygon instance.
+func (m *Record) GetGeoPolygon(key string) types.GeoPolygon {
+	polygon := types.GeoPolygon{}
+	_ = polygon.Scan(m.Get(key))
+	return polygon
+}
+
 // GetStringSlice returns the data value for "key" as a slice of non-zero unique strings.
 func (m *Record) GetStringSlice(key string) []string {
 	return list.ToUniqueStringSlice(m.Get(key))
@@ -1132,6 +1139,11 @@ func (m *Record) dbExport() (map[string]any, error) {
 	for _, field := range fields {
 		fieldName = field.GetName()
 
+		// the db generated columns are readonly
+		if f, ok := field.(GeneratedColumn); ok && f.IsGeneratedColumn() {
+			continue
+		}
+
 		if f, ok := field.(DriverValuer); ok {
 			v, err := f.DriverValue(m)
 			if err != nil {
@@ -1520,27 +1532,8 @@ func cascadeRecordDelete(app App, mainRecord *Record, refs map[*Collection][]Fie
 			continue // skip missing or view collections
 		}
 
-		recordTableName := inflector.Columnify(refCollection.Name)
-
 		for _, field := range fields {
-			prefixedFieldName := recordTableName + "." + inflector.Columnify(field.GetName())
-
-			query := app.RecordQuery(refCollection)
-
-			if opt, ok := field.(MultiValuer); !ok || !opt.IsMultiple() {
-				query.AndWhere(dbx.HashExp{prefixedFieldName: mainRecord.Id})
-			} else {
-				query.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
-					`SELECT 1 FROM %s {{__je__}} WHERE [[__je__.value]]={:jevalue}`,
-					dbutils.JSONEach(prefixedFieldName),
-				), dbx.Params{
-					"jevalue": mainRecord.Id,
-				})))
-			}
-
-			if refCollection.Id == mainRecord.Collection().Id {
-				query.AndWhere(dbx.Not(dbx.HashExp{recordTableName + ".id": mainRecord.Id}))
-			}
+			query := recordReferencesQuery(app, mainRecord, refCollection, field)
 
 			// trigger cascade for each batchSize rel items until there is none
 			batchSize := 4000
@@ -1572,7 +1565,36 @@ func cascadeRecordDelete(app App, mainRecord *Record, refs map[*Collection][]Fie
 	return nil
 }
 
-// deleteRefRecords checks if related records has to be deleted (if `CascadeDelete` is set)
+// recordReferencesQuery returns a query that selects all refCollection records
+// referencing mainRecord through the provided relation field.
+func recordReferencesQuery(app App, mainRecord *Record, refCollection *Collection, field Field) *dbx.SelectQuery {
+	recordTableName := inflector.Columnify(refCollection.Name)
+	prefixedFieldName := recordTableName + "." + inflector.Columnify(field.GetName())
+
+	query := app.RecordQuery(refCollection)
+
+	if opt, ok := field.(MultiValuer); !ok || !opt.IsMultiple() {
+		query.AndWhere(dbx.HashExp{prefixedFieldName: mainRecord.Id})
+	} else {
+		query.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
+			`SELECT 1 FROM %s {{__je__}} WHERE [[__je__.value]]={:jevalue}`,
+			dbutils.JSONEach(prefixedFieldName),
+		), dbx.Params{
+			"jevalue": mainRecord.Id,
+		})))
+	}
+
+	if refCollection.Id == mainRecord.Collection().Id {
+		query.AndWhere(dbx.Not(dbx.HashExp{recordTableName + ".id": mainRecord.Id}))
+	}
+
+	return query
+}
+
+// deleteRefRecords applies the relation field on delete action to the related records, aka.
+// prevents the delete (restrict)
+// OR
+// checks if related records has to be deleted (cascade)
 // OR
 // just unset the record id from any relation field values (if they are not required).
 //
@@ -1583,6 +1605,12 @@ func deleteRefRecords(app App, mainRecord *Record, refRecords []*Record, field F
 		return errors.New("only RelationField is supported at the moment, got " + field.Type())
 	}
 
+	action := relField.OnDeleteAction()
+
+	if action == RelationOnDeleteRestrict && len(refRecords) > 0 {
+		return fmt.Errorf("the record cannot be deleted because it is referenced by record %s (%s collection) with restricted on delete action", refRecords[0].Id, refRecords[0].Collection().Name)
+	}
+
 	for _, refRecord := range refRecords {
 		ids := refRecord.GetStringSlice(relField.Name)
 
@@ -1596,7 +1624,7 @@ func deleteRefRecords(app App, mainRecord *Record, refRecords []*Record, field F
 
 		// cascade delete the reference
 		// (only if there are no other active references in case of multiple select)
-		if relField.CascadeDelete && len(ids) == 0 {
+		if action == RelationOnDeleteCascade && len(ids) == 0 {
 			if err := app.Delete(refRecord); err != nil {
 				return err
 			}
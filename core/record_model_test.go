@@ -1053,6 +1053,43 @@ func TestRecordGetGeoPoint(t *testing.T) {
 	}
 }
 
+func TestRecordGetGeoPolygon(t *testing.T) {
+	t.Parallel()
+
+	polygon := `{"coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"type":"Polygon"}`
+
+	scenarios := []struct {
+		value    any
+		expected string
+	}{
+		{nil, `null`},
+		{"", `null`},
+		{0, `null`},
+		{"{}", `null`},
+		{"[]", `null`},
+		{`{"type":"Point","coordinates":[1,2]}`, `null`},
+		{polygon, polygon},
+		{[]byte(polygon), polygon},
+		{map[string]any{"type": "Polygon", "coordinates": [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, polygon},
+		{types.GeoPolygon{Type: "Polygon", Polygons: [][][][2]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}}, polygon},
+	}
+
+	collection := core.NewBaseCollection("test")
+	record := core.NewRecord(collection)
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.value), func(t *testing.T) {
+			record.Set("test", s.value)
+
+			polygonStr := record.GetGeoPolygon("test").String()
+
+			if polygonStr != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, polygonStr)
+			}
+		})
+	}
+}
+
 func TestRecordGetUnsavedFiles(t *testing.T) {
 	t.Parallel()
 
@@ -397,12 +397,12 @@ func (app *BaseApp) FindRecordsByFilter(
 
 	if sort != "" {
 		for _, sortField := range search.ParseSortFromString(sort) {
-			expr, err := sortField.BuildExpr(resolver)
+			expr, params, err := sortField.BuildExprWithParams(resolver)
 			if err != nil {
 				return nil, err
 			}
 			if expr != "" {
-				q.AndOrderBy(expr)
+				q.AndOrderBy(expr).AndBind(params)
 			}
 		}
 	}
@@ -430,6 +430,99 @@ func (app *BaseApp) FindRecordsByFilter(
 	return records, nil
 }
 
+// RecordsCursorKey returns the 32 characters key derived from the
+// Settings().Meta.CursorKey that is used to encrypt the records pagination cursors.
+//
+// Returns empty string if the settings cursor key is not set.
+func RecordsCursorKey(app App) string {
+	key := app.Settings().Meta.CursorKey
+	if key == "" {
+		return ""
+	}
+
+	return security.SHA256(key)[:32]
+}
+
+// FindRecordsByCursor returns limit number of records matching the
+// provided string filter after the specified keyset pagination cursor
+// together with the cursor of the next page (empty if there are no more records).
+//
+// NB! Use the last "params" argument to bind untrusted user variables!
+//
+// The filter and sort arguments are optional and have the same format as in
+// [BaseApp.FindRecordsByFilter] (random sort is not supported).
+// The records are always sorted additionally by "id" as tie-breaker.
+//
+// The cursor argument should be empty string for the first page
+// or the cursor returned from the previous call.
+//
+// If the limit argument is <= 0, the default search page size is used.
+//
+// Example:
+//
+//	records, next, err := app.FindRecordsByCursor(
+//		"posts",
+//		"visible = {:visible}",
+//		"-created",
+//		100,
+//		next,
+//		dbx.Params{"visible": true},
+//	)
+func (app *BaseApp) FindRecordsByCursor(
+	collectionModelOrIdentifier any,
+	filter string,
+	sort string,
+	limit int,
+	cursor string,
+	params ...dbx.Params,
+) ([]*Record, string, error) {
+	collection, err := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
+	if err != nil {
+		return nil, "", err
+	}
+
+	q := app.RecordQuery(collection)
+
+	resolver := NewRecordFieldResolver(
+		app,
+		collection, // the base collection
+		nil,        // no request data
+		true,       // allow searching hidden/protected fields like "email"
+	)
+
+	// note: the filter is attached directly to the query to allow binding the params
+	if filter != "" {
+		expr, err := search.FilterData(filter).BuildExpr(resolver, params...)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid filter expression: %w", err)
+		}
+		q.AndWhere(expr)
+	}
+
+	provider := search.NewProvider(resolver).
+		Query(q).
+		SkipTotal(true).
+		PerPage(limit).
+		Cursor(cursor)
+
+	if key := RecordsCursorKey(app); key != "" {
+		provider.CursorKey(key)
+	}
+
+	if sort != "" {
+		provider.Sort(search.ParseSortFromString(sort))
+	}
+
+	records := []*Record{}
+
+	result, err := provider.Exec(&records)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return records, result.NextCursor, nil
+}
+
 // FindFirstRecordByFilter returns the first available record matching the provided filter (if any).
 //
 // NB! Use the last params argument to bind untrusted user variables!
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"sort"
+)
+
+// RecordDeleteImpactItem describes a single record affected by a record delete.
+type RecordDeleteImpactItem struct {
+	CollectionId   string `json:"collectionId"`
+	CollectionName string `json:"collectionName"`
+	RecordId       string `json:"recordId"`
+
+	// Field is the name of the relation field referencing the deleted record.
+	Field string `json:"field"`
+
+	// Action is the effective on delete action of the relation field.
+	Action string `json:"action"`
+}
+
+// RecordDeleteImpact holds information about the records
+// that will be affected by a record delete.
+type RecordDeleteImpact struct {
+	// Deleted is a list with the records that will be cascade deleted.
+	Deleted []*RecordDeleteImpactItem `json:"deleted"`
+
+	// Updated is a list with the records whose relation field value will be updated.
+	Updated []*RecordDeleteImpactItem `json:"updated"`
+
+	// Blocked is a list with the records that will prevent the delete
+	// (eg. because of a restricted on delete action or required relation).
+	Blocked []*RecordDeleteImpactItem `json:"blocked"`
+
+	// Hidden holds the number of the affected records that are excluded
+	// from the above lists because the requester is not allowed to view them.
+	Hidden RecordDeleteImpactHidden `json:"hidden"`
+
+	// Allowed indicates whether the record could be deleted (aka. there are no blocked records).
+	Allowed bool `json:"allowed"`
+}
+
+// RecordDeleteImpactHidden holds the number of the hidden affected records per impact list.
+type RecordDeleteImpactHidden struct {
+	Deleted int `json:"deleted"`
+	Updated int `json:"updated"`
+	Blocked int `json:"blocked"`
+}
+
+// FindRecordDeleteImpact returns information about the records
+// that will be affected by the provided record delete
+// (including the nested cascade deletes) without making any changes.
+//
+// Note that the result reflects only the relation fields on delete actions
+// and doesn't include changes made by custom delete hooks.
+func (app *BaseApp) FindRecordDeleteImpact(record *Record) (*RecordDeleteImpact, error) {
+	impact := &RecordDeleteImpact{
+		Deleted: []*RecordDeleteImpactItem{},
+		Updated: []*RecordDeleteImpactItem{},
+		Blocked: []*RecordDeleteImpactItem{},
+	}
+
+	deleted := map[string]struct{}{recordImpactKey(record): {}}
+	updated := map[string]struct{}{}
+	remaining := map[string][]string{} // the remaining relation ids of the updated records
+
+	queue := []*Record{record}
+
+	for len(queue) > 0 {
+		mainRecord := queue[0]
+		queue = queue[1:]
+
+		refs, err := app.FindCachedCollectionReferences(mainRecord.Collection())
+		if err != nil {
+			return nil, err
+		}
+
+		// sort the refs keys to ensure the same order as in cascadeRecordDelete
+		sortedRefKeys := make([]*Collection, 0, len(refs))
+		for k := range refs {
+			sortedRefKeys = append(sortedRefKeys, k)
+		}
+		sort.Slice(sortedRefKeys, func(i, j int) bool {
+			return sortedRefKeys[i].Name < sortedRefKeys[j].Name
+		})
+
+		for _, refCollection := range sortedRefKeys {
+			if refCollection.IsView() {
+				continue
+			}
+
+			for _, field := range refs[refCollection] {
+				relField, _ := field.(*RelationField)
+				if relField == nil {
+					continue
+				}
+
+				refRecords := []*Record{}
+				err := recordReferencesQuery(app, mainRecord, refCollection, relField).All(&refRecords)
+				if err != nil {
+					return nil, err
+				}
+
+				action := relField.OnDeleteAction()
+
+				for _, refRecord := range refRecords {
+					key := recordImpactKey(refRecord)
+					if _, ok := deleted[key]; ok {
+						continue // already deleted
+					}
+
+					item := &RecordDeleteImpactItem{
+						CollectionId:   refCollection.Id,
+						CollectionName: refCollection.Name,
+						RecordId:       refRecord.Id,
+						Field:          relField.Name,
+						Action:         action,
+					}
+
+					if action == RelationOnDeleteRestrict {
+						impact.Blocked = append(impact.Blocked, item)
+						continue
+					}
+
+					fieldKey := key + "." + relField.Name
+
+					ids, ok := remaining[fieldKey]
+					if !ok {
+						ids = refRecord.GetStringSlice(relField.Name)
+					}
+					for i := len(ids) - 1; i >= 0; i-- {
+						if ids[i] == mainRecord.Id {
+							ids = append(ids[:i], ids[i+1:]...)
+							break
+						}
+					}
+					remaining[fieldKey] = ids
+
+					if len(ids) == 0 {
+						if action == RelationOnDeleteCascade {
+							deleted[key] = struct{}{}
+							impact.Deleted = append(impact.Deleted, item)
+							queue = append(queue, refRecord)
+							continue
+						}
+
+						if relField.Required {
+							impact.Blocked = append(impact.Blocked, item)
+							continue
+						}
+					}
+
+					if _, ok := updated[fieldKey]; !ok {
+						updated[fieldKey] = struct{}{}
+						impact.Updated = append(impact.Updated, item)
+					}
+				}
+			}
+		}
+	}
+
+	// exclude the updated records that will be deleted
+	filtered := impact.Updated[:0]
+	for _, item := range impact.Updated {
+		if _, ok := deleted[item.CollectionId+"/"+item.RecordId]; !ok {
+			filtered = append(filtered, item)
+		}
+	}
+	impact.Updated = filtered
+
+	impact.Allowed = len(impact.Blocked) == 0
+
+	return impact, nil
+}
+
+func recordImpactKey(record *Record) string {
+	return record.Collection().Id + "/" + record.Id
+}
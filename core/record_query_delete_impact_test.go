@@ -0,0 +1,83 @@
+package core_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFindRecordDeleteImpact(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		collection string
+		id         string
+		expected   []string
+	}{
+		{
+			"demo3",
+			"7nwo8tuiatetxdm",
+			[]string{
+				`"deleted":[{"collectionId":"4d1blo5cuycfaca","collectionName":"demo4","recordId":"qzaqccwrmva4o1n","field":"rel_one_cascade","action":"cascade"}]`,
+				`"updated":[{"collectionId":"4d1blo5cuycfaca","collectionName":"demo4","recordId":"i9naidtvr6qsgb4","field":"self_rel_one","action":"setNull"},{"collectionId":"9n89pl5vkct6330","collectionName":"demo5","recordId":"qjeql998mtp1azp","field":"rel_many","action":"setNull"}]`,
+				`"blocked":[{"collectionId":"4d1blo5cuycfaca","collectionName":"demo4","recordId":"i9naidtvr6qsgb4","field":"rel_many_no_cascade_required","action":"setNull"}]`,
+				`"allowed":false`,
+			},
+		},
+		{
+			"demo3",
+			"1tmknxy2868d869",
+			[]string{
+				`"deleted":[]`,
+				`"updated":[{"collectionId":"4d1blo5cuycfaca","collectionName":"demo4","recordId":"qzaqccwrmva4o1n","field":"rel_many_no_cascade","action":"setNull"},{"collectionId":"4d1blo5cuycfaca","collectionName":"demo4","recordId":"qzaqccwrmva4o1n","field":"rel_many_no_cascade_required","action":"setNull"}]`,
+				`"blocked":[]`,
+				`"allowed":true`,
+			},
+		},
+		{
+			"users",
+			"oap640cot4yru2s",
+			[]string{
+				`"deleted":[{"collectionId":"wsmn24bux7wo113","collectionName":"demo1","recordId":"84nmscqy84lsi1t","field":"rel_many","action":"cascade"}]`,
+				`"blocked":[]`,
+				`"allowed":true`,
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.collection+"_"+s.id, func(t *testing.T) {
+			record, err := app.FindRecordById(s.collection, s.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			impact, err := app.FindRecordDeleteImpact(record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := json.Marshal(impact)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rawStr := string(raw)
+
+			for _, str := range s.expected {
+				if !strings.Contains(rawStr, str) {
+					t.Fatalf("Expected\n%s\nin\n%s", str, rawStr)
+				}
+			}
+
+			// ensure that nothing was deleted
+			if _, err := app.FindRecordById(s.collection, s.id); err != nil {
+				t.Fatalf("Expected the record to still exist, got %v", err)
+			}
+		})
+	}
+}
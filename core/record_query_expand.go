@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/pocketbase/dbx"
@@ -107,6 +108,11 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 				From(indirectRel.Name).
 				Limit(1000) // the limit is arbitrary chosen and may change in the future
 
+			// preserve the junction links order
+			if indirectRelField.Name == JunctionFieldSource && strings.HasPrefix(indirectRel.Id, junctionCollectionIdPrefix) {
+				q.OrderBy("[["+JunctionFieldPosition+"]] ASC", "[[id]] ASC")
+			}
+
 			if indirectRelField.IsMultiple() {
 				q.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
 					"SELECT 1 FROM %s je WHERE je.value = {:id}",
@@ -148,6 +154,11 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 		}
 		relCollection = indirectRel
 	} else {
+		// polymorphic relation
+		if polyField, ok := mainCollection.Fields.GetByName(parts[0]).(*PolyRelationField); ok {
+			return app.expandPolyRelation(records, polyField, parts, fetchFunc, recursionLevel)
+		}
+
 		// direct relation
 		relField, _ = mainCollection.Fields.GetByName(parts[0]).(*RelationField)
 		if relField == nil {
@@ -246,6 +257,112 @@ func (app *BaseApp) expandRecords(records []*Record, expandPath string, fetchFun
 	return nil
 }
 
+// expandPolyRelation expands the references of a polymorphic relation field
+// by fetching the records of each referenced collection separately
+// (aka. each record is resolved with its own collection fetchFunc rules).
+//
+// Nested expands are applied only to the referenced collections that support them.
+func (app *BaseApp) expandPolyRelation(records []*Record, field *PolyRelationField, parts []string, fetchFunc ExpandFetchFunc, recursionLevel int) error {
+	// group the record ids by their collection
+	var collectionIds []string
+	grouped := map[string][]string{}
+	for _, record := range records {
+		for _, ref := range ParsePolyRefs(record.GetRaw(field.Name)) {
+			if !slices.Contains(field.CollectionIds, ref.CollectionId) {
+				continue // no longer allowed
+			}
+			if _, ok := grouped[ref.CollectionId]; !ok {
+				collectionIds = append(collectionIds, ref.CollectionId)
+			}
+			grouped[ref.CollectionId] = append(grouped[ref.CollectionId], ref.RecordId)
+		}
+	}
+
+	// fetch rels
+	// (errors are reported only if none of the referenced collections could be expanded,
+	// eg. to allow skipping the collections whose records are not accessible by the fetchFunc)
+	var errs []error
+	indexedRels := map[PolyRef]*Record{}
+	for _, collectionId := range collectionIds {
+		relCollection, err := getCollectionByModelOrIdentifier(app, collectionId)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		rels, err := fetchFunc(relCollection, grouped[collectionId])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// expand nested fields
+		if len(parts) > 1 && len(rels) > 0 {
+			if err := app.expandRecords(rels, parts[1], fetchFunc, recursionLevel+1); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		for _, rel := range rels {
+			indexedRels[PolyRef{CollectionId: collectionId, RecordId: rel.Id}] = rel
+		}
+	}
+
+	if len(errs) > 0 && len(errs) == len(collectionIds) {
+		return errors.Join(errs...)
+	}
+
+	for _, model := range records {
+		// init expand if not already
+		// (this is done to ensure that the "expand" key will be returned in the response even if empty)
+		if model.expand == nil {
+			model.SetExpand(nil)
+		}
+
+		refs := ParsePolyRefs(model.GetRaw(field.Name))
+
+		validRels := make([]*Record, 0, len(refs))
+		for _, ref := range refs {
+			if rel, ok := indexedRels[ref]; ok {
+				validRels = append(validRels, rel)
+			}
+		}
+
+		if len(validRels) == 0 {
+			continue // no valid relations
+		}
+
+		expandData := model.Expand()
+
+		// merge with the previously expanded rel records (if any)
+		var oldExpandedRels []*Record
+		switch v := expandData[field.Name].(type) {
+		case *Record:
+			oldExpandedRels = []*Record{v}
+		case []*Record:
+			oldExpandedRels = v
+		}
+		for _, oldExpandedRel := range oldExpandedRels {
+			for _, rel := range validRels {
+				if rel.Id == oldExpandedRel.Id && rel.Collection().Id == oldExpandedRel.Collection().Id {
+					rel.MergeExpand(oldExpandedRel.Expand())
+				}
+			}
+		}
+
+		// update the expanded data
+		if field.IsMultiple() {
+			expandData[field.Name] = validRels
+		} else {
+			expandData[field.Name] = validRels[0]
+		}
+
+		model.SetExpand(expandData)
+	}
+
+	return nil
+}
+
 // normalizeExpands normalizes expand strings and merges self containing paths
 // (eg. ["a.b.c", "a.b", "   test  ", "  ", "test"] -> ["a.b.c", "test"]).
 func normalizeExpands(paths []string) []string {
@@ -729,6 +729,106 @@ func TestFindRecordsByFilter(t *testing.T) {
 	}
 }
 
+func TestFindRecordsByCursor(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name               string
+		collectionIdOrName string
+		filter             string
+		sort               string
+		limit              int
+		params             []dbx.Params
+		expectError        bool
+	}{
+		{"missing collection", "missing", "", "", 1, nil, true},
+		{"invalid filter", "demo2", "someMissingField > 1", "", 1, nil, true},
+		{"random sort", "demo2", "", "@random", 1, nil, true},
+		{"default sort", "demo2", "", "", 1, nil, false},
+		{"multiple sort fields", "demo2", "", "-active,title", 2, nil, false},
+		{"sort with id", "demo2", "", "-id", 1, nil, false},
+		{"filter with params", "demo2", "active = {:active}", "-created", 1, []dbx.Params{{"active": true}}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var cursor string
+			var ids []string
+			for i := 0; ; i++ {
+				records, next, err := app.FindRecordsByCursor(
+					s.collectionIdOrName,
+					s.filter,
+					s.sort,
+					s.limit,
+					cursor,
+					s.params...,
+				)
+
+				hasErr := err != nil
+				if hasErr != s.expectError {
+					t.Fatalf("Expected hasErr to be %v, got %v (%v)", s.expectError, hasErr, err)
+				}
+
+				if hasErr {
+					return
+				}
+
+				if len(records) > s.limit {
+					t.Fatalf("[page %d] Expected at most %d records, got %d", i, s.limit, len(records))
+				}
+
+				for _, r := range records {
+					ids = append(ids, r.Id)
+				}
+
+				if next == "" {
+					break
+				}
+
+				if i > 10 {
+					t.Fatalf("Too many pages: %v", ids)
+				}
+
+				cursor = next
+			}
+
+			sort := s.sort
+			if !strings.Contains(sort, "id") {
+				sort = strings.TrimPrefix(sort+",id", ",")
+			}
+
+			expected, err := app.FindRecordsByFilter(s.collectionIdOrName, s.filter, sort, 0, 0, s.params...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedIds := make([]string, len(expected))
+			for i, r := range expected {
+				expectedIds[i] = r.Id
+			}
+
+			if len(expectedIds) == 0 || !slices.Equal(ids, expectedIds) {
+				t.Fatalf("Expected ids\n%v\ngot\n%v", expectedIds, ids)
+			}
+		})
+	}
+
+	t.Run("mismatched cursor", func(t *testing.T) {
+		_, next, err := app.FindRecordsByCursor("demo2", "", "title", 1, "")
+		if err != nil || next == "" {
+			t.Fatalf("Expected next cursor, got %q (%v)", next, err)
+		}
+
+		_, _, err = app.FindRecordsByCursor("demo2", "", "-title", 1, next)
+		if err == nil {
+			t.Fatal("Expected mismatched cursor error")
+		}
+	})
+}
+
 func TestFindFirstRecordByFilter(t *testing.T) {
 	t.Parallel()
 
@@ -123,6 +123,7 @@ type settings struct {
 	SMTP         SMTPConfig         `form:"smtp" json:"smtp"`
 	Backups      BackupsConfig      `form:"backups" json:"backups"`
 	S3           S3Config           `form:"s3" json:"s3"`
+	Storage      StorageConfig      `form:"storage" json:"storage"`
 	Meta         MetaConfig         `form:"meta" json:"meta"`
 	RateLimits   RateLimitsConfig   `form:"rateLimits" json:"rateLimits"`
 	TrustedProxy TrustedProxyConfig `form:"trustedProxy" json:"trustedProxy"`
@@ -148,6 +149,7 @@ func newDefaultSettings() *Settings {
 				HideControls:  false,
 				SenderName:    "Support",
 				SenderAddress: "support@example.com",
+				CursorKey:     security.RandomString(50),
 			},
 			Logs: LogsConfig{
 				MaxDays: 5,
@@ -164,6 +166,9 @@ func newDefaultSettings() *Settings {
 			Backups: BackupsConfig{
 				CronMaxKeep: 3,
 			},
+			Storage: StorageConfig{
+				FileSigningKey: security.RandomString(50),
+			},
 			Batch: BatchConfig{
 				Enabled:     false,
 				MaxRequests: 50,
@@ -284,6 +289,7 @@ func (s *Settings) PostValidate(ctx context.Context, app App) error {
 		validation.Field(&s.SMTP),
 		validation.Field(&s.S3),
 		validation.Field(&s.Backups),
+		validation.Field(&s.Storage),
 		validation.Field(&s.Batch),
 		validation.Field(&s.RateLimits),
 		validation.Field(&s.TrustedProxy),
@@ -331,6 +337,8 @@ func (s *Settings) MarshalJSON() ([]byte, error) {
 		&copy.SMTP.Password,
 		&copy.S3.Secret,
 		&copy.Backups.S3.Secret,
+		&copy.Storage.FileSigningKey,
+		&copy.Meta.CursorKey,
 	}
 
 	// mask all sensitive fields
@@ -472,6 +480,136 @@ func (c BackupsConfig) Validate() error {
 	)
 }
 
+// -------------------------------------------------------------------
+
+type StorageConfig struct {
+	// OrphansCron is a cron expression to schedule the deletion of the
+	// storage files that are not referenced by any record, eg. "0 3 * * *".
+	//
+	// Leave it empty to disable the orphaned files cleanup.
+	OrphansCron string `form:"orphansCron" json:"orphansCron"`
+
+	// Quotas is an optional list with the max total size of the
+	// files stored per collection and/or per collection records owner.
+	Quotas []StorageQuota `form:"quotas" json:"quotas"`
+
+	// MaxThumbsPerFile is the max number of stored thumbs per file.
+	//
+	// When exceeded, the stale and least recently used thumbs of the file are deleted.
+	// Set it to 0 for no limit.
+	MaxThumbsPerFile int `form:"maxThumbsPerFile" json:"maxThumbsPerFile"`
+
+	// FileSigningKey is the secret key used to sign the expiring file URLs
+	// (see [App.NewSignedFileURL]).
+	//
+	// Changing the key revokes all previously generated signed file URLs.
+	// Leave it empty to disable the signed file URLs.
+	FileSigningKey string `form:"fileSigningKey" json:"fileSigningKey,omitempty"`
+}
+
+// CollectionQuotas returns the storage quotas applicable to the specified collection.
+func (c *StorageConfig) CollectionQuotas(collection *Collection) []StorageQuota {
+	var result []StorageQuota
+
+	for _, q := range c.Quotas {
+		if q.Collection == collection.Id || q.Collection == collection.Name {
+			result = append(result, q)
+		}
+	}
+
+	return result
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c StorageConfig) MarshalJSON() ([]byte, error) {
+	type alias StorageConfig
+
+	// serialize as empty array
+	if c.Quotas == nil {
+		c.Quotas = []StorageQuota{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// Validate makes StorageConfig validatable by implementing [validation.Validatable] interface.
+func (c StorageConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.OrphansCron, validation.By(checkCronExpression)),
+		validation.Field(&c.Quotas, validation.By(checkUniqueQuotaOwner)),
+		validation.Field(&c.MaxThumbsPerFile, validation.Min(0)),
+		validation.Field(&c.FileSigningKey, validation.Length(30, 255)),
+	)
+}
+
+// checkUniqueQuotaOwner ensures that there are no duplicated quotas and
+// that all owner quotas of a single collection use the same owner field.
+func checkUniqueQuotaOwner(value any) error {
+	quotas, ok := value.([]StorageQuota)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	owners := make(map[string]string, len(quotas))
+	existing := make(map[string]struct{}, len(quotas))
+
+	for i, quota := range quotas {
+		key := quota.Collection + "@@" + quota.OwnerField
+		if _, ok := existing[key]; ok {
+			return validation.Errors{
+				strconv.Itoa(i): validation.Errors{
+					"collection": validation.NewError("validation_duplicated_storage_quota", "Storage quota for collection {{.collection}} already exists.").
+						SetParams(map[string]any{"collection": quota.Collection}),
+				},
+			}
+		}
+		existing[key] = struct{}{}
+
+		if quota.OwnerField == "" {
+			continue
+		}
+
+		if owner, ok := owners[quota.Collection]; ok && owner != quota.OwnerField {
+			return validation.Errors{
+				strconv.Itoa(i): validation.Errors{
+					"ownerField": validation.NewError("validation_conflicting_storage_quota_owner", "Storage quotas of the same collection must have the same owner field.").
+						SetParams(map[string]any{"collection": quota.Collection}),
+				},
+			}
+		}
+		owners[quota.Collection] = quota.OwnerField
+	}
+
+	return nil
+}
+
+// StorageQuota defines a single collection files storage limit.
+type StorageQuota struct {
+	// Collection is the name or id of the collection whose record files are limited.
+	Collection string `form:"collection" json:"collection"`
+
+	// OwnerField is an optional name of a single relation field identifying
+	// the owner of the collection record files (eg. "user").
+	//
+	// For auth collections it could be also set to "id" to limit
+	// the files of each auth record itself (eg. avatars).
+	//
+	// If empty, the quota applies to the total size of all collection files.
+	OwnerField string `form:"ownerField" json:"ownerField"`
+
+	// MaxSize is the max total size (in bytes) of the stored files.
+	MaxSize int64 `form:"maxSize" json:"maxSize"`
+}
+
+// Validate makes StorageQuota validatable by implementing [validation.Validatable] interface.
+func (c StorageQuota) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Collection, validation.Required, validation.Length(1, 255)),
+		validation.Field(&c.OwnerField, validation.Length(0, 255)),
+		validation.Field(&c.MaxSize, validation.Required, validation.Min(int64(1))),
+	)
+}
+
 func checkCronExpression(value any) error {
 	v, _ := value.(string)
 	if v == "" {
@@ -494,6 +632,14 @@ type MetaConfig struct {
 	SenderName    string `form:"senderName" json:"senderName"`
 	SenderAddress string `form:"senderAddress" json:"senderAddress"`
 	HideControls  bool   `form:"hideControls" json:"hideControls"`
+
+	// CursorKey is the secret key used to encrypt the records list
+	// pagination cursors (see [search.Provider.CursorKey]).
+	//
+	// Changing the key invalidates all previously issued cursors.
+	// If empty, a random per process key is used, aka. the issued
+	// cursors become invalid after restart or on another app instance.
+	CursorKey string `form:"cursorKey" json:"cursorKey,omitempty"`
 }
 
 // Validate makes MetaConfig validatable by implementing [validation.Validatable] interface.
@@ -503,6 +649,7 @@ func (c MetaConfig) Validate() error {
 		validation.Field(&c.AppURL, validation.Required, is.URL),
 		validation.Field(&c.SenderName, validation.Required, validation.Length(1, 255)),
 		validation.Field(&c.SenderAddress, is.EmailFormat, validation.Required),
+		validation.Field(&c.CursorKey, validation.Length(30, 255)),
 	)
 }
 
@@ -106,7 +106,7 @@ func TestSettingsMarshalJSON(t *testing.T) {
 	}
 	rawStr := string(raw)
 
-	expected := `{"smtp":{"enabled":false,"port":0,"host":"","username":"abc","authMethod":"","tls":false,"localName":""},"backups":{"cron":"","cronMaxKeep":0,"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false},"meta":{"appName":"test123","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"enabled":false},"trustedProxy":{"headers":[],"useLeftmostIP":false},"batch":{"enabled":false,"maxRequests":0,"timeout":0,"maxBodySize":0},"logs":{"maxDays":0,"minLevel":0,"logIP":false,"logAuthId":false}}`
+	expected := `{"smtp":{"enabled":false,"port":0,"host":"","username":"abc","authMethod":"","tls":false,"localName":""},"backups":{"cron":"","cronMaxKeep":0,"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false},"storage":{"orphansCron":"","quotas":[],"maxThumbsPerFile":0},"meta":{"appName":"test123","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"enabled":false},"trustedProxy":{"headers":[],"useLeftmostIP":false},"batch":{"enabled":false,"maxRequests":0,"timeout":0,"maxBodySize":0},"logs":{"maxDays":0,"minLevel":0,"logIP":false,"logAuthId":false}}`
 
 	if rawStr != expected {
 		t.Fatalf("Expected\n%v\ngot\n%v", expected, rawStr)
@@ -130,6 +130,7 @@ func TestSettingsValidate(t *testing.T) {
 	s.S3.Endpoint = "invalid"
 	s.Backups.Cron = "invalid"
 	s.Backups.CronMaxKeep = -10
+	s.Storage.OrphansCron = "invalid"
 	s.Batch.Enabled = true
 	s.Batch.MaxRequests = -1
 	s.Batch.Timeout = -1
@@ -148,6 +149,7 @@ func TestSettingsValidate(t *testing.T) {
 		`"smtp":{`,
 		`"s3":{`,
 		`"backups":{`,
+		`"storage":{`,
 		`"batch":{`,
 		`"rateLimits":{`,
 	}
@@ -430,6 +432,140 @@ func TestBackupsConfigValidate(t *testing.T) {
 	}
 }
 
+func TestStorageConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.StorageConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value",
+			core.StorageConfig{},
+			[]string{},
+		},
+		{
+			"invalid cron",
+			core.StorageConfig{OrphansCron: "invalid"},
+			[]string{"orphansCron"},
+		},
+		{
+			"negative max thumbs per file",
+			core.StorageConfig{MaxThumbsPerFile: -1},
+			[]string{"maxThumbsPerFile"},
+		},
+		{
+			"too short file signing key",
+			core.StorageConfig{FileSigningKey: "abc"},
+			[]string{"fileSigningKey"},
+		},
+		{
+			"invalid quota",
+			core.StorageConfig{Quotas: []core.StorageQuota{{Collection: "demo1"}}},
+			[]string{"quotas"},
+		},
+		{
+			"duplicated quotas",
+			core.StorageConfig{Quotas: []core.StorageQuota{
+				{Collection: "demo1", MaxSize: 1},
+				{Collection: "demo1", MaxSize: 2},
+			}},
+			[]string{"quotas"},
+		},
+		{
+			"conflicting quota owner fields",
+			core.StorageConfig{Quotas: []core.StorageQuota{
+				{Collection: "demo1", OwnerField: "rel_one", MaxSize: 1},
+				{Collection: "demo1", OwnerField: "rel_many", MaxSize: 1},
+			}},
+			[]string{"quotas"},
+		},
+		{
+			"valid data",
+			core.StorageConfig{
+				OrphansCron:    "0 3 * * *",
+				FileSigningKey: strings.Repeat("a", 30),
+				Quotas: []core.StorageQuota{
+					{Collection: "demo1", MaxSize: 100},
+					{Collection: "demo1", OwnerField: "rel_one", MaxSize: 10},
+					{Collection: "demo2", OwnerField: "rel_one", MaxSize: 10},
+				},
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestStorageConfigCollectionQuotas(t *testing.T) {
+	collection := core.NewBaseCollection("test")
+	collection.Id = "test_id"
+
+	config := core.StorageConfig{
+		Quotas: []core.StorageQuota{
+			{Collection: "test", MaxSize: 1},
+			{Collection: "other", MaxSize: 2},
+			{Collection: "test_id", OwnerField: "user", MaxSize: 3},
+		},
+	}
+
+	quotas := config.CollectionQuotas(collection)
+
+	if len(quotas) != 2 {
+		t.Fatalf("Expected 2 quotas, got %d", len(quotas))
+	}
+
+	if quotas[0].MaxSize != 1 || quotas[1].MaxSize != 3 {
+		t.Fatalf("Expected quotas with max size 1 and 3, got %v", quotas)
+	}
+}
+
+func TestStorageQuotaValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		quota          core.StorageQuota
+		expectedErrors []string
+	}{
+		{
+			"zero value",
+			core.StorageQuota{},
+			[]string{"collection", "maxSize"},
+		},
+		{
+			"invalid data",
+			core.StorageQuota{
+				Collection: strings.Repeat("a", 256),
+				OwnerField: strings.Repeat("a", 256),
+				MaxSize:    -1,
+			},
+			[]string{"collection", "ownerField", "maxSize"},
+		},
+		{
+			"valid data",
+			core.StorageQuota{
+				Collection: "demo1",
+				OwnerField: "rel_one",
+				MaxSize:    1,
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.quota.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
 func TestBatchConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -26,6 +26,10 @@ func (m *Param) TableName() string {
 // ReloadSettings initializes and reloads the stored application settings.
 //
 // If no settings were stored it will persist the current app ones.
+//
+// The randomly generated settings secrets that are missing from the
+// stored settings (eg. the ones introduced after the settings creation)
+// are also persisted to keep them stable between the app restarts.
 func (app *BaseApp) ReloadSettings() error {
 	param := &Param{}
 	err := app.ModelQuery(param).Model(paramsKeySettings, param)
@@ -44,17 +48,82 @@ func (app *BaseApp) ReloadSettings() error {
 	event := new(SettingsReloadEvent)
 	event.App = app
 
-	return app.OnSettingsReload().Trigger(event, func(e *SettingsReloadEvent) error {
-		return e.App.Settings().loadParam(e.App, param)
+	var hasMissingSecrets bool
+
+	err = app.OnSettingsReload().Trigger(event, func(e *SettingsReloadEvent) error {
+		var loadErr error
+		hasMissingSecrets, loadErr = e.App.Settings().loadParam(e.App, param)
+		return loadErr
 	})
+	if err != nil {
+		return err
+	}
+
+	// persist the newly generated secrets
+	// (ReloadSettings() will be invoked again by a system hook after successful save)
+	if hasMissingSecrets {
+		return app.SaveNoValidate(app.Settings())
+	}
+
+	return nil
 }
 
 // loadParam loads the settings from the stored param into the app ones.
 //
+// It returns true if some of the generated secrets were missing
+// from the stored settings and new ones were generated in their place.
+//
 // @todo note that the encryption may get removed in the future since it doesn't
 // really accomplish much and it might be better to find a way to encrypt the backups
 // or implement support for resolving env variables.
-func (s *Settings) loadParam(app App, param *Param) error {
+func (s *Settings) loadParam(app App, param *Param) (bool, error) {
+	// reset the generated secrets to detect the missing ones
+	// (the old values are restored on load failure)
+	s.mu.Lock()
+	secrets := s.generatedSecrets()
+	oldSecrets := make([]string, len(secrets))
+	for i, secret := range secrets {
+		oldSecrets[i] = *secret
+		*secret = ""
+	}
+	s.mu.Unlock()
+
+	if err := s.decodeParam(app, param); err != nil {
+		s.mu.Lock()
+		for i, secret := range secrets {
+			*secret = oldSecrets[i]
+		}
+		s.mu.Unlock()
+
+		return false, err
+	}
+
+	var hasMissing bool
+
+	s.mu.Lock()
+	for _, secret := range secrets {
+		if *secret == "" {
+			*secret = security.RandomString(50)
+			hasMissing = true
+		}
+	}
+	s.mu.Unlock()
+
+	return hasMissing, s.PostScan()
+}
+
+// generatedSecrets returns pointers to the settings secrets that are
+// randomly generated with the default settings.
+//
+// note: the caller is expected to handle the settings locking.
+func (s *Settings) generatedSecrets() []*string {
+	return []*string{
+		&s.Meta.CursorKey,
+		&s.Storage.FileSigningKey,
+	}
+}
+
+func (s *Settings) decodeParam(app App, param *Param) error {
 	// try first without decryption
 	s.mu.Lock()
 	plainDecodeErr := json.Unmarshal(param.Value, s)
@@ -84,5 +153,5 @@ func (s *Settings) loadParam(app App, param *Param) error {
 		}
 	}
 
-	return s.PostScan()
+	return nil
 }
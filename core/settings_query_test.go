@@ -157,3 +157,141 @@ func testEventCalls(t *testing.T, app *tests.TestApp, events map[string]int) {
 		}
 	}
 }
+
+func TestFileSigningKeyPersistence(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	key := app.Settings().Storage.FileSigningKey
+	if key == "" {
+		t.Fatal("Expected non-empty default file signing key")
+	}
+
+	// simulate app restart
+	restartedApp := core.NewBaseApp(core.BaseAppConfig{
+		DataDir:       app.DataDir(),
+		EncryptionEnv: app.EncryptionEnv(),
+	})
+	defer restartedApp.ResetBootstrapState()
+
+	if err := restartedApp.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := restartedApp.Settings().Storage.FileSigningKey; v != key {
+		t.Fatalf("Expected file signing key %q after restart, got %q", key, v)
+	}
+}
+
+func TestCursorKeyPersistence(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	key := app.Settings().Meta.CursorKey
+	if key == "" {
+		t.Fatal("Expected non-empty default cursor key")
+	}
+
+	_, cursor, err := app.FindRecordsByCursor("demo2", "", "id", 2, "")
+	if err != nil || cursor == "" {
+		t.Fatalf("Failed to generate test cursor: %v", err)
+	}
+
+	// simulate app restart
+	restartedApp := core.NewBaseApp(core.BaseAppConfig{
+		DataDir:       app.DataDir(),
+		EncryptionEnv: app.EncryptionEnv(),
+	})
+	defer restartedApp.ResetBootstrapState()
+
+	if err := restartedApp.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := restartedApp.Settings().Meta.CursorKey; v != key {
+		t.Fatalf("Expected cursor key %q after restart, got %q", key, v)
+	}
+
+	records, _, err := restartedApp.FindRecordsByCursor("demo2", "", "id", 2, cursor)
+	if err != nil {
+		t.Fatalf("Expected the cursor to remain valid after restart, got %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+
+	// change the key
+	restartedApp.Settings().Meta.CursorKey = key + "_new"
+	if _, _, err := restartedApp.FindRecordsByCursor("demo2", "", "id", 2, cursor); err == nil {
+		t.Fatal("Expected the cursor to be invalidated after the key change")
+	}
+}
+
+func TestReloadSettingsMissingGeneratedSecrets(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// ensure that the settings are stored unencrypted
+	t.Setenv("pb_test_env", "")
+	if err := app.Save(app.Settings()); err != nil {
+		t.Fatalf("Failed to save the settings: %v", err)
+	}
+
+	// strip the generated secrets from the stored settings
+	_, err := app.DB().NewQuery(
+		"UPDATE _params SET value = json_remove(CAST(value AS TEXT), '$.meta.cursorKey', '$.storage.fileSigningKey') WHERE id = {:id}",
+	).Bind(map[string]any{"id": "settings"}).Execute()
+	if err != nil {
+		t.Fatalf("Failed to strip the stored secrets: %v", err)
+	}
+
+	oldKey := app.Settings().Storage.FileSigningKey
+	oldCursorKey := app.Settings().Meta.CursorKey
+
+	if err := app.ReloadSettings(); err != nil {
+		t.Fatalf("Failed to reload the settings: %v", err)
+	}
+
+	key := app.Settings().Storage.FileSigningKey
+	if key == "" || key == oldKey {
+		t.Fatalf("Expected a newly generated file signing key, got %q (old %q)", key, oldKey)
+	}
+
+	cursorKey := app.Settings().Meta.CursorKey
+	if cursorKey == "" || cursorKey == oldCursorKey {
+		t.Fatalf("Expected a newly generated cursor key, got %q (old %q)", cursorKey, oldCursorKey)
+	}
+
+	var storedKey string
+	err = app.DB().NewQuery(
+		"SELECT json_extract(CAST(value AS TEXT), '$.storage.fileSigningKey') FROM _params WHERE id = {:id}",
+	).Bind(map[string]any{"id": "settings"}).Row(&storedKey)
+	if err != nil {
+		t.Fatalf("Failed to load the stored file signing key: %v", err)
+	}
+	if storedKey != key {
+		t.Fatalf("Expected the stored file signing key to be %q, got %q", key, storedKey)
+	}
+
+	var storedCursorKey string
+	err = app.DB().NewQuery(
+		"SELECT json_extract(CAST(value AS TEXT), '$.meta.cursorKey') FROM _params WHERE id = {:id}",
+	).Bind(map[string]any{"id": "settings"}).Row(&storedCursorKey)
+	if err != nil {
+		t.Fatalf("Failed to load the stored cursor key: %v", err)
+	}
+	if storedCursorKey != cursorKey {
+		t.Fatalf("Expected the stored cursor key to be %q, got %q", cursorKey, storedCursorKey)
+	}
+
+	// subsequent reloads should keep the persisted key
+	if err := app.ReloadSettings(); err != nil {
+		t.Fatalf("Failed to reload the settings: %v", err)
+	}
+	if v := app.Settings().Storage.FileSigningKey; v != key {
+		t.Fatalf("Expected file signing key %q after reload, got %q", key, v)
+	}
+	if v := app.Settings().Meta.CursorKey; v != cursorKey {
+		t.Fatalf("Expected cursor key %q after reload, got %q", cursorKey, v)
+	}
+}
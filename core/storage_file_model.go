@@ -0,0 +1,218 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+const CollectionNameStorageFiles = "_storageFiles"
+
+var (
+	_ Model        = (*StorageFile)(nil)
+	_ PreValidator = (*StorageFile)(nil)
+	_ RecordProxy  = (*StorageFile)(nil)
+)
+
+// StorageFile defines a Record proxy for working with the storageFiles collection.
+//
+// A StorageFile tracks the size and owner of a single record file stored
+// in a collection with storage quotas (see [StorageConfig.Quotas]).
+// The related [StorageUsage] counters are updated on StorageFile create, update and delete.
+type StorageFile struct {
+	*Record
+}
+
+// NewStorageFile instantiates and returns a new blank *StorageFile model.
+//
+// Example usage:
+//
+//	file := core.NewStorageFile(app)
+//	file.SetCollectionRef(record.Collection().Id)
+//	file.SetRecordRef(record.Id)
+//	file.SetOwnerRef(record.GetString("user"))
+//	file.SetField("documents")
+//	file.SetFilename("example_52iwbgds7l.pdf")
+//	file.SetSize(1024)
+//	app.Save(file)
+func NewStorageFile(app App) *StorageFile {
+	m := &StorageFile{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameStorageFiles)
+	if err != nil {
+		// this is just to make tests easier since storageFiles is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on StorageFile.PreValidate())
+		c = NewBaseCollection("@___invalid___")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *StorageFile) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameStorageFiles {
+		return errors.New("missing or invalid StorageFile ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *StorageFile) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *StorageFile) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" record field value.
+func (m *StorageFile) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *StorageFile) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *StorageFile) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *StorageFile) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// OwnerRef returns the "ownerRef" record field value.
+func (m *StorageFile) OwnerRef() string {
+	return m.GetString("ownerRef")
+}
+
+// SetOwnerRef updates the "ownerRef" record field value.
+func (m *StorageFile) SetOwnerRef(ownerId string) {
+	m.Set("ownerRef", ownerId)
+}
+
+// Field returns the "field" record field value.
+func (m *StorageFile) Field() string {
+	return m.GetString("field")
+}
+
+// SetField updates the "field" record field value.
+func (m *StorageFile) SetField(field string) {
+	m.Set("field", field)
+}
+
+// Filename returns the "filename" record field value.
+func (m *StorageFile) Filename() string {
+	return m.GetString("filename")
+}
+
+// SetFilename updates the "filename" record field value.
+func (m *StorageFile) SetFilename(filename string) {
+	m.Set("filename", filename)
+}
+
+// Size returns the "size" record field value.
+func (m *StorageFile) Size() int64 {
+	return int64(m.GetFloat("size"))
+}
+
+// SetSize updates the "size" record field value.
+func (m *StorageFile) SetSize(size int64) {
+	m.Set("size", size)
+}
+
+// Created returns the "created" record field value.
+func (m *StorageFile) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *StorageFile) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerStorageFileHooks() {
+	recordRefHooks[*StorageFile](app, CollectionNameStorageFiles)
+
+	// keep the usage counters in sync with the tracked files
+	// (including on record or collection cascade delete)
+	//
+	// note: the stored file state is loaded from the db because the
+	// in-memory Record.Original() may not reflect the last saved state
+	app.OnRecordCreateExecute(CollectionNameStorageFiles).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			return syncStorageUsage(e, false)
+		},
+		Priority: 99,
+	})
+
+	app.OnRecordUpdateExecute(CollectionNameStorageFiles).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			return syncStorageUsage(e, false)
+		},
+		Priority: 99,
+	})
+
+	app.OnRecordDeleteExecute(CollectionNameStorageFiles).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			return syncStorageUsage(e, true)
+		},
+		Priority: 99,
+	})
+}
+
+// syncStorageUsage executes the storage file db write and updates
+// the related usage counters within the same transaction.
+func syncStorageUsage(e *RecordEvent, isDelete bool) error {
+	originalApp := e.App
+	defer func() {
+		e.App = originalApp
+	}()
+
+	return e.App.RunInTransaction(func(txApp App) error {
+		e.App = txApp
+
+		var old *Record
+		if !e.Record.IsNew() {
+			var err error
+			old, err = txApp.FindRecordById(CollectionNameStorageFiles, cast.ToString(e.Record.LastSavedPK()))
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		if old != nil {
+			err := incrementStorageUsage(txApp, old.GetString("collectionRef"), old.GetString("ownerRef"), -1, -int64(old.GetFloat("size")))
+			if err != nil {
+				return fmt.Errorf("failed to decrement the storage usage: %w", err)
+			}
+		}
+
+		if !isDelete {
+			err := incrementStorageUsage(txApp, e.Record.GetString("collectionRef"), e.Record.GetString("ownerRef"), 1, int64(e.Record.GetFloat("size")))
+			if err != nil {
+				return fmt.Errorf("failed to increment the storage usage: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
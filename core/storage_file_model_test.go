@@ -0,0 +1,129 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewStorageFile(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	file := core.NewStorageFile(app)
+
+	if file.Collection().Name != core.CollectionNameStorageFiles {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameStorageFiles, file.Collection().Name)
+	}
+
+	if err := file.PreValidate(context.Background(), app); err != nil {
+		t.Fatalf("Expected no PreValidate error, got %v", err)
+	}
+}
+
+func TestStorageFileFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	file := core.NewStorageFile(app)
+
+	scenarios := []struct {
+		field  string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", file.SetCollectionRef, file.CollectionRef},
+		{"recordRef", file.SetRecordRef, file.RecordRef},
+		{"ownerRef", file.SetOwnerRef, file.OwnerRef},
+		{"field", file.SetField, file.Field},
+		{"filename", file.SetFilename, file.Filename},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.field, func(t *testing.T) {
+			for _, testValue := range []string{"test_1", ""} {
+				s.setter(testValue)
+
+				if v := s.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := file.GetString(s.field); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			}
+		})
+	}
+
+	file.SetSize(123)
+	if v := file.Size(); v != 123 {
+		t.Fatalf("Expected size %d, got %d", 123, v)
+	}
+}
+
+func TestStorageFileUsageCounters(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.FindRecordById(demo1, "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkUsage := func(t *testing.T, ownerId string, expectedFiles int, expectedSize int64) {
+		t.Helper()
+
+		usage, err := app.FindStorageUsage(demo1.Id, ownerId)
+		if err != nil {
+			t.Fatalf("Failed to find the %q usage: %v", ownerId, err)
+		}
+
+		if usage.Files() != expectedFiles || usage.Size() != expectedSize {
+			t.Fatalf("Expected %q usage (%d, %d), got (%d, %d)", ownerId, expectedFiles, expectedSize, usage.Files(), usage.Size())
+		}
+	}
+
+	file := core.NewStorageFile(app)
+	file.SetCollectionRef(demo1.Id)
+	file.SetRecordRef(record.Id)
+	file.SetOwnerRef("owner1")
+	file.SetField("file_many")
+	file.SetFilename("test.txt")
+	file.SetSize(10)
+	if err := app.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	checkUsage(t, "", 1, 10)
+	checkUsage(t, "owner1", 1, 10)
+
+	file.SetOwnerRef("owner2")
+	file.SetSize(5)
+	if err := app.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	checkUsage(t, "", 1, 5)
+	checkUsage(t, "owner1", 0, 0)
+	checkUsage(t, "owner2", 1, 5)
+
+	if err := app.Delete(file); err != nil {
+		t.Fatal(err)
+	}
+
+	checkUsage(t, "", 0, 0)
+	checkUsage(t, "owner2", 0, 0)
+}
@@ -0,0 +1,379 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameStorageUsage = "_storageUsage"
+
+var (
+	_ Model        = (*StorageUsage)(nil)
+	_ PreValidator = (*StorageUsage)(nil)
+	_ RecordProxy  = (*StorageUsage)(nil)
+)
+
+// StorageUsage defines a Record proxy for working with the storageUsage collection.
+//
+// A StorageUsage holds the total number and size of the tracked [StorageFile]
+// models of a single collection (when OwnerRef is empty) or of a single
+// collection records owner.
+//
+// The counters are updated automatically and they are not expected
+// to be modified manually.
+type StorageUsage struct {
+	*Record
+}
+
+// NewStorageUsage instantiates and returns a new blank *StorageUsage model.
+func NewStorageUsage(app App) *StorageUsage {
+	m := &StorageUsage{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameStorageUsage)
+	if err != nil {
+		// this is just to make tests easier since storageUsage is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on StorageUsage.PreValidate())
+		c = NewBaseCollection("@___invalid___")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *StorageUsage) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameStorageUsage {
+		return errors.New("missing or invalid StorageUsage ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *StorageUsage) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *StorageUsage) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" record field value.
+func (m *StorageUsage) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *StorageUsage) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// OwnerRef returns the "ownerRef" record field value.
+func (m *StorageUsage) OwnerRef() string {
+	return m.GetString("ownerRef")
+}
+
+// SetOwnerRef updates the "ownerRef" record field value.
+func (m *StorageUsage) SetOwnerRef(ownerId string) {
+	m.Set("ownerRef", ownerId)
+}
+
+// Files returns the "files" record field value.
+func (m *StorageUsage) Files() int {
+	return m.GetInt("files")
+}
+
+// SetFiles updates the "files" record field value.
+func (m *StorageUsage) SetFiles(files int) {
+	m.Set("files", files)
+}
+
+// Size returns the "size" record field value.
+func (m *StorageUsage) Size() int64 {
+	return int64(m.GetFloat("size"))
+}
+
+// SetSize updates the "size" record field value.
+func (m *StorageUsage) SetSize(size int64) {
+	m.Set("size", size)
+}
+
+// Created returns the "created" record field value.
+func (m *StorageUsage) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *StorageUsage) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// incrementStorageUsage atomically increments (or decrements for negative values)
+// the usage counters of the specified collection and owner (if not empty).
+//
+// Missing counters are created only on increment.
+func incrementStorageUsage(app App, collectionId string, ownerId string, files int, size int64) error {
+	owners := []string{""}
+	if ownerId != "" {
+		owners = append(owners, ownerId)
+	}
+
+	now := types.NowDateTime().String()
+
+	for _, owner := range owners {
+		params := dbx.Params{
+			"id":            GenerateDefaultRandomId(),
+			"collectionRef": collectionId,
+			"ownerRef":      owner,
+			"files":         files,
+			"size":          size,
+			"now":           now,
+		}
+
+		var query *dbx.Query
+		if files >= 0 && size >= 0 {
+			query = app.DB().NewQuery(
+				"INSERT INTO {{" + CollectionNameStorageUsage + "}} ([[id]], [[collectionRef]], [[ownerRef]], [[files]], [[size]], [[created]], [[updated]]) " +
+					"VALUES ({:id}, {:collectionRef}, {:ownerRef}, {:files}, {:size}, {:now}, {:now}) " +
+					"ON CONFLICT ([[collectionRef]], [[ownerRef]]) DO UPDATE SET " +
+					"[[files]] = [[files]] + excluded.[[files]], " +
+					"[[size]] = [[size]] + excluded.[[size]], " +
+					"[[updated]] = excluded.[[updated]]",
+			)
+		} else {
+			query = app.DB().NewQuery(
+				"UPDATE {{" + CollectionNameStorageUsage + "}} SET " +
+					"[[files]] = MAX(0, [[files]] + {:files}), " +
+					"[[size]] = MAX(0, [[size]] + {:size}), " +
+					"[[updated]] = {:now} " +
+					"WHERE [[collectionRef]] = {:collectionRef} AND [[ownerRef]] = {:ownerRef}",
+			)
+		}
+
+		if _, err := query.Bind(params).Execute(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storageQuotaOwner returns the id of the record files owner
+// identified by the specified quota owner field.
+func storageQuotaOwner(record *Record, ownerField string) string {
+	if ownerField == "" {
+		return ""
+	}
+
+	if ownerField == FieldNameId {
+		return record.Id
+	}
+
+	owners := record.GetStringSlice(ownerField)
+	if len(owners) == 0 {
+		return ""
+	}
+
+	return owners[0]
+}
+
+// storageFilesOwner returns the owner id of the tracked files of the specified record
+// (or false if the record collection doesn't have any storage quotas).
+func storageFilesOwner(app App, record *Record) (string, bool) {
+	quotas := app.Settings().Storage.CollectionQuotas(record.Collection())
+	if len(quotas) == 0 {
+		return "", false
+	}
+
+	for _, q := range quotas {
+		if q.OwnerField != "" {
+			return storageQuotaOwner(record, q.OwnerField), true
+		}
+	}
+
+	return "", true
+}
+
+// checkStorageQuotas checks whether saving the new files of the specified
+// record will exceed any of its collection storage quotas.
+//
+// The size of the existing record files that are going to be deleted is
+// subtracted from the current usage.
+//
+// The new files are checked with their upload size (the stored size of
+// the transformed images is known and tracked only after their upload).
+//
+// Note that this is only an early validation check and the quotas are
+// enforced after tracking the new files in the same write transaction
+// as the record save (see [enforceStorageQuotas]).
+func checkStorageQuotas(app App, record *Record) error {
+	var pending int64
+
+	for _, field := range record.Collection().Fields {
+		f, ok := field.(*FileField)
+		if !ok {
+			continue
+		}
+
+		for _, upload := range f.extractUploadableFiles(f.toSliceValue(record.GetRaw(f.Name))) {
+			pending += upload.Size
+		}
+	}
+
+	return checkStorageQuotasUsage(app, record, pending)
+}
+
+// enforceStorageQuotas checks whether the already tracked (aka. incremented)
+// usage of the specified record collection and owner exceeds any of the
+// collection storage quotas.
+//
+// It is expected to be called in the same write transaction as the usage
+// increment so that concurrent uploads cannot exceed the quota
+// (SQLite allows only one writer at a time).
+func enforceStorageQuotas(app App, record *Record) error {
+	return checkStorageQuotasUsage(app, record, 0)
+}
+
+func checkStorageQuotasUsage(app App, record *Record, pending int64) error {
+	quotas := app.Settings().Storage.CollectionQuotas(record.Collection())
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	var freed int64
+
+	if !record.IsNew() {
+		names := map[string][]string{}
+
+		for _, field := range record.Collection().Fields {
+			f, ok := field.(*FileField)
+			if !ok {
+				continue
+			}
+
+			files := f.toSliceValue(record.GetRaw(f.Name))
+
+			names[f.Name] = f.extractPlainStrings(files)
+			for _, upload := range f.extractUploadableFiles(files) {
+				names[f.Name] = append(names[f.Name], upload.Name)
+			}
+		}
+
+		tracked, err := app.FindAllStorageFilesByRecord(record)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range tracked {
+			if !slices.Contains(names[file.Field()], file.Filename()) {
+				freed += file.Size()
+			}
+		}
+	}
+
+	for _, q := range quotas {
+		owner := storageQuotaOwner(record, q.OwnerField)
+		if q.OwnerField != "" && owner == "" {
+			continue // no owner to check
+		}
+
+		var used int64
+		usage, err := app.FindStorageUsage(record.Collection().Id, owner)
+		if err == nil {
+			used = usage.Size()
+		}
+
+		if used-freed+pending > q.MaxSize {
+			return validation.NewError("validation_storage_quota_exceeded", "The storage quota of {{.maxSize}} bytes is exceeded.").
+				SetParams(map[string]any{"maxSize": q.MaxSize})
+		}
+	}
+
+	return nil
+}
+
+func (app *BaseApp) registerStorageUsageHooks() {
+	// execute the record save, the new files tracking and the storage quotas
+	// enforcement in a single transaction (see [FileField.Intercept])
+	wrapInTransaction := func(e *RecordEvent) error {
+		if e.App.IsTransactional() || len(e.App.Settings().Storage.CollectionQuotas(e.Record.Collection())) == 0 {
+			return e.Next()
+		}
+
+		originalApp := e.App
+		txErr := e.App.RunInTransaction(func(txApp App) error {
+			e.App = txApp
+			return e.Next()
+		})
+		e.App = originalApp
+
+		return txErr
+	}
+
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     wrapInTransaction,
+		Priority: 98, // before the system record create execute hook
+	})
+
+	app.OnRecordUpdateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func:     wrapInTransaction,
+		Priority: 98, // before the system record update execute hook
+	})
+
+	// move the tracked files to the new owner
+	//
+	// note: the tracked files are always checked because the in-memory
+	// Record.Original() may not reflect the last saved owner
+	app.OnRecordAfterUpdateSuccess().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			owner, ok := storageFilesOwner(e.App, e.Record)
+			if !ok {
+				return e.Next()
+			}
+
+			files, err := e.App.FindAllStorageFilesByRecord(e.Record)
+			if err != nil {
+				e.App.Logger().Warn("Failed to load the record storage files", "error", err, "recordId", e.Record.Id)
+				return e.Next()
+			}
+
+			for _, file := range files {
+				if file.OwnerRef() == owner {
+					continue
+				}
+
+				file.SetOwnerRef(owner)
+
+				if err := e.App.Save(file); err != nil {
+					e.App.Logger().Warn("Failed to update the storage file owner", "error", err, "storageFileId", file.Id)
+				}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	// delete the usage counters of the deleted collection
+	// (its tracked files are deleted by the storageFiles cascade hooks)
+	app.OnCollectionAfterDeleteSuccess().Bind(&hook.Handler[*CollectionEvent]{
+		Func: func(e *CollectionEvent) error {
+			_, err := e.App.DB().Delete(CollectionNameStorageUsage, dbx.HashExp{"collectionRef": e.Collection.Id}).Execute()
+			if err != nil {
+				e.App.Logger().Warn("Failed to delete the collection storage usage", "error", err, "collectionId", e.Collection.Id)
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// FindAllStorageFilesByRecord returns all tracked StorageFile models of the provided record.
+func (app *BaseApp) FindAllStorageFilesByRecord(record *Record) ([]*StorageFile, error) {
+	result := []*StorageFile{}
+
+	err := app.RecordQuery(CollectionNameStorageFiles).
+		AndWhere(dbx.HashExp{
+			"collectionRef": record.Collection().Id,
+			"recordRef":     record.Id,
+		}).
+		OrderBy("created ASC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindStorageUsage returns the StorageUsage model with the files counters
+// of the specified collection owner.
+//
+// Use an empty ownerId to find the total usage of the collection.
+func (app *BaseApp) FindStorageUsage(collectionId string, ownerId string) (*StorageUsage, error) {
+	result := &StorageUsage{}
+
+	err := app.RecordQuery(CollectionNameStorageUsage).
+		AndWhere(dbx.HashExp{
+			"collectionRef": collectionId,
+			"ownerRef":      ownerId,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/plugins/esmvm"
+	"github.com/pocketbase/pocketbase/plugins/filescan"
 	"github.com/pocketbase/pocketbase/plugins/ghupdate"
 	"github.com/pocketbase/pocketbase/plugins/jsvm"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
@@ -88,6 +89,22 @@ func main() {
 		"enable experimental ESM module support using the esmvm plugin (unstable)", // TODO: remove when MVP is completed.
 	)
 
+	var clamdAddress string
+	app.RootCmd.PersistentFlags().StringVar(
+		&clamdAddress,
+		"clamdAddress",
+		"",
+		"scan the new record files with the ClamAV daemon at the specified TCP address or unix socket path",
+	)
+
+	var clamdQuarantine bool
+	app.RootCmd.PersistentFlags().BoolVar(
+		&clamdQuarantine,
+		"clamdQuarantine",
+		false,
+		"quarantine the infected files instead of rejecting the record save",
+	)
+
 	app.RootCmd.ParseFlags(os.Args[1:])
 
 	// ---------------------------------------------------------------
@@ -118,6 +135,19 @@ func main() {
 		Dir:          migrationsDir,
 	})
 
+	// ClamAV file scanning
+	if clamdAddress != "" {
+		action := core.FileScanActionReject
+		if clamdQuarantine {
+			action = core.FileScanActionQuarantine
+		}
+
+		filescan.MustRegister(app, filescan.Config{
+			ClamdAddress: clamdAddress,
+			Action:       action,
+		})
+	}
+
 	// GitHub selfupdate
 	ghupdate.MustRegister(app, app.RootCmd, ghupdate.Config{})
 
@@ -89,6 +89,13 @@ func (form *RecordUpsert) HasManageAccess() bool {
 func (form *RecordUpsert) Load(data map[string]any) {
 	excludeFields := []string{core.FieldNameExpand}
 
+	// skip the readonly db generated fields
+	for _, field := range form.record.Collection().Fields {
+		if gc, ok := field.(core.GeneratedColumn); ok && gc.IsGeneratedColumn() {
+			excludeFields = append(excludeFields, field.GetName())
+		}
+	}
+
 	isAuth := form.record.Collection().IsAuth()
 
 	// load the special auth form fields
@@ -887,6 +887,37 @@ func TestRecordUpsertSubmitSuccess(t *testing.T) {
 	testFilesCount(t, testApp, record, 2) // the file + attrs
 }
 
+func TestRecordUpsertComputedFieldReadonly(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	col := core.NewBaseCollection("test_computed")
+	col.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.ComputedField{Name: "upper_title", Expression: "upper(title)"},
+	)
+	if err := testApp.Save(col); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(col)
+
+	form := forms.NewRecordUpsert(testApp, record)
+	form.GrantSuperuserAccess()
+	form.Load(map[string]any{
+		"title":       "test",
+		"upper_title": "custom", // should be ignored
+	})
+
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Expected Submit success, got error: %v", err)
+	}
+
+	if v := record.GetString("upper_title"); v != "TEST" {
+		t.Fatalf("Expected record.upper_title %q, got %q", "TEST", v)
+	}
+}
+
 func TestRecordUpsertPasswordsSync(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameFileScans)
+		col.System = true
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "recordRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "field",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "filename",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.SelectField{
+			Name:      "status",
+			System:    true,
+			Required:  true,
+			MaxSelect: 1,
+			Values:    []string{core.FileScanStatusClean, core.FileScanStatusInfected},
+		})
+		col.Fields.Add(&core.TextField{
+			Name:   "signature",
+			System: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:   "quarantineKey",
+			System: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_fileScans_collectionRef_recordRef", false, "collectionRef, recordRef", "")
+		col.AddIndex("idx_fileScans_status", false, "status", "")
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		// system collections cannot be deleted with txApp.Delete
+		if _, err := txApp.DB().DropTable(core.CollectionNameFileScans).Execute(); err != nil {
+			return err
+		}
+
+		_, err := txApp.DB().Delete("_collections", dbx.HashExp{"name": core.CollectionNameFileScans}).Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameFileRefs)
+		col.System = true
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "recordRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "field",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "filename",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "hash",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_fileRefs_unique_file", true, "collectionRef, recordRef, filename", "")
+		col.AddIndex("idx_fileRefs_hash", false, "hash", "")
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		// system collections cannot be deleted with txApp.Delete
+		if _, err := txApp.DB().DropTable(core.CollectionNameFileRefs).Execute(); err != nil {
+			return err
+		}
+
+		_, err := txApp.DB().Delete("_collections", dbx.HashExp{"name": core.CollectionNameFileRefs}).Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,119 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		files := core.NewBaseCollection(core.CollectionNameStorageFiles)
+		files.System = true
+
+		files.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		files.Fields.Add(&core.TextField{
+			Name:     "recordRef",
+			System:   true,
+			Required: true,
+		})
+		files.Fields.Add(&core.TextField{
+			Name:   "ownerRef",
+			System: true,
+		})
+		files.Fields.Add(&core.TextField{
+			Name:     "field",
+			System:   true,
+			Required: true,
+		})
+		files.Fields.Add(&core.TextField{
+			Name:     "filename",
+			System:   true,
+			Required: true,
+		})
+		files.Fields.Add(&core.NumberField{
+			Name:    "size",
+			System:  true,
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+		})
+		files.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		files.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		files.AddIndex("idx_storageFiles_unique_file", true, "collectionRef, recordRef, filename", "")
+		files.AddIndex("idx_storageFiles_collectionRef_ownerRef", false, "collectionRef, ownerRef", "")
+
+		if err := txApp.Save(files); err != nil {
+			return err
+		}
+
+		usage := core.NewBaseCollection(core.CollectionNameStorageUsage)
+		usage.System = true
+
+		ownerRule := "@request.auth.id != '' && ownerRef = @request.auth.id"
+		usage.ListRule = types.Pointer(ownerRule)
+		usage.ViewRule = types.Pointer(ownerRule)
+
+		usage.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		usage.Fields.Add(&core.TextField{
+			Name:   "ownerRef",
+			System: true,
+		})
+		usage.Fields.Add(&core.NumberField{
+			Name:    "files",
+			System:  true,
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+		})
+		usage.Fields.Add(&core.NumberField{
+			Name:    "size",
+			System:  true,
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+		})
+		usage.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		usage.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		usage.AddIndex("idx_storageUsage_unique_owner", true, "collectionRef, ownerRef", "")
+
+		return txApp.Save(usage)
+	}, func(txApp core.App) error {
+		// system collections cannot be deleted with txApp.Delete
+		for _, name := range []string{core.CollectionNameStorageUsage, core.CollectionNameStorageFiles} {
+			if _, err := txApp.DB().DropTable(name).Execute(); err != nil {
+				return err
+			}
+
+			_, err := txApp.DB().Delete("_collections", dbx.HashExp{"name": name}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
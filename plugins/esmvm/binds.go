@@ -733,6 +733,9 @@ func securityBinds(vm *sobek.Runtime) {
 
 		return string(result), err
 	})
+
+	// html
+	obj.Set("sanitizeHTML", security.SanitizeHTML)
 }
 
 func filesystemBinds(vm *sobek.Runtime) {
@@ -0,0 +1,212 @@
+package filescan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+var _ Scanner = (*ClamdScanner)(nil)
+
+// DefaultClamdAddress is the default clamd TCP address.
+const DefaultClamdAddress = "127.0.0.1:3310"
+
+// defaultClamdChunkSize is the default size of the INSTREAM data chunks
+// (it must be smaller than the clamd StreamMaxLength config option).
+const defaultClamdChunkSize = 32 << 10
+
+// ClamdScanner implements the [Scanner] interface using the
+// ClamAV daemon INSTREAM command over a TCP or unix socket.
+//
+// https://docs.clamav.net/manual/Usage/Scanning.html#clamd
+type ClamdScanner struct {
+	// Network is the clamd socket network ("tcp" or "unix").
+	//
+	// If empty, it is resolved based on the Address value
+	// ("unix" for absolute paths, otherwise "tcp").
+	Network string
+
+	// Address is the clamd socket address
+	// (eg. "127.0.0.1:3310" or "/run/clamav/clamd.ctl").
+	//
+	// Defaults to DefaultClamdAddress.
+	Address string
+
+	// Timeout is the max duration of a single clamd command
+	// (applied only if the context doesn't have an earlier deadline).
+	//
+	// Defaults to 1 minute.
+	Timeout time.Duration
+}
+
+// Ping checks whether the clamd daemon is reachable and responsive.
+func (s *ClamdScanner) Ping(ctx context.Context) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zPING\x00")); err != nil {
+		return err
+	}
+
+	reply, err := readClamdReply(conn)
+	if err != nil {
+		return err
+	}
+
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected clamd PING reply %q", reply)
+	}
+
+	return nil
+}
+
+// Scan implements the [Scanner] interface and streams
+// the reader content to clamd via the INSTREAM command.
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	w := bufio.NewWriterSize(conn, defaultClamdChunkSize+4)
+
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, defaultClamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := w.Write(size); err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return nil, err
+			}
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	// zero-length chunk to mark the end of the stream
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := w.Write(size); err != nil {
+		return nil, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	reply, err := readClamdReply(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClamdScanReply(reply)
+}
+
+func (s *ClamdScanner) dial(ctx context.Context) (net.Conn, error) {
+	address := s.Address
+	if address == "" {
+		address = DefaultClamdAddress
+	}
+
+	network := s.Network
+	if network == "" {
+		if strings.HasPrefix(address, "/") {
+			network = "unix"
+		} else {
+			network = "tcp"
+		}
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, errors.Join(err, conn.Close())
+	}
+
+	// abort the pending reads/writes on context cancellation
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+
+	return &clamdConn{Conn: conn, stop: stop}, nil
+}
+
+type clamdConn struct {
+	net.Conn
+	stop func() bool
+}
+
+func (c *clamdConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+// readClamdReply reads a single null-terminated clamd reply.
+func readClamdReply(r io.Reader) (string, error) {
+	reply, err := bufio.NewReader(r).ReadString(0)
+	if err != nil && (err != io.EOF || reply == "") {
+		return "", fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+
+	return strings.TrimSpace(strings.TrimSuffix(reply, "\x00")), nil
+}
+
+// parseClamdScanReply parses a clamd INSTREAM reply, eg.:
+//
+//	stream: OK
+//	stream: Win.Test.EICAR_HDB-1 FOUND
+//	INSTREAM size limit exceeded. ERROR
+func parseClamdScanReply(reply string) (*Result, error) {
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{
+			Infected:  true,
+			Signature: strings.TrimSuffix(reply, " FOUND"),
+		}, nil
+	case strings.HasSuffix(reply, " ERROR"):
+		return nil, fmt.Errorf("clamd scan error: %s", strings.TrimSuffix(reply, " ERROR"))
+	default:
+		return nil, fmt.Errorf("unexpected clamd scan reply %q", reply)
+	}
+}
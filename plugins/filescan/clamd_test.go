@@ -0,0 +1,163 @@
+package filescan_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/plugins/filescan"
+)
+
+// newFakeClamd starts a minimal clamd server that replies with
+// "FOUND" for every stream that contains the "EICAR" keyword.
+func newFakeClamd(t *testing.T, network, address string) net.Listener {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handleFakeClamdConn(conn)
+		}
+	}()
+
+	return l
+}
+
+func handleFakeClamdConn(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+
+	cmd, err := r.ReadString(0)
+	if err != nil {
+		return
+	}
+
+	switch cmd {
+	case "zPING\x00":
+		conn.Write([]byte("PONG\x00"))
+	case "zINSTREAM\x00":
+		var data bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(r, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if data.Len()+int(n) > 1024*1024 {
+				conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+				return
+			}
+			if _, err := io.CopyN(&data, r, int64(n)); err != nil {
+				return
+			}
+		}
+
+		if strings.Contains(data.String(), "EICAR") {
+			conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		} else {
+			conn.Write([]byte("stream: OK\x00"))
+		}
+	default:
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+	}
+}
+
+func TestClamdScannerPing(t *testing.T) {
+	t.Parallel()
+
+	l := newFakeClamd(t, "tcp", "127.0.0.1:0")
+
+	scanner := &filescan.ClamdScanner{Address: l.Addr().String()}
+	if err := scanner.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected successful ping, got %v", err)
+	}
+
+	l.Close()
+
+	if err := scanner.Ping(context.Background()); err == nil {
+		t.Fatal("Expected ping error after closing the server")
+	}
+}
+
+func TestClamdScannerScan(t *testing.T) {
+	t.Parallel()
+
+	tcp := newFakeClamd(t, "tcp", "127.0.0.1:0")
+	unix := newFakeClamd(t, "unix", filepath.Join(t.TempDir(), "clamd.sock"))
+
+	scanners := map[string]*filescan.ClamdScanner{
+		"tcp":  {Address: tcp.Addr().String()},
+		"unix": {Address: unix.Addr().String()},
+	}
+
+	scenarios := []struct {
+		name              string
+		content           string
+		expectError       bool
+		expectedInfected  bool
+		expectedSignature string
+	}{
+		{"empty", "", false, false, ""},
+		{"clean", "hello world", false, false, ""},
+		{"infected", "X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*", false, true, "Eicar-Test-Signature"},
+		{"multiple chunks infected", strings.Repeat("a", 100<<10) + "EICAR", false, true, "Eicar-Test-Signature"},
+		{"size limit error", strings.Repeat("a", 2<<20), true, false, ""},
+	}
+
+	for network, scanner := range scanners {
+		for _, s := range scenarios {
+			t.Run(network+"_"+s.name, func(t *testing.T) {
+				result, err := scanner.Scan(context.Background(), strings.NewReader(s.content))
+
+				hasErr := err != nil
+				if hasErr != s.expectError {
+					t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+				}
+
+				if hasErr {
+					return
+				}
+
+				if result.Infected != s.expectedInfected {
+					t.Fatalf("Expected infected %v, got %v", s.expectedInfected, result.Infected)
+				}
+
+				if result.Signature != s.expectedSignature {
+					t.Fatalf("Expected signature %q, got %q", s.expectedSignature, result.Signature)
+				}
+			})
+		}
+	}
+}
+
+func TestClamdScannerScanCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	l := newFakeClamd(t, "tcp", "127.0.0.1:0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scanner := &filescan.ClamdScanner{Address: l.Addr().String()}
+
+	if _, err := scanner.Scan(ctx, strings.NewReader("test")); err == nil {
+		t.Fatal("Expected canceled context error")
+	}
+}
@@ -0,0 +1,188 @@
+// Package filescan implements a file scanning plugin that checks every new
+// record file before its upload (eg. for malware or content moderation).
+//
+// By default the files are scanned with the ClamAV daemon (clamd).
+//
+// Example usage:
+//
+//	filescan.MustRegister(app, filescan.Config{
+//		ClamdAddress: "/run/clamav/clamd.ctl",
+//		Action:       core.FileScanActionQuarantine,
+//	})
+package filescan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// Result defines a single file scan result.
+type Result struct {
+	// Infected indicates whether a threat or content violation was detected.
+	Infected bool
+
+	// Signature is the optional name of the detected threat or content violation.
+	Signature string
+}
+
+// Scanner defines a file content scanner.
+type Scanner interface {
+	// Scan scans the content of r and returns the scan result.
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// ScannerFunc is an adapter to allow the use of an ordinary function as [Scanner].
+type ScannerFunc func(ctx context.Context, r io.Reader) (*Result, error)
+
+// Scan implements the [Scanner] interface.
+func (fn ScannerFunc) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	return fn(ctx, r)
+}
+
+// Config defines the config options of the filescan plugin.
+type Config struct {
+	// Scanner is the file content scanner to use.
+	//
+	// If not set, defaults to [ClamdScanner] configured with
+	// the ClamdNetwork, ClamdAddress and Timeout options.
+	Scanner Scanner
+
+	// ClamdNetwork is the clamd socket network ("tcp" or "unix").
+	ClamdNetwork string
+
+	// ClamdAddress is the clamd socket address (default to DefaultClamdAddress).
+	ClamdAddress string
+
+	// Timeout is the max duration of a single file scan (default to 1 minute).
+	Timeout time.Duration
+
+	// Action specifies how to handle the infected files
+	// (core.FileScanActionReject (default) or core.FileScanActionQuarantine).
+	Action string
+
+	// QuarantinePrefix is the storage prefix where the infected files
+	// are moved when Action is core.FileScanActionQuarantine
+	// (default to core.StorageQuarantineDirName).
+	QuarantinePrefix string
+
+	// Collections is an optional list of collection names or ids
+	// whose files to scan (if empty, the files of all collections are scanned).
+	Collections []string
+
+	// FailOpen allows the file upload if the scanner fails
+	// (the error is only logged).
+	//
+	// By default the scanner failure rejects the record save.
+	FailOpen bool
+}
+
+// MustRegister registers the filescan plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the filescan plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	p := &plugin{app: app, config: config}
+
+	if p.config.Action == "" {
+		p.config.Action = core.FileScanActionReject
+	}
+
+	if !slices.Contains([]string{core.FileScanActionReject, core.FileScanActionQuarantine}, p.config.Action) {
+		return fmt.Errorf("invalid filescan action %q", p.config.Action)
+	}
+
+	if p.config.Timeout <= 0 {
+		p.config.Timeout = time.Minute
+	}
+
+	if p.config.Scanner == nil {
+		p.config.Scanner = &ClamdScanner{
+			Network: p.config.ClamdNetwork,
+			Address: p.config.ClamdAddress,
+			Timeout: p.config.Timeout,
+		}
+	}
+
+	app.OnRecordFileScan(p.config.Collections...).Bind(&hook.Handler[*core.RecordFileScanEvent]{
+		Id:   "__pbFileScan__",
+		Func: p.scan,
+	})
+
+	return nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+}
+
+func (p *plugin) scan(e *core.RecordFileScanEvent) error {
+	result, err := p.scanFile(e)
+	if err != nil {
+		if !p.config.FailOpen {
+			return err
+		}
+
+		e.App.Logger().Warn(
+			"Failed to scan record file",
+			"error", err,
+			"recordId", e.Record.Id,
+			"collectionId", e.Record.Collection().Id,
+			"filename", e.File.Name,
+		)
+
+		return e.Next()
+	}
+
+	if result.Infected {
+		e.Status = core.FileScanStatusInfected
+		e.Signature = result.Signature
+		e.Action = p.config.Action
+		if p.config.QuarantinePrefix != "" {
+			e.QuarantinePrefix = p.config.QuarantinePrefix
+		}
+	} else {
+		e.Status = core.FileScanStatusClean
+	}
+
+	return e.Next()
+}
+
+func (p *plugin) scanFile(e *core.RecordFileScanEvent) (*Result, error) {
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	r, err := e.File.Reader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	result, err := p.config.Scanner.Scan(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, errors.New("missing scan result")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,168 @@
+package filescan_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/filescan"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestRegisterInvalidAction(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	err := filescan.Register(app, filescan.Config{Action: "invalid"})
+	if err == nil {
+		t.Fatal("Expected invalid action error")
+	}
+}
+
+func TestFileScan(t *testing.T) {
+	t.Parallel()
+
+	scanner := filescan.ScannerFunc(func(ctx context.Context, r io.Reader) (*filescan.Result, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch string(data) {
+		case "error":
+			return nil, errors.New("test scan error")
+		case "EICAR":
+			return &filescan.Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+		default:
+			return &filescan.Result{}, nil
+		}
+	})
+
+	scenarios := []struct {
+		name            string
+		config          filescan.Config
+		content         string
+		expectError     bool
+		expectedFiles   int
+		expectedStatus  string
+		expectedKeyPart string
+	}{
+		{
+			"clean file",
+			filescan.Config{},
+			"test",
+			false,
+			1,
+			core.FileScanStatusClean,
+			"",
+		},
+		{
+			"infected file with reject action",
+			filescan.Config{},
+			"EICAR",
+			true,
+			0,
+			"",
+			"",
+		},
+		{
+			"infected file with quarantine action",
+			filescan.Config{Action: core.FileScanActionQuarantine, QuarantinePrefix: "custom_quarantine"},
+			"EICAR",
+			false,
+			0,
+			core.FileScanStatusInfected,
+			"custom_quarantine/",
+		},
+		{
+			"infected file in not matching collection",
+			filescan.Config{Collections: []string{"demo2"}},
+			"EICAR",
+			false,
+			1,
+			"",
+			"",
+		},
+		{
+			"scanner error",
+			filescan.Config{},
+			"error",
+			true,
+			0,
+			"",
+			"",
+		},
+		{
+			"scanner error with FailOpen",
+			filescan.Config{FailOpen: true},
+			"error",
+			false,
+			1,
+			"",
+			"",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			s.config.Scanner = scanner
+			filescan.MustRegister(app, s.config)
+
+			collection, err := app.FindCollectionByNameOrId("demo1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := filesystem.NewFileFromBytes([]byte(s.content), "test.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record := core.NewRecord(collection)
+			record.Set("file_many", []any{file})
+
+			err = app.Save(record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if files := record.GetStringSlice("file_many"); len(files) != s.expectedFiles {
+				t.Fatalf("Expected %d record files, got %v", s.expectedFiles, files)
+			}
+
+			scan, err := app.FindFileScanByFilename(record, "file_many", file.Name)
+			if s.expectedStatus == "" {
+				if err == nil {
+					t.Fatalf("Expected no scan record, got %v", scan)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if scan.Status() != s.expectedStatus {
+				t.Fatalf("Expected status %q, got %q", s.expectedStatus, scan.Status())
+			}
+
+			if !strings.HasPrefix(scan.QuarantineKey(), s.expectedKeyPart) {
+				t.Fatalf("Expected quarantine key to start with %q, got %q", s.expectedKeyPart, scan.QuarantineKey())
+			}
+		})
+	}
+}
@@ -733,6 +733,9 @@ func securityBinds(vm *goja.Runtime) {
 
 		return string(result), err
 	})
+
+	// html
+	obj.Set("sanitizeHTML", security.SanitizeHTML)
 }
 
 func filesystemBinds(vm *goja.Runtime) {
@@ -848,7 +848,7 @@ func TestSecurityBindsCount(t *testing.T) {
 	vm := goja.New()
 	securityBinds(vm)
 
-	testBindsCount(vm, "$security", 16, t)
+	testBindsCount(vm, "$security", 17, t)
 }
 
 func TestSecurityCryptoBinds(t *testing.T) {
@@ -995,6 +995,29 @@ func TestSecurityEncryptAndDecryptBinds(t *testing.T) {
 	}
 }
 
+func TestSecuritySanitizeHTMLBinds(t *testing.T) {
+	vm := goja.New()
+	baseBinds(vm)
+	securityBinds(vm)
+
+	_, err := vm.RunString(`
+		const html = '<p onclick="alert(1)">a<script>alert(2)</script><a href="javascript:alert(3)" id="b">b</a></p>'
+
+		const result1 = $security.sanitizeHTML(html)
+		if (result1 != '<p>a<a>b</a></p>') {
+			throw new Error("Expected the default policy result, got " + result1)
+		}
+
+		const result2 = $security.sanitizeHTML(html, { allowedTags: ["a"], allowedAttributes: ["id"] })
+		if (result2 != 'a<a id="b">b</a>') {
+			throw new Error("Expected the custom policy result, got " + result2)
+		}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestFilesystemBinds(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -1579,7 +1602,7 @@ func TestHooksBindsCount(t *testing.T) {
 	vm := goja.New()
 	hooksBinds(app, vm, nil)
 
-	testBindsCount(vm, "this", 82, t)
+	testBindsCount(vm, "this", 84, t)
 }
 
 func TestHooksBinds(t *testing.T) {
@@ -1282,6 +1282,7 @@ declare function migrate(
 /** @group PocketBase */declare function onRecordUpdateRequest(handler: (e: core.RecordRequestEvent) => void, ...tags: string[]): void
 /** @group PocketBase */declare function onRecordValidate(handler: (e: core.RecordEvent) => void, ...tags: string[]): void
 /** @group PocketBase */declare function onRecordViewRequest(handler: (e: core.RecordRequestEvent) => void, ...tags: string[]): void
+/** @group PocketBase */declare function onRecordsAggregateRequest(handler: (e: core.RecordsAggregateRequestEvent) => void, ...tags: string[]): void
 /** @group PocketBase */declare function onRecordsListRequest(handler: (e: core.RecordsListRequestEvent) => void, ...tags: string[]): void
 /** @group PocketBase */declare function onSettingsListRequest(handler: (e: core.SettingsListRequestEvent) => void): void
 /** @group PocketBase */declare function onSettingsReload(handler: (e: core.SettingsReloadEvent) => void): void
@@ -7339,6 +7340,16 @@ namespace core {
    * triggered and called only if their event data origin matches the tags.
    */
   onRecordsListRequest(...tags: string[]): (hook.TaggedHook<RecordsListRequestEvent | undefined>)
+  /**
+   * OnRecordsAggregateRequest hook is triggered on each API Records aggregate request.
+   * 
+   * Could be used to validate or modify the aggregated groups before returning them to the client.
+   * 
+   * If the optional "tags" list (Collection ids or names) is specified,
+   * then all event handlers registered via the created hook will be
+   * triggered and called only if their event data origin matches the tags.
+   */
+  onRecordsAggregateRequest(...tags: string[]): (hook.TaggedHook<RecordsAggregateRequestEvent | undefined>)
   /**
    * OnRecordViewRequest hook is triggered on each API Record view request.
    * 
@@ -8060,6 +8071,9 @@ namespace core {
  interface BaseApp {
   onRecordsListRequest(...tags: string[]): (hook.TaggedHook<RecordsListRequestEvent | undefined>)
  }
+ interface BaseApp {
+  onRecordsAggregateRequest(...tags: string[]): (hook.TaggedHook<RecordsAggregateRequestEvent | undefined>)
+ }
  interface BaseApp {
   onRecordViewRequest(...tags: string[]): (hook.TaggedHook<RecordRequestEvent | undefined>)
  }
@@ -9497,6 +9511,13 @@ namespace core {
   records: Array<(Record | undefined)>
   result?: search.Result
  }
+ type _sRaGgRq = hook.Event&RequestEvent&baseCollectionEventData
+ interface RecordsAggregateRequestEvent extends _sRaGgRq {
+  /**
+   * Result.Items holds the aggregated groups.
+   */
+  result?: search.Result
+ }
  type _smswQmI = hook.Event&RequestEvent&baseCollectionEventData
  interface RecordRequestEvent extends _smswQmI {
   record?: Record
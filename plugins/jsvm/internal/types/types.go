@@ -848,7 +848,7 @@ declare namespace $mails {
 
 /**
  * ` + "`" + `$security` + "`" + ` defines low level helpers for creating
- * and parsing JWTs, random string generation, AES encryption, etc.
+ * and parsing JWTs, random string generation, AES encryption, HTML sanitization, etc.
  *
  * @group PocketBase
  */
@@ -866,6 +866,7 @@ declare namespace $security {
   let md5:                            security.md5
   let sha256:                         security.sha256
   let sha512:                         security.sha512
+  let sanitizeHTML:                   security.sanitizeHTML
 
   /**
    * {@inheritDoc security.newJWT}
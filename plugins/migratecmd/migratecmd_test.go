@@ -59,6 +59,7 @@ migrate((app) => {
         "presentable": false,
         "primaryKey": true,
         "required": true,
+        "searchable": false,
         "system": true,
         "type": "text"
       },
@@ -86,6 +87,7 @@ migrate((app) => {
         "presentable": false,
         "primaryKey": false,
         "required": true,
+        "searchable": false,
         "system": true,
         "type": "text"
       },
@@ -235,6 +237,7 @@ func init() {
 					"presentable": false,
 					"primaryKey": true,
 					"required": true,
+					"searchable": false,
 					"system": true,
 					"type": "text"
 				},
@@ -262,6 +265,7 @@ func init() {
 					"presentable": false,
 					"primaryKey": false,
 					"required": true,
+					"searchable": false,
 					"system": true,
 					"type": "text"
 				},
@@ -500,6 +504,7 @@ migrate((app) => {
         "presentable": false,
         "primaryKey": true,
         "required": true,
+        "searchable": false,
         "system": true,
         "type": "text"
       },
@@ -527,6 +532,7 @@ migrate((app) => {
         "presentable": false,
         "primaryKey": false,
         "required": true,
+        "searchable": false,
         "system": true,
         "type": "text"
       },
@@ -679,6 +685,7 @@ func init() {
 					"presentable": false,
 					"primaryKey": true,
 					"required": true,
+					"searchable": false,
 					"system": true,
 					"type": "text"
 				},
@@ -706,6 +713,7 @@ func init() {
 					"presentable": false,
 					"primaryKey": false,
 					"required": true,
+					"searchable": false,
 					"system": true,
 					"type": "text"
 				},
@@ -932,6 +940,7 @@ migrate((app) => {
     "presentable": false,
     "primaryKey": false,
     "required": false,
+    "searchable": false,
     "system": false,
     "type": "text"
   }))
@@ -1063,6 +1072,7 @@ func init() {
 			"presentable": false,
 			"primaryKey": false,
 			"required": false,
+			"searchable": false,
 			"system": false,
 			"type": "text"
 		}` + "`" + `)); err != nil {
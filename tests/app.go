@@ -717,6 +717,14 @@ func NewTestAppWithConfig(config core.BaseAppConfig) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordsAggregateRequest().Bind(&hook.Handler[*core.RecordsAggregateRequestEvent]{
+		Func: func(e *core.RecordsAggregateRequestEvent) error {
+			t.registerEventCall("OnRecordsAggregateRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordViewRequest().Bind(&hook.Handler[*core.RecordRequestEvent]{
 		Func: func(e *core.RecordRequestEvent) error {
 			t.registerEventCall("OnRecordViewRequest")
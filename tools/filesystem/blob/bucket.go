@@ -22,6 +22,7 @@ import (
 	"io"
 	"log"
 	"mime"
+	"net/http"
 	"runtime"
 	"strings"
 	"sync"
@@ -30,8 +31,9 @@ import (
 )
 
 var (
-	ErrNotFound = errors.New("resource not found")
-	ErrClosed   = errors.New("bucket or blob is closed")
+	ErrNotFound    = errors.New("resource not found")
+	ErrClosed      = errors.New("bucket or blob is closed")
+	ErrUnsupported = errors.New("operation is not supported by the bucket driver")
 )
 
 // Bucket provides an easy and portable way to interact with blobs
@@ -693,6 +695,79 @@ func (b *Bucket) Delete(ctx context.Context, key string) (err error) {
 	return wrapError(b.drv, b.drv.Delete(ctx, key), key)
 }
 
+// SignedURLOptions sets options for SignedURL.
+type SignedURLOptions struct {
+	// Expiry sets how long the returned URL is valid for.
+	// Defaults to DefaultSignedURLExpiry.
+	Expiry time.Duration
+
+	// Method is the HTTP method that can be used on the URL; one of "GET" or "PUT".
+	// Defaults to "GET".
+	Method string
+
+	// ContentType specifies the Content-Type HTTP header the user agent
+	// is permitted to use in the PUT request.
+	// It must match exactly.
+	ContentType string
+
+	// ResponseContentType overrides the Content-Type header of the GET response.
+	ResponseContentType string
+
+	// ResponseContentDisposition overrides the Content-Disposition header of the GET response.
+	ResponseContentDisposition string
+
+	// ResponseCacheControl overrides the Cache-Control header of the GET response.
+	ResponseCacheControl string
+}
+
+// DefaultSignedURLExpiry is the default duration for SignedURLOptions.Expiry.
+const DefaultSignedURLExpiry = 1 * time.Hour
+
+// SignedURL returns a URL that can be used to GET (default) or PUT
+// the blob for the duration specified in opts.Expiry.
+//
+// If the bucket driver doesn't support signed URLs, returns ErrUnsupported.
+func (b *Bucket) SignedURL(ctx context.Context, key string, opts *SignedURLOptions) (string, error) {
+	if !utf8.ValidString(key) {
+		return "", fmt.Errorf("SignedURL key must be a valid UTF-8 string: %q", key)
+	}
+
+	signer, ok := b.drv.(DriverSigner)
+	if !ok {
+		return "", ErrUnsupported
+	}
+
+	dopts := new(SignedURLOptions)
+	if opts != nil {
+		*dopts = *opts
+	}
+
+	if dopts.Expiry < 0 {
+		return "", errors.New("SignedURLOptions.Expiry must be >= 0")
+	}
+	if dopts.Expiry == 0 {
+		dopts.Expiry = DefaultSignedURLExpiry
+	}
+
+	switch dopts.Method {
+	case "":
+		dopts.Method = http.MethodGet
+	case http.MethodGet, http.MethodPut:
+	default:
+		return "", fmt.Errorf("unsupported SignedURLOptions.Method %q", dopts.Method)
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return "", ErrClosed
+	}
+
+	url, err := signer.SignedURL(ctx, key, dopts)
+
+	return url, wrapError(b.drv, err, key)
+}
+
 // Close releases any resources used for the bucket.
 //
 // @todo Consider removing it.
@@ -95,3 +95,13 @@ type Driver interface {
 	// It is up to the driver as to how these will be handled.
 	Close() error
 }
+
+// DriverSigner is an optional interface that could be implemented
+// by the drivers that support presigned URLs.
+type DriverSigner interface {
+	// SignedURL returns a URL that can be used to access the object
+	// associated with key without credentials for the duration specified in opts.Expiry.
+	//
+	// opts is guaranteed to be non-nil.
+	SignedURL(ctx context.Context, key string, opts *SignedURLOptions) (string, error)
+}
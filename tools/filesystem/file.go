@@ -89,6 +89,22 @@ func NewFileFromMultipart(mh *multipart.FileHeader) (*File, error) {
 	return f, nil
 }
 
+// NewFileFromReader creates a new File from the provided generic FileReader.
+func NewFileFromReader(reader FileReader, size int64, name string) (*File, error) {
+	if size <= 0 {
+		return nil, errors.New("cannot create an empty file")
+	}
+
+	f := &File{}
+
+	f.Reader = reader
+	f.Size = size
+	f.OriginalName = name
+	f.Name = normalizeName(f.Reader, f.OriginalName)
+
+	return f, nil
+}
+
 // NewFileFromURL creates a new File from the provided url by
 // downloading the resource and load it as BytesReader.
 //
@@ -153,6 +169,50 @@ func (r *PathReader) Open() (io.ReadSeekCloser, error) {
 
 // -------------------------------------------------------------------
 
+var _ FileReader = (*ObjectReader)(nil)
+
+// ObjectReader defines a FileReader from an existing storage object.
+//
+// When uploaded with [System.UploadFile] (without image transform)
+// the object is copied server-side without streaming its content through the app,
+// therefore it is expected that the uploading filesystem is
+// for the same storage as the one returned by NewSystem.
+type ObjectReader struct {
+	// NewSystem initializes a new instance of the filesystem containing the object.
+	NewSystem func() (*System, error)
+
+	// Key is the storage key of the object.
+	Key string
+}
+
+// Open implements the [filesystem.FileReader] interface.
+func (r *ObjectReader) Open() (io.ReadSeekCloser, error) {
+	fsys, err := r.NewSystem()
+	if err != nil {
+		return nil, err
+	}
+
+	br, err := fsys.GetReader(r.Key)
+	if err != nil {
+		fsys.Close()
+		return nil, err
+	}
+
+	return &objectReadSeekCloser{ReadSeekCloser: br, fsys: fsys}, nil
+}
+
+type objectReadSeekCloser struct {
+	io.ReadSeekCloser
+	fsys *System
+}
+
+// Close implements the [io.ReadSeekCloser] interface.
+func (r *objectReadSeekCloser) Close() error {
+	return errors.Join(r.ReadSeekCloser.Close(), r.fsys.Close())
+}
+
+// -------------------------------------------------------------------
+
 var _ FileReader = (*BytesReader)(nil)
 
 // BytesReader defines a FileReader from bytes content.
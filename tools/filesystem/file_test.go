@@ -100,6 +100,31 @@ func TestNewFileFromBytes(t *testing.T) {
 	}
 }
 
+func TestNewFileFromReader(t *testing.T) {
+	reader := &filesystem.BytesReader{Bytes: []byte("text\n")}
+
+	// zero size
+	if _, err := filesystem.NewFileFromReader(reader, 0, "photo.jpg"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	originalName := "image_!@ special"
+	normalizedNamePattern := regexp.QuoteMeta("image_special_") + `\w{10}` + regexp.QuoteMeta(".txt")
+	f, err := filesystem.NewFileFromReader(reader, 5, originalName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Size != 5 {
+		t.Fatalf("Expected Size %v, got %v", 5, f.Size)
+	}
+	if f.OriginalName != originalName {
+		t.Fatalf("Expected OriginalName %q, got %q", originalName, f.OriginalName)
+	}
+	if match, err := regexp.Match(normalizedNamePattern, []byte(f.Name)); !match {
+		t.Fatalf("Expected Name to match %v, got %q (%v)", normalizedNamePattern, f.Name, err)
+	}
+}
+
 func TestNewFileFromMultipart(t *testing.T) {
 	formData, mp, err := tests.MockMultipartData(nil, "test")
 	if err != nil {
@@ -2,6 +2,7 @@ package filesystem
 
 import (
 	"context"
+	"crypto/md5"
 	"errors"
 	"image"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/disintegration/imaging"
 	"github.com/fatih/color"
@@ -22,6 +24,7 @@ import (
 	"github.com/pocketbase/pocketbase/tools/filesystem/internal/fileblob"
 	"github.com/pocketbase/pocketbase/tools/filesystem/internal/s3blob"
 	"github.com/pocketbase/pocketbase/tools/filesystem/internal/s3blob/s3"
+	"github.com/pocketbase/pocketbase/tools/filesystem/internal/webpenc"
 	"github.com/pocketbase/pocketbase/tools/list"
 
 	// explicit webp decoder because disintegration/imaging does not support webp
@@ -174,6 +177,86 @@ func (s *System) Copy(srcKey, dstKey string) error {
 	return s.bucket.Copy(s.ctx, dstKey, srcKey)
 }
 
+// Checksum returns the MD5 checksum of the file stored at fileKey.
+//
+// The checksum is taken from the file attributes (if available),
+// otherwise it is calculated by reading the entire file content.
+//
+// If the file doesn't exist, it returns ErrNotFound.
+func (s *System) Checksum(fileKey string) ([]byte, error) {
+	attrs, err := s.Attributes(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(attrs.MD5) > 0 {
+		return attrs.MD5, nil
+	}
+
+	r, err := s.GetReader(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	h := md5.New()
+
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// CopyTo copies the file stored at srcKey to the dstKey location
+// of another (eg. local -> S3) filesystem.
+//
+// The file content type and metadata are preserved and the written
+// content is verified against the source file MD5 checksum.
+//
+// If srcKey file doesn't exist, it returns ErrNotFound.
+//
+// If dstKey file already exists, it is overwritten.
+func (s *System) CopyTo(dst *System, srcKey, dstKey string) error {
+	attrs, err := s.Attributes(srcKey)
+	if err != nil {
+		return err
+	}
+
+	checksum, err := s.Checksum(srcKey)
+	if err != nil {
+		return err
+	}
+
+	r, err := s.GetReader(srcKey)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	opts := &blob.WriterOptions{
+		CacheControl:       attrs.CacheControl,
+		ContentDisposition: attrs.ContentDisposition,
+		ContentEncoding:    attrs.ContentEncoding,
+		ContentLanguage:    attrs.ContentLanguage,
+		ContentType:        attrs.ContentType,
+		ContentMD5:         checksum,
+		Metadata:           attrs.Metadata,
+	}
+
+	w, err := dst.bucket.NewWriter(dst.ctx, dstKey, opts)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.ReadFrom(r); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
 // List returns a flat list with info for all files under the specified prefix.
 func (s *System) List(prefix string) ([]*blob.ListObject, error) {
 	files := []*blob.ListObject{}
@@ -214,8 +297,63 @@ func (s *System) Upload(content []byte, fileKey string) error {
 	return w.Close()
 }
 
+// UploadReader streams the content of r to the fileKey location
+// and returns the number of the stored bytes.
+//
+// On read error the already read content (if any) is still stored
+// and the read error is returned together with the stored bytes count
+// (this allows resuming interrupted uploads from the last received byte).
+func (s *System) UploadReader(r io.Reader, fileKey string) (int64, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	w, err := s.bucket.NewWriter(ctx, fileKey, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	sr := &stickyErrReader{r: r}
+
+	written, copyErr := io.Copy(w, sr)
+
+	// abort on write error or if nothing was read
+	if (copyErr != nil && copyErr != sr.err) || written == 0 {
+		cancel()
+		w.Close()
+
+		if copyErr == nil {
+			copyErr = sr.err
+		}
+
+		return 0, copyErr
+	}
+
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+
+	return written, sr.err
+}
+
+// stickyErrReader remembers the first non-EOF read error of the wrapped reader.
+type stickyErrReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *stickyErrReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
 // UploadFile uploads the provided File to the fileKey location.
-func (s *System) UploadFile(file *File, fileKey string) error {
+//
+// If transform is set and the file is a supported image,
+// the image is transformed before storing it (see [ImageTransform]).
+func (s *System) UploadFile(file *File, fileKey string, transform ...ImageTransform) error {
 	f, err := file.Reader.Open()
 	if err != nil {
 		return err
@@ -236,8 +374,27 @@ func (s *System) UploadFile(file *File, fileKey string) error {
 		// to prevent the metadata to grow too big in size
 		originalName = originalName[:255]
 	}
+
+	var content io.Reader = f
+	contentType := mt.String()
+
+	// copy server-side the existing storage objects (if their content type is not spoofed)
+	if or, ok := file.Reader.(*ObjectReader); ok && (len(transform) == 0 || !transform[0].CanApply(contentType)) {
+		attrs, err := s.Attributes(or.Key)
+		if err == nil && attrs.ContentType == contentType {
+			return s.Copy(or.Key, fileKey)
+		}
+	}
+
+	if len(transform) > 0 {
+		content, contentType, err = transform[0].apply(f, contentType)
+		if err != nil {
+			return err
+		}
+	}
+
 	opts := &blob.WriterOptions{
-		ContentType: mt.String(),
+		ContentType: contentType,
 		Metadata: map[string]string{
 			metadataOriginalName: originalName,
 		},
@@ -248,7 +405,7 @@ func (s *System) UploadFile(file *File, fileKey string) error {
 		return err
 	}
 
-	if _, err := w.ReadFrom(f); err != nil {
+	if _, err := w.ReadFrom(content); err != nil {
 		w.Close()
 		return err
 	}
@@ -257,7 +414,10 @@ func (s *System) UploadFile(file *File, fileKey string) error {
 }
 
 // UploadMultipart uploads the provided multipart file to the fileKey location.
-func (s *System) UploadMultipart(fh *multipart.FileHeader, fileKey string) error {
+//
+// If transform is set and the file is a supported image,
+// the image is transformed before storing it (see [ImageTransform]).
+func (s *System) UploadMultipart(fh *multipart.FileHeader, fileKey string, transform ...ImageTransform) error {
 	f, err := fh.Open()
 	if err != nil {
 		return err
@@ -278,8 +438,18 @@ func (s *System) UploadMultipart(fh *multipart.FileHeader, fileKey string) error
 		// to prevent the metadata to grow too big in size
 		originalName = originalName[:255]
 	}
+
+	var content io.Reader = f
+	contentType := mt.String()
+	if len(transform) > 0 {
+		content, contentType, err = transform[0].apply(f, contentType)
+		if err != nil {
+			return err
+		}
+	}
+
 	opts := &blob.WriterOptions{
-		ContentType: mt.String(),
+		ContentType: contentType,
 		Metadata: map[string]string{
 			metadataOriginalName: originalName,
 		},
@@ -290,7 +460,7 @@ func (s *System) UploadMultipart(fh *multipart.FileHeader, fileKey string) error
 		return err
 	}
 
-	_, err = w.ReadFrom(f)
+	_, err = w.ReadFrom(content)
 	if err != nil {
 		w.Close()
 		return err
@@ -421,6 +591,35 @@ var manualExtensionContentTypes = map[string]string{
 // force "Content-Disposition: attachment" header.
 const forceAttachmentParam = "download"
 
+// SignedURL returns a presigned URL that could be used by third parties
+// to access (GET or PUT) the fileKey location without credentials.
+//
+// Returns blob.ErrUnsupported if the storage doesn't support presigned URLs (eg. local filesystem).
+func (s *System) SignedURL(fileKey string, opts *blob.SignedURLOptions) (string, error) {
+	return s.bucket.SignedURL(s.ctx, fileKey, opts)
+}
+
+// SignedServeURL returns a presigned GET URL for the file at fileKey location
+// with the same Content-Type and Content-Disposition response headers as [System.Serve].
+//
+// Returns blob.ErrUnsupported if the storage doesn't support presigned URLs (eg. local filesystem).
+func (s *System) SignedServeURL(req *http.Request, fileKey string, name string, expiry time.Duration) (string, error) {
+	attrs, err := s.Attributes(fileKey)
+	if err != nil {
+		return "", err
+	}
+
+	disposition, contentType := serveContentHeaders(req, attrs.ContentType, name)
+
+	return s.SignedURL(fileKey, &blob.SignedURLOptions{
+		Expiry:                     expiry,
+		Method:                     http.MethodGet,
+		ResponseContentType:        contentType,
+		ResponseContentDisposition: disposition,
+		ResponseCacheControl:       defaultServeCacheControl,
+	})
+}
+
 // Serve serves the file at fileKey location to an HTTP response.
 //
 // If the `download` query parameter is used the file will be always served for
@@ -435,13 +634,32 @@ func (s *System) Serve(res http.ResponseWriter, req *http.Request, fileKey strin
 	}
 	defer br.Close()
 
+	disposition, contentType := serveContentHeaders(req, br.ContentType(), name)
+
+	setHeaderIfMissing(res, "Content-Disposition", disposition)
+	setHeaderIfMissing(res, "Content-Type", contentType)
+	setHeaderIfMissing(res, "Content-Security-Policy", "default-src 'none'; media-src 'self'; style-src 'unsafe-inline'; sandbox")
+	setHeaderIfMissing(res, "Cache-Control", defaultServeCacheControl)
+
+	http.ServeContent(res, req, name, br.ModTime(), br)
+
+	return nil
+}
+
+// default cache-control header of the served files
+// (valid for 30 days but the cache is allowed to reuse the file for any requests
+// that are made in the last day while revalidating the res in the background)
+const defaultServeCacheControl = "max-age=2592000, stale-while-revalidate=86400"
+
+// serveContentHeaders returns the Content-Disposition and Content-Type
+// header values for serving a file with the specified real content type.
+func serveContentHeaders(req *http.Request, realContentType string, name string) (string, string) {
 	var forceAttachment bool
 	if raw := req.URL.Query().Get(forceAttachmentParam); raw != "" {
 		forceAttachment, _ = strconv.ParseBool(raw)
 	}
 
 	disposition := "attachment"
-	realContentType := br.ContentType()
 	if !forceAttachment && list.ExistInSlice(realContentType, inlineServeContentTypes) {
 		disposition = "inline"
 	}
@@ -453,18 +671,7 @@ func (s *System) Serve(res http.ResponseWriter, req *http.Request, fileKey strin
 		extContentType = ct
 	}
 
-	setHeaderIfMissing(res, "Content-Disposition", disposition+"; filename="+name)
-	setHeaderIfMissing(res, "Content-Type", extContentType)
-	setHeaderIfMissing(res, "Content-Security-Policy", "default-src 'none'; media-src 'self'; style-src 'unsafe-inline'; sandbox")
-
-	// set a default cache-control header
-	// (valid for 30 days but the cache is allowed to reuse the file for any requests
-	// that are made in the last day while revalidating the res in the background)
-	setHeaderIfMissing(res, "Cache-Control", "max-age=2592000, stale-while-revalidate=86400")
-
-	http.ServeContent(res, req, name, br.ModTime(), br)
-
-	return nil
+	return disposition + "; filename=" + name, extContentType
 }
 
 // note: expects key to be in a canonical form (eg. "accept-encoding" should be "Accept-Encoding").
@@ -474,31 +681,38 @@ func setHeaderIfMissing(res http.ResponseWriter, key string, value string) {
 	}
 }
 
-var ThumbSizeRegex = regexp.MustCompile(`^(\d+)x(\d+)(t|b|f)?$`)
+var ThumbSizeRegex = regexp.MustCompile(`^(\d+)x(\d+)(t|b|f|c|s|p)?(?:_q(\d{1,3}))?(?:\.(webp|png|jpg|jpeg))?$`)
 
 // CreateThumb creates a new thumb image for the file at originalKey location.
 // The new thumb file is stored at thumbKey location.
 //
-// thumbSize is in the format:
+// thumbSize is in the format "WxH[mode][_q{quality}][.{format}]":
 // - 0xH  (eg. 0x100)    - resize to H height preserving the aspect ratio
 // - Wx0  (eg. 300x0)    - resize to W width preserving the aspect ratio
 // - WxH  (eg. 300x100)  - resize and crop to WxH viewbox (from center)
+// - WxHc (eg. 300x100c) - resize and crop to WxH viewbox (from center)
 // - WxHt (eg. 300x100t) - resize and crop to WxH viewbox (from top)
 // - WxHb (eg. 300x100b) - resize and crop to WxH viewbox (from bottom)
 // - WxHf (eg. 300x100f) - fit inside a WxH viewbox (without cropping)
-func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string) error {
-	sizeParts := ThumbSizeRegex.FindStringSubmatch(thumbSize)
-	if len(sizeParts) != 4 {
-		return errors.New("thumb size must be in WxH, WxHt, WxHb or WxHf format")
+// - WxHs (eg. 300x100s) - resize and crop to WxH viewbox (around the area with the most details)
+// - WxHp (eg. 300x100p) - resize and crop to WxH viewbox (around the focal point, if provided)
+//
+// The optional "_q{quality}" suffix (eg. 300x100_q80) specifies the JPEG encoding quality
+// (it is not allowed in combination with the explicit webp and png formats).
+//
+// The optional ".{format}" suffix (eg. 300x100.webp) specifies the thumb
+// output format (webp, png or jpg). If not set, the thumb preserves the
+// original image format (with the exception of webp which fallbacks to png).
+//
+// Note that the webp thumbs are always encoded lossless.
+func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string, focalPoint ...FocalPoint) error {
+	size, err := ParseThumbSize(thumbSize)
+	if err != nil {
+		return err
 	}
 
-	width, _ := strconv.Atoi(sizeParts[1])
-	height, _ := strconv.Atoi(sizeParts[2])
-	resizeType := sizeParts[3]
-
-	if width == 0 && height == 0 {
-		return errors.New("thumb width and height cannot be zero at the same time")
-	}
+	width := size.Width
+	height := size.Height
 
 	// fetch the original
 	r, readErr := s.GetReader(originalKey)
@@ -520,43 +734,66 @@ func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string) err
 		// force resize preserving aspect ratio
 		thumbImg = imaging.Resize(img, width, height, imaging.Linear)
 	} else {
-		switch resizeType {
-		case "f":
+		switch size.Mode {
+		case ThumbModeFit:
 			// fit
 			thumbImg = imaging.Fit(img, width, height, imaging.Linear)
-		case "t":
+		case ThumbModeTop:
 			// fill and crop from top
 			thumbImg = imaging.Fill(img, width, height, imaging.Top, imaging.Linear)
-		case "b":
+		case ThumbModeBottom:
 			// fill and crop from bottom
 			thumbImg = imaging.Fill(img, width, height, imaging.Bottom, imaging.Linear)
+		case ThumbModeSmart:
+			// fill and crop around the area with the most details
+			thumbImg = fillSmart(img, width, height)
+		case ThumbModeFocal:
+			// fill and crop around the focal point (fallbacks to center)
+			focal := FocalPoint{X: 0.5, Y: 0.5}
+			if len(focalPoint) > 0 {
+				focal = focalPoint[0]
+			}
+			thumbImg = fillFocal(img, width, height, focal)
 		default:
 			// fill and crop from center
 			thumbImg = imaging.Fill(img, width, height, imaging.Center, imaging.Linear)
 		}
 	}
 
-	originalContentType := r.ContentType()
-
 	opts := &blob.WriterOptions{
-		ContentType: originalContentType,
+		ContentType: size.ContentType(r.ContentType()),
 	}
 
-	var format imaging.Format
+	var encode func(w io.Writer) error
 
-	switch originalContentType {
-	case "image/jpeg":
-		format = imaging.JPEG
-	case "image/gif":
-		format = imaging.GIF
-	case "image/tiff":
-		format = imaging.TIFF
-	case "image/bmp":
-		format = imaging.BMP
+	switch opts.ContentType {
+	case "image/webp":
+		encode = func(w io.Writer) error {
+			return webpenc.Encode(w, thumbImg)
+		}
 	default:
-		// fallback to PNG (this includes webp!)
-		opts.ContentType = "image/png"
-		format = imaging.PNG
+		var format imaging.Format
+		switch opts.ContentType {
+		case "image/jpeg":
+			format = imaging.JPEG
+		case "image/gif":
+			format = imaging.GIF
+		case "image/tiff":
+			format = imaging.TIFF
+		case "image/bmp":
+			format = imaging.BMP
+		default:
+			format = imaging.PNG
+		}
+
+		quality := size.Quality
+		if quality == 0 {
+			quality = 95 // imaging's default
+		}
+
+		encode = func(w io.Writer) error {
+			return imaging.Encode(w, thumbImg, format, imaging.JPEGQuality(quality))
+		}
 	}
 
 	// open a thumb storage writer (aka. prepare for upload)
@@ -566,7 +803,7 @@ func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string) err
 	}
 
 	// thumb encode (aka. upload)
-	err = imaging.Encode(w, thumbImg, format)
+	err = encode(w)
 	if err != nil {
 		w.Close()
 		return err
@@ -2,7 +2,9 @@ package filesystem_test
 
 import (
 	"bytes"
+	"crypto/md5"
 	"errors"
+	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
@@ -14,9 +16,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
 )
 
 func TestFileSystemExists(t *testing.T) {
@@ -305,6 +310,92 @@ func TestFileSystemUploadFile(t *testing.T) {
 	}
 }
 
+func TestFileSystemUploadFileFromObject(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	newSystem := func() (*filesystem.System, error) {
+		return filesystem.NewLocal(dir)
+	}
+
+	scenarios := []struct {
+		name              string
+		srcKey            string
+		expectContentType string
+	}{
+		// server-side copy (the existing content type is preserved)
+		{"matching content type", "image.png", "image/png"},
+		// streamed upload (the content type is detected)
+		{"spoofed content type", "image_spoof.png", "text/plain; charset=utf-8"},
+	}
+
+	if err := fsys.Upload([]byte("test"), "image_spoof.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			srcAttrs, err := fsys.Attributes(s.srcKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := filesystem.NewFileFromReader(&filesystem.ObjectReader{NewSystem: newSystem, Key: s.srcKey}, srcAttrs.Size, s.srcKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fileKey := "copies/" + s.srcKey
+
+			if err := fsys.UploadFile(file, fileKey); err != nil {
+				t.Fatal(err)
+			}
+
+			attrs, err := fsys.Attributes(fileKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if attrs.Size != srcAttrs.Size {
+				t.Fatalf("Expected size %d, got %d", srcAttrs.Size, attrs.Size)
+			}
+
+			if attrs.ContentType != s.expectContentType {
+				t.Fatalf("Expected content type %q, got %q", s.expectContentType, attrs.ContentType)
+			}
+		})
+	}
+}
+
+func TestFileSystemSignedURL(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	_, err = fsys.SignedURL("image.png", nil)
+	if !errors.Is(err, blob.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+
+	_, err = fsys.SignedServeURL(req, "image.png", "test.png", time.Hour)
+	if !errors.Is(err, blob.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
 func TestFileSystemUpload(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -327,6 +418,67 @@ func TestFileSystemUpload(t *testing.T) {
 	}
 }
 
+func TestFileSystemUploadReader(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	readErr := errors.New("test")
+
+	scenarios := []struct {
+		name            string
+		reader          io.Reader
+		expectedWritten int64
+		expectedErr     error
+		expectedContent string // empty for missing file
+	}{
+		{"empty reader", strings.NewReader(""), 0, nil, ""},
+		{"read error without content", iotest.ErrReader(readErr), 0, readErr, ""},
+		{"full content", strings.NewReader("demo"), 4, nil, "demo"},
+		{"partial content with read error", io.MultiReader(strings.NewReader("abc"), iotest.ErrReader(readErr)), 3, readErr, "abc"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			fileKey := fmt.Sprintf("reader/%d.txt", i)
+
+			written, err := fsys.UploadReader(s.reader, fileKey)
+			if written != s.expectedWritten {
+				t.Fatalf("Expected %d written bytes, got %d", s.expectedWritten, written)
+			}
+
+			if !errors.Is(err, s.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", s.expectedErr, err)
+			}
+
+			exists, _ := fsys.Exists(fileKey)
+			if exists != (s.expectedContent != "") {
+				t.Fatalf("Expected file exists %v, got %v", s.expectedContent != "", exists)
+			}
+
+			if !exists {
+				return
+			}
+
+			r, err := fsys.GetReader(fileKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			content, _ := io.ReadAll(r)
+			if str := string(content); str != s.expectedContent {
+				t.Fatalf("Expected content %q, got %q", s.expectedContent, str)
+			}
+		})
+	}
+}
+
 func TestFileSystemServe(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -690,6 +842,84 @@ func TestFileSystemCopy(t *testing.T) {
 	}
 }
 
+func TestFileSystemChecksum(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	if _, err := fsys.Checksum("missing.txt"); err == nil {
+		t.Fatal("Expected error for missing file, got nil")
+	}
+
+	checksum, err := fsys.Checksum("test/sub1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := md5.Sum([]byte("sub1"))
+	if !bytes.Equal(checksum, expected[:]) {
+		t.Fatalf("Expected checksum %x, got %x", expected, checksum)
+	}
+}
+
+func TestFileSystemCopyTo(t *testing.T) {
+	srcDir := createTestDir(t)
+	defer os.RemoveAll(srcDir)
+
+	dstDir := t.TempDir()
+
+	src, err := filesystem.NewLocal(srcDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	dst, err := filesystem.NewLocal(dstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	// copy missing file
+	if err := src.CopyTo(dst, "missing.txt", "missing.txt"); err == nil {
+		t.Fatal("Expected error for missing file, got nil")
+	}
+
+	// copy existing file
+	if err := src.CopyTo(dst, "image.png", "a/b/image.png"); err != nil {
+		t.Fatalf("Failed to copy file: %v", err)
+	}
+
+	srcAttrs, err := src.Attributes("image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dstAttrs, err := dst.Attributes("a/b/image.png")
+	if err != nil {
+		t.Fatalf("Missing copied file: %v", err)
+	}
+
+	if dstAttrs.Size != srcAttrs.Size {
+		t.Fatalf("Expected file size %d, got %d", srcAttrs.Size, dstAttrs.Size)
+	}
+
+	if dstAttrs.ContentType != "image/png" {
+		t.Fatalf("Expected content type %q, got %q", "image/png", dstAttrs.ContentType)
+	}
+
+	srcChecksum, _ := src.Checksum("image.png")
+	dstChecksum, _ := dst.Checksum("a/b/image.png")
+	if !bytes.Equal(srcChecksum, dstChecksum) {
+		t.Fatalf("Expected checksum %x, got %x", srcChecksum, dstChecksum)
+	}
+}
+
 func TestFileSystemList(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -866,6 +1096,20 @@ func TestFileSystemCreateThumb(t *testing.T) {
 		{"image.webp", "thumb.webp", "100x100", "image/png"},
 		// without extension (should extract the mimetype from its stored ContentType)
 		{"image_noext", "image_noext.jpeg", "100x100", "image/jpeg"},
+		// existing image file with WxHc thumb size
+		{"image.png", "thumb_WxHc", "100x100c", "image/png"},
+		// existing image file with WxHs thumb size
+		{"image.png", "thumb_WxHs", "100x100s", "image/png"},
+		// existing image file with WxHp thumb size (without focal point)
+		{"image.png", "thumb_WxHp", "100x100p", "image/png"},
+		// invalid quality
+		{"image.png", "thumb_q0", "100x100_q0", ""},
+		// webp output format
+		{"image.png", "thumb_webp", "100x100.webp", "image/webp"},
+		// jpg output format with quality
+		{"image.png", "thumb_jpg", "100x50f_q50.jpg", "image/jpeg"},
+		// png output format
+		{"image.jpg", "thumb_png", "0x50.png", "image/png"},
 	}
 
 	for _, s := range scenarios {
@@ -0,0 +1,282 @@
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	ImageFormatJPEG = "jpeg"
+	ImageFormatPNG  = "png"
+)
+
+// DefaultImageQuality is the JPEG encoding quality used when ImageTransform.Quality is not set.
+const DefaultImageQuality = 90
+
+// MaxImageTransformPixels is the max allowed number of pixels (width*height)
+// of an image that needs to be decoded in order to be transformed.
+//
+// It prevents excessive memory usage when decoding highly compressed
+// images with huge dimensions (aka. "decompression bombs").
+var MaxImageTransformPixels = 50_000_000
+
+// ErrImageTooLarge is returned when the image to transform exceeds MaxImageTransformPixels.
+var ErrImageTooLarge = errors.New("the image dimensions are too large to be transformed")
+
+// transformableImageTypes lists the image content types that could be transformed.
+//
+// note: gif is excluded on purpose to avoid breaking animated images.
+var transformableImageTypes = map[string]imaging.Format{
+	"image/jpeg": imaging.JPEG,
+	"image/png":  imaging.PNG,
+	"image/webp": imaging.PNG, // there is no webp encoder so fallback to PNG
+	"image/tiff": imaging.TIFF,
+	"image/bmp":  imaging.BMP,
+}
+
+// ImageTransform defines the optional transformations that could be
+// applied to an uploaded image before storing it.
+//
+// Note that a transformed image is always re-encoded and therefore
+// all of its original metadata (EXIF, etc.) is dropped.
+type ImageTransform struct {
+	// MaxWidth specifies the max allowed image width (0 for no limit).
+	//
+	// Larger images are downscaled preserving their aspect ratio.
+	MaxWidth int `json:"maxWidth"`
+
+	// MaxHeight specifies the max allowed image height (0 for no limit).
+	//
+	// Larger images are downscaled preserving their aspect ratio.
+	MaxHeight int `json:"maxHeight"`
+
+	// Format specifies the target image format (ImageFormatJPEG or ImageFormatPNG).
+	//
+	// Leave it empty to keep the original image format.
+	Format string `json:"format"`
+
+	// Quality specifies the JPEG encoding quality (1-100).
+	//
+	// If zero, DefaultImageQuality is used when re-encoding a JPEG image.
+	Quality int `json:"quality"`
+
+	// StripMetadata forces re-encoding the image in order to remove
+	// its metadata (EXIF, GPS location, etc.) even if no other change is needed.
+	StripMetadata bool `json:"stripMetadata"`
+
+	// AutoOrient rotates/flips the image based on its EXIF orientation tag.
+	//
+	// Because the metadata is dropped on re-encoding, without it
+	// the transformed image may appear rotated.
+	AutoOrient bool `json:"autoOrient"`
+}
+
+// IsZero reports whether the transform has no options set.
+func (t ImageTransform) IsZero() bool {
+	return t == ImageTransform{}
+}
+
+// CanApply reports whether the transform could be applied to a file with the specified content type.
+func (t ImageTransform) CanApply(contentType string) bool {
+	if t.IsZero() {
+		return false
+	}
+
+	_, ok := transformableImageTypes[contentType]
+
+	return ok
+}
+
+// Ext returns the file extension (with the leading dot) of a transformed
+// file with the specified original content type.
+//
+// Returns empty string if the transform is not applicable
+// or the transformed file preserves its original format.
+func (t ImageTransform) Ext(contentType string) string {
+	if !t.CanApply(contentType) {
+		return ""
+	}
+
+	originalFormat := transformableImageTypes[contentType]
+
+	format := t.targetFormat(contentType)
+	if format == originalFormat && contentType != "image/webp" {
+		return ""
+	}
+
+	switch format {
+	case imaging.JPEG:
+		return ".jpg"
+	case imaging.TIFF:
+		return ".tiff"
+	case imaging.BMP:
+		return ".bmp"
+	default:
+		return ".png"
+	}
+}
+
+// ContentType returns the content type of a transformed
+// file with the specified original content type.
+//
+// Returns the original content type if the transform is not applicable.
+func (t ImageTransform) ContentType(contentType string) string {
+	if !t.CanApply(contentType) {
+		return contentType
+	}
+
+	switch t.targetFormat(contentType) {
+	case imaging.JPEG:
+		return "image/jpeg"
+	case imaging.TIFF:
+		return "image/tiff"
+	case imaging.BMP:
+		return "image/bmp"
+	default:
+		return "image/png"
+	}
+}
+
+// TransformFile applies the transform to the provided file and returns
+// a new in-memory File with the transformed image content
+// (the file name extension is updated in case of image format conversion).
+//
+// Returns the provided file as it is if the transform is not
+// applicable or no changes to the image are necessary.
+func (t ImageTransform) TransformFile(file *File) (*File, error) {
+	f, err := file.Reader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mt, err := mimetype.DetectReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// rewind
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	content, _, err := t.apply(f, mt.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if content == io.Reader(f) {
+		return file, nil // nothing to change
+	}
+
+	b, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	name := file.Name
+	if ext := t.Ext(mt.String()); ext != "" {
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + ext
+	}
+
+	return &File{
+		Reader:       &BytesReader{b},
+		Name:         name,
+		OriginalName: file.OriginalName,
+		Size:         int64(len(b)),
+	}, nil
+}
+
+func (t ImageTransform) targetFormat(contentType string) imaging.Format {
+	switch t.Format {
+	case ImageFormatJPEG:
+		return imaging.JPEG
+	case ImageFormatPNG:
+		return imaging.PNG
+	default:
+		return transformableImageTypes[contentType]
+	}
+}
+
+func (t ImageTransform) exceeds(width, height int) bool {
+	return (t.MaxWidth > 0 && width > t.MaxWidth) || (t.MaxHeight > 0 && height > t.MaxHeight)
+}
+
+// apply applies the transform to the image read from f.
+//
+// It returns the reader with the content to store and its content type
+// (which could be the unmodified f if no changes are necessary).
+func (t ImageTransform) apply(f io.ReadSeeker, contentType string) (io.Reader, string, error) {
+	if !t.CanApply(contentType) {
+		return f, contentType, nil
+	}
+
+	switch t.Format {
+	case "", ImageFormatJPEG, ImageFormatPNG:
+	default:
+		return nil, "", errors.New("unsupported image transform format " + t.Format)
+	}
+
+	format := t.targetFormat(contentType)
+
+	config, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// rewind
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	// nothing to change
+	if !t.StripMetadata &&
+		!t.AutoOrient &&
+		t.Quality == 0 &&
+		t.Ext(contentType) == "" &&
+		!t.exceeds(config.Width, config.Height) {
+		return f, contentType, nil
+	}
+
+	if config.Width*config.Height > MaxImageTransformPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	// (note: only the first frame for animated image formats)
+	img, err := imaging.Decode(f, imaging.AutoOrientation(t.AutoOrient))
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := img.Bounds()
+	if t.exceeds(bounds.Dx(), bounds.Dy()) {
+		if t.MaxWidth > 0 && t.MaxHeight > 0 {
+			img = imaging.Fit(img, t.MaxWidth, t.MaxHeight, imaging.Lanczos)
+		} else if t.MaxWidth > 0 {
+			img = imaging.Resize(img, t.MaxWidth, 0, imaging.Lanczos)
+		} else {
+			img = imaging.Resize(img, 0, t.MaxHeight, imaging.Lanczos)
+		}
+	}
+
+	quality := t.Quality
+	if quality <= 0 || quality > 100 {
+		quality = DefaultImageQuality
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = imaging.Encode(buf, img, format, imaging.JPEGQuality(quality))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf, t.ContentType(contentType), nil
+}
@@ -0,0 +1,329 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestImageTransformIsZero(t *testing.T) {
+	scenarios := []struct {
+		transform filesystem.ImageTransform
+		expected  bool
+	}{
+		{filesystem.ImageTransform{}, true},
+		{filesystem.ImageTransform{MaxWidth: 1}, false},
+		{filesystem.ImageTransform{Format: "png"}, false},
+		{filesystem.ImageTransform{StripMetadata: true}, false},
+	}
+
+	for i, s := range scenarios {
+		if v := s.transform.IsZero(); v != s.expected {
+			t.Errorf("[%d] Expected %v, got %v", i, s.expected, v)
+		}
+	}
+}
+
+func TestImageTransformExt(t *testing.T) {
+	scenarios := []struct {
+		transform   filesystem.ImageTransform
+		contentType string
+		expected    string
+	}{
+		{filesystem.ImageTransform{}, "image/jpeg", ""},
+		{filesystem.ImageTransform{Format: "png"}, "text/plain", ""},
+		{filesystem.ImageTransform{Format: "png"}, "image/gif", ""},
+		{filesystem.ImageTransform{Format: "png"}, "image/png", ""},
+		{filesystem.ImageTransform{Format: "png"}, "image/jpeg", ".png"},
+		{filesystem.ImageTransform{Format: "jpeg"}, "image/png", ".jpg"},
+		{filesystem.ImageTransform{MaxWidth: 10}, "image/jpeg", ""},
+		{filesystem.ImageTransform{MaxWidth: 10}, "image/webp", ".png"},
+		{filesystem.ImageTransform{MaxWidth: 10}, "image/bmp", ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.contentType+"_"+s.transform.Format, func(t *testing.T) {
+			if v := s.transform.Ext(s.contentType); v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestImageTransformContentType(t *testing.T) {
+	scenarios := []struct {
+		transform   filesystem.ImageTransform
+		contentType string
+		expected    string
+	}{
+		{filesystem.ImageTransform{}, "image/jpeg", "image/jpeg"},
+		{filesystem.ImageTransform{Format: "png"}, "text/plain", "text/plain"},
+		{filesystem.ImageTransform{Format: "png"}, "image/gif", "image/gif"},
+		{filesystem.ImageTransform{Format: "png"}, "image/png", "image/png"},
+		{filesystem.ImageTransform{Format: "png"}, "image/jpeg", "image/png"},
+		{filesystem.ImageTransform{Format: "jpeg"}, "image/png", "image/jpeg"},
+		{filesystem.ImageTransform{MaxWidth: 10}, "image/webp", "image/png"},
+		{filesystem.ImageTransform{MaxWidth: 10}, "image/bmp", "image/bmp"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.contentType+"_"+s.transform.Format, func(t *testing.T) {
+			if v := s.transform.ContentType(s.contentType); v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestImageTransformTransformFile(t *testing.T) {
+	pngBuf := new(bytes.Buffer)
+	if err := png.Encode(pngBuf, image.NewRGBA(image.Rect(0, 0, 100, 50))); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := filesystem.NewFileFromBytes(pngBuf.Bytes(), "test.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("nothing to change", func(t *testing.T) {
+		result, err := filesystem.ImageTransform{MaxWidth: 200}.TransformFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != file {
+			t.Fatalf("Expected the same file, got %v", result)
+		}
+	})
+
+	t.Run("format conversion", func(t *testing.T) {
+		result, err := filesystem.ImageTransform{MaxWidth: 50, Format: "jpeg"}.TransformFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedName := strings.TrimSuffix(file.Name, ".png") + ".jpg"
+		if result.Name != expectedName {
+			t.Fatalf("Expected name %q, got %q", expectedName, result.Name)
+		}
+
+		if result.OriginalName != file.OriginalName {
+			t.Fatalf("Expected original name %q, got %q", file.OriginalName, result.OriginalName)
+		}
+
+		r, err := result.Reader.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if int64(len(content)) != result.Size {
+			t.Fatalf("Expected size %d, got %d", len(content), result.Size)
+		}
+
+		config, format, err := image.DecodeConfig(bytes.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format != "jpeg" || config.Width != 50 || config.Height != 25 {
+			t.Fatalf("Expected 50x25 jpeg image, got %dx%d %s", config.Width, config.Height, format)
+		}
+	})
+
+	t.Run("too many pixels", func(t *testing.T) {
+		defer func(original int) {
+			filesystem.MaxImageTransformPixels = original
+		}(filesystem.MaxImageTransformPixels)
+
+		filesystem.MaxImageTransformPixels = 100*50 - 1
+
+		_, err := filesystem.ImageTransform{MaxWidth: 50}.TransformFile(file)
+		if !errors.Is(err, filesystem.ErrImageTooLarge) {
+			t.Fatalf("Expected ErrImageTooLarge, got %v", err)
+		}
+	})
+}
+
+func TestFileSystemUploadFileWithImageTransform(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	pngBuf := new(bytes.Buffer)
+	if err := png.Encode(pngBuf, image.NewRGBA(image.Rect(0, 0, 200, 100))); err != nil {
+		t.Fatal(err)
+	}
+
+	jpgBuf := new(bytes.Buffer)
+	if err := jpeg.Encode(jpgBuf, image.NewRGBA(image.Rect(0, 0, 50, 40)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name              string
+		content           []byte
+		transform         filesystem.ImageTransform
+		expectError       bool
+		expectUnchanged   bool
+		expectContentType string
+		expectWidth       int
+		expectHeight      int
+	}{
+		{
+			"non-image file",
+			[]byte("test"),
+			filesystem.ImageTransform{MaxWidth: 10, Format: "png"},
+			false,
+			true,
+			"text/plain; charset=utf-8",
+			0,
+			0,
+		},
+		{
+			"within the max dimensions",
+			jpgBuf.Bytes(),
+			filesystem.ImageTransform{MaxWidth: 100, MaxHeight: 100},
+			false,
+			true,
+			"image/jpeg",
+			50,
+			40,
+		},
+		{
+			"strip metadata",
+			jpgBuf.Bytes(),
+			filesystem.ImageTransform{MaxWidth: 100, StripMetadata: true},
+			false,
+			false,
+			"image/jpeg",
+			50,
+			40,
+		},
+		{
+			"max width",
+			pngBuf.Bytes(),
+			filesystem.ImageTransform{MaxWidth: 100},
+			false,
+			false,
+			"image/png",
+			100,
+			50,
+		},
+		{
+			"max height",
+			pngBuf.Bytes(),
+			filesystem.ImageTransform{MaxHeight: 20},
+			false,
+			false,
+			"image/png",
+			40,
+			20,
+		},
+		{
+			"max width and height",
+			pngBuf.Bytes(),
+			filesystem.ImageTransform{MaxWidth: 50, MaxHeight: 50},
+			false,
+			false,
+			"image/png",
+			50,
+			25,
+		},
+		{
+			"format conversion",
+			pngBuf.Bytes(),
+			filesystem.ImageTransform{Format: "jpeg", Quality: 50},
+			false,
+			false,
+			"image/jpeg",
+			200,
+			100,
+		},
+		{
+			"unsupported format",
+			pngBuf.Bytes(),
+			filesystem.ImageTransform{Format: "gif"},
+			true,
+			false,
+			"",
+			0,
+			0,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			file, err := filesystem.NewFileFromBytes(s.content, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = fsys.UploadFile(file, s.name, s.transform)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+			if hasErr {
+				return
+			}
+
+			r, err := fsys.GetReader(s.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			if r.ContentType() != s.expectContentType {
+				t.Fatalf("Expected content type %q, got %q", s.expectContentType, r.ContentType())
+			}
+
+			content := new(bytes.Buffer)
+			if _, err := content.ReadFrom(r); err != nil {
+				t.Fatal(err)
+			}
+
+			unchanged := bytes.Equal(content.Bytes(), s.content)
+			if unchanged != s.expectUnchanged {
+				t.Fatalf("Expected unchanged %v, got %v", s.expectUnchanged, unchanged)
+			}
+
+			if mt := mimetype.Detect(content.Bytes()); !mt.Is(s.expectContentType) {
+				t.Fatalf("Expected stored file mimetype %q, got %q", s.expectContentType, mt.String())
+			}
+
+			if s.expectWidth == 0 {
+				return
+			}
+
+			config, _, err := image.DecodeConfig(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if config.Width != s.expectWidth || config.Height != s.expectHeight {
+				t.Fatalf("Expected %dx%d image, got %dx%d", s.expectWidth, s.expectHeight, config.Width, config.Height)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPresignExpires is the max allowed presigned URL expiration duration.
+const MaxPresignExpires = 7 * 24 * time.Hour
+
+// PresignURL generates a presigned URL for the specified object key
+// that allows anyone with it to perform the method request
+// without the need of credentials until the URL expires.
+//
+// The optional request funcs could be used to register additional signed
+// headers (eg. Content-Type for PUT requests) or query parameters
+// (eg. response-content-disposition for GET requests).
+// The client using the presigned URL must send the same signed headers.
+//
+// If the "X-Amz-Date" query parameter is set by the optional request funcs
+// it is used as signing time instead of the current date time.
+//
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
+func (s3 *S3) PresignURL(method string, key string, expires time.Duration, optFuncs ...func(*http.Request)) (string, error) {
+	if expires <= 0 || expires > MaxPresignExpires {
+		return "", errors.New("the presigned URL expiration must be between 1 second and 7 days")
+	}
+
+	req, err := http.NewRequest(method, s3.URL(key), nil)
+	if err != nil {
+		return "", err
+	}
+
+	// apply optional request funcs
+	for _, fn := range optFuncs {
+		if fn != nil {
+			fn(req)
+		}
+	}
+
+	query := req.URL.Query()
+
+	reqDateTime, _ := time.Parse(dateTimeFormat, query.Get("X-Amz-Date"))
+	if reqDateTime.IsZero() {
+		reqDateTime = time.Now().UTC()
+	}
+
+	date := reqDateTime.Format("20060102")
+
+	scope := strings.Join([]string{
+		date,
+		s3.Region,
+		awsS3ServiceCode,
+		awsTerminationString,
+	}, "/")
+
+	req.Header.Set("host", req.URL.Host)
+
+	canonicalHeaders, signedHeaders := canonicalAndSignedHeaders(req)
+
+	query.Set("X-Amz-Algorithm", awsSignAlgorithm)
+	query.Set("X-Amz-Credential", s3.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", reqDateTime.Format(dateTimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	query.Del("X-Amz-Signature")
+
+	canonicalQuery := escapeQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapePath(req.URL.Path),
+		canonicalQuery,
+		canonicalHeaders,
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		awsSignAlgorithm,
+		reqDateTime.Format(dateTimeFormat),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	req.URL.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s3.signature(date, stringToSign)
+
+	return req.URL.String(), nil
+}
@@ -210,14 +210,10 @@ func (s3 *S3) sign(req *http.Request) {
 		hashedCanonicalRequest,
 	}, "\n")
 
-	// 4. Derive a signing key for SigV4
+	// 4. Derive a signing key for SigV4 and calculate the signature
 	// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html#derive-signing-key
 	// ---------------------------------------------------------------
-	dateKey := hmacSHA256([]byte("AWS4"+s3.SecretKey), date)
-	dateRegionKey := hmacSHA256(dateKey, s3.Region)
-	dateRegionServiceKey := hmacSHA256(dateRegionKey, awsS3ServiceCode)
-	signingKey := hmacSHA256(dateRegionServiceKey, awsTerminationString)
-	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	signature := s3.signature(date, stringToSign)
 
 	// 5. Add the signature to the request
 	// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html#add-signature-to-request
@@ -233,6 +229,16 @@ func (s3 *S3) sign(req *http.Request) {
 	req.Header.Set("authorization", authorization)
 }
 
+// signature derives the SigV4 signing key for the specified date and returns the stringToSign signature.
+func (s3 *S3) signature(date string, stringToSign string) string {
+	dateKey := hmacSHA256([]byte("AWS4"+s3.SecretKey), date)
+	dateRegionKey := hmacSHA256(dateKey, s3.Region)
+	dateRegionServiceKey := hmacSHA256(dateRegionKey, awsS3ServiceCode)
+	signingKey := hmacSHA256(dateRegionServiceKey, awsTerminationString)
+
+	return hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+}
+
 func sha256Hex(content []byte) string {
 	h := sha256.New()
 	h.Write(content)
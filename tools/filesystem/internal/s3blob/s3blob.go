@@ -154,6 +154,32 @@ func (drv *driver) ListPaged(ctx context.Context, opts *blob.ListOptions) (*blob
 	return &page, nil
 }
 
+// SignedURL implements [blob/DriverSigner.SignedURL].
+func (drv *driver) SignedURL(ctx context.Context, key string, opts *blob.SignedURLOptions) (string, error) {
+	key = escapeKey(key)
+
+	return drv.s3.PresignURL(opts.Method, key, opts.Expiry, func(r *http.Request) {
+		if opts.Method == http.MethodPut {
+			if opts.ContentType != "" {
+				r.Header.Set("Content-Type", opts.ContentType)
+			}
+			return
+		}
+
+		query := r.URL.Query()
+		if opts.ResponseContentType != "" {
+			query.Set("response-content-type", opts.ResponseContentType)
+		}
+		if opts.ResponseContentDisposition != "" {
+			query.Set("response-content-disposition", opts.ResponseContentDisposition)
+		}
+		if opts.ResponseCacheControl != "" {
+			query.Set("response-cache-control", opts.ResponseCacheControl)
+		}
+		r.URL.RawQuery = query.Encode()
+	})
+}
+
 // Attributes implements [blob/Driver.Attributes].
 func (drv *driver) Attributes(ctx context.Context, key string) (*blob.Attributes, error) {
 	key = escapeKey(key)
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
 	"github.com/pocketbase/pocketbase/tools/filesystem/internal/s3blob"
@@ -222,6 +223,82 @@ func TestDriverCopyEscaping(t *testing.T) {
 	}
 }
 
+func TestDriverSignedURL(t *testing.T) {
+	t.Parallel()
+
+	drv, err := s3blob.New(&s3.S3{
+		Bucket:    "test_bucket",
+		Region:    "test_region",
+		Endpoint:  "https://example.com",
+		AccessKey: "123",
+		SecretKey: "abc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, ok := drv.(blob.DriverSigner)
+	if !ok {
+		t.Fatal("Expected the driver to implement blob.DriverSigner")
+	}
+
+	scenarios := []struct {
+		name        string
+		key         string
+		opts        *blob.SignedURLOptions
+		expectParts []string
+	}{
+		{
+			"GET with response overrides",
+			"../a b",
+			&blob.SignedURLOptions{
+				Method:                     http.MethodGet,
+				Expiry:                     time.Hour,
+				ResponseContentType:        "image/png",
+				ResponseContentDisposition: "inline; filename=test.png",
+			},
+			[]string{
+				"https://test_bucket.example.com/..__0x2f__a%20b?",
+				"X-Amz-Expires=3600",
+				"X-Amz-SignedHeaders=host&",
+				"response-content-disposition=inline%3B%20filename%3Dtest.png",
+				"response-content-type=image%2Fpng",
+				"X-Amz-Signature=",
+			},
+		},
+		{
+			"PUT with content type",
+			"test",
+			&blob.SignedURLOptions{
+				Method:      http.MethodPut,
+				Expiry:      time.Minute,
+				ContentType: "text/plain",
+			},
+			[]string{
+				"https://test_bucket.example.com/test?",
+				"X-Amz-Expires=60",
+				"X-Amz-SignedHeaders=content-type%3Bhost&",
+				"X-Amz-Signature=",
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := signer.SignedURL(context.Background(), s.key, s.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, part := range s.expectParts {
+				if !strings.Contains(result, part) {
+					t.Fatalf("Expected %q in\n%s", part, result)
+				}
+			}
+		})
+	}
+}
+
 func TestDriverAttributes(t *testing.T) {
 	t.Parallel()
 
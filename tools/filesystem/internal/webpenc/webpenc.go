@@ -0,0 +1,397 @@
+// Package webpenc implements a minimal lossless WebP (VP8L) encoder.
+//
+// The encoder intentionally supports only a small subset of the format
+// (the "subtract green" transform and a single set of prefix codes
+// without backward references or color cache) which is enough
+// for the relatively small thumb images.
+//
+// There is no lossy (VP8) encoding and therefore no quality option -
+// the produced images are usually larger than the lossy WebP images
+// of other encoders but could be decoded by any WebP decoder
+// (including golang.org/x/image/webp).
+package webpenc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"sort"
+)
+
+const (
+	maxDimension = 1 << 14
+
+	// the number of literal (green) symbols + the length prefix codes (24)
+	greenAlphabetSize    = 256 + 24
+	distanceAlphabetSize = 40
+
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+
+	transformSubtractGreen = 2
+)
+
+// the order in which the code length code lengths are stored
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// Encode writes the image m to w in lossless WebP format.
+func Encode(w io.Writer, m image.Image) error {
+	b := m.Bounds()
+
+	width, height := b.Dx(), b.Dy()
+	if width <= 0 || height <= 0 {
+		return errors.New("webpenc: empty image")
+	}
+	if width > maxDimension || height > maxDimension {
+		return errors.New("webpenc: image is too large")
+	}
+
+	nrgba, ok := m.(*image.NRGBA)
+	if !ok || nrgba.Rect.Min != (image.Point{}) {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Bounds(), m, b.Min, draw.Src)
+	}
+
+	// collect the "subtract green" transformed argb channels
+	// and their histograms
+	// ---
+	pixels := make([][4]byte, 0, width*height) // green, red, blue, alpha
+	var histograms [4][]int
+	histograms[0] = make([]int, greenAlphabetSize)
+	histograms[1] = make([]int, 256)
+	histograms[2] = make([]int, 256)
+	histograms[3] = make([]int, 256)
+
+	hasAlpha := false
+
+	for y := 0; y < height; y++ {
+		row := nrgba.Pix[y*nrgba.Stride : y*nrgba.Stride+width*4]
+		for x := 0; x < width*4; x += 4 {
+			g := row[x+1]
+			p := [4]byte{g, row[x] - g, row[x+2] - g, row[x+3]}
+			if p[3] != 0xff {
+				hasAlpha = true
+			}
+			for i, v := range p {
+				histograms[i][v]++
+			}
+			pixels = append(pixels, p)
+		}
+	}
+
+	bw := &bitWriter{}
+
+	// header
+	bw.writeBits(0x2f, 8)
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	if hasAlpha {
+		bw.writeBits(1, 1)
+	} else {
+		bw.writeBits(0, 1)
+	}
+	bw.writeBits(0, 3) // version
+
+	// transforms
+	bw.writeBits(1, 1)
+	bw.writeBits(transformSubtractGreen, 2)
+	bw.writeBits(0, 1) // no more transforms
+
+	bw.writeBits(0, 1) // no color cache
+	bw.writeBits(0, 1) // no meta prefix codes
+
+	// prefix codes
+	var codes [4]*prefixCode
+	for i, h := range histograms {
+		codes[i] = writePrefixCode(bw, h)
+	}
+	writePrefixCode(bw, make([]int, distanceAlphabetSize)) // unused
+
+	// pixels
+	for _, p := range pixels {
+		for i, v := range p {
+			codes[i].write(bw, int(v))
+		}
+	}
+
+	data := bw.bytes()
+
+	// RIFF container
+	chunkSize := len(data)
+	padding := chunkSize & 1
+
+	buf := bytes.NewBuffer(make([]byte, 0, 20+chunkSize+padding))
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(4+8+chunkSize+padding))
+	buf.WriteString("WEBPVP8L")
+	binary.Write(buf, binary.LittleEndian, uint32(chunkSize))
+	buf.Write(data)
+	if padding > 0 {
+		buf.WriteByte(0)
+	}
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+// -------------------------------------------------------------------
+
+type prefixCode struct {
+	lengths []int
+	codes   []uint32 // bit reversed canonical codes
+
+	// single indicates that the code has only one symbol
+	// and therefore no bits are written for it
+	single bool
+}
+
+func (c *prefixCode) write(bw *bitWriter, symbol int) {
+	if c.single {
+		return
+	}
+
+	if l := c.lengths[symbol]; l > 0 {
+		bw.writeBits(c.codes[symbol], l)
+	}
+}
+
+// writePrefixCode writes the prefix code for the provided symbols histogram
+// and returns the prefix code that should be used for encoding the symbols.
+func writePrefixCode(bw *bitWriter, histogram []int) *prefixCode {
+	symbols := make([]int, 0, 2)
+	for s, count := range histogram {
+		if count > 0 {
+			symbols = append(symbols, s)
+			if len(symbols) > 2 {
+				break
+			}
+		}
+	}
+
+	// simple code
+	if len(symbols) <= 2 && (len(symbols) == 0 || symbols[len(symbols)-1] < 256) {
+		code := &prefixCode{
+			lengths: make([]int, len(histogram)),
+			codes:   make([]uint32, len(histogram)),
+		}
+
+		if len(symbols) == 0 {
+			symbols = append(symbols, 0)
+		}
+
+		bw.writeBits(1, 1)
+		bw.writeBits(uint32(len(symbols)-1), 1)
+		if symbols[0] <= 1 {
+			bw.writeBits(0, 1)
+			bw.writeBits(uint32(symbols[0]), 1)
+		} else {
+			bw.writeBits(1, 1)
+			bw.writeBits(uint32(symbols[0]), 8)
+		}
+
+		if len(symbols) == 2 {
+			bw.writeBits(uint32(symbols[1]), 8)
+			code.lengths[symbols[0]], code.codes[symbols[0]] = 1, 0
+			code.lengths[symbols[1]], code.codes[symbols[1]] = 1, 1
+		}
+
+		return code
+	}
+
+	// normal code
+	code := newPrefixCode(histogram, maxCodeLength)
+
+	clHistogram := make([]int, len(codeLengthCodeOrder))
+	for _, l := range code.lengths {
+		clHistogram[l]++
+	}
+	clCode := newPrefixCode(clHistogram, maxCodeLengthCodeLength)
+
+	numCodes := len(codeLengthCodeOrder)
+	for numCodes > 4 && clCode.lengths[codeLengthCodeOrder[numCodes-1]] == 0 {
+		numCodes--
+	}
+
+	bw.writeBits(0, 1)
+	bw.writeBits(uint32(numCodes-4), 4)
+	for i := 0; i < numCodes; i++ {
+		bw.writeBits(uint32(clCode.lengths[codeLengthCodeOrder[i]]), 3)
+	}
+
+	bw.writeBits(0, 1) // max_symbol = alphabet size
+	for _, l := range code.lengths {
+		clCode.write(bw, l)
+	}
+
+	return code
+}
+
+// newPrefixCode builds a length limited canonical prefix code for the provided histogram.
+func newPrefixCode(histogram []int, maxLength int) *prefixCode {
+	counts := make([]int, len(histogram))
+	copy(counts, histogram)
+
+	var lengths []int
+	for {
+		lengths = huffmanLengths(counts)
+
+		valid := true
+		for _, l := range lengths {
+			if l > maxLength {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			break
+		}
+
+		// flatten the distribution and try again
+		for i, c := range counts {
+			if c > 0 {
+				counts[i] = (c + 1) / 2
+			}
+		}
+	}
+
+	code := &prefixCode{
+		lengths: lengths,
+		codes:   make([]uint32, len(lengths)),
+	}
+
+	// the decoders expect a single symbol to be stored with nonzero length
+	used := -1
+	for s, c := range counts {
+		if c > 0 {
+			if used >= 0 {
+				used = -1
+				break
+			}
+			used = s
+		}
+	}
+	if used >= 0 {
+		code.lengths[used] = 1
+		code.single = true
+		return code
+	}
+
+	// canonical codes (the same as in DEFLATE)
+	var lengthsCount [maxCodeLength + 1]int
+	for _, l := range lengths {
+		if l > 0 {
+			lengthsCount[l]++
+		}
+	}
+
+	var nextCode [maxCodeLength + 2]uint32
+	var c uint32
+	for l := 1; l <= maxCodeLength; l++ {
+		c = (c + uint32(lengthsCount[l-1])) << 1
+		nextCode[l] = c
+	}
+
+	for s, l := range lengths {
+		if l > 0 {
+			code.codes[s] = reverseBits(nextCode[l], l)
+			nextCode[l]++
+		}
+	}
+
+	return code
+}
+
+// huffmanLengths returns the Huffman code lengths for the provided symbol counts.
+func huffmanLengths(counts []int) []int {
+	type node struct {
+		count  int
+		symbol int // -1 for internal nodes
+		left   *node
+		right  *node
+	}
+
+	lengths := make([]int, len(counts))
+
+	nodes := make([]*node, 0, len(counts))
+	for s, c := range counts {
+		if c > 0 {
+			nodes = append(nodes, &node{count: c, symbol: s})
+		}
+	}
+
+	if len(nodes) <= 1 {
+		return lengths
+	}
+
+	// note: a simple sorted slice is used instead of a heap
+	// because the alphabets are relatively small
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].count < nodes[j].count
+	})
+
+	for len(nodes) > 1 {
+		parent := &node{count: nodes[0].count + nodes[1].count, symbol: -1, left: nodes[0], right: nodes[1]}
+		nodes = nodes[2:]
+
+		i := sort.Search(len(nodes), func(i int) bool {
+			return nodes[i].count > parent.count
+		})
+		nodes = append(nodes, nil)
+		copy(nodes[i+1:], nodes[i:])
+		nodes[i] = parent
+	}
+
+	var walk func(n *node, depth int)
+	walk = func(n *node, depth int) {
+		if n.symbol >= 0 {
+			lengths[n.symbol] = depth
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk(nodes[0], 0)
+
+	return lengths
+}
+
+func reverseBits(code uint32, length int) uint32 {
+	var result uint32
+	for i := 0; i < length; i++ {
+		result = result<<1 | code&1
+		code >>= 1
+	}
+	return result
+}
+
+// -------------------------------------------------------------------
+
+// bitWriter writes bits in LSB-first order.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits int
+}
+
+func (w *bitWriter) writeBits(v uint32, n int) {
+	w.acc |= uint64(v) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nbits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc = 0
+		w.nbits = 0
+	}
+	return w.buf
+}
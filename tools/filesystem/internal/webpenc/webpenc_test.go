@@ -0,0 +1,108 @@
+package webpenc_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/bits"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem/internal/webpenc"
+	"golang.org/x/image/webp"
+)
+
+func TestEncode(t *testing.T) {
+	gradient := image.NewNRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x * 4), uint8(y * 5), uint8(x * y), 255})
+		}
+	}
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 7, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 7; x++ {
+			transparent.SetNRGBA(x, y, color.NRGBA{uint8(x * 30), 10, uint8(y), uint8(x * 35)})
+		}
+	}
+
+	twoColors := image.NewNRGBA(image.Rect(0, 0, 5, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			if (x+y)%2 == 0 {
+				twoColors.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+			} else {
+				twoColors.SetNRGBA(x, y, color.NRGBA{0, 0, 255, 255})
+			}
+		}
+	}
+
+	// geometric distribution of the channel values
+	// (to ensure that the code lengths are limited)
+	skewed := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+	for i := 1; i <= 256*256; i++ {
+		v := uint8(bits.TrailingZeros(uint(i)))
+		skewed.SetNRGBA((i-1)%256, (i-1)/256, color.NRGBA{v, v * 2, v * 3, 255})
+	}
+
+	scenarios := []struct {
+		name string
+		img  image.Image
+	}{
+		{"1x1", image.NewNRGBA(image.Rect(0, 0, 1, 1))},
+		{"solid", image.NewUniform(color.NRGBA{10, 20, 30, 255})},
+		{"two colors", twoColors},
+		{"gradient", gradient},
+		{"transparent", transparent},
+		{"skewed", skewed},
+		{"rgba with offset", image.NewRGBA(image.Rect(5, 5, 20, 10))},
+		{"gray", image.NewGray(image.Rect(0, 0, 300, 2))},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			img := s.img
+			if _, ok := img.(*image.Uniform); ok {
+				sub := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+				for y := 0; y < 10; y++ {
+					for x := 0; x < 10; x++ {
+						sub.Set(x, y, img.At(x, y))
+					}
+				}
+				img = sub
+			}
+
+			buf := new(bytes.Buffer)
+			if err := webpenc.Encode(buf, img); err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := webp.Decode(buf)
+			if err != nil {
+				t.Fatalf("Failed to decode the encoded image: %v", err)
+			}
+
+			b := img.Bounds()
+			if decoded.Bounds().Dx() != b.Dx() || decoded.Bounds().Dy() != b.Dy() {
+				t.Fatalf("Expected %v size, got %v", b.Size(), decoded.Bounds().Size())
+			}
+
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					expected := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y))
+					actual := color.NRGBAModel.Convert(decoded.At(x, y))
+					if expected != actual {
+						t.Fatalf("Expected pixel (%d,%d) to be %v, got %v", x, y, expected, actual)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeEmpty(t *testing.T) {
+	err := webpenc.Encode(new(bytes.Buffer), image.NewNRGBA(image.Rect(0, 0, 0, 0)))
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
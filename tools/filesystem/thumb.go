@@ -0,0 +1,220 @@
+package filesystem
+
+import (
+	"errors"
+	"image"
+	"math"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	ThumbModeCenter = "c"
+	ThumbModeTop    = "t"
+	ThumbModeBottom = "b"
+	ThumbModeFit    = "f"
+	ThumbModeSmart  = "s"
+	ThumbModeFocal  = "p"
+)
+
+const (
+	ThumbFormatWebp = "webp"
+	ThumbFormatPNG  = "png"
+	ThumbFormatJPG  = "jpg"
+)
+
+// ThumbSize defines a parsed thumb size spec.
+type ThumbSize struct {
+	Width  int
+	Height int
+
+	// Mode is the crop mode of the thumb (one of the ThumbMode* constants or empty for center crop).
+	Mode string
+
+	// Format is the optional output format of the thumb (one of the ThumbFormat* constants).
+	//
+	// If empty, the thumb preserves the original image format.
+	Format string
+
+	// Quality is the optional JPEG encoding quality (1-100).
+	//
+	// Note that the WebP thumbs are always encoded lossless.
+	Quality int
+}
+
+// FocalPoint defines the relative (0-1) coordinates of the most
+// important image area used for cropping the "p" mode thumbs.
+type FocalPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Clamp returns a new FocalPoint with coordinates limited to the 0-1 range.
+func (p FocalPoint) Clamp() FocalPoint {
+	return FocalPoint{
+		X: math.Max(0, math.Min(1, p.X)),
+		Y: math.Max(0, math.Min(1, p.Y)),
+	}
+}
+
+// String returns a short string representation of the focal point
+// (eg. "0.25,0.5") that is safe to use as part of a file key.
+func (p FocalPoint) String() string {
+	p = p.Clamp()
+
+	return strconv.FormatFloat(p.X, 'f', -1, 64) + "," + strconv.FormatFloat(p.Y, 'f', -1, 64)
+}
+
+// ParseThumbSize parses the provided thumb size spec in the format
+// "WxH[mode][_q{quality}][.{format}]" (eg. "100x100", "300x200s_q80.jpg", "300x0.webp").
+//
+// See [System.CreateThumb] for the list of the supported modes.
+func ParseThumbSize(spec string) (ThumbSize, error) {
+	result := ThumbSize{}
+
+	parts := ThumbSizeRegex.FindStringSubmatch(spec)
+	if len(parts) != 6 {
+		return result, errors.New("thumb size must be in WxH[mode][_q{quality}][.{format}] format")
+	}
+
+	result.Width, _ = strconv.Atoi(parts[1])
+	result.Height, _ = strconv.Atoi(parts[2])
+	result.Mode = parts[3]
+	result.Quality, _ = strconv.Atoi(parts[4])
+	result.Format = parts[5]
+
+	if result.Format == "jpeg" {
+		result.Format = ThumbFormatJPG
+	}
+
+	if result.Width == 0 && result.Height == 0 {
+		return result, errors.New("thumb width and height cannot be zero at the same time")
+	}
+
+	if parts[4] != "" && (result.Quality < 1 || result.Quality > 100) {
+		return result, errors.New("thumb quality must be between 1 and 100")
+	}
+
+	// the webp thumbs are always lossless and the png format doesn't have a quality setting
+	if parts[4] != "" && (result.Format == ThumbFormatWebp || result.Format == ThumbFormatPNG) {
+		return result, errors.New("thumb quality is not supported for the " + result.Format + " format")
+	}
+
+	return result, nil
+}
+
+// ContentType returns the content type of the thumb created from an original with the specified content type.
+func (ts ThumbSize) ContentType(originalContentType string) string {
+	switch ts.Format {
+	case ThumbFormatWebp:
+		return "image/webp"
+	case ThumbFormatPNG:
+		return "image/png"
+	case ThumbFormatJPG:
+		return "image/jpeg"
+	}
+
+	switch originalContentType {
+	case "image/jpeg", "image/gif", "image/tiff", "image/bmp":
+		return originalContentType
+	default:
+		// fallback to PNG (this includes webp!)
+		return "image/png"
+	}
+}
+
+// fillFocal resizes and crops img to a width x height viewbox
+// keeping the focal point as close to the center as possible.
+func fillFocal(img image.Image, width, height int, focal FocalPoint) *image.NRGBA {
+	resized := resizeToCover(img, width, height)
+
+	focal = focal.Clamp()
+
+	b := resized.Bounds()
+
+	x := int(math.Round(focal.X*float64(b.Dx()))) - width/2
+	y := int(math.Round(focal.Y*float64(b.Dy()))) - height/2
+
+	x = max(0, min(x, b.Dx()-width))
+	y = max(0, min(y, b.Dy()-height))
+
+	return imaging.Crop(resized, image.Rect(x, y, x+width, y+height))
+}
+
+// fillSmart resizes and crops img to a width x height viewbox
+// selecting the area with the most details (aka. highest edges energy).
+func fillSmart(img image.Image, width, height int) *image.NRGBA {
+	resized := resizeToCover(img, width, height)
+
+	b := resized.Bounds()
+
+	horizontal := b.Dx() > width
+	if !horizontal && b.Dy() <= height {
+		return resized // nothing to crop
+	}
+
+	gray := imaging.Grayscale(resized)
+
+	// calculate the energy of each column (or row)
+	energy := make([]float64, max(b.Dx(), b.Dy()))
+	for y := 1; y < b.Dy()-1; y++ {
+		for x := 1; x < b.Dx()-1; x++ {
+			i := y*gray.Stride + x*4
+			dx := float64(gray.Pix[i+4]) - float64(gray.Pix[i-4])
+			dy := float64(gray.Pix[i+gray.Stride]) - float64(gray.Pix[i-gray.Stride])
+			e := math.Abs(dx) + math.Abs(dy)
+			if horizontal {
+				energy[x] += e
+			} else {
+				energy[y] += e
+			}
+		}
+	}
+
+	size, window := b.Dy(), height
+	if horizontal {
+		size, window = b.Dx(), width
+	}
+
+	// find the window with the highest energy
+	// (on equal energy the window closest to the center is preferred)
+	var sum float64
+	for i := 0; i < window; i++ {
+		sum += energy[i]
+	}
+
+	center := (size - window) / 2
+	best, bestSum := 0, sum
+	for offset := 1; offset <= size-window; offset++ {
+		sum += energy[offset+window-1] - energy[offset-1]
+		if sum > bestSum || (sum == bestSum && abs(offset-center) < abs(best-center)) {
+			best, bestSum = offset, sum
+		}
+	}
+
+	if horizontal {
+		return imaging.Crop(resized, image.Rect(best, 0, best+width, height))
+	}
+
+	return imaging.Crop(resized, image.Rect(0, best, width, best+height))
+}
+
+// resizeToCover resizes img preserving its aspect ratio so that it covers the width x height viewbox.
+func resizeToCover(img image.Image, width, height int) *image.NRGBA {
+	b := img.Bounds()
+
+	scale := math.Max(float64(width)/float64(b.Dx()), float64(height)/float64(b.Dy()))
+
+	w := max(width, int(math.Round(float64(b.Dx())*scale)))
+	h := max(height, int(math.Round(float64(b.Dy())*scale)))
+
+	return imaging.Resize(img, w, h, imaging.Linear)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,183 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestParseThumbSize(t *testing.T) {
+	scenarios := []struct {
+		spec        string
+		expectError bool
+		expected    filesystem.ThumbSize
+	}{
+		{"", true, filesystem.ThumbSize{}},
+		{"invalid", true, filesystem.ThumbSize{}},
+		{"0x0", true, filesystem.ThumbSize{}},
+		{"0x0f.webp", true, filesystem.ThumbSize{}},
+		{"100x100x", true, filesystem.ThumbSize{}},
+		{"100x100_q0", true, filesystem.ThumbSize{}},
+		{"100x100_q101", true, filesystem.ThumbSize{}},
+		{"100x100.gif", true, filesystem.ThumbSize{}},
+		{"100x100_q80.webp", true, filesystem.ThumbSize{}},
+		{"100x100_q80.png", true, filesystem.ThumbSize{}},
+		{"100x100", false, filesystem.ThumbSize{Width: 100, Height: 100}},
+		{"0x50t", false, filesystem.ThumbSize{Width: 0, Height: 50, Mode: "t"}},
+		{"100x50s.webp", false, filesystem.ThumbSize{Width: 100, Height: 50, Mode: "s", Format: "webp"}},
+		{"100x50p_q80", false, filesystem.ThumbSize{Width: 100, Height: 50, Mode: "p", Quality: 80}},
+		{"100x50_q1.jpeg", false, filesystem.ThumbSize{Width: 100, Height: 50, Quality: 1, Format: "jpg"}},
+		{"100x50c.png", false, filesystem.ThumbSize{Width: 100, Height: 50, Mode: "c", Format: "png"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.spec, func(t *testing.T) {
+			result, err := filesystem.ParseThumbSize(s.spec)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && result != s.expected {
+				t.Fatalf("Expected\n%#v\ngot\n%#v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestThumbSizeContentType(t *testing.T) {
+	scenarios := []struct {
+		spec     string
+		original string
+		expected string
+	}{
+		{"100x100", "image/jpeg", "image/jpeg"},
+		{"100x100", "image/gif", "image/gif"},
+		{"100x100", "image/webp", "image/png"},
+		{"100x100", "image/png", "image/png"},
+		{"100x100.webp", "image/jpeg", "image/webp"},
+		{"100x100.png", "image/jpeg", "image/png"},
+		{"100x100.jpg", "image/png", "image/jpeg"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.spec+"_"+s.original, func(t *testing.T) {
+			size, err := filesystem.ParseThumbSize(s.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := size.ContentType(s.original); v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestFocalPointString(t *testing.T) {
+	scenarios := []struct {
+		point    filesystem.FocalPoint
+		expected string
+	}{
+		{filesystem.FocalPoint{}, "0,0"},
+		{filesystem.FocalPoint{X: 0.25, Y: 1}, "0.25,1"},
+		{filesystem.FocalPoint{X: -1, Y: 2}, "0,1"},
+	}
+
+	for _, s := range scenarios {
+		if v := s.point.String(); v != s.expected {
+			t.Errorf("Expected %q, got %q", s.expected, v)
+		}
+	}
+}
+
+func TestFileSystemCreateThumbCrop(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	// 200x100 image with solid red left half and black and white stripes right half
+	red := color.NRGBA{255, 0, 0, 255}
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			switch {
+			case x < 100:
+				img.SetNRGBA(x, y, red)
+			case x%4 < 2:
+				img.SetNRGBA(x, y, color.NRGBA{0, 0, 0, 255})
+			default:
+				img.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+			}
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.Upload(buf.Bytes(), "crop.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		size        string
+		focalPoint  []filesystem.FocalPoint
+		expectSolid bool
+	}{
+		{"smart", "50x50s", nil, false},
+		{"focal left", "50x50p", []filesystem.FocalPoint{{X: 0, Y: 0.5}}, true},
+		{"focal right", "50x50p", []filesystem.FocalPoint{{X: 1, Y: 0.5}}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			thumbKey := "thumb_" + s.name
+
+			err := fsys.CreateThumb("crop.png", thumbKey, s.size, s.focalPoint...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := fsys.GetReader(thumbKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			thumb, _, err := image.Decode(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if b := thumb.Bounds(); b.Dx() != 50 || b.Dy() != 50 {
+				t.Fatalf("Expected 50x50 thumb, got %dx%d", b.Dx(), b.Dy())
+			}
+
+			// note: the last few pixels are skipped because of the resampling blending
+			solid := true
+			for x := 0; x < 45; x++ {
+				if color.NRGBAModel.Convert(thumb.At(x, 25)) != red {
+					solid = false
+					break
+				}
+			}
+
+			if solid != s.expectSolid {
+				t.Fatalf("Expected solid red thumb %v, got %v", s.expectSolid, solid)
+			}
+		})
+	}
+}
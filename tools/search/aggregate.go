@@ -0,0 +1,427 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"golang.org/x/sync/errgroup"
+)
+
+// URL aggregate query params
+const (
+	GroupByQueryParam   string = "groupBy"
+	AggregateQueryParam string = "agg"
+)
+
+// supported aggregate functions
+const (
+	AggregateCount string = "count"
+	AggregateSum   string = "sum"
+	AggregateAvg   string = "avg"
+	AggregateMin   string = "min"
+	AggregateMax   string = "max"
+)
+
+// AggregateFuncs lists all supported aggregate functions.
+var AggregateFuncs = []string{AggregateCount, AggregateSum, AggregateAvg, AggregateMin, AggregateMax}
+
+// Common aggregate errors.
+var (
+	ErrAggregateExprLimit        = errors.New("max group by and aggregate expressions limit reached")
+	ErrAggregateFieldLengthLimit = errors.New("max group by or aggregate field length limit reached")
+)
+
+var aggregateExprRegex = regexp.MustCompile(`^(\w+)\(\s*([^()]*?)\s*\)$`)
+
+// AggregateField defines a single search aggregate expression (eg. "sum(amount)").
+type AggregateField struct {
+	// Func is the aggregate function name (one of AggregateFuncs).
+	Func string `json:"func"`
+
+	// Field is the aggregated field identifier.
+	//
+	// It is optional only for the "count" function (aka. "count()" counts the group items).
+	Field string `json:"field"`
+}
+
+// Key returns the normalized aggregate expression that is used
+// as aggregate result item key and sort field name (eg. "sum(amount)").
+func (a AggregateField) Key() string {
+	return a.Func + "(" + a.Field + ")"
+}
+
+// ParseAggregatesFromString parses the provided comma separated string
+// expression into a slice of AggregateFields.
+//
+// Invalid expressions are returned with empty Func and they are
+// rejected on [Provider.ExecAggregate].
+//
+// Example:
+//
+//	fields := search.ParseAggregatesFromString("count(),sum(amount)")
+func ParseAggregatesFromString(str string) (fields []AggregateField) {
+	for _, expr := range splitSortExpressions(str) {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+
+		matches := aggregateExprRegex.FindStringSubmatch(expr)
+		if len(matches) != 3 {
+			fields = append(fields, AggregateField{Field: expr})
+			continue
+		}
+
+		fields = append(fields, AggregateField{Func: strings.ToLower(matches[1]), Field: matches[2]})
+	}
+
+	return
+}
+
+// parseGroupByFromString parses the provided comma separated group by fields.
+func parseGroupByFromString(str string) (fields []string) {
+	for _, field := range strings.Split(str, ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return
+}
+
+// GroupBy sets the `groupBy` field of the current search provider.
+func (s *Provider) GroupBy(fields []string) *Provider {
+	s.groupBy = fields
+	return s
+}
+
+// AddGroupBy appends the provided field to the existing provider's groupBy field.
+func (s *Provider) AddGroupBy(field string) *Provider {
+	s.groupBy = append(s.groupBy, field)
+	return s
+}
+
+// Aggregates sets the `aggregates` field of the current search provider.
+func (s *Provider) Aggregates(aggregates []AggregateField) *Provider {
+	s.aggregates = aggregates
+	return s
+}
+
+// AddAggregate appends the provided AggregateField to the existing provider's aggregates field.
+func (s *Provider) AddAggregate(aggregate AggregateField) *Provider {
+	s.aggregates = append(s.aggregates, aggregate)
+	return s
+}
+
+// ExecAggregate executes the search provider in aggregate mode and returns
+// a paginated result with one item per group.
+//
+// The filters are applied before grouping and the result items
+// are maps with the groupBy fields and the aggregate keys, eg.:
+//
+//	{"status": "active", "count()": 10, "sum(amount)": 123.4}
+//
+// The scaled integer fields (see [ResolverResult.Scale]) are grouped, summed
+// and compared by their exact integer value and the result is returned as
+// decimal string (eg. "sum(amount)": "0.30"). The avg function result is
+// always calculated with their REAL value.
+//
+// The groupBy fields and the aggregated fields are resolved with the provider's
+// field resolver (relation paths included). A group by multiple values field
+// path (eg. a multiple relation) places the item in each of its value groups.
+// The aggregated fields must resolve to a single value per item.
+//
+// If no aggregates are specified, it defaults to "count()".
+// The sort fields must be one of the groupBy fields or aggregate keys
+// (if not specified the groups are sorted by the groupBy fields).
+//
+// The total number of groupBy and aggregate expressions is limited
+// by the provider's max filter expressions limit.
+func (s *Provider) ExecAggregate() (*Result, error) {
+	if s.query == nil {
+		return nil, ErrEmptyQuery
+	}
+
+	aggregates := s.aggregates
+	if len(aggregates) == 0 {
+		aggregates = []AggregateField{{Func: AggregateCount}}
+	}
+
+	if len(s.groupBy)+len(aggregates) > s.maxFilterExprLimit {
+		return nil, ErrAggregateExprLimit
+	}
+
+	// shallow clone the provider's query
+	sourceQuery := *s.query
+
+	if err := s.applyFilters(&sourceQuery); err != nil {
+		return nil, err
+	}
+
+	params := dbx.Params{}
+
+	rowCol := s.countCol
+	if queryInfo := sourceQuery.Info(); len(queryInfo.From) > 0 {
+		rowCol = dbutils.AliasOrIdentifier(queryInfo.From[0]) + "." + rowCol
+	}
+
+	// source columns
+	// ---
+	selects := []string{"[[" + rowCol + "]] AS [[__pb_row]]"}
+
+	keys := make([]string, 0, len(s.groupBy)+len(aggregates))
+	scales := make([]int, 0, len(s.groupBy)+len(aggregates))
+	groupCols := make([]string, 0, len(s.groupBy))
+	for i, field := range s.groupBy {
+		if len(field) > MaxSortFieldLength {
+			return nil, ErrAggregateFieldLengthLimit
+		}
+
+		if slices.Contains(keys, field) {
+			return nil, fmt.Errorf("duplicated group by field %q", field)
+		}
+
+		if isInternalIdentifier(field) {
+			return nil, fmt.Errorf("invalid group by field %q", field)
+		}
+
+		result, err := s.fieldResolver.Resolve(field)
+		if err != nil || len(result.Params) > 0 || result.Identifier == "" || strings.EqualFold(result.Identifier, "null") {
+			return nil, fmt.Errorf("invalid group by field %q", field)
+		}
+
+		// note: the scaled identifiers are grouped by their exact
+		// integer value and formatted as decimal string after the scan
+		col := "g" + strconv.Itoa(i)
+		selects = append(selects, result.Identifier+" AS [["+col+"]]")
+		groupCols = append(groupCols, "[["+col+"]]")
+		keys = append(keys, field)
+		scales = append(scales, result.Scale)
+	}
+
+	aggCols := make([]string, 0, len(aggregates))
+	for i, agg := range aggregates {
+		if len(agg.Field) > MaxSortFieldLength {
+			return nil, ErrAggregateFieldLengthLimit
+		}
+
+		if !slices.Contains(AggregateFuncs, agg.Func) {
+			return nil, fmt.Errorf("invalid aggregate expression %q", agg.Key())
+		}
+
+		if slices.Contains(keys, agg.Key()) {
+			return nil, fmt.Errorf("duplicated aggregate expression %q", agg.Key())
+		}
+
+		if agg.Field == "" {
+			if agg.Func != AggregateCount {
+				return nil, fmt.Errorf("missing %s aggregate field", agg.Func)
+			}
+
+			aggCols = append(aggCols, "COUNT(*)")
+			keys = append(keys, agg.Key())
+			scales = append(scales, 0)
+			continue
+		}
+
+		if isInternalIdentifier(agg.Field) {
+			return nil, fmt.Errorf("invalid aggregate field %q", agg.Field)
+		}
+
+		result, err := s.fieldResolver.Resolve(agg.Field)
+		if err != nil || len(result.Params) > 0 || result.Identifier == "" || strings.EqualFold(result.Identifier, "null") {
+			return nil, fmt.Errorf("invalid aggregate field %q", agg.Field)
+		}
+
+		if result.MultiMatchSubQuery != nil {
+			return nil, fmt.Errorf("the aggregate field %q must resolve to a single value", agg.Field)
+		}
+
+		// aggregate the scaled integers directly to avoid the float rounding errors
+		// (except for avg because its result could have more decimal places)
+		var scale int
+		switch agg.Func {
+		case AggregateSum, AggregateMin, AggregateMax:
+			scale = result.Scale
+		case AggregateAvg:
+			result = result.unscaled()
+		}
+
+		col := "a" + strconv.Itoa(i)
+		selects = append(selects, result.Identifier+" AS [["+col+"]]")
+		aggCols = append(aggCols, strings.ToUpper(agg.Func)+"([["+col+"]])")
+		keys = append(keys, agg.Key())
+		scales = append(scales, scale)
+	}
+
+	// apply field resolver query modifications (if any)
+	if err := s.fieldResolver.UpdateQuery(&sourceQuery); err != nil {
+		return nil, err
+	}
+
+	// note: the source rows are deduplicated in case of joins from the filters
+	// (the groupBy values are part of the row so that the multiple values fields
+	// are still counted in each group)
+	sourceQuery.Distinct(true).Select(selects...).OrderBy( /* reset */ ).Limit(-1).Offset(-1)
+
+	source := sourceQuery.Build()
+	for k, v := range source.Params() {
+		params[k] = v
+	}
+
+	withSource := "WITH [[__pb_source]] AS (" + source.SQL() + ")"
+
+	sourceInfo := sourceQuery.Info()
+	builder := sourceInfo.Builder
+
+	// newQuery binds the source params and inherits the source query context and build hook
+	newQuery := func(q *dbx.SelectQuery) *dbx.SelectQuery {
+		q.Bind(params)
+		if sourceInfo.Context != nil {
+			q.WithContext(sourceInfo.Context)
+		}
+		if sourceInfo.BuildHook != nil {
+			q.WithBuildHook(sourceInfo.BuildHook)
+		}
+		return q
+	}
+
+	// sort
+	// ---
+	if len(s.sort) > s.maxSortExprLimit {
+		return nil, ErrSortExprLimit
+	}
+
+	orderBy := make([]string, 0, max(len(s.sort), len(groupCols)))
+	for _, sortField := range s.sort {
+		name := strings.Join(strings.Fields(sortField.Name), "")
+
+		index := slices.IndexFunc(keys, func(k string) bool {
+			return strings.Join(strings.Fields(k), "") == name
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("invalid aggregate sort field %q", sortField.Name)
+		}
+
+		orderBy = append(orderBy, "[[c"+strconv.Itoa(index)+"]] "+sortField.Direction)
+	}
+	if len(orderBy) == 0 {
+		for i := range groupCols {
+			orderBy = append(orderBy, "[[c"+strconv.Itoa(i)+"]] "+SortAsc)
+		}
+	}
+
+	s.normalizePagination()
+
+	// negative value to differentiate from the zero default
+	totalCount := -1
+	totalPages := -1
+
+	countExec := func() error {
+		if len(groupCols) == 0 {
+			totalCount = 1 // a single group with all items
+		} else {
+			countQuery := newQuery(builder.Select("COUNT(*)").
+				PreFragment(withSource + ", [[__pb_groups]] AS (SELECT 1 FROM [[__pb_source]] GROUP BY " + strings.Join(groupCols, ", ") + ")").
+				From("__pb_groups"))
+
+			if err := countQuery.Row(&totalCount); err != nil {
+				return err
+			}
+		}
+
+		totalPages = int(math.Ceil(float64(totalCount) / float64(s.perPage)))
+
+		return nil
+	}
+
+	items := []map[string]any{}
+
+	itemsExec := func() error {
+		cols := make([]string, 0, len(keys))
+		for i, col := range append(slices.Clone(groupCols), aggCols...) {
+			cols = append(cols, col+" AS [[c"+strconv.Itoa(i)+"]]")
+		}
+
+		itemsQuery := newQuery(builder.Select(cols...).
+			PreFragment(withSource).
+			From("__pb_source").
+			GroupBy(groupCols...).
+			OrderBy(orderBy...).
+			Limit(int64(s.perPage)).
+			Offset(int64(s.perPage * (s.page - 1))))
+
+		rows, err := itemsQuery.Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			values := make([]any, len(keys))
+			pointers := make([]any, len(keys))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+
+			if err := rows.Scan(pointers...); err != nil {
+				return err
+			}
+
+			item := make(map[string]any, len(keys))
+			for i, key := range keys {
+				if v, ok := values[i].([]byte); ok {
+					item[key] = string(v)
+				} else if scales[i] > 0 {
+					item[key] = formatScaled(values[i], scales[i])
+				} else {
+					item[key] = values[i]
+				}
+			}
+
+			items = append(items, item)
+		}
+
+		return rows.Err()
+	}
+
+	if !s.skipTotal {
+		// execute the 2 queries concurrently
+		errg := new(errgroup.Group)
+		errg.Go(countExec)
+		errg.Go(itemsExec)
+		if err := errg.Wait(); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := itemsExec(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Result{
+		Page:       s.page,
+		PerPage:    s.perPage,
+		TotalItems: totalCount,
+		TotalPages: totalPages,
+		Items:      items,
+	}, nil
+}
+
+// ParseAndExecAggregate is a short convenient method to trigger
+// both `Parse()` and `ExecAggregate()` in a single call.
+func (s *Provider) ParseAndExecAggregate(urlQuery string) (*Result, error) {
+	if err := s.Parse(urlQuery); err != nil {
+		return nil, err
+	}
+
+	return s.ExecAggregate()
+}
@@ -0,0 +1,258 @@
+package search
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseAggregatesFromString(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		value    string
+		expected string
+	}{
+		{"", `null`},
+		{" , ", `null`},
+		{"count()", `[{"func":"count","field":""}]`},
+		{"COUNT( ), sum( amount ),avg(rel.total)", `[{"func":"count","field":""},{"func":"sum","field":"amount"},{"func":"avg","field":"rel.total"}]`},
+		{"invalid,max(a)", `[{"func":"","field":"invalid"},{"func":"max","field":"a"}]`},
+		{"min(a(b))", `[{"func":"","field":"min(a(b))"}]`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.value, func(t *testing.T) {
+			result := ParseAggregatesFromString(s.value)
+
+			encoded, _ := json.Marshal(result)
+			if str := string(encoded); str != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestAggregateFieldKey(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		field    AggregateField
+		expected string
+	}{
+		{AggregateField{}, "()"},
+		{AggregateField{Func: "count"}, "count()"},
+		{AggregateField{Func: "sum", Field: "a.b"}, "sum(a.b)"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expected, func(t *testing.T) {
+			if key := s.field.Key(); key != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, key)
+			}
+		})
+	}
+}
+
+func TestProviderParseAggregate(t *testing.T) {
+	t.Parallel()
+
+	p := NewProvider(&testFieldResolver{})
+
+	err := p.Parse("groupBy=a,+b.c,,&agg=count(),sum(d)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, _ := json.Marshal(p.groupBy)
+	if str := string(encoded); str != `["a","b.c"]` {
+		t.Fatalf("Unexpected groupBy %s", str)
+	}
+
+	encoded, _ = json.Marshal(p.aggregates)
+	if str := string(encoded); str != `[{"func":"count","field":""},{"func":"sum","field":"d"}]` {
+		t.Fatalf("Unexpected aggregates %s", str)
+	}
+}
+
+func TestProviderExecAggregateEmptyQuery(t *testing.T) {
+	t.Parallel()
+
+	p := NewProvider(&testFieldResolver{})
+
+	_, err := p.ExecAggregate()
+	if err != ErrEmptyQuery {
+		t.Fatalf("Expected ErrEmptyQuery, got %v", err)
+	}
+}
+
+func TestProviderExecAggregate(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	scenarios := []struct {
+		name          string
+		urlQuery      string
+		maxExprLimit  int
+		expectError   bool
+		expectResult  string
+		expectQueries int
+	}{
+		{
+			"default count without groupBy",
+			"",
+			0,
+			false,
+			`{"items":[{"count()":2}],"page":1,"perPage":30,"totalItems":1,"totalPages":1}`,
+			1,
+		},
+		{
+			"all aggregate functions with filter",
+			"filter=test1>0&agg=count(),sum(test1),avg(test1),min(test1),max(test2),count(test2)",
+			0,
+			false,
+			`{"items":[{"avg(test1)":1.5,"count()":2,"count(test2)":2,"max(test2)":"test2.2","min(test1)":1,"sum(test1)":3}],"page":1,"perPage":30,"totalItems":1,"totalPages":1}`,
+			1,
+		},
+		{
+			"groupBy with default sort",
+			"groupBy=test2&agg=sum(test1)",
+			0,
+			false,
+			`{"items":[{"sum(test1)":1,"test2":"test2.1"},{"sum(test1)":2,"test2":"test2.2"}],"page":1,"perPage":30,"totalItems":2,"totalPages":1}`,
+			2,
+		},
+		{
+			"multiple groupBy with sort and pagination",
+			"groupBy=test3,test2&sort=-count(),-test2&perPage=1&page=1",
+			0,
+			false,
+			`{"items":[{"count()":1,"test2":"test2.2","test3":""}],"page":1,"perPage":1,"totalItems":2,"totalPages":2}`,
+			2,
+		},
+		{
+			"sort by aggregate key with whitespaces",
+			"groupBy=test2&agg=sum(test1)&sort=sum( test1 )&skipTotal=1",
+			0,
+			false,
+			`{"items":[{"sum(test1)":1,"test2":"test2.1"},{"sum(test1)":2,"test2":"test2.2"}],"page":1,"perPage":30,"totalItems":-1,"totalPages":-1}`,
+			1,
+		},
+		{
+			"invalid sort field",
+			"groupBy=test2&sort=test1",
+			0,
+			true,
+			"",
+			0,
+		},
+		{
+			"invalid aggregate function",
+			"agg=median(test1)",
+			0,
+			true,
+			"",
+			0,
+		},
+		{
+			"invalid aggregate expression",
+			"agg=test1",
+			0,
+			true,
+			"",
+			0,
+		},
+		{
+			"missing aggregate field",
+			"agg=sum()",
+			0,
+			true,
+			"",
+			0,
+		},
+		{
+			"unresolvable groupBy field",
+			"groupBy=unknown",
+			0,
+			true,
+			"",
+			0,
+		},
+		{
+			"unresolvable aggregate field",
+			"agg=sum(unknown)",
+			0,
+			true,
+			"",
+			0,
+		},
+		{
+			"duplicated groupBy field",
+			"groupBy=test2,test2",
+			0,
+			true,
+			"",
+			0,
+		},
+		{
+			"duplicated aggregate",
+			"agg=count(),count()",
+			0,
+			true,
+			"",
+			0,
+		},
+		{
+			"groupBy and aggregate expressions limit",
+			"groupBy=test2,test3&agg=count()",
+			2,
+			true,
+			"",
+			0,
+		},
+		{
+			"groupBy field length limit",
+			"groupBy=" + strings.Repeat("a", MaxSortFieldLength+1),
+			0,
+			true,
+			"",
+			0,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			testDB.CalledQueries = []string{}
+
+			p := NewProvider(&testFieldResolver{}).
+				Query(testDB.Select("*").From("test"))
+
+			if s.maxExprLimit > 0 {
+				p.MaxFilterExprLimit(s.maxExprLimit)
+			}
+
+			result, err := p.ParseAndExecAggregate(s.urlQuery)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			encoded, _ := json.Marshal(result)
+			if str := string(encoded); str != s.expectResult {
+				t.Fatalf("Expected result\n%s\ngot\n%s", s.expectResult, str)
+			}
+
+			if total := len(testDB.CalledQueries); total != s.expectQueries {
+				t.Fatalf("Expected %d queries, got %d: %v", s.expectQueries, total, testDB.CalledQueries)
+			}
+		})
+	}
+}
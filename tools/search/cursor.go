@@ -0,0 +1,225 @@
+package search
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// CursorQueryParam is the URL search query param for the keyset pagination cursor.
+//
+// Its presence (even with empty value) enables the cursor pagination mode.
+const CursorQueryParam string = "cursor"
+
+// Common cursor errors.
+var (
+	ErrInvalidCursor    = errors.New("invalid or mismatched pagination cursor")
+	ErrCursorRandomSort = errors.New("the cursor pagination doesn't support random sort")
+)
+
+// defaultCursorKey is the random per process key that is used to
+// encrypt the cursors when no explicit [Provider.CursorKey] is set.
+var defaultCursorKey = security.RandomString(32)
+
+// cursorTieBreakerCol is the unique column that is always appended
+// to the cursor pagination sort expressions to guarantee stable order.
+const cursorTieBreakerCol = "id"
+
+// cursorSortExpr defines a single resolved cursor pagination sort expression.
+type cursorSortExpr struct {
+	expr string
+	desc bool
+}
+
+// cursorData defines the decoded cursor data.
+type cursorData struct {
+	// Sort is a short hash of the sort fields the cursor was generated for.
+	Sort string `json:"s"`
+
+	// Values are the sort expressions values of the last page item.
+	Values []any `json:"v"`
+}
+
+// Cursor enables the keyset (aka. cursor) pagination mode and sets
+// the cursor of the last item from the previous page
+// (an empty cursor fetches the first page).
+//
+// In cursor mode the page offset is ignored and the items are always
+// sorted additionally by the unique "id" column as tie-breaker.
+// If there are more items, the [Result.NextCursor] is set with the
+// value that should be used to fetch the next page.
+//
+// Note that the sort fields are expected to resolve to a single value
+// per item (eg. multiple relation paths are not supported).
+func (s *Provider) Cursor(cursor string) *Provider {
+	s.cursor = cursor
+	s.cursorMode = true
+	return s
+}
+
+// CursorKey sets the 32 characters key that is used to encrypt the
+// pagination cursors (see [security.Encrypt]).
+//
+// The cursors hold the sort values of the last page item (that may not be
+// visible to the client, eg. a hidden email) and they are always encrypted.
+// If no key is set, a random per process key is used, aka. the issued
+// cursors become invalid after restart.
+func (s *Provider) CursorKey(key string) *Provider {
+	s.cursorKey = key
+	return s
+}
+
+func (s *Provider) resolveCursorKey() string {
+	if s.cursorKey == "" {
+		return defaultCursorKey
+	}
+
+	return s.cursorKey
+}
+
+// cursorSortHash returns a short hash of the provider's sort fields
+// that is used to ensure that a cursor is reused with the same sort.
+func (s *Provider) cursorSortHash() string {
+	var sb strings.Builder
+	for _, sortField := range s.sort {
+		sb.WriteString(sortField.Direction)
+		sb.WriteString(":")
+		sb.WriteString(sortField.Name)
+		sb.WriteString(",")
+	}
+
+	return security.SHA256(sb.String())[:10]
+}
+
+// encodeCursor encrypts the provided sort expressions values into an opaque cursor string.
+func (s *Provider) encodeCursor(values []any) (string, error) {
+	raw, err := json.Marshal(cursorData{Sort: s.cursorSortHash(), Values: values})
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := security.Encrypt(raw, s.resolveCursorKey())
+	if err != nil {
+		return "", err
+	}
+
+	// re-encode to be safe for use as url query param
+	cipherBytes, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(cipherBytes), nil
+}
+
+// decodeCursor decrypts the provider's cursor and returns its sort expressions values.
+func (s *Provider) decodeCursor(totalExprs int) ([]any, error) {
+	cipherBytes, err := base64.RawURLEncoding.DecodeString(s.cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	raw, err := security.Decrypt(base64.StdEncoding.EncodeToString(cipherBytes), s.resolveCursorKey())
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	data := cursorData{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if data.Sort != s.cursorSortHash() || len(data.Values) != totalExprs {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, v := range data.Values {
+		switch val := v.(type) {
+		case json.Number:
+			if n, err := val.Int64(); err == nil {
+				data.Values[i] = n
+			} else if f, err := val.Float64(); err == nil {
+				data.Values[i] = f
+			} else {
+				return nil, ErrInvalidCursor
+			}
+		case string, nil:
+			// valid
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return data.Values, nil
+}
+
+// buildCursorExpr builds the keyset condition expression that matches
+// the items after the provided sort expressions values, eg.:
+//
+//	(a > v1) OR (a IS v1 AND b < v2) OR (a IS v1 AND b IS v2 AND id > v3)
+//
+// NULL values are sorted first in ASC order and last in DESC order.
+func buildCursorExpr(exprs []cursorSortExpr, values []any) dbx.Expression {
+	params := dbx.Params{}
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		name := "__pb_cursor" + strconv.Itoa(i)
+		params[name] = v
+		placeholders[i] = "{:" + name + "}"
+	}
+
+	ors := make([]string, 0, len(exprs))
+	for i, e := range exprs {
+		parts := make([]string, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			parts = append(parts, exprs[j].expr+" IS "+placeholders[j])
+		}
+
+		var after string
+		switch {
+		case values[i] == nil && e.desc:
+			continue // nothing is after NULL in DESC order
+		case values[i] == nil:
+			after = e.expr + " IS NOT NULL"
+		case e.desc:
+			after = "(" + e.expr + " < " + placeholders[i] + " OR " + e.expr + " IS NULL)"
+		default:
+			after = e.expr + " > " + placeholders[i]
+		}
+		parts = append(parts, after)
+
+		ors = append(ors, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(ors) == 0 {
+		return dbx.NewExp("0=1")
+	}
+
+	return dbx.NewExp("("+strings.Join(ors, " OR ")+")", params)
+}
+
+// normalizeCursorValue normalizes a single scanned db value so that
+// it could be safely encoded in a cursor.
+func normalizeCursorValue(v any) any {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case bool:
+		if val {
+			return int64(1)
+		}
+		return int64(0)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,367 @@
+package search
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestProviderCursor(t *testing.T) {
+	t.Parallel()
+
+	p := NewProvider(&testFieldResolver{})
+
+	if p.cursorMode {
+		t.Fatal("Expected the cursor mode to be disabled by default")
+	}
+
+	p.Cursor("test")
+
+	if !p.cursorMode {
+		t.Fatal("Expected the cursor mode to be enabled")
+	}
+
+	if p.cursor != "test" {
+		t.Fatalf("Expected cursor %q, got %q", "test", p.cursor)
+	}
+}
+
+func TestProviderParseCursor(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		query          string
+		expectedMode   bool
+		expectedCursor string
+	}{
+		{"", false, ""},
+		{"page=2", false, ""},
+		{"cursor=", true, ""},
+		{"cursor=abc&page=2", true, "abc"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.query, func(t *testing.T) {
+			p := NewProvider(&testFieldResolver{})
+
+			if err := p.Parse(s.query); err != nil {
+				t.Fatal(err)
+			}
+
+			if p.cursorMode != s.expectedMode {
+				t.Fatalf("Expected cursor mode %v, got %v", s.expectedMode, p.cursorMode)
+			}
+
+			if p.cursor != s.expectedCursor {
+				t.Fatalf("Expected cursor %q, got %q", s.expectedCursor, p.cursor)
+			}
+		})
+	}
+}
+
+func TestProviderEncodeDecodeCursor(t *testing.T) {
+	t.Parallel()
+
+	p := NewProvider(&testFieldResolver{}).Sort(ParseSortFromString("-a,b"))
+
+	cursor, err := p.encodeCursor([]any{int64(1), 1.5, "test", nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Cursor(cursor)
+
+	values, err := p.decodeCursor(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, _ := json.Marshal(values)
+	if str := string(encoded); str != `[1,1.5,"test",null]` {
+		t.Fatalf("Unexpected decoded values %s", str)
+	}
+
+	if _, ok := values[0].(int64); !ok {
+		t.Fatalf("Expected the integer value to be decoded as int64, got %T", values[0])
+	}
+
+	if _, ok := values[1].(float64); !ok {
+		t.Fatalf("Expected the float value to be decoded as float64, got %T", values[1])
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "test") {
+		t.Fatalf("Expected the cursor values to be encrypted, got %q", raw)
+	}
+}
+
+func TestProviderDecodeInvalidCursor(t *testing.T) {
+	t.Parallel()
+
+	p := NewProvider(&testFieldResolver{}).Sort(ParseSortFromString("-a,b"))
+
+	validCursor, err := p.encodeCursor([]any{1, "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mismatchedCursor, err := NewProvider(&testFieldResolver{}).Sort(ParseSortFromString("a,b")).encodeCursor([]any{1, "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKeyCursor, err := NewProvider(&testFieldResolver{}).
+		Sort(ParseSortFromString("-a,b")).
+		CursorKey(strings.Repeat("a", 32)).
+		encodeCursor([]any{1, "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encode := func(raw string) string {
+		encrypted, err := security.Encrypt([]byte(raw), p.resolveCursorKey())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cipherBytes, err := base64.StdEncoding.DecodeString(encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return base64.RawURLEncoding.EncodeToString(cipherBytes)
+	}
+
+	plain := base64.RawURLEncoding.EncodeToString(
+		[]byte(fmt.Sprintf(`{"s":%q,"v":[1,"test"]}`, p.cursorSortHash())),
+	)
+
+	scenarios := []struct {
+		name       string
+		cursor     string
+		totalExprs int
+	}{
+		{"non base64", "!@#", 2},
+		{"short", "YWJj", 2},
+		{"not encrypted", plain, 2},
+		{"different key", otherKeyCursor, 2},
+		{"non json", encode("test"), 2},
+		{"different sort", mismatchedCursor, 2},
+		{"different total values", validCursor, 3},
+		{"unsupported value type", encode(fmt.Sprintf(`{"s":%q,"v":[{"a":1},"test"]}`, p.cursorSortHash())), 2},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			p.Cursor(s.cursor)
+
+			_, err := p.decodeCursor(s.totalExprs)
+			if err != ErrInvalidCursor {
+				t.Fatalf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildCursorExpr(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name     string
+		exprs    []cursorSortExpr
+		values   []any
+		expected string
+	}{
+		{
+			"single asc",
+			[]cursorSortExpr{{expr: "a"}},
+			[]any{1},
+			"((a > {:__pb_cursor0}))",
+		},
+		{
+			"single desc",
+			[]cursorSortExpr{{expr: "a", desc: true}},
+			[]any{1},
+			"(((a < {:__pb_cursor0} OR a IS NULL)))",
+		},
+		{
+			"single asc null",
+			[]cursorSortExpr{{expr: "a"}},
+			[]any{nil},
+			"((a IS NOT NULL))",
+		},
+		{
+			"single desc null",
+			[]cursorSortExpr{{expr: "a", desc: true}},
+			[]any{nil},
+			"0=1",
+		},
+		{
+			"multiple",
+			[]cursorSortExpr{{expr: "a", desc: true}, {expr: "b"}, {expr: "[[id]]"}},
+			[]any{nil, "test", 1},
+			"((a IS {:__pb_cursor0} AND b > {:__pb_cursor1}) OR (a IS {:__pb_cursor0} AND b IS {:__pb_cursor1} AND [[id]] > {:__pb_cursor2}))",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			params := dbx.Params{}
+
+			result := buildCursorExpr(s.exprs, s.values).Build(nil, params)
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+
+			if result == "0=1" {
+				return
+			}
+
+			if len(params) != len(s.values) {
+				t.Fatalf("Expected %d params, got %v", len(s.values), params)
+			}
+
+			for i, v := range s.values {
+				if p := params[fmt.Sprintf("__pb_cursor%d", i)]; p != v {
+					t.Fatalf("Expected param %d to be %v, got %v", i, v, p)
+				}
+			}
+		})
+	}
+}
+
+func TestProviderExecCursor(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	scenarios := []struct {
+		name          string
+		urlQuery      string
+		expectedPages []string // the serialized items test1 values (same as the ids) of each page
+		expectedTotal int
+	}{
+		{
+			"default sort",
+			"perPage=1",
+			[]string{`[1]`, `[2]`},
+			2,
+		},
+		{
+			"multiple sort fields with tie",
+			"perPage=1&sort=test3,-test2",
+			[]string{`[2]`, `[1]`},
+			2,
+		},
+		{
+			"explicit id sort",
+			"perPage=1&sort=-id",
+			[]string{`[2]`, `[1]`},
+			2,
+		},
+		{
+			"with filter and skipTotal",
+			"perPage=1&filter=test1>1&skipTotal=1",
+			[]string{`[2]`},
+			-1,
+		},
+		{
+			"single page with the page param ignored",
+			"perPage=10&page=2&sort=-test1",
+			[]string{`[2,1]`},
+			2,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var cursor string
+			for i, expectedIds := range s.expectedPages {
+				items := []testTableStruct{}
+
+				p := NewProvider(&testFieldResolver{}).
+					Query(testDB.Select("*").From("test"))
+
+				result, err := p.ParseAndExec(s.urlQuery+"&cursor="+cursor, &items)
+				if err != nil {
+					t.Fatalf("[page %d] %v", i, err)
+				}
+
+				ids := make([]int, len(items))
+				for j, item := range items {
+					ids[j] = item.Test1
+				}
+
+				encoded, _ := json.Marshal(ids)
+				if str := string(encoded); str != expectedIds {
+					t.Fatalf("[page %d] Expected items %s, got %s", i, expectedIds, str)
+				}
+
+				if result.Page != 1 {
+					t.Fatalf("[page %d] Expected result page 1, got %d", i, result.Page)
+				}
+
+				if result.TotalItems != s.expectedTotal {
+					t.Fatalf("[page %d] Expected totalItems %d, got %d", i, s.expectedTotal, result.TotalItems)
+				}
+
+				isLast := i == len(s.expectedPages)-1
+				if isLast != (result.NextCursor == "") {
+					t.Fatalf("[page %d] Expected empty next cursor %v, got %q", i, isLast, result.NextCursor)
+				}
+
+				cursor = result.NextCursor
+			}
+		})
+	}
+}
+
+func TestProviderExecCursorErrors(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	scenarios := []struct {
+		name     string
+		urlQuery string
+	}{
+		{"random sort", "cursor=&sort=@random"},
+		{"invalid cursor", "cursor=invalid"},
+		{"sort mismatch", "cursor=" + mustEncodeTestCursor(t, "-test1", 1, 1)},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			items := []testTableStruct{}
+
+			p := NewProvider(&testFieldResolver{}).
+				Query(testDB.Select("*").From("test"))
+
+			_, err := p.ParseAndExec(s.urlQuery+"&sort=test1", &items)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func mustEncodeTestCursor(t *testing.T, sort string, values ...any) string {
+	cursor, err := NewProvider(&testFieldResolver{}).Sort(ParseSortFromString(sort)).encodeCursor(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cursor
+}
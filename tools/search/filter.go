@@ -85,7 +85,7 @@ func (f FilterData) BuildExprWithLimit(
 		return buildParsedFilterExpr(data, fieldResolver, &maxExpressions)
 	}
 
-	data, err := fexpr.Parse(raw)
+	data, err := parseFilter(raw)
 	if err != nil {
 		// depending on the users demand we may allow empty expressions
 		// (aka. expressions consisting only of whitespaces or comments)
@@ -153,6 +153,15 @@ func buildParsedFilterExpr(data []fexpr.ExprGroup, fieldResolver FieldResolver,
 }
 
 func resolveTokenizedExpr(expr fexpr.Expr, fieldResolver FieldResolver) (dbx.Expression, error) {
+	if isInExpr(expr) {
+		lResult, rResult, err := resolveInExpr(expr, fieldResolver)
+		if err != nil {
+			return nil, err
+		}
+
+		return buildResolversExpr(lResult, expr.Op, rResult)
+	}
+
 	lResult, lErr := resolveToken(expr.Left, fieldResolver)
 	if lErr != nil || lResult.Identifier == "" {
 		return nil, fmt.Errorf("invalid left operand %q - %v", expr.Left.Literal, lErr)
@@ -171,6 +180,8 @@ func buildResolversExpr(
 	op fexpr.SignOp,
 	right *ResolverResult,
 ) (dbx.Expression, error) {
+	left, right = normalizeScaledOperands(op, left, right)
+
 	var expr dbx.Expression
 
 	switch op {
@@ -200,6 +211,10 @@ func buildResolversExpr(
 		expr = dbx.NewExp(fmt.Sprintf("%s > %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
 	case fexpr.SignGte, fexpr.SignAnyGte:
 		expr = dbx.NewExp(fmt.Sprintf("%s >= %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
+	case signIn, signAnyIn:
+		expr = dbx.NewExp(fmt.Sprintf("%s IN %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
+	case signNotIn:
+		expr = dbx.NewExp(fmt.Sprintf("(%s IS NULL OR %s NOT IN %s)", left.Identifier, left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
 	}
 
 	if expr == nil {
@@ -261,6 +276,10 @@ var normalizedIdentifiers = map[string]string{
 func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResult, error) {
 	switch token.Type {
 	case fexpr.TokenIdentifier:
+		if isInternalIdentifier(token.Literal) {
+			return nil, fmt.Errorf("unknown identifier %q", token.Literal)
+		}
+
 		// check for macros
 		// ---
 		if macroFunc, ok := identifierMacros[token.Literal]; ok {
@@ -290,6 +309,8 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 		}
 
 		return result, err
+	case tokenInternalIdentifier:
+		return fieldResolver.Resolve(token.Literal)
 	case fexpr.TokenText:
 		placeholder := "t" + security.PseudorandomString(8)
 
@@ -312,7 +333,12 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 
 		args, _ := token.Meta.([]fexpr.Token)
 		return fn(func(argToken fexpr.Token) (*ResolverResult, error) {
-			return resolveToken(argToken, fieldResolver)
+			result, err := resolveToken(argToken, fieldResolver)
+			if err != nil {
+				return nil, err
+			}
+
+			return result.unscaled(), nil
 		}, args...)
 	}
 
@@ -456,7 +482,8 @@ func isAnyMatchOp(op fexpr.SignOp) bool {
 		fexpr.SignAnyLt,
 		fexpr.SignAnyLte,
 		fexpr.SignAnyGt,
-		fexpr.SignAnyGte:
+		fexpr.SignAnyGte,
+		signAnyIn:
 		return true
 	}
 
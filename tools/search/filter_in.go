@@ -0,0 +1,329 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+)
+
+// IN filter operators.
+//
+// They are not part of the fexpr grammar and are recognized by
+// [parseFilter] from the fexpr scanner tokens.
+const (
+	signIn    fexpr.SignOp = "IN"
+	signNotIn fexpr.SignOp = "NOT IN"
+	signAnyIn fexpr.SignOp = "?IN"
+)
+
+// tokenArray is the type of the array literal tokens (eg. `['a', 'b']`)
+// with the array items loaded in Token.Meta (the fexpr scanner never produces it).
+const tokenArray fexpr.TokenType = "array"
+
+// MaxInListItems specifies the max allowed number of array literal items in a single IN expression.
+const MaxInListItems = 500
+
+// parser's state machine steps (the same as in [fexpr.Parse])
+const (
+	stepBeforeSign = iota
+	stepSign
+	stepAfterSign
+	stepJoin
+)
+
+// parseFilter parses the provided filter text and returns its processed AST
+// in the form of [fexpr.ExprGroup] slice(s).
+//
+// It is similar to [fexpr.Parse] but additionally supports the IN, NOT IN
+// and ?IN operators (case-insensitive) with an array literal or a single
+// operand that is expected to resolve to a JSON array, eg.:
+//
+//	status IN ['a', 'b']
+//	status NOT IN @request.body.a
+//	tags ?IN {:tags}
+//
+// Array literals are allowed only as right operand of the IN operators.
+func parseFilter(text string) ([]fexpr.ExprGroup, error) {
+	result := []fexpr.ExprGroup{}
+	scanner := fexpr.NewScanner([]byte(text))
+	step := stepBeforeSign
+	join := fexpr.JoinAnd
+
+	var expr fexpr.Expr
+
+	for {
+		t, err := scanner.Scan()
+		if err != nil {
+			if t.Type == fexpr.TokenUnexpected && t.Literal == "[" {
+				return nil, errors.New("array literals are supported only as right operand of the IN operators")
+			}
+
+			// the "?" of the "?IN" operator is not a valid fexpr sign operator
+			if step != stepSign || t.Type != fexpr.TokenSign || t.Literal != "?" {
+				return nil, err
+			}
+		}
+
+		if t.Type == fexpr.TokenEOF {
+			break
+		}
+
+		if t.Type == fexpr.TokenWS || t.Type == fexpr.TokenComment {
+			continue
+		}
+
+		if t.Type == fexpr.TokenGroup {
+			groupResult, err := parseFilter(t.Literal)
+			if err != nil {
+				return nil, err
+			}
+
+			// append only if non-empty group
+			if len(groupResult) > 0 {
+				result = append(result, fexpr.ExprGroup{Join: join, Item: groupResult})
+			}
+
+			step = stepJoin
+			continue
+		}
+
+		switch step {
+		case stepBeforeSign:
+			if !isOperandToken(t) {
+				return nil, fmt.Errorf("expected left operand (identifier, function, text or number), got %q (%s)", t.Literal, t.Type)
+			}
+
+			expr = fexpr.Expr{Left: t}
+
+			step = stepSign
+		case stepSign:
+			op, err := scanInOperator(scanner, t)
+			if err != nil {
+				return nil, err
+			}
+
+			if op != "" {
+				right, err := scanInOperand(scanner)
+				if err != nil {
+					return nil, err
+				}
+
+				expr.Op = op
+				expr.Right = right
+				result = append(result, fexpr.ExprGroup{Join: join, Item: expr})
+
+				step = stepJoin
+				continue
+			}
+
+			if t.Type != fexpr.TokenSign {
+				return nil, fmt.Errorf("expected a sign operator, got %q (%s)", t.Literal, t.Type)
+			}
+
+			expr.Op = fexpr.SignOp(t.Literal)
+			step = stepAfterSign
+		case stepAfterSign:
+			if !isOperandToken(t) {
+				return nil, fmt.Errorf("expected right operand (identifier, function text or number), got %q (%s)", t.Literal, t.Type)
+			}
+
+			expr.Right = t
+			result = append(result, fexpr.ExprGroup{Join: join, Item: expr})
+
+			step = stepJoin
+		case stepJoin:
+			if t.Type != fexpr.TokenJoin {
+				return nil, fmt.Errorf("expected && or ||, got %q (%s)", t.Literal, t.Type)
+			}
+
+			join = fexpr.JoinAnd
+			if t.Literal == "||" {
+				join = fexpr.JoinOr
+			}
+
+			step = stepBeforeSign
+		}
+	}
+
+	if step != stepJoin {
+		if len(result) == 0 && expr.IsZero() {
+			return nil, fexpr.ErrEmpty
+		}
+
+		return nil, fexpr.ErrIncomplete
+	}
+
+	return result, nil
+}
+
+// scanInOperator checks whether the current operator token t starts
+// an IN operator and returns its sign (or empty string if it is not).
+//
+// The remaining IN operator tokens (if any) are consumed from the scanner.
+func scanInOperator(scanner *fexpr.Scanner, t fexpr.Token) (fexpr.SignOp, error) {
+	switch {
+	case t.Type == fexpr.TokenIdentifier && strings.EqualFold(t.Literal, "in"):
+		return signIn, nil
+	case t.Type == fexpr.TokenIdentifier && strings.EqualFold(t.Literal, "not"):
+		next, err := scanNonWhitespace(scanner)
+		if err != nil || next.Type != fexpr.TokenIdentifier || !strings.EqualFold(next.Literal, "in") {
+			return "", fmt.Errorf("expected IN after NOT, got %q (%s)", next.Literal, next.Type)
+		}
+		return signNotIn, nil
+	case t.Type == fexpr.TokenSign && t.Literal == "?":
+		// must be immediately followed by "in"
+		next, err := scanner.Scan()
+		if err != nil || next.Type != fexpr.TokenIdentifier || !strings.EqualFold(next.Literal, "in") {
+			return "", fmt.Errorf("invalid sign operator %q", t.Literal+next.Literal)
+		}
+		return signAnyIn, nil
+	}
+
+	return "", nil
+}
+
+// scanInOperand consumes and returns the right operand of an IN operator,
+// aka. an array literal or a single text, number, identifier or function token.
+func scanInOperand(scanner *fexpr.Scanner) (fexpr.Token, error) {
+	t, err := scanNonWhitespace(scanner)
+
+	if t.Type == fexpr.TokenUnexpected && t.Literal == "[" {
+		return scanArrayLiteral(scanner)
+	}
+
+	if err != nil {
+		return t, err
+	}
+
+	if t.Type == fexpr.TokenEOF {
+		return t, errors.New("missing IN operator right operand")
+	}
+
+	if !isOperandToken(t) {
+		return t, fmt.Errorf("invalid IN operator right operand %q (%s)", t.Literal, t.Type)
+	}
+
+	return t, nil
+}
+
+// scanArrayLiteral consumes the items of an array literal
+// (the opening bracket is expected to be already consumed).
+func scanArrayLiteral(scanner *fexpr.Scanner) (fexpr.Token, error) {
+	items := []fexpr.Token{}
+
+	expectItem := true
+
+	for {
+		t, err := scanNonWhitespace(scanner)
+
+		if t.Type == fexpr.TokenUnexpected {
+			switch t.Literal {
+			case "]":
+				if expectItem && len(items) > 0 {
+					return t, errors.New("invalid array literal - trailing comma")
+				}
+				return fexpr.Token{Type: tokenArray, Literal: "array", Meta: items}, nil
+			case ",":
+				if expectItem {
+					return t, errors.New("invalid array literal - missing item before comma")
+				}
+				expectItem = true
+				continue
+			case "[":
+				return t, errors.New("nested array literals are not supported")
+			}
+		}
+
+		if err != nil {
+			return t, err
+		}
+
+		if t.Type == fexpr.TokenEOF {
+			return t, errors.New("invalid array literal - missing closing bracket")
+		}
+
+		if !expectItem || !isOperandToken(t) {
+			return t, fmt.Errorf("invalid array literal item %q (%s)", t.Literal, t.Type)
+		}
+
+		items = append(items, t)
+		expectItem = false
+	}
+}
+
+// scanNonWhitespace returns the next scanner token that is not a whitespace or a comment.
+func scanNonWhitespace(scanner *fexpr.Scanner) (fexpr.Token, error) {
+	for {
+		t, err := scanner.Scan()
+		if err != nil || (t.Type != fexpr.TokenWS && t.Type != fexpr.TokenComment) {
+			return t, err
+		}
+	}
+}
+
+func isOperandToken(t fexpr.Token) bool {
+	return t.Type == fexpr.TokenIdentifier ||
+		t.Type == fexpr.TokenText ||
+		t.Type == fexpr.TokenNumber ||
+		t.Type == fexpr.TokenFunction
+}
+
+// isInExpr checks whether the expression uses one of the IN operators.
+func isInExpr(expr fexpr.Expr) bool {
+	return expr.Op == signIn || expr.Op == signNotIn || expr.Op == signAnyIn
+}
+
+// resolveInExpr resolves an IN, NOT IN or ?IN filter expression.
+func resolveInExpr(expr fexpr.Expr, fieldResolver FieldResolver) (*ResolverResult, *ResolverResult, error) {
+	left, err := resolveToken(expr.Left, fieldResolver)
+	if err != nil || left.Identifier == "" {
+		return nil, nil, fmt.Errorf("invalid left operand %q - %v", expr.Left.Literal, err)
+	}
+
+	if expr.Right.Type == tokenArray {
+		items, _ := expr.Right.Meta.([]fexpr.Token)
+
+		if len(items) > MaxInListItems {
+			return nil, nil, fmt.Errorf("too many %s operator items (max allowed %d)", expr.Op, MaxInListItems)
+		}
+
+		right := &ResolverResult{}
+		identifiers := make([]string, len(items))
+		for i, token := range items {
+			item, err := resolveToken(token, fieldResolver)
+			if err != nil || item.Identifier == "" {
+				return nil, nil, fmt.Errorf("invalid %s operator item %q - %v", expr.Op, token.Literal, err)
+			}
+
+			if item.MultiMatchSubQuery != nil {
+				return nil, nil, fmt.Errorf("the %s operator item %q must be a single value", expr.Op, token.Literal)
+			}
+
+			identifiers[i] = item.unscaled().Identifier
+			right.Params = mergeParams(right.Params, item.Params)
+		}
+
+		right.Identifier = "(" + strings.Join(identifiers, ", ") + ")"
+
+		return left, right, nil
+	}
+
+	value, err := resolveToken(expr.Right, fieldResolver)
+	if err != nil || value.Identifier == "" {
+		return nil, nil, fmt.Errorf("invalid right operand %q - %v", expr.Right.Literal, err)
+	}
+
+	if value.MultiMatchSubQuery != nil {
+		return nil, nil, fmt.Errorf("the %s operator right operand %q must be a single value", expr.Op, expr.Right.Literal)
+	}
+
+	// note: non-array values are normalized to a single item array
+	v := value.unscaled().Identifier
+
+	return left, &ResolverResult{
+		Identifier: "(SELECT [[value]] FROM json_each(CASE WHEN iif(json_valid(" + v + "), json_type(" + v + ")='array', FALSE) THEN " + v + " ELSE json_array(" + v + ") END))",
+		Params:     value.Params,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package search
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		raw         string
+		expectError bool
+		expected    string
+	}{
+		{"", true, ""},
+		{"a = 1 && b != 'in [1]'", false, "a = 1 && b != in [1]"},
+		{"a IN [1, 'b', c]", false, "a IN [1, b, c]"},
+		{"a in[]", false, "a IN []"},
+		{"a NOT  IN ['x]', \"y[\"]", false, "a NOT IN [x], y[]"},
+		{"a not\nin @request.body.b", false, "a NOT IN @request.body.b"},
+		{"a?in \"[1,2]\"", false, "a ?IN [1,2]"},
+		{"lower(a) In lower(b) && (c ?IN [1])", false, "lower(a) IN lower(b) && (c ?IN [1])"},
+		{"a = 1 // in [", false, "a = 1"},
+		{"a IN [1, // comment\n 2]", false, "a IN [1, 2]"},
+		{"in = 1 && a = index && not = inside", false, "in = 1 && a = index && not = inside"},
+		{"a = 'b\\'' && c IN [1]", false, "a = b' && c IN [1]"},
+		// the quoted text is scanned by fexpr the same way as in the regular expressions
+		{"a = 'b\\\\' && c IN [1]", true, ""},
+		{"[1] = a", true, ""},
+		{"a = [1]", true, ""},
+		{"a IN [1, [2]]", true, ""},
+		{"a IN [1", true, ""},
+		{"a IN [1,]", true, ""},
+		{"a IN [,1]", true, ""},
+		{"a IN [1 2]", true, ""},
+		{"a IN", true, ""},
+		{"a IN (1, 2)", true, ""},
+		{"a NOT = 1", true, ""},
+		{"a ? IN [1]", true, ""},
+		{"a ?INx [1]", true, ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.raw, func(t *testing.T) {
+			result, err := parseFilter(s.raw)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if str := formatParsedFilter(result); str != s.expected {
+				t.Fatalf("Expected\n%q\ngot\n%q", s.expected, str)
+			}
+		})
+	}
+}
+
+// formatParsedFilter serializes the parsed filter groups for easier comparison.
+func formatParsedFilter(groups []fexpr.ExprGroup) string {
+	formatToken := func(t fexpr.Token) string {
+		switch t.Type {
+		case tokenArray:
+			items, _ := t.Meta.([]fexpr.Token)
+			literals := make([]string, len(items))
+			for i, item := range items {
+				literals[i] = item.Literal
+			}
+			return "[" + strings.Join(literals, ", ") + "]"
+		case fexpr.TokenFunction:
+			args, _ := t.Meta.([]fexpr.Token)
+			literals := make([]string, len(args))
+			for i, arg := range args {
+				literals[i] = arg.Literal
+			}
+			return t.Literal + "(" + strings.Join(literals, ", ") + ")"
+		default:
+			return t.Literal
+		}
+	}
+
+	var sb strings.Builder
+
+	for i, group := range groups {
+		if i > 0 {
+			sb.WriteString(" " + string(group.Join) + " ")
+		}
+
+		switch item := group.Item.(type) {
+		case fexpr.Expr:
+			sb.WriteString(formatToken(item.Left) + " " + string(item.Op) + " " + formatToken(item.Right))
+		case []fexpr.ExprGroup:
+			sb.WriteString("(" + formatParsedFilter(item) + ")")
+		}
+	}
+
+	return sb.String()
+}
+
+func TestFilterInExec(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	resolver := NewSimpleFieldResolver("test1", "test2", "test3")
+
+	scenarios := []struct {
+		name        string
+		filter      FilterData
+		params      dbx.Params
+		expectError bool
+		expectIds   string // the serialized items test1 values
+	}{
+		{"IN with array literal", "test2 IN ['test2.2', 'missing']", nil, false, `[2]`},
+		{"IN with empty array literal", "test2 IN []", nil, false, `[]`},
+		{"NOT IN with array literal", "test1 NOT IN [1, 3]", nil, false, `[2]`},
+		{"NOT IN with empty array literal", "test1 NOT IN []", nil, false, `[1,2]`},
+		{"IN with column items", "test1 IN [3, test1]", nil, false, `[1,2]`},
+		{"IN with slice param", "test2 in {:list}", dbx.Params{"list": []string{"test2.1", "test2.2"}}, false, `[1,2]`},
+		{"NOT IN with slice param", "test1 not in {:list}", dbx.Params{"list": []int{1}}, false, `[2]`},
+		{"IN with single value param", "test1 IN {:value}", dbx.Params{"value": 2}, false, `[2]`},
+		{"IN with json array text", `test2 IN '["test2.1"]'`, nil, false, `[1]`},
+		{"?IN with array literal", "test1 ?IN [2]", nil, false, `[2]`},
+		{"IN combined with other expressions", "test1 > 0 && (test1 IN [1] || test2 = 'test2.2')", nil, false, `[1,2]`},
+		{"invalid operator", "test1 > __pb_in_list(1)", nil, true, ""},
+		{"internal function name as right operand", "test1 = __pb_in_list(1, 2)", nil, true, ""},
+		{"internal function name as IN operand", "test1 IN __pb_in_value('[1]')", nil, true, ""},
+		{"invalid array item", "test1 IN [missing]", nil, true, ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			expr, err := s.filter.BuildExpr(resolver, s.params)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			items := []testTableStruct{}
+
+			err = testDB.Select("*").From("test").AndWhere(expr).OrderBy("test1 ASC").All(&items)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := make([]int, len(items))
+			for i, item := range items {
+				ids[i] = item.Test1
+			}
+
+			encoded, _ := json.Marshal(ids)
+			if str := string(encoded); str != s.expectIds {
+				t.Fatalf("Expected items %s, got %s", s.expectIds, str)
+			}
+		})
+	}
+}
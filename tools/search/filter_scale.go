@@ -0,0 +1,200 @@
+package search
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+)
+
+var placeholderRegex = regexp.MustCompile(`\{:\w+\}`)
+
+// normalizeScaledOperands prepares the scaled integer operands (see [ResolverResult.Scale])
+// for the op expression by scaling the literal values of the other operand or,
+// if that is not possible, by converting the scaled identifiers back to their REAL value.
+func normalizeScaledOperands(op fexpr.SignOp, left, right *ResolverResult) (*ResolverResult, *ResolverResult) {
+	if left.Scale == 0 && right.Scale == 0 {
+		return left, right
+	}
+
+	// the LIKE expressions compare the text representation of the values
+	if isLikeOp(op) {
+		return left.unscaled(), right.unscaled()
+	}
+
+	if left.Scale == right.Scale {
+		return left, right
+	}
+
+	if right.Scale == 0 {
+		if scaled, ok := scaleLiteral(right, left.Scale); ok {
+			return left, scaled
+		}
+	}
+
+	if left.Scale == 0 {
+		if scaled, ok := scaleLiteral(left, right.Scale); ok {
+			return scaled, right
+		}
+	}
+
+	return left.unscaled(), right.unscaled()
+}
+
+// unscaled returns a copy of the result with the scaled integer identifier
+// converted back to its REAL value.
+//
+// Returns the result as it is if it is not scaled.
+func (r *ResolverResult) unscaled() *ResolverResult {
+	if r.Scale <= 0 {
+		return r
+	}
+
+	unscale := func(identifier string) string {
+		// note: the CAST is to preserve the numeric affinity when compared with text values
+		return "CAST(" + identifier + " / 1" + strings.Repeat("0", r.Scale) + ".0 AS REAL)"
+	}
+
+	clone := *r
+	clone.Scale = 0
+	clone.Identifier = unscale(r.Identifier)
+
+	if r.MultiMatchSubQuery != nil {
+		mm := *r.MultiMatchSubQuery
+		mm.ValueIdentifier = unscale(mm.ValueIdentifier)
+		clone.MultiMatchSubQuery = &mm
+	}
+
+	return &clone
+}
+
+// formatScaled returns the decimal string representation of the provided
+// scaled integer value (eg. 1234 with scale 2 -> "12.34").
+//
+// Returns the value as it is if it is not an integer (eg. NULL).
+func formatScaled(v any, scale int) any {
+	n, ok := v.(int64)
+	if !ok || scale <= 0 {
+		return v
+	}
+
+	str := strconv.FormatInt(n, 10)
+
+	var sign string
+	if n < 0 {
+		sign = "-"
+		str = str[1:]
+	}
+
+	if len(str) <= scale {
+		str = strings.Repeat("0", scale-len(str)+1) + str
+	}
+
+	return sign + str[:len(str)-scale] + "." + str[len(str)-scale:]
+}
+
+// scaleLiteral returns a copy of the literal result (aka. an identifier
+// consisting only of placeholders, eg. "{:a}" or "({:a}, {:b})") with
+// its numeric param values scaled to the specified number of decimal places.
+//
+// Returns false if the result is not a literal or some of its param values is not a number.
+func scaleLiteral(result *ResolverResult, scale int) (*ResolverResult, bool) {
+	if len(result.Params) == 0 || result.MultiMatchSubQuery != nil || result.Scale != 0 {
+		return nil, false
+	}
+
+	if strings.Trim(placeholderRegex.ReplaceAllString(result.Identifier, ""), "(), ") != "" {
+		return nil, false
+	}
+
+	params := make(dbx.Params, len(result.Params))
+	for k, v := range result.Params {
+		scaled, ok := scaleNumber(v, scale)
+		if !ok {
+			return nil, false
+		}
+		params[k] = scaled
+	}
+
+	clone := *result
+	clone.Params = params
+
+	return &clone, true
+}
+
+// scaleNumber multiplies the provided numeric value by 10^scale
+// by shifting its decimal point to avoid the float rounding errors.
+//
+// The result is int64 if the scaled number doesn't have fractional part, otherwise - float64.
+func scaleNumber(v any, scale int) (any, bool) {
+	var str string
+
+	switch n := v.(type) {
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, false
+		}
+		str = strconv.FormatFloat(n, 'f', -1, 64)
+	case int64:
+		str = strconv.FormatInt(n, 10)
+	case int:
+		str = strconv.Itoa(n)
+	case string:
+		str = strings.TrimSpace(n)
+	default:
+		return nil, false
+	}
+
+	sign := ""
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		sign = strings.TrimPrefix(str[:1], "+")
+		str = str[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if intPart+fracPart == "" || !isDigits(intPart+fracPart) {
+		return nil, false
+	}
+
+	if len(fracPart) < scale {
+		fracPart += strings.Repeat("0", scale-len(fracPart))
+	}
+	intPart += fracPart[:scale]
+	fracPart = strings.TrimRight(fracPart[scale:], "0")
+
+	if fracPart == "" {
+		if scaled, err := strconv.ParseInt(sign+intPart, 10, 64); err == nil {
+			return scaled, true
+		}
+		fracPart = "0" // int64 overflow
+	}
+
+	scaled, err := strconv.ParseFloat(sign+intPart+"."+fracPart, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	return scaled, true
+}
+
+func isLikeOp(op fexpr.SignOp) bool {
+	switch op {
+	case fexpr.SignLike, fexpr.SignAnyLike, fexpr.SignNlike, fexpr.SignAnyNlike:
+		return true
+	default:
+		return false
+	}
+}
+
+func isDigits(str string) bool {
+	for _, c := range str {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
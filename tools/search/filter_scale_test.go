@@ -0,0 +1,146 @@
+package search
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+)
+
+// scaledFieldResolver resolves the "price" and "amount" fields as scaled integer identifiers.
+type scaledFieldResolver struct {
+	*SimpleFieldResolver
+}
+
+func (r *scaledFieldResolver) Resolve(field string) (*ResolverResult, error) {
+	switch field {
+	case "price":
+		return &ResolverResult{Identifier: "[[price]]", Scale: 2}, nil
+	case "amount":
+		return &ResolverResult{Identifier: "[[amount]]", Scale: 3}, nil
+	default:
+		return r.SimpleFieldResolver.Resolve(field)
+	}
+}
+
+func TestScaleNumber(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		value    any
+		scale    int
+		expectOk bool
+		expected any
+	}{
+		{nil, 2, false, nil},
+		{true, 2, false, nil},
+		{"", 2, false, nil},
+		{"abc", 2, false, nil},
+		{"1e5", 2, false, nil},
+		{"1.2.3", 2, false, nil},
+		{"-", 2, false, nil},
+		{".", 2, false, nil},
+		{0.0, 2, true, int64(0)},
+		{1.1, 2, true, int64(110)},
+		{0.3, 2, true, int64(30)},
+		{-12.5, 2, true, int64(-1250)},
+		{1.005, 2, true, 100.5},
+		{int64(3), 2, true, int64(300)},
+		{7, 3, true, int64(7000)},
+		{" 12.5 ", 2, true, int64(1250)},
+		{"+.5", 2, true, int64(50)},
+		{"-0.001", 2, true, -0.1},
+		{"12.50000", 2, true, int64(1250)},
+		{"99999999999999999999", 2, true, 9999999999999999999900.0},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%v_%d", s.value, s.scale), func(t *testing.T) {
+			result, ok := scaleNumber(s.value, s.scale)
+			if ok != s.expectOk {
+				t.Fatalf("Expected ok %v, got %v (%v)", s.expectOk, ok, result)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %v (%T), got %v (%T)", s.expected, s.expected, result, result)
+			}
+		})
+	}
+}
+
+func TestFormatScaled(t *testing.T) {
+	scenarios := []struct {
+		value    any
+		scale    int
+		expected any
+	}{
+		{nil, 2, nil},
+		{1.5, 2, 1.5},
+		{int64(30), 0, int64(30)},
+		{int64(0), 2, "0.00"},
+		{int64(30), 2, "0.30"},
+		{int64(5), 3, "0.005"},
+		{int64(-5), 2, "-0.05"},
+		{int64(123456), 2, "1234.56"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%v_%d", s.value, s.scale), func(t *testing.T) {
+			result := formatScaled(s.value, s.scale)
+			if result != s.expected {
+				t.Fatalf("Expected %v (%T), got %v (%T)", s.expected, s.expected, result, result)
+			}
+		})
+	}
+}
+
+func TestFilterScaledOperands(t *testing.T) {
+	t.Parallel()
+
+	resolver := &scaledFieldResolver{NewSimpleFieldResolver("test1")}
+
+	scenarios := []struct {
+		filter         string
+		expectPattern  string
+		expectedParams []any
+	}{
+		{"price > 1.1", "[[price]] > {:TEST}", []any{int64(110)}},
+		{"1.1 < price", "{:TEST} < [[price]]", []any{int64(110)}},
+		{"price = '12.5'", "[[price]] = {:TEST}", []any{int64(1250)}},
+		{"price <= 1.005", "[[price]] <= {:TEST}", []any{100.5}},
+		{"price IN [1, '2.5']", "[[price]] IN ({:TEST}, {:TEST})", []any{int64(100), int64(250)}},
+		{"price = price", "COALESCE([[price]], '') = COALESCE([[price]], '')", nil},
+		{"price = ''", "((CAST([[price]] / 100.0 AS REAL) = '' OR CAST([[price]] / 100.0 AS REAL) IS NULL))", nil},
+		{"price > test1", "CAST([[price]] / 100.0 AS REAL) > [[test1]]", nil},
+		{"price > amount", "CAST([[price]] / 100.0 AS REAL) > CAST([[amount]] / 1000.0 AS REAL)", nil},
+		{"price ~ '1.5'", "CAST([[price]] / 100.0 AS REAL) LIKE {:TEST} ESCAPE '\\'", []any{"%1.5%"}},
+		{"price IN [1, 'a']", "CAST([[price]] / 100.0 AS REAL) IN ({:TEST}, {:TEST})", nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			expr, err := FilterData(s.filter).BuildExpr(resolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			params := dbx.Params{}
+			rawSql := expr.Build(&dbx.DB{}, params)
+
+			pattern := regexp.MustCompile(strings.ReplaceAll("^"+regexp.QuoteMeta(s.expectPattern)+"$", "TEST", `\w+`))
+			if !pattern.MatchString(rawSql) {
+				t.Fatalf("Pattern %v don't match with expression: \n%v", pattern, rawSql)
+			}
+
+			for _, expected := range s.expectedParams {
+				if !slices.Contains(slices.Collect(maps.Values(params)), expected) {
+					t.Fatalf("Missing expected param %v (%T) in %v", expected, expected, params)
+				}
+			}
+		})
+	}
+}
@@ -139,6 +139,24 @@ func TestFilterDataBuildExpr(t *testing.T) {
 			false,
 			"((COALESCE([[test1]], '') = COALESCE([[test2]], '') OR COALESCE([[test2]], '') IS NOT COALESCE([[test3]], '')) AND ([[test2]] LIKE {:TEST} ESCAPE '\\' OR [[test2]] NOT LIKE {:TEST} ESCAPE '\\') AND {:TEST} LIKE ('%' || [[test1]] || '%') ESCAPE '\\' AND {:TEST} NOT LIKE ('%' || [[test2]] || '%') ESCAPE '\\' AND [[test3]] > {:TEST} AND [[test3]] >= {:TEST} AND [[test3]] <= {:TEST} AND {:TEST} < {:TEST})",
 		},
+		{
+			"IN operators",
+			"test1 IN [1, 'a', test2] && test2 not in [] && test3 ?IN '[\"a\"]'",
+			false,
+			"([[test1]] IN ({:TEST}, {:TEST}, [[test2]]) AND ([[test2]] IS NULL OR [[test2]] NOT IN ()) AND [[test3]] IN (SELECT [[value]] FROM json_each(CASE WHEN iif(json_valid({:TEST}), json_type({:TEST})='array', FALSE) THEN {:TEST} ELSE json_array({:TEST}) END)))",
+		},
+		{
+			"array literal outside of IN operator",
+			"test1 = [1, 2]",
+			true,
+			"",
+		},
+		{
+			"IN operator with missing right operand",
+			"test1 IN && test2 = 1",
+			true,
+			"",
+		},
 		{
 			"geoDistance function",
 			"geoDistance(1,2,3,4) < 567",
@@ -261,6 +279,8 @@ func TestFilterDataBuildExprWithLimit(t *testing.T) {
 		{3, "1 = 1 || 1 = 1", false},
 		{6, "(1=1 || 1=1) && (1=1 || (1=1 || 1=1)) && (1=1)", false},
 		{5, "(1=1 || 1=1) && (1=1 || (1=1 || 1=1)) && (1=1)", true},
+		{1, "1 IN [1, 2, 3, 4, 5]", false},
+		{1, "1 IN [1, 2] || 1 NOT IN [3]", true},
 	}
 
 	for i, s := range scenarios {
@@ -58,6 +58,10 @@ type Result struct {
 	PerPage    int `json:"perPage"`
 	TotalItems int `json:"totalItems"`
 	TotalPages int `json:"totalPages"`
+
+	// NextCursor is the cursor of the next page in cursor pagination mode
+	// (empty if there are no more items or the cursor mode is not enabled).
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // Provider represents a single configured search provider instance.
@@ -67,6 +71,11 @@ type Provider struct {
 	countCol           string
 	sort               []SortField
 	filter             []FilterData
+	groupBy            []string
+	aggregates         []AggregateField
+	cursor             string
+	cursorKey          string
+	cursorMode         bool
 	page               int
 	perPage            int
 	skipTotal          bool
@@ -195,6 +204,10 @@ func (s *Provider) Parse(urlQuery string) error {
 		s.SkipTotal(v)
 	}
 
+	if params.Has(CursorQueryParam) {
+		s.Cursor(params.Get(CursorQueryParam))
+	}
+
 	if raw := params.Get(PageQueryParam); raw != "" {
 		v, err := strconv.Atoi(raw)
 		if err != nil {
@@ -221,6 +234,18 @@ func (s *Provider) Parse(urlQuery string) error {
 		s.AddFilter(FilterData(raw))
 	}
 
+	if raw := params.Get(GroupByQueryParam); raw != "" {
+		for _, field := range parseGroupByFromString(raw) {
+			s.AddGroupBy(field)
+		}
+	}
+
+	if raw := params.Get(AggregateQueryParam); raw != "" {
+		for _, aggregate := range ParseAggregatesFromString(raw) {
+			s.AddAggregate(aggregate)
+		}
+	}
+
 	return nil
 }
 
@@ -235,32 +260,33 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	modelsQuery := *s.query
 
 	// build filters
-	for _, f := range s.filter {
-		if len(f) > MaxFilterLength {
-			return nil, ErrFilterLengthLimit
-		}
-		expr, err := f.BuildExprWithLimit(s.fieldResolver, s.maxFilterExprLimit)
-		if err != nil {
-			return nil, err
-		}
-		if expr != nil {
-			modelsQuery.AndWhere(expr)
-		}
+	if err := s.applyFilters(&modelsQuery); err != nil {
+		return nil, err
 	}
 
 	// apply sorting
 	if len(s.sort) > s.maxSortExprLimit {
 		return nil, ErrSortExprLimit
 	}
+	var cursorExprs []cursorSortExpr
+	var hasTieBreaker bool
 	for _, sortField := range s.sort {
 		if len(sortField.Name) > MaxSortFieldLength {
 			return nil, ErrSortFieldLengthLimit
 		}
-		expr, err := sortField.BuildExpr(s.fieldResolver)
+		if s.cursorMode && sortField.Name == randomSortKey {
+			return nil, ErrCursorRandomSort
+		}
+		expr, params, err := sortField.BuildExprWithParams(s.fieldResolver)
 		if err != nil {
 			return nil, err
 		}
 		if expr != "" {
+			if len(params) > 0 {
+				// note: merge in a new map to avoid modifying the shared base query params
+				modelsQuery.Bind(mergeParams(modelsQuery.Info().Params, params))
+			}
+
 			// ensure that _rowid_ expressions are always prefixed with the first FROM table
 			if sortField.Name == rowidSortKey && !strings.Contains(expr, ".") {
 				queryInfo := modelsQuery.Info()
@@ -270,6 +296,39 @@ func (s *Provider) Exec(items any) (*Result, error) {
 			}
 
 			modelsQuery.AndOrderBy(expr)
+
+			if s.cursorMode {
+				cursorExprs = append(cursorExprs, cursorSortExpr{
+					expr: strings.TrimSuffix(expr, " "+sortField.Direction),
+					desc: sortField.Direction == SortDesc,
+				})
+				hasTieBreaker = hasTieBreaker || sortField.Name == cursorTieBreakerCol
+			}
+		}
+	}
+
+	var tieBreakerCol string
+	var tieBreaker string
+	var cursorExpr dbx.Expression
+	if s.cursorMode {
+		tieBreakerCol = cursorTieBreakerCol
+		if queryInfo := modelsQuery.Info(); len(queryInfo.From) > 0 {
+			tieBreakerCol = dbutils.AliasOrIdentifier(queryInfo.From[0]) + "." + tieBreakerCol
+		}
+		tieBreaker = "[[" + tieBreakerCol + "]]"
+
+		if !hasTieBreaker {
+			modelsQuery.AndOrderBy(tieBreaker + " " + SortAsc)
+			cursorExprs = append(cursorExprs, cursorSortExpr{expr: tieBreaker})
+		}
+
+		if s.cursor != "" {
+			values, err := s.decodeCursor(len(cursorExprs))
+			if err != nil {
+				return nil, err
+			}
+
+			cursorExpr = buildCursorExpr(cursorExprs, values)
 		}
 	}
 
@@ -278,17 +337,7 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		return nil, err
 	}
 
-	// normalize page
-	if s.page <= 0 {
-		s.page = 1
-	}
-
-	// normalize perPage
-	if s.perPage <= 0 {
-		s.perPage = DefaultPerPage
-	} else if s.perPage > MaxPerPage {
-		s.perPage = MaxPerPage
-	}
+	s.normalizePagination()
 
 	// negative value to differentiate from the zero default
 	totalCount := -1
@@ -325,6 +374,15 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		return nil
 	}
 
+	if s.cursorMode {
+		s.page = 1 // the cursor replaces the page offset
+
+		// note: applied after the count query clone to keep the total count unaffected
+		if cursorExpr != nil {
+			modelsQuery.AndWhere(cursorExpr)
+		}
+	}
+
 	// apply pagination to the original query and fetch the models
 	modelsExec := func() error {
 		modelsQuery.Limit(int64(s.perPage))
@@ -333,18 +391,81 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		return modelsQuery.All(items)
 	}
 
-	if !s.skipTotal {
-		// execute the 2 queries concurrently
-		errg := new(errgroup.Group)
-		errg.Go(countExec)
-		errg.Go(modelsExec)
-		if err := errg.Wait(); err != nil {
-			return nil, err
+	// fetch the page items ids together with their sort values and
+	// load the models by the fetched ids so that the next page cursor
+	// always matches the last returned item
+	var nextCursor string
+	keysQuery := modelsQuery // shallow clone
+	cursorExec := func() error {
+		selects := make([]string, len(cursorExprs), len(cursorExprs)+1)
+		for i, e := range cursorExprs {
+			selects[i] = e.expr + " AS [[__pb_cursor" + strconv.Itoa(i) + "]]"
 		}
-	} else {
-		if err := modelsExec(); err != nil {
-			return nil, err
+		selects = append(selects, tieBreaker+" AS [[__pb_cursor_id]]")
+
+		// note: keysQuery is shallow cloned and slice/map in-place modifications should be avoided
+		keysQuery.Select(selects...).Limit(int64(s.perPage + 1)).Offset(0)
+
+		rows, err := keysQuery.Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		ids := make([]any, 0, s.perPage)
+		var last []any
+		for rows.Next() {
+			if len(ids) == s.perPage {
+				// there are more items
+				for i, v := range last {
+					last[i] = normalizeCursorValue(v)
+				}
+
+				nextCursor, err = s.encodeCursor(last)
+				if err != nil {
+					return err
+				}
+
+				break
+			}
+
+			values := make([]any, len(cursorExprs)+1)
+			pointers := make([]any, len(values))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+
+			if err := rows.Scan(pointers...); err != nil {
+				return err
+			}
+
+			ids = append(ids, normalizeCursorValue(values[len(cursorExprs)]))
+			last = values[:len(cursorExprs)]
 		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// note: the cursor condition is kept so that the loaded models
+		// are still within the current page even if modified in the meantime
+		modelsQuery.AndWhere(dbx.In(tieBreakerCol, ids...))
+
+		return modelsExec()
+	}
+
+	// execute the queries concurrently
+	errg := new(errgroup.Group)
+	if s.cursorMode {
+		errg.Go(cursorExec)
+	} else {
+		errg.Go(modelsExec)
+	}
+	if !s.skipTotal {
+		errg.Go(countExec)
+	}
+	if err := errg.Wait(); err != nil {
+		return nil, err
 	}
 
 	result := &Result{
@@ -352,12 +473,46 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		PerPage:    s.perPage,
 		TotalItems: totalCount,
 		TotalPages: totalPages,
+		NextCursor: nextCursor,
 		Items:      items,
 	}
 
 	return result, nil
 }
 
+// applyFilters builds and applies the provider's filters to the specified query.
+func (s *Provider) applyFilters(query *dbx.SelectQuery) error {
+	for _, f := range s.filter {
+		if len(f) > MaxFilterLength {
+			return ErrFilterLengthLimit
+		}
+		expr, err := f.BuildExprWithLimit(s.fieldResolver, s.maxFilterExprLimit)
+		if err != nil {
+			return err
+		}
+		if expr != nil {
+			query.AndWhere(expr)
+		}
+	}
+
+	return nil
+}
+
+// normalizePagination normalizes the provider's page and perPage fields.
+func (s *Provider) normalizePagination() {
+	// normalize page
+	if s.page <= 0 {
+		s.page = 1
+	}
+
+	// normalize perPage
+	if s.perPage <= 0 {
+		s.perPage = DefaultPerPage
+	} else if s.perPage > MaxPerPage {
+		s.perPage = MaxPerPage
+	}
+}
+
 // ParseAndExec is a short convenient method to trigger both
 // `Parse()` and `Exec()` in a single call.
 func (s *Provider) ParseAndExec(urlQuery string, modelsSlice any) (*Result, error) {
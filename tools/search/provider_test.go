@@ -396,6 +396,19 @@ func TestProviderExecNonEmptyQuery(t *testing.T) {
 				"SELECT * FROM `test` WHERE (NOT (`test1` IS NULL)) AND (((test3 IS NOT '' AND test3 IS NOT NULL))) ORDER BY `test1` ASC, `test3` ASC LIMIT 10",
 			},
 		},
+		{
+			"function sort field with params",
+			1,
+			10,
+			[]SortField{{"strftime('%Y', test2)", SortDesc}},
+			[]FilterData{},
+			true,
+			false,
+			`{"items":[{"test1":1,"test2":"test2.1","test3":""},{"test1":2,"test2":"test2.2","test3":""}],"page":1,"perPage":10,"totalItems":-1,"totalPages":-1}`,
+			[]string{
+				"SELECT * FROM `test` WHERE NOT (`test1` IS NULL) ORDER BY `test1` ASC, strftime('%Y',test2) DESC LIMIT 10",
+			},
+		},
 		{
 			"pagination test",
 			2,
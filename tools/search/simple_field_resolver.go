@@ -8,6 +8,7 @@ import (
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/vector"
 )
 
 type NullFallbackPreference int
@@ -42,6 +43,22 @@ type ResolverResult struct {
 	// AfterBuild is an optional function that will be called after building
 	// and combining the result of both resolved operands/sides in a single expression.
 	AfterBuild func(expr dbx.Expression) dbx.Expression
+
+	// Scale specifies the number of decimal places of a scaled INTEGER
+	// identifier (eg. "12.34" stored as 1234 has Scale 2).
+	//
+	// When building the expression, the literal values of the other operand
+	// are scaled to the same number of decimal places so that the scaled
+	// integer could be compared directly. In all other cases the identifier
+	// is converted back to its REAL value.
+	Scale int
+
+	// VectorIndex is an optional nearest neighbour index of the resolved
+	// vector column identifier.
+	//
+	// If set, the vectorDistance token function checks only the index
+	// candidates of the searched vector (the other rows resolve to NULL).
+	VectorIndex vector.Index
 }
 
 // FieldResolver defines an interface for managing search fields.
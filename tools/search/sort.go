@@ -1,8 +1,12 @@
 package search
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
 )
 
 const (
@@ -23,25 +27,82 @@ type SortField struct {
 }
 
 // BuildExpr resolves the sort field into a valid db sort expression.
+//
+// Note that sort fields that require bound params (eg. function
+// sort fields with text arguments) are not supported.
+// Use [SortField.BuildExprWithParams] instead.
 func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
+	expr, params, err := s.BuildExprWithParams(fieldResolver)
+	if err != nil {
+		return "", err
+	}
+
+	if len(params) > 0 {
+		return "", fmt.Errorf("invalid sort field %q", s.Name)
+	}
+
+	return expr, nil
+}
+
+// BuildExprWithParams resolves the sort field into a valid db sort
+// expression and its params that should be bound to the query.
+//
+// In addition to the regular field identifiers, it also supports
+// sorting by the result of a single token function (eg. "geoDistance(lon, lat, 1, 2)").
+func (s *SortField) BuildExprWithParams(fieldResolver FieldResolver) (string, dbx.Params, error) {
 	// special case for random sort
 	if s.Name == randomSortKey {
-		return "RANDOM()", nil
+		return "RANDOM()", nil, nil
 	}
 
 	// special case for the builtin SQLite rowid column
 	if s.Name == rowidSortKey {
-		return fmt.Sprintf("[[_rowid_]] %s", s.Direction), nil
+		return fmt.Sprintf("[[_rowid_]] %s", s.Direction), nil, nil
+	}
+
+	// function sort field
+	if strings.Contains(s.Name, "(") {
+		result, err := resolveSortFunction(s.Name, fieldResolver)
+		if err != nil || result.Identifier == "" {
+			return "", nil, fmt.Errorf("invalid sort field %q", s.Name)
+		}
+
+		return fmt.Sprintf("%s %s", result.Identifier, s.Direction), result.Params, nil
+	}
+
+	if isInternalIdentifier(s.Name) {
+		return "", nil, fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
 	result, err := fieldResolver.Resolve(s.Name)
 
 	// invalidate empty fields and non-column identifiers
 	if err != nil || len(result.Params) > 0 || result.Identifier == "" || strings.ToLower(result.Identifier) == "null" {
-		return "", fmt.Errorf("invalid sort field %q", s.Name)
+		return "", nil, fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
-	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil
+	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil, nil
+}
+
+func resolveSortFunction(name string, fieldResolver FieldResolver) (*ResolverResult, error) {
+	scanner := fexpr.NewScanner([]byte(name))
+
+	token, err := scanner.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != fexpr.TokenFunction {
+		return nil, errors.New("not a function")
+	}
+
+	// ensure that there is nothing else after the function
+	next, err := scanner.Scan()
+	if err != nil || next.Type != fexpr.TokenEOF {
+		return nil, errors.New("expected a single function")
+	}
+
+	return resolveToken(token, fieldResolver)
 }
 
 // ParseSortFromString parses the provided string expression
@@ -51,9 +112,7 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 //
 //	fields := search.ParseSortFromString("-name,+created")
 func ParseSortFromString(str string) (fields []SortField) {
-	data := strings.Split(str, ",")
-
-	for _, field := range data {
+	for _, field := range splitSortExpressions(str) {
 		// trim whitespaces
 		field = strings.TrimSpace(field)
 		if strings.HasPrefix(field, "-") {
@@ -65,3 +124,33 @@ func ParseSortFromString(str string) (fields []SortField) {
 
 	return
 }
+
+// splitSortExpressions splits the provided sort string by comma
+// ignoring the commas inside function arguments and quoted text.
+func splitSortExpressions(str string) []string {
+	var result []string
+
+	var depth int
+	var quote rune
+	var start int
+
+	for i, c := range str {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth <= 0:
+			result = append(result, str[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(result, str[start:])
+}
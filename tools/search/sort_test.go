@@ -3,6 +3,7 @@ package search_test
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/tools/search"
@@ -32,6 +33,14 @@ func TestSortFieldBuildExpr(t *testing.T) {
 		{search.SortField{"@random", search.SortDesc}, false, "RANDOM()"},
 		// special _rowid_ field
 		{search.SortField{"@rowid", search.SortDesc}, false, "[[_rowid_]] DESC"},
+		// unknown function
+		{search.SortField{"unknown(test1)", search.SortDesc}, true, ""},
+		// function followed by other tokens
+		{search.SortField{"strftime('%Y', test1) test2", search.SortDesc}, true, ""},
+		// function with params
+		{search.SortField{"strftime('%Y', test1)", search.SortDesc}, true, ""},
+		// function without params
+		{search.SortField{"geoDistance(test1, test2, test3, test1)", search.SortAsc}, false, "(6371 * acos(cos(radians([[test2]])) * cos(radians([[test1]])) * cos(radians([[test3]]) - radians([[test1]])) + sin(radians([[test2]])) * sin(radians([[test1]])))) ASC"},
 	}
 
 	for _, s := range scenarios {
@@ -50,6 +59,47 @@ func TestSortFieldBuildExpr(t *testing.T) {
 	}
 }
 
+func TestSortFieldBuildExprWithParams(t *testing.T) {
+	resolver := search.NewSimpleFieldResolver("test1", "test2")
+
+	scenarios := []struct {
+		sortField        search.SortField
+		expectError      bool
+		expectExpression string
+		expectParams     int
+	}{
+		{search.SortField{"unknown", search.SortAsc}, true, "", 0},
+		{search.SortField{"test1", search.SortDesc}, false, "[[test1]] DESC", 0},
+		{search.SortField{"@random", search.SortDesc}, false, "RANDOM()", 0},
+		{search.SortField{"unknown(test1)", search.SortAsc}, true, "", 0},
+		{search.SortField{"strftime('%Y', test1)", search.SortDesc}, false, "strftime({:PLACEHOLDER},[[test1]]) DESC", 1},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.sortField.Name, func(t *testing.T) {
+			result, params, err := s.sortField.BuildExprWithParams(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if len(params) != s.expectParams {
+				t.Fatalf("Expected %d params, got %d (%v)", s.expectParams, len(params), params)
+			}
+
+			// replace the random generated placeholders
+			for k := range params {
+				result = strings.ReplaceAll(result, "{:"+k+"}", "{:PLACEHOLDER}")
+			}
+
+			if result != s.expectExpression {
+				t.Fatalf("Expected expression %v, got %v", s.expectExpression, result)
+			}
+		})
+	}
+}
+
 func TestParseSortFromString(t *testing.T) {
 	scenarios := []struct {
 		value    string
@@ -62,6 +112,7 @@ func TestParseSortFromString(t *testing.T) {
 		{"test1,-test2,+test3", `[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"DESC"},{"name":"test3","direction":"ASC"}]`},
 		{"@random,-test", `[{"name":"@random","direction":"ASC"},{"name":"test","direction":"DESC"}]`},
 		{"-@rowid,-test", `[{"name":"@rowid","direction":"DESC"},{"name":"test","direction":"DESC"}]`},
+		{"-fn(a, 'b,c', \"d)\"),test", `[{"name":"fn(a, 'b,c', \"d)\")","direction":"DESC"},{"name":"test","direction":"ASC"}]`},
 	}
 
 	for _, s := range scenarios {
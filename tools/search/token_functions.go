@@ -8,8 +8,35 @@ import (
 
 	"github.com/ganigeorgiev/fexpr"
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/vector"
+	"github.com/spf13/cast"
 )
 
+// Special identifiers that the field resolvers must be able to resolve
+// in order to support the full-text search token functions (match, rank).
+//
+// They are resolved only as internal token functions arguments and
+// cannot be used as regular filter, sort or aggregate fields.
+const (
+	// RowidIdentifier is the identifier of the base table builtin SQLite rowid column.
+	RowidIdentifier = "@rowid"
+
+	// FullTextSearchIdentifier is the identifier of the base table FTS5 virtual table.
+	FullTextSearchIdentifier = "@fts"
+)
+
+// tokenInternalIdentifier is the type of the special identifier tokens
+// created by the token functions (the fexpr scanner never produces it).
+const tokenInternalIdentifier fexpr.TokenType = "internal_identifier"
+
+// isInternalIdentifier checks whether the identifier is one of
+// the special identifiers reserved for the token functions.
+func isInternalIdentifier(identifier string) bool {
+	return strings.EqualFold(identifier, RowidIdentifier) ||
+		strings.EqualFold(identifier, FullTextSearchIdentifier)
+}
+
 var TokenFunctions = map[string]func(
 	argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error),
 	args ...fexpr.Token,
@@ -58,6 +85,217 @@ var TokenFunctions = map[string]func(
 		}, nil
 	},
 
+	// geoWithin(point, polygon) checks whether a point is inside a GeoJSON Polygon or MultiPolygon
+	// (eg. `geoWithin(address, deliveryZone) = true`).
+	//
+	// The point argument could be either a "geoPoint" field identifier or a serialized
+	// {"lon":x,"lat":y} object string and the polygon argument could be either a "geoPolygon"
+	// field identifier or a serialized GeoJSON geometry string.
+	//
+	// The check is evaluated for every row using the registered "geo_within" SQLite function
+	// and it resolves to NULL if any of the arguments is empty or invalid.
+	//
+	// Similar to geoDistance, geoWithin doesn't apply a "match-all" constraints
+	// in case the arguments are multiple relation fields identifiers.
+	"geoWithin": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("[geoWithin] expected 2 arguments, got %d", len(args))
+		}
+
+		resolvedArgs := make([]*ResolverResult, 2)
+		for i, arg := range args {
+			if arg.Type != fexpr.TokenIdentifier && arg.Type != fexpr.TokenText {
+				return nil, fmt.Errorf("[geoWithin] argument %d must be an identifier or text", i)
+			}
+			resolved, err := argTokenResolverFunc(arg)
+			if err != nil {
+				return nil, fmt.Errorf("[geoWithin] failed to resolve argument %d: %w", i, err)
+			}
+			resolvedArgs[i] = resolved
+		}
+
+		return &ResolverResult{
+			NullFallback: NullFallbackDisabled,
+			Identifier:   "geo_within(" + resolvedArgs[0].Identifier + ", " + resolvedArgs[1].Identifier + ")",
+			Params:       mergeParams(resolvedArgs[0].Params, resolvedArgs[1].Params),
+		}, nil
+	},
+
+	// geoBBox(point, minLon, minLat, maxLon, maxLat) checks whether a point is inside
+	// the specified bounding box (eg. `geoBBox(address, 23.2, 42.6, 23.4, 42.8) = true`).
+	//
+	// The point argument could be either a "geoPoint" field identifier or a serialized
+	// {"lon":x,"lat":y} object string.
+	// The bounding box arguments could be either a plain number or a column identifier.
+	//
+	// A bounding box with minLon greater than maxLon is treated as crossing
+	// the antimeridian (eg. `geoBBox(address, 170, -20, -170, 20) = true`).
+	"geoBBox": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		if len(args) != 5 {
+			return nil, fmt.Errorf("[geoBBox] expected 5 arguments, got %d", len(args))
+		}
+
+		if args[0].Type != fexpr.TokenIdentifier && args[0].Type != fexpr.TokenText {
+			return nil, errors.New("[geoBBox] the point argument must be an identifier or text")
+		}
+
+		resolvedArgs := make([]*ResolverResult, 5)
+		for i, arg := range args {
+			if i > 0 && arg.Type != fexpr.TokenIdentifier && arg.Type != fexpr.TokenNumber {
+				return nil, fmt.Errorf("[geoBBox] argument %d must be an identifier or number", i)
+			}
+			resolved, err := argTokenResolverFunc(arg)
+			if err != nil {
+				return nil, fmt.Errorf("[geoBBox] failed to resolve argument %d: %w", i, err)
+			}
+			resolvedArgs[i] = resolved
+		}
+
+		point := resolvedArgs[0].Identifier
+		minLon := resolvedArgs[1].Identifier
+		minLat := resolvedArgs[2].Identifier
+		maxLon := resolvedArgs[3].Identifier
+		maxLat := resolvedArgs[4].Identifier
+
+		lon := `json_extract(` + point + `, '$.lon')`
+		lat := `json_extract(` + point + `, '$.lat')`
+
+		regularLonExpr := lon + ` BETWEEN ` + minLon + ` AND ` + maxLon
+		crossingLonExpr := `(` + lon + ` >= ` + minLon + ` OR ` + lon + ` <= ` + maxLon + `)`
+
+		var lonExpr string
+		if args[1].Type == fexpr.TokenNumber && args[3].Type == fexpr.TokenNumber {
+			if cast.ToFloat64(args[1].Literal) <= cast.ToFloat64(args[3].Literal) {
+				lonExpr = regularLonExpr
+			} else {
+				lonExpr = crossingLonExpr
+			}
+		} else {
+			lonExpr = `(CASE WHEN ` + minLon + ` <= ` + maxLon + ` THEN ` + regularLonExpr + ` ELSE ` + crossingLonExpr + ` END)`
+		}
+
+		return &ResolverResult{
+			NullFallback: NullFallbackDisabled,
+			Identifier:   `(` + lonExpr + ` AND ` + lat + ` BETWEEN ` + minLat + ` AND ` + maxLat + `)`,
+			Params: mergeParams(
+				resolvedArgs[0].Params,
+				resolvedArgs[1].Params,
+				resolvedArgs[2].Params,
+				resolvedArgs[3].Params,
+				resolvedArgs[4].Params,
+			),
+		}, nil
+	},
+
+	// vectorDistance(vectorA, vectorB, [metric], [candidates]) calculates the distance
+	// between 2 float32 vectors (lower values are "closer").
+	//
+	// The vector arguments could be either a collection field identifier
+	// (eg. "vector" field or @request.query.* value) or a JSON array string.
+	//
+	// The optional metric argument must be one of "cosine" (default), "dot" (negative inner product) or "l2".
+	//
+	// The distance is evaluated for every row using the registered vector.SQLFunctionName SQLite function
+	// and it resolves to NULL if the vectors are empty or with different dimensions.
+	//
+	// If one of the vector arguments is an identifier with a vector index (see [ResolverResult.VectorIndex])
+	// and the other one is a literal vector, only the nearest "candidates" (default to
+	// vector.DefaultIndexCandidates) rows returned by the index are checked and
+	// for all other rows the function resolves to NULL.
+	// Otherwise all rows matching the other filter conditions are checked (aka. brute-force).
+	//
+	// Similar to geoDistance, vectorDistance doesn't apply a "match-all" constraints
+	// in case the arguments are multiple relation fields identifiers.
+	"vectorDistance": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		if len(args) < 2 || len(args) > 4 {
+			return nil, fmt.Errorf("[vectorDistance] expected 2, 3 or 4 arguments, got %d", len(args))
+		}
+
+		metric := vector.MetricCosine
+		if len(args) >= 3 {
+			if args[2].Type != fexpr.TokenText || !slices.Contains(vector.Metrics, args[2].Literal) {
+				return nil, fmt.Errorf("[vectorDistance] the metric argument must be one of %v", vector.Metrics)
+			}
+			metric = args[2].Literal
+		}
+
+		candidates := vector.DefaultIndexCandidates
+		if len(args) == 4 {
+			k, err := cast.ToIntE(args[3].Literal)
+			if args[3].Type != fexpr.TokenNumber || err != nil || k <= 0 {
+				return nil, errors.New("[vectorDistance] the candidates argument must be a positive integer")
+			}
+			candidates = k
+		}
+
+		resolvedArgs := make([]*ResolverResult, 2)
+		for i, arg := range args[:2] {
+			if arg.Type != fexpr.TokenIdentifier && arg.Type != fexpr.TokenText {
+				return nil, fmt.Errorf("[vectorDistance] argument %d must be an identifier or text", i)
+			}
+			resolved, err := argTokenResolverFunc(arg)
+			if err != nil {
+				return nil, fmt.Errorf("[vectorDistance] failed to resolve argument %d: %w", i, err)
+			}
+			resolvedArgs[i] = encodeVectorLiteral(resolved)
+		}
+
+		result := &ResolverResult{
+			NullFallback: NullFallbackDisabled,
+			Identifier:   vector.SQLFunctionName + "('" + metric + "', " + resolvedArgs[0].Identifier + ", " + resolvedArgs[1].Identifier + ")",
+			Params:       mergeParams(resolvedArgs[0].Params, resolvedArgs[1].Params),
+		}
+
+		return limitVectorIndexCandidates(argTokenResolverFunc, result, resolvedArgs, metric, candidates)
+	},
+
+	// match(query) checks whether the full-text search index of the
+	// base collection contains all terms of the specified query.
+	//
+	// The query argument could be either a plain text or an identifier
+	// (eg. @request.query.q) and its whitespace separated terms are
+	// matched in any order (a term with trailing "*" is matched as prefix).
+	//
+	// It resolves to a boolean and it is expected to be used as
+	// `match('example') = true` or `match('example') = false`.
+	//
+	// The field resolver must be able to resolve the RowidIdentifier
+	// and FullTextSearchIdentifier identifiers.
+	"match": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		rowid, fts, query, err := resolveFullTextSearchArgs("match", argTokenResolverFunc, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ResolverResult{
+			NullFallback: NullFallbackDisabled,
+			Identifier: "(" + rowid.Identifier + " IN (SELECT [[rowid]] FROM " + fts.Identifier +
+				" WHERE " + fts.Identifier + " MATCH fts_query(" + query.Identifier + ")))",
+			Params: mergeParams(rowid.Params, fts.Params, query.Params),
+		}, nil
+	},
+
+	// rank(query) returns the full-text search relevance of the base
+	// collection record for the specified query (see match).
+	//
+	// Lower values indicate better match (aka. it is expected
+	// to be used as ascending sort, eg. `sort=rank('example')`).
+	// The records that don't match the query resolve to 0 (aka. the worst rank).
+	"rank": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		rowid, fts, query, err := resolveFullTextSearchArgs("rank", argTokenResolverFunc, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ResolverResult{
+			NullFallback: NullFallbackDisabled,
+			Identifier: "IFNULL((SELECT [[rank]] FROM " + fts.Identifier +
+				" WHERE " + fts.Identifier + " MATCH fts_query(" + query.Identifier + ")" +
+				" AND [[rowid]] = " + rowid.Identifier + "), 0)",
+			Params: mergeParams(rowid.Params, fts.Params, query.Params),
+		}, nil
+	},
+
 	// strftime(format, [timeValue, modifier1, modifier2, ...]) returns
 	// a date string formatted according to the specified format argument.
 	//
@@ -179,6 +417,488 @@ var TokenFunctions = map[string]func(
 
 		return result, nil
 	},
+
+	// date([timeValue, modifier1, modifier2, ...]) returns the date
+	// part of the specified time-value as "YYYY-MM-DD" string.
+	//
+	// It is similar to the builtin SQLite date function and the same
+	// time-value and modifier rules as for strftime apply
+	// (if no time-value is specified it defaults to the current date).
+	//
+	// A multi-match constraint will be also applied in case the time-value
+	// is an identifier as a result of a multi-value relation field.
+	"date": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		totalArgs := len(args)
+
+		// limit the number of arguments to prevent abuse
+		if totalArgs > 10 {
+			return nil, fmt.Errorf("[date] too many arguments (max allowed 10, got %d)", totalArgs)
+		}
+
+		if totalArgs == 0 {
+			return &ResolverResult{NullFallback: NullFallbackEnforced, Identifier: "date()"}, nil
+		}
+
+		timeValue, err := resolveTokenFunctionArg("date", argTokenResolverFunc, args[0], 0, timeValueTokenTypes...)
+		if err != nil {
+			return nil, err
+		}
+
+		modifiers := make([]*ResolverResult, totalArgs-1)
+		for i, arg := range args[1:] {
+			modifiers[i], err = resolveTokenFunctionArg("date", argTokenResolverFunc, arg, i+1, fexpr.TokenText)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return wrapTokenFunctionValue("date", NullFallbackEnforced, timeValue, modifiers, func(value string) string {
+			identifiers := []string{value}
+			for _, m := range modifiers {
+				identifiers = append(identifiers, m.Identifier)
+			}
+			return "date(" + strings.Join(identifiers, ",") + ")"
+		})
+	},
+
+	// dateAdd(timeValue, amount, unit) adds the specified amount of units
+	// to the time-value and returns the result as "YYYY-MM-DD HH:MM:SS.SSSZ"
+	// string (aka. the same format as the PocketBase "date" fields).
+	//
+	// The time-value argument accepts the same values as strftime.
+	// The amount argument could be either a plain number or an identifier
+	// (eg. @request.query.days) and the unit argument must be one of
+	// "seconds", "minutes", "hours", "days", "months" or "years".
+	//
+	// Example: `created > dateAdd(@now, -7, 'days')`.
+	//
+	// A multi-match constraint will be also applied in case the time-value
+	// is an identifier as a result of a multi-value relation field.
+	"dateAdd": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		return resolveDateShift("dateAdd", false, argTokenResolverFunc, args...)
+	},
+
+	// dateSub(timeValue, amount, unit) is the same as dateAdd
+	// but subtracts the specified amount of units from the time-value.
+	//
+	// Example: `created > dateSub(@now, 7, 'days')`.
+	"dateSub": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		return resolveDateShift("dateSub", true, argTokenResolverFunc, args...)
+	},
+
+	// lower(value) returns the value with all ASCII characters converted to lower case.
+	//
+	// The value argument could be an identifier, text or another function
+	// (eg. `lower(name) ~ 'john%'`).
+	//
+	// A multi-match constraint will be also applied in case the value
+	// is an identifier as a result of a multi-value relation field.
+	"lower": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		return resolveStringFunction("lower", argTokenResolverFunc, args...)
+	},
+
+	// upper(value) returns the value with all ASCII characters converted to upper case.
+	//
+	// The same argument rules as for lower apply.
+	"upper": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		return resolveStringFunction("upper", argTokenResolverFunc, args...)
+	},
+
+	// trim(value) returns the value with the leading and trailing spaces removed.
+	//
+	// The same argument rules as for lower apply.
+	"trim": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		return resolveStringFunction("trim", argTokenResolverFunc, args...)
+	},
+
+	// length(value) returns the number of characters of the value
+	// (NULL values are treated as empty string, aka. resolve to 0).
+	//
+	// The same argument rules as for lower apply.
+	"length": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("[length] expected 1 argument, got %d", len(args))
+		}
+
+		value, err := resolveTokenFunctionArg("length", argTokenResolverFunc, args[0], 0, stringValueTokenTypes...)
+		if err != nil {
+			return nil, err
+		}
+
+		return wrapTokenFunctionValue("length", NullFallbackDisabled, value, nil, func(value string) string {
+			return "LENGTH(COALESCE(" + value + ", ''))"
+		})
+	},
+
+	// substr(value, start, [length]) returns a substring of the value
+	// starting with the start-th character (the first character is 1).
+	//
+	// The value argument could be an identifier, text or another function.
+	// The start and the optional length arguments could be either a plain
+	// number or an identifier (negative start counts from the end of the value).
+	//
+	// A multi-match constraint will be also applied in case the value
+	// is an identifier as a result of a multi-value relation field.
+	"substr": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		if len(args) != 2 && len(args) != 3 {
+			return nil, fmt.Errorf("[substr] expected 2 or 3 arguments, got %d", len(args))
+		}
+
+		value, err := resolveTokenFunctionArg("substr", argTokenResolverFunc, args[0], 0, stringValueTokenTypes...)
+		if err != nil {
+			return nil, err
+		}
+
+		others := make([]*ResolverResult, len(args)-1)
+		for i, arg := range args[1:] {
+			others[i], err = resolveTokenFunctionArg("substr", argTokenResolverFunc, arg, i+1, numberValueTokenTypes...)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return wrapTokenFunctionValue("substr", NullFallbackEnforced, value, others, func(value string) string {
+			identifiers := []string{value}
+			for _, o := range others {
+				identifiers = append(identifiers, o.Identifier)
+			}
+			return "SUBSTR(" + strings.Join(identifiers, ",") + ")"
+		})
+	},
+
+	// coalesce(value1, value2, ...) returns the first non-empty value
+	// (aka. not NULL and not empty string) or the last value if all are empty.
+	//
+	// Empty strings are also skipped for consistency with the non-nullable
+	// PocketBase "text" and "date" fields (eg. `coalesce(nickname, name) = 'john'`).
+	//
+	// The arguments could be an identifier, text, number or another function
+	// and they must resolve to a single value (aka. multiple relation paths are not supported).
+	"coalesce": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		totalArgs := len(args)
+
+		if totalArgs < 2 {
+			return nil, fmt.Errorf("[coalesce] expected at least 2 arguments, got %d", totalArgs)
+		}
+
+		// limit the number of arguments to prevent abuse
+		if totalArgs > 10 {
+			return nil, fmt.Errorf("[coalesce] too many arguments (max allowed 10, got %d)", totalArgs)
+		}
+
+		result := &ResolverResult{
+			NullFallback: NullFallbackEnforced,
+			Params:       dbx.Params{},
+		}
+
+		identifiers := make([]string, totalArgs)
+		for i, arg := range args {
+			resolved, err := resolveTokenFunctionArg("coalesce", argTokenResolverFunc, arg, i, anyValueTokenTypes...)
+			if err != nil {
+				return nil, err
+			}
+
+			if resolved.MultiMatchSubQuery != nil {
+				return nil, fmt.Errorf("[coalesce] argument %d must be a single value", i)
+			}
+
+			if err = concatUniqueParams(result.Params, resolved.Params); err != nil {
+				return nil, err
+			}
+
+			if i == totalArgs-1 {
+				identifiers[i] = resolved.Identifier
+			} else {
+				identifiers[i] = "NULLIF(" + resolved.Identifier + ", '')"
+			}
+		}
+
+		result.Identifier = "COALESCE(" + strings.Join(identifiers, ",") + ")"
+
+		return result, nil
+	},
+}
+
+// resolveFullTextSearchArgs resolves the base table rowid and FTS5
+// table identifiers together with the single query argument of
+// a full-text search token function.
+func resolveFullTextSearchArgs(
+	name string,
+	argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error),
+	args ...fexpr.Token,
+) (rowid *ResolverResult, fts *ResolverResult, query *ResolverResult, err error) {
+	if len(args) != 1 {
+		return nil, nil, nil, fmt.Errorf("[%s] expected 1 argument, got %d", name, len(args))
+	}
+
+	if args[0].Type != fexpr.TokenIdentifier && args[0].Type != fexpr.TokenText {
+		return nil, nil, nil, fmt.Errorf("[%s] the query argument must be an identifier or text", name)
+	}
+
+	query, err = argTokenResolverFunc(args[0])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("[%s] failed to resolve the query argument: %w", name, err)
+	}
+
+	if query.MultiMatchSubQuery != nil {
+		return nil, nil, nil, fmt.Errorf("[%s] the query argument must be a single value", name)
+	}
+
+	rowid, err = argTokenResolverFunc(fexpr.Token{Type: tokenInternalIdentifier, Literal: RowidIdentifier})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("[%s] failed to resolve the rowid identifier: %w", name, err)
+	}
+
+	fts, err = argTokenResolverFunc(fexpr.Token{Type: tokenInternalIdentifier, Literal: FullTextSearchIdentifier})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("[%s] full-text search is not supported: %w", name, err)
+	}
+
+	return rowid, fts, query, nil
+}
+
+// encodeVectorLiteral returns a copy of the single param literal result
+// (eg. "{:a}" with a JSON array text value) with its value pre-encoded
+// as vector BLOB so that it is not parsed again for every checked row.
+//
+// Returns the result as it is if it is not a literal with a valid vector value.
+func encodeVectorLiteral(result *ResolverResult) *ResolverResult {
+	if len(result.Params) != 1 || result.MultiMatchSubQuery != nil {
+		return result
+	}
+
+	for name, value := range result.Params {
+		str, ok := value.(string)
+		if !ok || result.Identifier != "{:"+name+"}" {
+			return result
+		}
+
+		v, err := vector.Parse(str)
+		if err != nil || len(v) == 0 {
+			return result
+		}
+
+		clone := *result
+		clone.Params = dbx.Params{name: vector.Encode(v)}
+
+		return &clone
+	}
+
+	return result
+}
+
+// limitVectorIndexCandidates wraps the vectorDistance result so that
+// it is calculated only for the vector index candidates rows.
+//
+// Returns the result as it is if none of the resolved arguments
+// has a vector index or the index doesn't limit the candidates.
+func limitVectorIndexCandidates(
+	argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error),
+	result *ResolverResult,
+	resolvedArgs []*ResolverResult,
+	metric string,
+	k int,
+) (*ResolverResult, error) {
+	for i, arg := range resolvedArgs {
+		if arg.VectorIndex == nil {
+			continue
+		}
+
+		query := literalVector(resolvedArgs[1-i])
+		if len(query) == 0 {
+			continue
+		}
+
+		ids, err := arg.VectorIndex.Candidates(metric, query, k)
+		if err != nil {
+			return nil, fmt.Errorf("[vectorDistance] failed to lookup the vector index candidates: %w", err)
+		}
+		if ids == nil {
+			continue // all rows are candidates
+		}
+
+		id, err := argTokenResolverFunc(fexpr.Token{Type: fexpr.TokenIdentifier, Literal: "id"})
+		if err != nil {
+			return nil, fmt.Errorf("[vectorDistance] failed to resolve the vector index id identifier: %w", err)
+		}
+
+		params := mergeParams(result.Params, id.Params)
+
+		placeholders := make([]string, len(ids))
+		for j, v := range ids {
+			name := "vi" + security.PseudorandomString(8)
+			params[name] = v
+			placeholders[j] = "{:" + name + "}"
+		}
+
+		clone := *result
+		clone.Identifier = "(CASE WHEN " + id.Identifier + " IN (" + strings.Join(placeholders, ",") + ") THEN " + result.Identifier + " END)"
+		clone.Params = params
+
+		return &clone, nil
+	}
+
+	return result, nil
+}
+
+// literalVector returns the decoded vector of a result created with
+// [encodeVectorLiteral] (or nil if the result is not a literal vector).
+func literalVector(result *ResolverResult) []float32 {
+	if len(result.Params) != 1 || result.MultiMatchSubQuery != nil {
+		return nil
+	}
+
+	for name, value := range result.Params {
+		encoded, ok := value.([]byte)
+		if !ok || result.Identifier != "{:"+name+"}" {
+			return nil
+		}
+
+		v, _ := vector.Decode(encoded)
+
+		return v
+	}
+
+	return nil
+}
+
+// token function argument types
+var (
+	timeValueTokenTypes   = []fexpr.TokenType{fexpr.TokenText, fexpr.TokenIdentifier, fexpr.TokenNumber, fexpr.TokenFunction}
+	stringValueTokenTypes = []fexpr.TokenType{fexpr.TokenText, fexpr.TokenIdentifier, fexpr.TokenFunction}
+	numberValueTokenTypes = []fexpr.TokenType{fexpr.TokenNumber, fexpr.TokenIdentifier, fexpr.TokenFunction}
+	anyValueTokenTypes    = []fexpr.TokenType{fexpr.TokenText, fexpr.TokenIdentifier, fexpr.TokenNumber, fexpr.TokenFunction}
+)
+
+// dateShiftUnits lists the supported dateAdd and dateSub units.
+var dateShiftUnits = []string{"seconds", "minutes", "hours", "days", "months", "years"}
+
+// resolveTokenFunctionArg resolves a single token function argument
+// ensuring that it is one of the allowed token types.
+func resolveTokenFunctionArg(
+	name string,
+	argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error),
+	arg fexpr.Token,
+	index int,
+	allowedTypes ...fexpr.TokenType,
+) (*ResolverResult, error) {
+	if !slices.Contains(allowedTypes, arg.Type) {
+		return nil, fmt.Errorf("[%s] argument %d must be one of %v", name, index, allowedTypes)
+	}
+
+	resolved, err := argTokenResolverFunc(arg)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve argument %d: %w", name, index, err)
+	}
+
+	return resolved, nil
+}
+
+// wrapTokenFunctionValue returns a new ResolverResult with identifier
+// generated from the wrap function applied on the value identifier.
+//
+// The params of the value and the other single value arguments are merged in the result
+// and if the value has a multi-match subquery, the wrap function is applied also on its value identifier.
+func wrapTokenFunctionValue(
+	name string,
+	nullFallback NullFallbackPreference,
+	value *ResolverResult,
+	others []*ResolverResult,
+	wrap func(value string) string,
+) (*ResolverResult, error) {
+	result := &ResolverResult{
+		NullFallback: nullFallback,
+		Identifier:   wrap(value.Identifier),
+		Params:       dbx.Params{},
+	}
+
+	if err := concatUniqueParams(result.Params, value.Params); err != nil {
+		return nil, err
+	}
+
+	for i, o := range others {
+		if o.MultiMatchSubQuery != nil {
+			return nil, fmt.Errorf("[%s] argument %d must be a single value", name, i+1)
+		}
+
+		if err := concatUniqueParams(result.Params, o.Params); err != nil {
+			return nil, err
+		}
+	}
+
+	if value.MultiMatchSubQuery != nil {
+		result.MultiMatchSubQuery = value.MultiMatchSubQuery
+		result.MultiMatchSubQuery.ValueIdentifier = wrap(value.MultiMatchSubQuery.ValueIdentifier)
+		if result.MultiMatchSubQuery.Params == nil {
+			result.MultiMatchSubQuery.Params = dbx.Params{}
+		}
+
+		if err := concatUniqueParams(result.MultiMatchSubQuery.Params, result.Params); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// resolveStringFunction resolves a single argument builtin SQLite string function (lower, upper, trim).
+func resolveStringFunction(
+	name string,
+	argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error),
+	args ...fexpr.Token,
+) (*ResolverResult, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("[%s] expected 1 argument, got %d", name, len(args))
+	}
+
+	value, err := resolveTokenFunctionArg(name, argTokenResolverFunc, args[0], 0, stringValueTokenTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapTokenFunctionValue(name, NullFallbackEnforced, value, nil, func(value string) string {
+		return strings.ToUpper(name) + "(" + value + ")"
+	})
+}
+
+// resolveDateShift resolves the dateAdd and dateSub token functions arguments.
+func resolveDateShift(
+	name string,
+	subtract bool,
+	argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error),
+	args ...fexpr.Token,
+) (*ResolverResult, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("[%s] expected 3 arguments, got %d", name, len(args))
+	}
+
+	if args[2].Type != fexpr.TokenText || !slices.Contains(dateShiftUnits, args[2].Literal) {
+		return nil, fmt.Errorf("[%s] the unit argument must be one of %v", name, dateShiftUnits)
+	}
+	unit := args[2].Literal
+
+	timeValue, err := resolveTokenFunctionArg(name, argTokenResolverFunc, args[0], 0, timeValueTokenTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := resolveTokenFunctionArg(name, argTokenResolverFunc, args[1], 1, numberValueTokenTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := ""
+	if subtract {
+		sign = "-"
+	}
+
+	// note: the amount is explicitly casted because the SQLite modifiers are
+	// strings and the unit is safe to concatenate because it is from a predefined list
+	modifier := "(" + sign + "CAST(" + amount.Identifier + " AS REAL) || ' " + unit + "')"
+
+	return wrapTokenFunctionValue(name, NullFallbackEnforced, timeValue, []*ResolverResult{amount}, func(value string) string {
+		return "strftime('%Y-%m-%d %H:%M:%fZ'," + value + "," + modifier + ")"
+	})
 }
 
 func concatUniqueParams(destParams, newParams dbx.Params) error {
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -9,8 +10,43 @@ import (
 	"github.com/ganigeorgiev/fexpr"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/vector"
 )
 
+func TestTokenFunctionsInternalIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	resolver := NewSimpleFieldResolver(`^@\w+$`, "title")
+
+	invalidFilters := []string{
+		"@rowid > 1",
+		"@ROWID > 1",
+		"@fts = 'test'",
+		"title = @fts",
+	}
+	for _, filter := range invalidFilters {
+		if _, err := FilterData(filter).BuildExpr(resolver); err == nil {
+			t.Errorf("[%s] Expected filter error", filter)
+		}
+	}
+
+	invalidSortFields := []string{"@fts", "@ROWID"}
+	for _, name := range invalidSortFields {
+		if _, err := (&SortField{Name: name, Direction: SortAsc}).BuildExpr(resolver); err == nil {
+			t.Errorf("[%s] Expected sort error", name)
+		}
+	}
+
+	// the token functions should still be able to resolve them
+	result, err := resolveToken(fexpr.Token{Type: tokenInternalIdentifier, Literal: RowidIdentifier}, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Identifier != "[[@rowid]]" {
+		t.Fatalf("Expected the internal identifier to be resolved, got %q", result.Identifier)
+	}
+}
+
 func TestTokenFunctionsGeoDistance(t *testing.T) {
 	t.Parallel()
 
@@ -209,18 +245,12 @@ func TestTokenFunctionsGeoDistanceExec(t *testing.T) {
 	}
 }
 
-func TestTokenFunctionsStrftime(t *testing.T) {
+func TestTokenFunctionsVectorDistance(t *testing.T) {
 	t.Parallel()
 
-	testDB, err := createTestDB()
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer testDB.Close()
-
-	fn, ok := TokenFunctions["strftime"]
+	fn, ok := TokenFunctions["vectorDistance"]
 	if !ok {
-		t.Error("Expected strftime token function to be registered.")
+		t.Error("Expected vectorDistance token function to be registered.")
 	}
 
 	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
@@ -242,255 +272,221 @@ func TestTokenFunctionsStrftime(t *testing.T) {
 			nil,
 			true,
 		},
-
-		// format arg
-		// -----------------------------------------------------------
 		{
-			"(format arg) invalid token type function",
+			"< 2 args",
 			[]fexpr.Token{
-				{Literal: "abc", Type: fexpr.TokenFunction},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
 			},
 			baseTokenResolver,
 			nil,
 			true,
 		},
 		{
-			"(format arg) invalid token type ws",
+			"> 4 args",
 			[]fexpr.Token{
-				{Literal: "abc", Type: fexpr.TokenWS},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "l2", Type: fexpr.TokenText},
+				{Literal: "10", Type: fexpr.TokenNumber},
+				{Literal: "c", Type: fexpr.TokenIdentifier},
 			},
 			baseTokenResolver,
 			nil,
 			true,
 		},
 		{
-			"(format arg) invalid token type number",
+			"non-number candidates",
 			[]fexpr.Token{
-				{Literal: "abc", Type: fexpr.TokenNumber},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "l2", Type: fexpr.TokenText},
+				{Literal: "c", Type: fexpr.TokenIdentifier},
 			},
 			baseTokenResolver,
 			nil,
 			true,
 		},
 		{
-			"(format arg) invalid token type identifier",
+			"non-positive candidates",
 			[]fexpr.Token{
-				{Literal: "abc", Type: fexpr.TokenIdentifier},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "l2", Type: fexpr.TokenText},
+				{Literal: "0", Type: fexpr.TokenNumber},
 			},
 			baseTokenResolver,
 			nil,
 			true,
 		},
 		{
-			"(format arg) valid token type text",
+			"unsupported number argument",
 			[]fexpr.Token{
-				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
 			},
 			baseTokenResolver,
-			&ResolverResult{
-				NullFallback: NullFallbackEnforced,
-				Identifier:   `strftime({:format})`,
-				Params:       map[string]any{"format": "abc"},
-			},
-			false,
+			nil,
+			true,
 		},
-
-		// format + time-value args
-		// -----------------------------------------------------------
 		{
-			"(format arg) invalid token type function",
+			"unknown metric",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText},
-				{Literal: "2", Type: fexpr.TokenFunction},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "unknown", Type: fexpr.TokenText},
 			},
 			baseTokenResolver,
 			nil,
 			true,
 		},
 		{
-			"(format arg) invalid token type ws",
+			"non-text metric",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText},
-				{Literal: "2", Type: fexpr.TokenWS},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "l2", Type: fexpr.TokenIdentifier},
 			},
 			baseTokenResolver,
 			nil,
 			true,
 		},
 		{
-			"(format arg) valid token type number",
+			"valid arguments but with resolver error",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText},
-				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
 			},
-			baseTokenResolver,
-			&ResolverResult{
-				NullFallback: NullFallbackEnforced,
-				Identifier:   `strftime({:format},{:time})`,
-				Params:       map[string]any{"format": "1", "time": "2"},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				return nil, errors.New("test")
 			},
-			false,
+			nil,
+			true,
 		},
 		{
-			"(format arg) valid token type identifier",
+			"default metric",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText},
-				{Literal: "2", Type: fexpr.TokenIdentifier},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "[1,2]", Type: fexpr.TokenText},
 			},
 			baseTokenResolver,
 			&ResolverResult{
-				NullFallback: NullFallbackEnforced,
-				Identifier:   `strftime({:format},{:time})`,
-				Params:       map[string]any{"format": "1", "time": "2"},
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `vector_distance('cosine', {:a}, {:b})`,
+				Params: map[string]any{
+					"a": "a",
+					"b": vector.Encode([]float32{1, 2}),
+				},
 			},
 			false,
 		},
 		{
-			"(format arg) valid token type text",
+			"invalid vector text",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText},
-				{Literal: "2", Type: fexpr.TokenText},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "[1,", Type: fexpr.TokenText},
 			},
 			baseTokenResolver,
 			&ResolverResult{
-				NullFallback: NullFallbackEnforced,
-				Identifier:   `strftime({:format},{:time})`,
-				Params:       map[string]any{"format": "1", "time": "2"},
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `vector_distance('cosine', {:a}, {:b})`,
+				Params: map[string]any{
+					"a": "a",
+					"b": "[1,",
+				},
 			},
 			false,
 		},
-
-		// format + time-value + modifier args
-		// -----------------------------------------------------------
 		{
-			"(modifiers arg) invalid token type function",
+			"explicit metric",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText}, // valid format
-				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
-				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
-				{Literal: "4", Type: fexpr.TokenFunction},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "l2", Type: fexpr.TokenText},
 			},
 			baseTokenResolver,
-			nil,
-			true,
-		},
-		{
-			"(modifiers arg) invalid token type ws",
-			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText}, // valid format
-				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
-				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
-				{Literal: "4", Type: fexpr.TokenWS},
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `vector_distance('l2', {:a}, {:b})`,
+				Params: map[string]any{
+					"a": "a",
+					"b": "b",
+				},
 			},
-			baseTokenResolver,
-			nil,
-			true,
+			false,
 		},
 		{
-			"(modifiers arg) valid token type number",
+			"brute-force vector index",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText}, // valid format
-				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
-				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
-				{Literal: "4", Type: fexpr.TokenNumber},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "[1,2]", Type: fexpr.TokenText},
 			},
-			baseTokenResolver,
-			nil,
-			true,
-		},
-		{
-			"(modifiers arg) valid token type identifier",
-			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText}, // valid format
-				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
-				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
-				{Literal: "4", Type: fexpr.TokenIdentifier},
+			vectorIndexTokenResolver(vector.BruteForce{}),
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `vector_distance('cosine', [[a]], {:b})`,
+				Params: map[string]any{
+					"b": vector.Encode([]float32{1, 2}),
+				},
 			},
-			baseTokenResolver,
-			nil,
-			true,
+			false,
 		},
 		{
-			"(modifiers arg) valid token type text",
+			"vector index with non-literal vector",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText}, // valid format
-				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
-				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
-				{Literal: "4", Type: fexpr.TokenText}, // valid modifier
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
 			},
-			baseTokenResolver,
+			vectorIndexTokenResolver(&testVectorIndex{ids: []string{"r1"}}),
 			&ResolverResult{
-				NullFallback: NullFallbackEnforced,
-				Identifier:   `strftime({:format},{:time},{:m1},{:m2})`,
-				Params:       map[string]any{"format": "1", "time": "2", "m1": "3", "m2": "4"},
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `vector_distance('cosine', [[a]], [[b]])`,
 			},
 			false,
 		},
-
-		// -----------------------------------------------------------
-
 		{
-			"= 10 args limit",
+			"vector index with default candidates",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText},
-				{Literal: "2", Type: fexpr.TokenText},
-				{Literal: "3", Type: fexpr.TokenText},
-				{Literal: "4", Type: fexpr.TokenText},
-				{Literal: "5", Type: fexpr.TokenText},
-				{Literal: "6", Type: fexpr.TokenText},
-				{Literal: "7", Type: fexpr.TokenText},
-				{Literal: "8", Type: fexpr.TokenText},
-				{Literal: "9", Type: fexpr.TokenText},
-				{Literal: "10", Type: fexpr.TokenText},
+				{Literal: "[1,2]", Type: fexpr.TokenText},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
 			},
-			baseTokenResolver,
+			vectorIndexTokenResolver(&testVectorIndex{ids: []string{"r1", "r2"}, expectedK: vector.DefaultIndexCandidates}),
 			&ResolverResult{
-				NullFallback: NullFallbackEnforced,
-				Identifier:   `strftime({:format},{:time},{:m1},{:m2},{:m3},{:m4},{:m5},{:m6},{:m7},{:m8})`,
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `(CASE WHEN [[id]] IN ({:r1},{:r2}) THEN vector_distance('cosine', {:b}, [[a]]) END)`,
 				Params: map[string]any{
-					"format": "1",
-					"time":   "2",
-					"m1":     "3",
-					"m2":     "4",
-					"m3":     "5",
-					"m4":     "6",
-					"m5":     "7",
-					"m6":     "8",
-					"m7":     "9",
-					"m8":     "10",
+					"b":  vector.Encode([]float32{1, 2}),
+					"r1": "r1",
+					"r2": "r2",
 				},
 			},
 			false,
 		},
 		{
-			"> 10 args limit",
+			"vector index with explicit candidates",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText},
-				{Literal: "2", Type: fexpr.TokenText},
-				{Literal: "3", Type: fexpr.TokenText},
-				{Literal: "4", Type: fexpr.TokenText},
-				{Literal: "5", Type: fexpr.TokenText},
-				{Literal: "6", Type: fexpr.TokenText},
-				{Literal: "7", Type: fexpr.TokenText},
-				{Literal: "8", Type: fexpr.TokenText},
-				{Literal: "9", Type: fexpr.TokenText},
-				{Literal: "10", Type: fexpr.TokenText},
-				{Literal: "11", Type: fexpr.TokenText},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "[1,2]", Type: fexpr.TokenText},
+				{Literal: "l2", Type: fexpr.TokenText},
+				{Literal: "5", Type: fexpr.TokenNumber},
 			},
-			baseTokenResolver,
-			nil,
-			true,
+			vectorIndexTokenResolver(&testVectorIndex{ids: []string{"r1"}, expectedK: 5}),
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `(CASE WHEN [[id]] IN ({:r1}) THEN vector_distance('l2', [[a]], {:b}) END)`,
+				Params: map[string]any{
+					"b":  vector.Encode([]float32{1, 2}),
+					"r1": "r1",
+				},
+			},
+			false,
 		},
 		{
-			"valid arguments but with resolver error",
+			"vector index error",
 			[]fexpr.Token{
-				{Literal: "1", Type: fexpr.TokenText},
-				{Literal: "2", Type: fexpr.TokenText},
-				{Literal: "3", Type: fexpr.TokenText},
-			},
-			func(t fexpr.Token) (*ResolverResult, error) {
-				return nil, errors.New("test")
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "[1,2]", Type: fexpr.TokenText},
 			},
+			vectorIndexTokenResolver(&testVectorIndex{err: errors.New("test")}),
 			nil,
 			true,
 		},
@@ -510,47 +506,1497 @@ func TestTokenFunctionsStrftime(t *testing.T) {
 	}
 }
 
-func TestTokenFunctionsStrftimeExec(t *testing.T) {
-	t.Parallel()
+type testVectorIndex struct {
+	ids       []string
+	err       error
+	expectedK int
+}
 
-	testDB, err := createTestDB()
-	if err != nil {
-		t.Fatal(err)
+func (idx *testVectorIndex) Candidates(metric string, query []float32, k int) ([]string, error) {
+	if idx.expectedK > 0 && k != idx.expectedK {
+		return nil, fmt.Errorf("expected k %d, got %d", idx.expectedK, k)
 	}
-	defer testDB.Close()
 
-	fn, ok := TokenFunctions["strftime"]
-	if !ok {
-		t.Error("Expected strftime token function to be registered.")
-	}
+	return idx.ids, idx.err
+}
 
-	result, err := fn(
-		func(t fexpr.Token) (*ResolverResult, error) {
-			placeholder := "t" + security.PseudorandomString(5)
-			return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
-		},
-		fexpr.Token{Literal: "%Y-%m", Type: fexpr.TokenText},
-		fexpr.Token{Literal: "2026-01-02 01:02:03.456Z", Type: fexpr.TokenText},
-		fexpr.Token{Literal: "+1 years", Type: fexpr.TokenText},
-		fexpr.Token{Literal: "+5 months", Type: fexpr.TokenText},
-	)
-	if err != nil {
-		t.Fatal(err)
+// vectorIndexTokenResolver returns a token resolver that resolves the
+// identifiers as columns (with the provided vector index) and the text tokens as params.
+func vectorIndexTokenResolver(idx vector.Index) func(t fexpr.Token) (*ResolverResult, error) {
+	return func(t fexpr.Token) (*ResolverResult, error) {
+		if t.Type == fexpr.TokenIdentifier {
+			return &ResolverResult{Identifier: "[[" + t.Literal + "]]", VectorIndex: idx}, nil
+		}
+
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
 	}
+}
 
-	column := []string{}
-	err = testDB.NewQuery("select " + result.Identifier).Bind(result.Params).Column(&column)
-	if err != nil {
-		t.Fatal(err)
+func TestTokenFunctionsGeoWithin(t *testing.T) {
+	t.Parallel()
+
+	fn, ok := TokenFunctions["geoWithin"]
+	if !ok {
+		t.Error("Expected geoWithin token function to be registered.")
 	}
 
-	if len(column) != 1 {
-		t.Fatalf("Expected exactly 1 column value as result, got %v", column)
+	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
 	}
 
-	expected := "2027-06"
-	if column[0] != expected {
-		t.Fatalf("Expected date value %s, got %s", expected, column[0])
+	scenarios := []struct {
+		name      string
+		args      []fexpr.Token
+		resolver  func(t fexpr.Token) (*ResolverResult, error)
+		result    *ResolverResult
+		expectErr bool
+	}{
+		{
+			"no args",
+			nil,
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"< 2 args",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"> 2 args",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "c", Type: fexpr.TokenIdentifier},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"unsupported number argument",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"valid arguments but with resolver error",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				return nil, errors.New("test")
+			},
+			nil,
+			true,
+		},
+		{
+			"valid arguments",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: `{"type":"Polygon","coordinates":[]}`, Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `geo_within({:point}, {:polygon})`,
+				Params: map[string]any{
+					"point":   "a",
+					"polygon": `{"type":"Polygon","coordinates":[]}`,
+				},
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := fn(s.resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			testCompareResults(t, s.result, result)
+		})
+	}
+}
+
+func TestTokenFunctionsGeoBBox(t *testing.T) {
+	t.Parallel()
+
+	fn, ok := TokenFunctions["geoBBox"]
+	if !ok {
+		t.Error("Expected geoBBox token function to be registered.")
+	}
+
+	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
+	}
+
+	scenarios := []struct {
+		name      string
+		args      []fexpr.Token
+		resolver  func(t fexpr.Token) (*ResolverResult, error)
+		result    *ResolverResult
+		expectErr bool
+	}{
+		{
+			"no args",
+			nil,
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"< 5 args",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "3", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"number point argument",
+			[]fexpr.Token{
+				{Literal: "0", Type: fexpr.TokenNumber},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "3", Type: fexpr.TokenNumber},
+				{Literal: "4", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"text bbox argument",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "3", Type: fexpr.TokenNumber},
+				{Literal: "4", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"valid arguments but with resolver error",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "3", Type: fexpr.TokenNumber},
+				{Literal: "4", Type: fexpr.TokenNumber},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				return nil, errors.New("test")
+			},
+			nil,
+			true,
+		},
+		{
+			"valid arguments",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "3", Type: fexpr.TokenNumber},
+				{Literal: "4", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `(json_extract({:p}, '$.lon') BETWEEN {:minLon} AND {:maxLon} AND json_extract({:p}, '$.lat') BETWEEN {:minLat} AND {:maxLat})`,
+				Params: map[string]any{
+					"p":      "a",
+					"minLon": 1,
+					"minLat": 2,
+					"maxLon": 3,
+					"maxLat": 4,
+				},
+			},
+			false,
+		},
+		{
+			"antimeridian crossing bbox",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "170", Type: fexpr.TokenNumber},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "-170", Type: fexpr.TokenNumber},
+				{Literal: "4", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `((json_extract({:p}, '$.lon') >= {:minLon} OR json_extract({:p}, '$.lon') <= {:maxLon}) AND json_extract({:p}, '$.lat') BETWEEN {:minLat} AND {:maxLat})`,
+				Params: map[string]any{
+					"p":      "a",
+					"minLon": 170,
+					"minLat": 2,
+					"maxLon": -170,
+					"maxLat": 4,
+				},
+			},
+			false,
+		},
+		{
+			"identifier bbox arguments",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "c", Type: fexpr.TokenIdentifier},
+				{Literal: "4", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `((CASE WHEN {:minLon} <= {:maxLon} THEN json_extract({:p}, '$.lon') BETWEEN {:minLon} AND {:maxLon} ELSE (json_extract({:p}, '$.lon') >= {:minLon} OR json_extract({:p}, '$.lon') <= {:maxLon}) END) AND json_extract({:p}, '$.lat') BETWEEN {:minLat} AND {:maxLat})`,
+				Params: map[string]any{
+					"p":      "a",
+					"minLon": "b",
+					"minLat": 2,
+					"maxLon": "c",
+					"maxLat": 4,
+				},
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := fn(s.resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			testCompareResults(t, s.result, result)
+		})
+	}
+}
+
+func TestTokenFunctionsMatch(t *testing.T) {
+	t.Parallel()
+
+	fn, ok := TokenFunctions["match"]
+	if !ok {
+		t.Error("Expected match token function to be registered.")
+	}
+
+	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
+		switch t.Literal {
+		case RowidIdentifier:
+			return &ResolverResult{Identifier: "[[demo._rowid_]]"}, nil
+		case FullTextSearchIdentifier:
+			return &ResolverResult{Identifier: "{{_fts_demo}}"}, nil
+		}
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
+	}
+
+	scenarios := []struct {
+		name      string
+		args      []fexpr.Token
+		resolver  func(t fexpr.Token) (*ResolverResult, error)
+		result    *ResolverResult
+		expectErr bool
+	}{
+		{
+			"no args",
+			nil,
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"> 1 args",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenText},
+				{Literal: "b", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"unsupported number argument",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"multi-match query argument",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				return &ResolverResult{Identifier: "[[a]]", MultiMatchSubQuery: &MultiMatchSubquery{}}, nil
+			},
+			nil,
+			true,
+		},
+		{
+			"unsupported full-text search identifier",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenText},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				if t.Literal == FullTextSearchIdentifier {
+					return nil, errors.New("test")
+				}
+				return baseTokenResolver(t)
+			},
+			nil,
+			true,
+		},
+		{
+			"text argument",
+			[]fexpr.Token{
+				{Literal: "hello world", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   "([[demo._rowid_]] IN (SELECT [[rowid]] FROM {{_fts_demo}} WHERE {{_fts_demo}} MATCH fts_query({:a})))",
+				Params: map[string]any{
+					"a": "hello world",
+				},
+			},
+			false,
+		},
+		{
+			"identifier argument",
+			[]fexpr.Token{
+				{Literal: "@request.query.q", Type: fexpr.TokenIdentifier},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   "([[demo._rowid_]] IN (SELECT [[rowid]] FROM {{_fts_demo}} WHERE {{_fts_demo}} MATCH fts_query({:a})))",
+				Params: map[string]any{
+					"a": "@request.query.q",
+				},
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := fn(s.resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			testCompareResults(t, s.result, result)
+		})
+	}
+}
+
+func TestTokenFunctionsRank(t *testing.T) {
+	t.Parallel()
+
+	fn, ok := TokenFunctions["rank"]
+	if !ok {
+		t.Error("Expected rank token function to be registered.")
+	}
+
+	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
+		switch t.Literal {
+		case RowidIdentifier:
+			return &ResolverResult{Identifier: "[[demo._rowid_]]"}, nil
+		case FullTextSearchIdentifier:
+			return &ResolverResult{Identifier: "{{_fts_demo}}"}, nil
+		}
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
+	}
+
+	scenarios := []struct {
+		name      string
+		args      []fexpr.Token
+		resolver  func(t fexpr.Token) (*ResolverResult, error)
+		result    *ResolverResult
+		expectErr bool
+	}{
+		{
+			"no args",
+			nil,
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"> 1 args",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenText},
+				{Literal: "b", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"unsupported number argument",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"multi-match query argument",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				return &ResolverResult{Identifier: "[[a]]", MultiMatchSubQuery: &MultiMatchSubquery{}}, nil
+			},
+			nil,
+			true,
+		},
+		{
+			"unsupported full-text search identifier",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenText},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				if t.Literal == FullTextSearchIdentifier {
+					return nil, errors.New("test")
+				}
+				return baseTokenResolver(t)
+			},
+			nil,
+			true,
+		},
+		{
+			"text argument",
+			[]fexpr.Token{
+				{Literal: "hello world", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   "IFNULL((SELECT [[rank]] FROM {{_fts_demo}} WHERE {{_fts_demo}} MATCH fts_query({:a}) AND [[rowid]] = [[demo._rowid_]]), 0)",
+				Params: map[string]any{
+					"a": "hello world",
+				},
+			},
+			false,
+		},
+		{
+			"identifier argument",
+			[]fexpr.Token{
+				{Literal: "@request.query.q", Type: fexpr.TokenIdentifier},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   "IFNULL((SELECT [[rank]] FROM {{_fts_demo}} WHERE {{_fts_demo}} MATCH fts_query({:a}) AND [[rowid]] = [[demo._rowid_]]), 0)",
+				Params: map[string]any{
+					"a": "@request.query.q",
+				},
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := fn(s.resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			testCompareResults(t, s.result, result)
+		})
+	}
+}
+
+func TestTokenFunctionsStrftime(t *testing.T) {
+	t.Parallel()
+
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	fn, ok := TokenFunctions["strftime"]
+	if !ok {
+		t.Error("Expected strftime token function to be registered.")
+	}
+
+	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
+	}
+
+	scenarios := []struct {
+		name      string
+		args      []fexpr.Token
+		resolver  func(t fexpr.Token) (*ResolverResult, error)
+		result    *ResolverResult
+		expectErr bool
+	}{
+		{
+			"no args",
+			nil,
+			baseTokenResolver,
+			nil,
+			true,
+		},
+
+		// format arg
+		// -----------------------------------------------------------
+		{
+			"(format arg) invalid token type function",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenFunction},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(format arg) invalid token type ws",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenWS},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(format arg) invalid token type number",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(format arg) invalid token type identifier",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenIdentifier},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(format arg) valid token type text",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `strftime({:format})`,
+				Params:       map[string]any{"format": "abc"},
+			},
+			false,
+		},
+
+		// format + time-value args
+		// -----------------------------------------------------------
+		{
+			"(format arg) invalid token type function",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenFunction},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(format arg) invalid token type ws",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenWS},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(format arg) valid token type number",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `strftime({:format},{:time})`,
+				Params:       map[string]any{"format": "1", "time": "2"},
+			},
+			false,
+		},
+		{
+			"(format arg) valid token type identifier",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenIdentifier},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `strftime({:format},{:time})`,
+				Params:       map[string]any{"format": "1", "time": "2"},
+			},
+			false,
+		},
+		{
+			"(format arg) valid token type text",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `strftime({:format},{:time})`,
+				Params:       map[string]any{"format": "1", "time": "2"},
+			},
+			false,
+		},
+
+		// format + time-value + modifier args
+		// -----------------------------------------------------------
+		{
+			"(modifiers arg) invalid token type function",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText}, // valid format
+				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
+				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
+				{Literal: "4", Type: fexpr.TokenFunction},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(modifiers arg) invalid token type ws",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText}, // valid format
+				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
+				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
+				{Literal: "4", Type: fexpr.TokenWS},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(modifiers arg) valid token type number",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText}, // valid format
+				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
+				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
+				{Literal: "4", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(modifiers arg) valid token type identifier",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText}, // valid format
+				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
+				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
+				{Literal: "4", Type: fexpr.TokenIdentifier},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"(modifiers arg) valid token type text",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText}, // valid format
+				{Literal: "2", Type: fexpr.TokenText}, // valid time-value
+				{Literal: "3", Type: fexpr.TokenText}, // valid modifier
+				{Literal: "4", Type: fexpr.TokenText}, // valid modifier
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `strftime({:format},{:time},{:m1},{:m2})`,
+				Params:       map[string]any{"format": "1", "time": "2", "m1": "3", "m2": "4"},
+			},
+			false,
+		},
+
+		// -----------------------------------------------------------
+
+		{
+			"= 10 args limit",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenText},
+				{Literal: "3", Type: fexpr.TokenText},
+				{Literal: "4", Type: fexpr.TokenText},
+				{Literal: "5", Type: fexpr.TokenText},
+				{Literal: "6", Type: fexpr.TokenText},
+				{Literal: "7", Type: fexpr.TokenText},
+				{Literal: "8", Type: fexpr.TokenText},
+				{Literal: "9", Type: fexpr.TokenText},
+				{Literal: "10", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `strftime({:format},{:time},{:m1},{:m2},{:m3},{:m4},{:m5},{:m6},{:m7},{:m8})`,
+				Params: map[string]any{
+					"format": "1",
+					"time":   "2",
+					"m1":     "3",
+					"m2":     "4",
+					"m3":     "5",
+					"m4":     "6",
+					"m5":     "7",
+					"m6":     "8",
+					"m7":     "9",
+					"m8":     "10",
+				},
+			},
+			false,
+		},
+		{
+			"> 10 args limit",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenText},
+				{Literal: "3", Type: fexpr.TokenText},
+				{Literal: "4", Type: fexpr.TokenText},
+				{Literal: "5", Type: fexpr.TokenText},
+				{Literal: "6", Type: fexpr.TokenText},
+				{Literal: "7", Type: fexpr.TokenText},
+				{Literal: "8", Type: fexpr.TokenText},
+				{Literal: "9", Type: fexpr.TokenText},
+				{Literal: "10", Type: fexpr.TokenText},
+				{Literal: "11", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"valid arguments but with resolver error",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenText},
+				{Literal: "3", Type: fexpr.TokenText},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				return nil, errors.New("test")
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := fn(s.resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			testCompareResults(t, s.result, result)
+		})
+	}
+}
+
+func TestTokenFunctionsStrftimeExec(t *testing.T) {
+	t.Parallel()
+
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	fn, ok := TokenFunctions["strftime"]
+	if !ok {
+		t.Error("Expected strftime token function to be registered.")
+	}
+
+	result, err := fn(
+		func(t fexpr.Token) (*ResolverResult, error) {
+			placeholder := "t" + security.PseudorandomString(5)
+			return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
+		},
+		fexpr.Token{Literal: "%Y-%m", Type: fexpr.TokenText},
+		fexpr.Token{Literal: "2026-01-02 01:02:03.456Z", Type: fexpr.TokenText},
+		fexpr.Token{Literal: "+1 years", Type: fexpr.TokenText},
+		fexpr.Token{Literal: "+5 months", Type: fexpr.TokenText},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	column := []string{}
+	err = testDB.NewQuery("select " + result.Identifier).Bind(result.Params).Column(&column)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(column) != 1 {
+		t.Fatalf("Expected exactly 1 column value as result, got %v", column)
+	}
+
+	expected := "2027-06"
+	if column[0] != expected {
+		t.Fatalf("Expected date value %s, got %s", expected, column[0])
+	}
+}
+
+func TestTokenFunctionsDate(t *testing.T) {
+	t.Parallel()
+
+	fn, ok := TokenFunctions["date"]
+	if !ok {
+		t.Error("Expected date token function to be registered.")
+	}
+
+	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
+	}
+
+	scenarios := []struct {
+		name      string
+		args      []fexpr.Token
+		resolver  func(t fexpr.Token) (*ResolverResult, error)
+		result    *ResolverResult
+		expectErr bool
+	}{
+		{
+			"no args",
+			nil,
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `date()`,
+			},
+			false,
+		},
+		{
+			"invalid time-value token type",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenWS},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"invalid modifier token type",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "b", Type: fexpr.TokenNumber},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"too many args",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenText},
+				{Literal: "3", Type: fexpr.TokenText},
+				{Literal: "4", Type: fexpr.TokenText},
+				{Literal: "5", Type: fexpr.TokenText},
+				{Literal: "6", Type: fexpr.TokenText},
+				{Literal: "7", Type: fexpr.TokenText},
+				{Literal: "8", Type: fexpr.TokenText},
+				{Literal: "9", Type: fexpr.TokenText},
+				{Literal: "10", Type: fexpr.TokenText},
+				{Literal: "11", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			nil,
+			true,
+		},
+		{
+			"time-value with modifiers",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "+1 days", Type: fexpr.TokenText},
+				{Literal: "start of month", Type: fexpr.TokenText},
+			},
+			baseTokenResolver,
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `date({:a},{:b},{:c})`,
+				Params: map[string]any{
+					"a": "a",
+					"b": "+1 days",
+					"c": "start of month",
+				},
+			},
+			false,
+		},
+		{
+			"multi-match time-value",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "+1 days", Type: fexpr.TokenText},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				if t.Type == fexpr.TokenIdentifier {
+					return &ResolverResult{
+						Identifier: "[[a.b]]",
+						MultiMatchSubQuery: &MultiMatchSubquery{
+							TargetTableAlias: "test_a",
+							FromTableName:    "test_b",
+							FromTableAlias:   "test_b",
+							ValueIdentifier:  "[[test_b.value]]",
+						},
+					}, nil
+				}
+				return &ResolverResult{Identifier: "{:a}", Params: map[string]any{"a": t.Literal}}, nil
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `date([[a.b]],{:a})`,
+				Params:       map[string]any{"a": "+1 days"},
+				MultiMatchSubQuery: &MultiMatchSubquery{
+					TargetTableAlias: "test_a",
+					FromTableName:    "test_b",
+					FromTableAlias:   "test_b",
+					ValueIdentifier:  "date([[test_b.value]],{:a})",
+					Params:           map[string]any{"a": "+1 days"},
+				},
+			},
+			false,
+		},
+		{
+			"resolver error",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+			},
+			func(t fexpr.Token) (*ResolverResult, error) {
+				return nil, errors.New("test")
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := fn(s.resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			testCompareResults(t, s.result, result)
+		})
+	}
+}
+
+func TestTokenFunctionsDateShift(t *testing.T) {
+	t.Parallel()
+
+	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
+	}
+
+	scenarios := []struct {
+		name      string
+		fn        string
+		args      []fexpr.Token
+		result    *ResolverResult
+		expectErr bool
+	}{
+		{
+			"(dateAdd) no args",
+			"dateAdd",
+			nil,
+			nil,
+			true,
+		},
+		{
+			"(dateAdd) missing unit",
+			"dateAdd",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenNumber},
+			},
+			nil,
+			true,
+		},
+		{
+			"(dateAdd) invalid unit",
+			"dateAdd",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "weeks", Type: fexpr.TokenText},
+			},
+			nil,
+			true,
+		},
+		{
+			"(dateAdd) non-text unit",
+			"dateAdd",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "days", Type: fexpr.TokenIdentifier},
+			},
+			nil,
+			true,
+		},
+		{
+			"(dateAdd) invalid amount token type",
+			"dateAdd",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenText},
+				{Literal: "days", Type: fexpr.TokenText},
+			},
+			nil,
+			true,
+		},
+		{
+			"(dateAdd) valid args",
+			"dateAdd",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "days", Type: fexpr.TokenText},
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `strftime('%Y-%m-%d %H:%M:%fZ',{:a},(CAST({:b} AS REAL) || ' days'))`,
+				Params:       map[string]any{"a": "a", "b": "1"},
+			},
+			false,
+		},
+		{
+			"(dateSub) valid args",
+			"dateSub",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenText},
+				{Literal: "b", Type: fexpr.TokenIdentifier},
+				{Literal: "months", Type: fexpr.TokenText},
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `strftime('%Y-%m-%d %H:%M:%fZ',{:a},(-CAST({:b} AS REAL) || ' months'))`,
+				Params:       map[string]any{"a": "a", "b": "b"},
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			fn, ok := TokenFunctions[s.fn]
+			if !ok {
+				t.Fatalf("Expected %s token function to be registered.", s.fn)
+			}
+
+			result, err := fn(baseTokenResolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			testCompareResults(t, s.result, result)
+		})
+	}
+}
+
+func TestTokenFunctionsStringFunctions(t *testing.T) {
+	t.Parallel()
+
+	baseTokenResolver := func(t fexpr.Token) (*ResolverResult, error) {
+		if t.Type == fexpr.TokenIdentifier {
+			return &ResolverResult{
+				Identifier: "[[a.b]]",
+				MultiMatchSubQuery: &MultiMatchSubquery{
+					TargetTableAlias: "test_a",
+					FromTableName:    "test_b",
+					FromTableAlias:   "test_b",
+					ValueIdentifier:  "[[test_b.value]]",
+				},
+			}, nil
+		}
+		placeholder := "t" + security.PseudorandomString(5)
+		return &ResolverResult{Identifier: "{:" + placeholder + "}", Params: map[string]any{placeholder: t.Literal}}, nil
+	}
+
+	scenarios := []struct {
+		name      string
+		fn        string
+		args      []fexpr.Token
+		result    *ResolverResult
+		expectErr bool
+	}{
+		{
+			"(lower) no args",
+			"lower",
+			nil,
+			nil,
+			true,
+		},
+		{
+			"(lower) > 1 args",
+			"lower",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenText},
+				{Literal: "b", Type: fexpr.TokenText},
+			},
+			nil,
+			true,
+		},
+		{
+			"(lower) invalid token type",
+			"lower",
+			[]fexpr.Token{
+				{Literal: "1", Type: fexpr.TokenNumber},
+			},
+			nil,
+			true,
+		},
+		{
+			"(lower) text",
+			"lower",
+			[]fexpr.Token{
+				{Literal: "ABC", Type: fexpr.TokenText},
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `LOWER({:a})`,
+				Params:       map[string]any{"a": "ABC"},
+			},
+			false,
+		},
+		{
+			"(upper) multi-match identifier",
+			"upper",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `UPPER([[a.b]])`,
+				MultiMatchSubQuery: &MultiMatchSubquery{
+					TargetTableAlias: "test_a",
+					FromTableName:    "test_b",
+					FromTableAlias:   "test_b",
+					ValueIdentifier:  "UPPER([[test_b.value]])",
+				},
+			},
+			false,
+		},
+		{
+			"(trim) text",
+			"trim",
+			[]fexpr.Token{
+				{Literal: " abc ", Type: fexpr.TokenText},
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `TRIM({:a})`,
+				Params:       map[string]any{"a": " abc "},
+			},
+			false,
+		},
+		{
+			"(length) no args",
+			"length",
+			nil,
+			nil,
+			true,
+		},
+		{
+			"(length) multi-match identifier",
+			"length",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackDisabled,
+				Identifier:   `LENGTH(COALESCE([[a.b]], ''))`,
+				MultiMatchSubQuery: &MultiMatchSubquery{
+					TargetTableAlias: "test_a",
+					FromTableName:    "test_b",
+					FromTableAlias:   "test_b",
+					ValueIdentifier:  "LENGTH(COALESCE([[test_b.value]], ''))",
+				},
+			},
+			false,
+		},
+		{
+			"(substr) 1 arg",
+			"substr",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+			},
+			nil,
+			true,
+		},
+		{
+			"(substr) invalid start token type",
+			"substr",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "1", Type: fexpr.TokenText},
+			},
+			nil,
+			true,
+		},
+		{
+			"(substr) multi-match start",
+			"substr",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+			},
+			nil,
+			true,
+		},
+		{
+			"(substr) start and length",
+			"substr",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "1", Type: fexpr.TokenNumber},
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `SUBSTR({:a},{:b},{:c})`,
+				Params:       map[string]any{"a": "abc", "b": "2", "c": "1"},
+			},
+			false,
+		},
+		{
+			"(substr) > 3 args",
+			"substr",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "2", Type: fexpr.TokenNumber},
+				{Literal: "1", Type: fexpr.TokenNumber},
+				{Literal: "1", Type: fexpr.TokenNumber},
+			},
+			nil,
+			true,
+		},
+		{
+			"(coalesce) 1 arg",
+			"coalesce",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+			},
+			nil,
+			true,
+		},
+		{
+			"(coalesce) invalid token type",
+			"coalesce",
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: " ", Type: fexpr.TokenWS},
+			},
+			nil,
+			true,
+		},
+		{
+			"(coalesce) multi-match arg",
+			"coalesce",
+			[]fexpr.Token{
+				{Literal: "a", Type: fexpr.TokenIdentifier},
+				{Literal: "abc", Type: fexpr.TokenText},
+			},
+			nil,
+			true,
+		},
+		{
+			"(coalesce) multiple args",
+			"coalesce",
+			[]fexpr.Token{
+				{Literal: "", Type: fexpr.TokenText},
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "1", Type: fexpr.TokenNumber},
+			},
+			&ResolverResult{
+				NullFallback: NullFallbackEnforced,
+				Identifier:   `COALESCE(NULLIF({:a}, ''),NULLIF({:b}, ''),{:c})`,
+				Params:       map[string]any{"a": "", "b": "abc", "c": "1"},
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			fn, ok := TokenFunctions[s.fn]
+			if !ok {
+				t.Fatalf("Expected %s token function to be registered.", s.fn)
+			}
+
+			result, err := fn(baseTokenResolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			testCompareResults(t, s.result, result)
+		})
+	}
+}
+
+func TestTokenFunctionsScalarExec(t *testing.T) {
+	t.Parallel()
+
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	resolver := NewSimpleFieldResolver(`^test\d$`)
+
+	scenarios := []struct {
+		expr     string
+		expected string
+	}{
+		{`date('2026-01-02 01:02:03.456Z')`, "2026-01-02"},
+		{`date('2026-01-02 01:02:03.456Z', 'start of month', '+1 days')`, "2026-01-02"},
+		{`dateAdd('2026-01-02 01:02:03.456Z', 7, 'days')`, "2026-01-09 01:02:03.456Z"},
+		{`dateAdd('2026-01-31 01:02:03.456Z', 1, 'months')`, "2026-03-03 01:02:03.456Z"},
+		{`dateSub('2026-01-02 01:02:03.456Z', 2, 'hours')`, "2026-01-01 23:02:03.456Z"},
+		{`dateSub('2026-01-02 01:02:03.456Z', -1, 'years')`, "2027-01-02 01:02:03.456Z"},
+		{`dateAdd(date('2026-01-02 01:02:03.456Z'), 30, 'seconds')`, "2026-01-02 00:00:30.000Z"},
+		{`lower('AbC')`, "abc"},
+		{`upper('AbC')`, "ABC"},
+		{`trim('  a b  ')`, "a b"},
+		{`length('abc')`, "3"},
+		{`length(null)`, "0"},
+		{`substr('abcde', 2)`, "bcde"},
+		{`substr('abcde', -2, 1)`, "d"},
+		{`upper(trim(substr(' abcde', 1, 3)))`, "AB"},
+		{`coalesce('', null, 'abc', 'def')`, "abc"},
+		{`coalesce('', 1)`, "1"},
+		{`length(coalesce('', ''))`, "0"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expr, func(t *testing.T) {
+			token, err := fexpr.NewScanner([]byte(s.expr)).Scan()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := resolveToken(token, resolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			column := []string{}
+			err = testDB.NewQuery("select " + result.Identifier).Bind(result.Params).Column(&column)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(column) != 1 || column[0] != s.expected {
+				t.Fatalf("Expected %q, got %v", s.expected, column)
+			}
+		})
+	}
+}
+
+func TestTokenFunctionsScalarFilterAndSort(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	scenarios := []struct {
+		query    string
+		expected string
+	}{
+		{"filter=upper(test2)='TEST2.2'", `[2]`},
+		{"filter=substr(test2, -1) = '1'", `[1]`},
+		{"filter=length(trim(test3)) = 0 %26%26 coalesce(test3, test2) = 'test2.1'", `[1]`},
+		{"filter=dateAdd('2026-01-01', test1, 'days') > '2026-01-02 12:00:00.000Z'", `[2]`},
+		{"sort=-lower(test2)", `[2,1]`},
+		{"sort=substr(test2, -1)", `[1,2]`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.query, func(t *testing.T) {
+			items := []testTableStruct{}
+
+			_, err := NewProvider(NewSimpleFieldResolver(`^test\d$`)).
+				Query(testDB.Select("*").From("test")).
+				ParseAndExec(s.query, &items)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			values := make([]int, len(items))
+			for i, item := range items {
+				values[i] = item.Test1
+			}
+
+			encoded, _ := json.Marshal(values)
+			if str := string(encoded); str != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, str)
+			}
+		})
 	}
 }
 
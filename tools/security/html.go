@@ -0,0 +1,429 @@
+package security
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DefaultHTMLAllowedTags is the list with the HTML tags allowed by default in [SanitizeHTML].
+var DefaultHTMLAllowedTags = []string{
+	"a", "abbr", "b", "blockquote", "br", "caption", "cite", "code", "col", "colgroup",
+	"dd", "del", "details", "div", "dl", "dt", "em", "figcaption", "figure",
+	"h1", "h2", "h3", "h4", "h5", "h6", "hr", "i", "img", "ins", "kbd", "li", "mark",
+	"ol", "p", "pre", "q", "s", "samp", "small", "span", "strike", "strong", "sub", "summary", "sup",
+	"table", "tbody", "td", "tfoot", "th", "thead", "tr", "u", "ul",
+}
+
+// DefaultHTMLAllowedAttributes is the list with the HTML attributes allowed by default in [SanitizeHTML].
+//
+// Each item could be either a plain attribute name allowed for all tags (eg. "class")
+// or a tag specific attribute in the format "tag:attribute" (eg. "a:href").
+//
+// The "style" attribute declarations are further filtered with [DefaultHTMLAllowedCSSProperties].
+var DefaultHTMLAllowedAttributes = []string{
+	"class", "title", "dir", "lang", "style",
+	"a:href", "a:target", "a:rel",
+	"img:src", "img:alt", "img:width", "img:height",
+	"blockquote:cite", "q:cite", "del:cite", "ins:cite",
+	"ol:start", "ol:type", "ol:reversed", "li:value",
+	"td:colspan", "td:rowspan", "th:colspan", "th:rowspan", "th:scope",
+	"col:span", "colgroup:span",
+	"details:open",
+	"table:border", "table:cellpadding", "table:cellspacing", "table:width", "table:height",
+	"td:width", "td:height", "th:width", "th:height",
+}
+
+// DefaultHTMLAllowedCSSProperties is the list with the CSS properties
+// allowed by default in the "style" attribute (see [SanitizeHTML]).
+//
+// It covers the common rich text editors formatting (text color, alignment, indentation, table sizes, etc.).
+var DefaultHTMLAllowedCSSProperties = []string{
+	"color", "background-color",
+	"font-family", "font-size", "font-style", "font-weight", "line-height", "letter-spacing",
+	"text-align", "text-decoration", "text-decoration-line", "text-indent", "text-transform",
+	"vertical-align", "white-space", "list-style-type",
+	"margin", "margin-top", "margin-right", "margin-bottom", "margin-left",
+	"padding", "padding-top", "padding-right", "padding-bottom", "padding-left",
+	"width", "height", "max-width", "min-width", "float",
+	"border", "border-width", "border-style", "border-color", "border-collapse", "border-spacing",
+}
+
+// DefaultHTMLAllowedURLSchemes is the list with the URL schemes allowed
+// by default in [SanitizeHTML] (relative URLs are always allowed).
+var DefaultHTMLAllowedURLSchemes = []string{"http", "https", "mailto", "tel"}
+
+// HTMLPolicy defines the allow-lists used by [SanitizeHTML].
+//
+// Empty list fallbacks to its related default (eg. [DefaultHTMLAllowedTags]).
+type HTMLPolicy struct {
+	// AllowedTags is a list with the allowed HTML tag names (eg. "p", "a").
+	AllowedTags []string `json:"allowedTags"`
+
+	// AllowedAttributes is a list with the allowed HTML attributes.
+	//
+	// Each item could be either a plain attribute name allowed for all tags (eg. "class")
+	// or a tag specific attribute in the format "tag:attribute" (eg. "a:href").
+	// Attribute name with "*" suffix matches all attributes with the same prefix (eg. "data-*").
+	AllowedAttributes []string `json:"allowedAttributes"`
+
+	// AllowedURLSchemes is a list with the allowed URL schemes
+	// for the URL attributes like href and src (eg. "https", "mailto").
+	AllowedURLSchemes []string `json:"allowedURLSchemes"`
+
+	// AllowedCSSProperties is a list with the allowed CSS properties
+	// in the "style" attribute (if the attribute itself is allowed).
+	AllowedCSSProperties []string `json:"allowedCSSProperties"`
+}
+
+// SanitizeHTML parses rawHTML as HTML fragment and returns its
+// sanitized serialization according to the provided policy
+// (nil policy fallbacks to the default allow-lists).
+//
+// Not allowed tags are removed but their children are preserved,
+// except for the tags whose content is never rendered as text (script, style, iframe, svg, etc.)
+// which are removed together with their content.
+// Not allowed attributes and URL attributes with not allowed scheme are removed.
+// The "style" attribute is reduced to its allowed CSS property declarations
+// (declarations with "url()", "expression()" or escape sequences are always removed).
+//
+// Regardless of the policy, script tags, event handler attributes (eg. onclick),
+// comments and "javascript:" and "vbscript:" URLs are always removed.
+func SanitizeHTML(rawHTML string, policy *HTMLPolicy) string {
+	if rawHTML == "" {
+		return ""
+	}
+
+	if policy == nil {
+		policy = &HTMLPolicy{}
+	}
+
+	s := newHTMLSanitizer(policy)
+
+	nodes, err := html.ParseFragment(strings.NewReader(rawHTML), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		// the html package parser doesn't return errors for malformed
+		// markup so this should happen only for a reader error
+		return html.EscapeString(rawHTML)
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(rawHTML))
+
+	for _, n := range nodes {
+		s.render(&builder, n)
+	}
+
+	return builder.String()
+}
+
+// -------------------------------------------------------------------
+
+// htmlDropContentTags is a list with the tags that are removed
+// together with their content if not explicitly allowed.
+var htmlDropContentTags = map[string]struct{}{
+	"script": {}, "style": {}, "iframe": {}, "frame": {}, "frameset": {}, "object": {}, "embed": {},
+	"applet": {}, "noscript": {}, "noembed": {}, "noframes": {}, "template": {}, "textarea": {},
+	"select": {}, "title": {}, "xmp": {}, "plaintext": {}, "head": {}, "svg": {}, "math": {},
+}
+
+// htmlURLAttributes is a list with the attributes that are expected to contain URLs.
+var htmlURLAttributes = map[string]struct{}{
+	"href": {}, "src": {}, "cite": {}, "action": {}, "formaction": {}, "poster": {},
+	"background": {}, "longdesc": {}, "lowsrc": {}, "dynsrc": {}, "data": {},
+	"codebase": {}, "manifest": {}, "ping": {}, "xlink:href": {},
+}
+
+// htmlVoidTags is a list with the tags that don't have a closing tag.
+var htmlVoidTags = map[string]struct{}{
+	"area": {}, "base": {}, "br": {}, "col": {}, "embed": {}, "hr": {}, "img": {}, "input": {},
+	"keygen": {}, "link": {}, "meta": {}, "param": {}, "source": {}, "track": {}, "wbr": {},
+}
+
+type htmlSanitizer struct {
+	tags         map[string]struct{}
+	attrs        map[string]struct{}
+	attrPrefixes []string
+	schemes      map[string]struct{}
+	cssProps     map[string]struct{}
+}
+
+func newHTMLSanitizer(policy *HTMLPolicy) *htmlSanitizer {
+	tags := policy.AllowedTags
+	if len(tags) == 0 {
+		tags = DefaultHTMLAllowedTags
+	}
+
+	attrs := policy.AllowedAttributes
+	if len(attrs) == 0 {
+		attrs = DefaultHTMLAllowedAttributes
+	}
+
+	schemes := policy.AllowedURLSchemes
+	if len(schemes) == 0 {
+		schemes = DefaultHTMLAllowedURLSchemes
+	}
+
+	cssProps := policy.AllowedCSSProperties
+	if len(cssProps) == 0 {
+		cssProps = DefaultHTMLAllowedCSSProperties
+	}
+
+	s := &htmlSanitizer{
+		tags:     make(map[string]struct{}, len(tags)),
+		attrs:    make(map[string]struct{}, len(attrs)),
+		schemes:  make(map[string]struct{}, len(schemes)),
+		cssProps: make(map[string]struct{}, len(cssProps)),
+	}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "script" {
+			continue // never allowed
+		}
+		s.tags[tag] = struct{}{}
+	}
+
+	for _, attr := range attrs {
+		attr = strings.ToLower(strings.TrimSpace(attr))
+		if prefix, ok := strings.CutSuffix(attr, "*"); ok {
+			s.attrPrefixes = append(s.attrPrefixes, prefix)
+		} else {
+			s.attrs[attr] = struct{}{}
+		}
+	}
+
+	for _, scheme := range schemes {
+		s.schemes[strings.ToLower(strings.TrimSpace(scheme))] = struct{}{}
+	}
+
+	for _, prop := range cssProps {
+		s.cssProps[strings.ToLower(strings.TrimSpace(prop))] = struct{}{}
+	}
+
+	return s
+}
+
+func (s *htmlSanitizer) render(builder *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		builder.WriteString(html.EscapeString(n.Data))
+	case html.ElementNode:
+		// foreign content (svg, math) is never allowed
+		if n.Namespace != "" {
+			return
+		}
+
+		if _, ok := s.tags[n.Data]; !ok {
+			if _, ok := htmlDropContentTags[n.Data]; ok {
+				return
+			}
+
+			s.renderChildren(builder, n)
+
+			return
+		}
+
+		builder.WriteString("<")
+		builder.WriteString(n.Data)
+		s.renderAttributes(builder, n)
+		builder.WriteString(">")
+
+		if _, ok := htmlVoidTags[n.Data]; ok {
+			return
+		}
+
+		s.renderChildren(builder, n)
+
+		builder.WriteString("</")
+		builder.WriteString(n.Data)
+		builder.WriteString(">")
+	case html.DocumentNode:
+		s.renderChildren(builder, n)
+	default:
+		// comments, doctype, etc.
+	}
+}
+
+func (s *htmlSanitizer) renderChildren(builder *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		s.render(builder, c)
+	}
+}
+
+func (s *htmlSanitizer) renderAttributes(builder *strings.Builder, n *html.Node) {
+	var hasTarget bool
+	relIndex := -1
+
+	attrs := make([]html.Attribute, 0, len(n.Attr))
+
+	for _, attr := range n.Attr {
+		key := attr.Key
+		if attr.Namespace != "" {
+			key = attr.Namespace + ":" + attr.Key
+		}
+
+		if !s.isAllowedAttribute(n.Data, key) {
+			continue
+		}
+
+		if _, ok := htmlURLAttributes[key]; ok && !s.isAllowedURL(attr.Val) {
+			continue
+		}
+
+		if key == "srcset" && !s.isAllowedSrcset(attr.Val) {
+			continue
+		}
+
+		if key == "style" {
+			attr.Val = s.sanitizeStyle(attr.Val)
+			if attr.Val == "" {
+				continue
+			}
+		}
+
+		if key == "target" && attr.Val != "" && attr.Val != "_self" {
+			hasTarget = true
+		}
+
+		if key == "rel" {
+			relIndex = len(attrs)
+		}
+
+		attrs = append(attrs, html.Attribute{Key: key, Val: attr.Val})
+	}
+
+	// prevent reverse tabnabbing
+	if hasTarget {
+		if relIndex < 0 {
+			attrs = append(attrs, html.Attribute{Key: "rel", Val: "noopener noreferrer"})
+		} else if !strings.Contains(strings.ToLower(attrs[relIndex].Val), "noopener") {
+			attrs[relIndex].Val = strings.TrimSpace(attrs[relIndex].Val + " noopener noreferrer")
+		}
+	}
+
+	for _, attr := range attrs {
+		builder.WriteString(" ")
+		builder.WriteString(attr.Key)
+		builder.WriteString(`="`)
+		builder.WriteString(html.EscapeString(attr.Val))
+		builder.WriteString(`"`)
+	}
+}
+
+func (s *htmlSanitizer) isAllowedAttribute(tag string, attr string) bool {
+	// event handlers are never allowed
+	if strings.HasPrefix(attr, "on") {
+		return false
+	}
+
+	if _, ok := s.attrs[attr]; ok {
+		return true
+	}
+
+	if _, ok := s.attrs[tag+":"+attr]; ok {
+		return true
+	}
+
+	for _, prefix := range s.attrPrefixes {
+		if strings.HasPrefix(attr, prefix) || strings.HasPrefix(tag+":"+attr, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *htmlSanitizer) isAllowedURL(rawURL string) bool {
+	// browsers ignore the whitespaces and control characters in the scheme (eg. "java\tscript:")
+	normalized := strings.Map(func(r rune) rune {
+		if r <= 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, rawURL)
+
+	i := strings.IndexAny(normalized, ":/?#\\")
+	if i <= 0 || normalized[i] != ':' {
+		return true // relative url
+	}
+
+	scheme := strings.ToLower(normalized[:i])
+	if scheme == "javascript" || scheme == "vbscript" {
+		return false
+	}
+
+	_, ok := s.schemes[scheme]
+
+	return ok
+}
+
+func (s *htmlSanitizer) isAllowedSrcset(srcset string) bool {
+	for _, candidate := range strings.Split(srcset, ",") {
+		url, _, _ := strings.Cut(strings.TrimSpace(candidate), " ")
+		if !s.isAllowedURL(url) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sanitizeStyle returns the allowed CSS declarations of a style attribute value.
+func (s *htmlSanitizer) sanitizeStyle(style string) string {
+	declarations := make([]string, 0, 5)
+
+	for _, declaration := range strings.Split(style, ";") {
+		prop, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+
+		prop = strings.ToLower(strings.TrimSpace(prop))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if _, ok := s.cssProps[prop]; !ok {
+			continue
+		}
+
+		if !isSafeCSSValue(value) {
+			continue
+		}
+
+		declarations = append(declarations, prop+": "+value)
+	}
+
+	return strings.Join(declarations, "; ")
+}
+
+// isSafeCSSValue checks whether the CSS value doesn't contain
+// constructs that could load external resources or execute code.
+func isSafeCSSValue(value string) bool {
+	// escape sequences and comments could be used to obfuscate the checks below
+	if strings.ContainsAny(value, "\\<>") || strings.Contains(value, "/*") {
+		return false
+	}
+
+	normalized := strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value))
+
+	for _, unsafe := range []string{"url(", "image(", "image-set(", "expression(", "javascript:", "vbscript:", "@import", "-moz-binding"} {
+		if strings.Contains(normalized, unsafe) {
+			return false
+		}
+	}
+
+	return true
+}
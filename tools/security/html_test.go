@@ -0,0 +1,150 @@
+package security_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		html     string
+		policy   *security.HTMLPolicy
+		expected string
+	}{
+		{
+			"empty",
+			"",
+			nil,
+			"",
+		},
+		{
+			"plain text",
+			"a < b & c",
+			nil,
+			"a &lt; b &amp; c",
+		},
+		{
+			"allowed markup",
+			`<p class="test">Hello <strong>world</strong><br><img src="/a.png" alt="a"></p>`,
+			nil,
+			`<p class="test">Hello <strong>world</strong><br><img src="/a.png" alt="a"></p>`,
+		},
+		{
+			"scripts and event handlers",
+			`<p onclick="alert(1)" onmouseover=alert(2)>a<script>alert(3)</script><img src=x onerror=alert(4)></p>`,
+			nil,
+			`<p>a<img src="x"></p>`,
+		},
+		{
+			"not allowed tags with preserved children",
+			`<section><font color="red">a</font><p>b</p></section>`,
+			nil,
+			`a<p>b</p>`,
+		},
+		{
+			"not allowed tags with dropped content",
+			`a<style>p{}</style><iframe src="https://example.com">b</iframe><svg><script>alert(1)</script></svg><noscript>c</noscript>d`,
+			nil,
+			`ad`,
+		},
+		{
+			"comments and doctype",
+			`<!DOCTYPE html><!-- <script>alert(1)</script> -->a`,
+			nil,
+			`a`,
+		},
+		{
+			"not allowed attributes",
+			`<a href="/a" id="test" onclick="alert(1)" data-test="1">a</a>`,
+			nil,
+			`<a href="/a">a</a>`,
+		},
+		{
+			"style declarations",
+			`<p style="text-align:center; COLOR: #f00;position:fixed;background-color:rgb(0, 0, 0);;invalid">a</p>` +
+				`<span style="background-color: url(https://example.com/a.png); width: expression(alert(1)); font-family: 'Open Sans', sans-serif">b</span>` +
+				`<span style="color: r\65 d; margin: 0/**/; font-size: 12px">c</span>` +
+				`<span style="position: absolute">d</span>`,
+			nil,
+			`<p style="text-align: center; color: #f00; background-color: rgb(0, 0, 0)">a</p>` +
+				`<span style="font-family: &#39;Open Sans&#39;, sans-serif">b</span>` +
+				`<span style="font-size: 12px">c</span>` +
+				`<span>d</span>`,
+		},
+		{
+			"url schemes",
+			`<a href="javascript:alert(1)">a</a>` +
+				`<a href=" JaVa&#x09;ScRiPt:alert(1)">b</a>` +
+				`<a href="vbscript:alert(1)">c</a>` +
+				`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">d</a>` +
+				`<a href="https://example.com?a=1&amp;b=2">e</a>` +
+				`<a href="mailto:test@example.com">f</a>` +
+				`<a href="#test">g</a>` +
+				`<a href="a/b:c">h</a>`,
+			nil,
+			`<a>a</a><a>b</a><a>c</a><a>d</a>` +
+				`<a href="https://example.com?a=1&amp;b=2">e</a>` +
+				`<a href="mailto:test@example.com">f</a>` +
+				`<a href="#test">g</a>` +
+				`<a href="a/b:c">h</a>`,
+		},
+		{
+			"target rel enforcement",
+			`<a href="/a" target="_blank">a</a><a href="/b" target="_blank" rel="nofollow">b</a><a href="/c" target="_self">c</a>`,
+			nil,
+			`<a href="/a" target="_blank" rel="noopener noreferrer">a</a>` +
+				`<a href="/b" target="_blank" rel="nofollow noopener noreferrer">b</a>` +
+				`<a href="/c" target="_self">c</a>`,
+		},
+		{
+			"unbalanced and malformed markup",
+			`<p><b>a<i>b</p>c</i>"'<img src="x" alt='"><script>alert(1)</script>'>`,
+			nil,
+			`<p><b>a<i>b</i></b></p><b><i>c</i>&#34;&#39;<img src="x" alt="&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;"></b>`,
+		},
+		{
+			"custom policy",
+			`<p>a</p><span style="color:red" data-id="1" data-x="2">b</span><a href="ftp://example.com" title="c">c</a><img src="data:image/png;base64,iVBO" srcset="https://example.com/a.png 1x, javascript:alert(1) 2x">`,
+			&security.HTMLPolicy{
+				AllowedTags:       []string{"span", "a", "img"},
+				AllowedAttributes: []string{"span:style", "data-*", "a:href", "img:src", "img:srcset"},
+				AllowedURLSchemes: []string{"ftp", "data"},
+			},
+			`a<span style="color: red" data-id="1" data-x="2">b</span><a href="ftp://example.com">c</a><img src="data:image/png;base64,iVBO">`,
+		},
+		{
+			"custom CSS properties policy",
+			`<p style="position: relative; color: red; top: url(x)">a</p>`,
+			&security.HTMLPolicy{
+				AllowedCSSProperties: []string{"position", "top"},
+			},
+			`<p style="position: relative">a</p>`,
+		},
+		{
+			"script and event handlers can't be allowed",
+			`<script>alert(1)</script><p onclick="alert(2)">a</p>`,
+			&security.HTMLPolicy{
+				AllowedTags:       []string{"script", "p"},
+				AllowedAttributes: []string{"onclick", "on*"},
+			},
+			`<p>a</p>`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := security.SanitizeHTML(s.html, s.policy)
+
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+
+			// the sanitization must be idempotent
+			if again := security.SanitizeHTML(result, s.policy); again != result {
+				t.Fatalf("Expected the sanitized result to not change on second pass, got\n%s", again)
+			}
+		})
+	}
+}
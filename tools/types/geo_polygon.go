@@ -0,0 +1,263 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Supported GeoPolygon GeoJSON geometry types.
+const (
+	GeoPolygonTypePolygon      = "Polygon"
+	GeoPolygonTypeMultiPolygon = "MultiPolygon"
+)
+
+// GeoPolygon defines a struct for storing GeoJSON Polygon or MultiPolygon geometry
+// (e.g. {"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}).
+//
+// Each polygon consists of one or more linear rings where the first one
+// is the exterior ring and the others are the interior rings (aka. holes).
+// Each ring position is in [lon, lat] order (https://datatracker.ietf.org/doc/html/rfc7946#section-3.1.6).
+//
+// The zero GeoPolygon value is serialized as null.
+type GeoPolygon struct {
+	// Type is the GeoJSON geometry type ("Polygon" or "MultiPolygon").
+	Type string
+
+	// Polygons is the list with the geometry polygons
+	// (always with 1 item for the "Polygon" type).
+	Polygons [][][][2]float64
+}
+
+// IsZero checks whether the current GeoPolygon has no polygons.
+func (p GeoPolygon) IsZero() bool {
+	return len(p.Polygons) == 0
+}
+
+// String returns the string GeoJSON representation of the current GeoPolygon instance.
+func (p GeoPolygon) String() string {
+	raw, _ := json.Marshal(p)
+	return string(raw)
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (p GeoPolygon) MarshalJSON() ([]byte, error) {
+	if p.IsZero() {
+		return []byte("null"), nil
+	}
+
+	if p.Type == GeoPolygonTypeMultiPolygon || len(p.Polygons) > 1 {
+		return json.Marshal(map[string]any{
+			"type":        GeoPolygonTypeMultiPolygon,
+			"coordinates": p.Polygons,
+		})
+	}
+
+	return json.Marshal(map[string]any{
+		"type":        GeoPolygonTypePolygon,
+		"coordinates": p.Polygons[0],
+	})
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+func (p *GeoPolygon) UnmarshalJSON(data []byte) error {
+	p.Type = ""
+	p.Polygons = nil
+
+	raw := struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}{}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch raw.Type {
+	case "":
+		if len(raw.Coordinates) != 0 && string(raw.Coordinates) != "null" {
+			return errors.New("missing GeoJSON geometry type")
+		}
+		return nil // zero value
+	case GeoPolygonTypePolygon:
+		var polygon [][][2]float64
+		if err := json.Unmarshal(raw.Coordinates, &polygon); err != nil {
+			return fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		if len(polygon) > 0 {
+			p.Polygons = [][][][2]float64{polygon}
+		}
+	case GeoPolygonTypeMultiPolygon:
+		if err := json.Unmarshal(raw.Coordinates, &p.Polygons); err != nil {
+			return fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported GeoJSON geometry type %q", raw.Type)
+	}
+
+	p.Type = raw.Type
+
+	return nil
+}
+
+// Value implements the [driver.Valuer] interface.
+func (p GeoPolygon) Value() (driver.Value, error) {
+	if p.IsZero() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(p)
+
+	return string(data), err
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided value
+// into the current GeoPolygon instance.
+//
+// The value argument could be nil (no-op), another GeoPolygon instance,
+// map or serialized GeoJSON geometry object.
+func (p *GeoPolygon) Scan(value any) error {
+	var err error
+
+	switch v := value.(type) {
+	case nil:
+		// no cast needed
+	case *GeoPolygon:
+		p.Type = v.Type
+		p.Polygons = v.Polygons
+	case GeoPolygon:
+		p.Type = v.Type
+		p.Polygons = v.Polygons
+	case JSONRaw:
+		if len(v) != 0 {
+			err = json.Unmarshal(v, p)
+		}
+	case []byte:
+		if len(v) != 0 {
+			err = json.Unmarshal(v, p)
+		}
+	case string:
+		if len(v) != 0 {
+			err = json.Unmarshal([]byte(v), p)
+		}
+	default:
+		var raw []byte
+		raw, err = json.Marshal(v)
+		if err != nil {
+			err = fmt.Errorf("unable to marshalize value for scanning: %w", err)
+		} else {
+			err = json.Unmarshal(raw, p)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("[GeoPolygon] unable to scan value %v: %w", value, err)
+	}
+
+	return nil
+}
+
+// Validate checks whether the GeoPolygon rings are valid, aka.
+// each ring is closed, has at least 4 positions and all
+// positions are with valid longitude and latitude.
+func (p GeoPolygon) Validate() error {
+	for i, polygon := range p.Polygons {
+		if len(polygon) == 0 {
+			return fmt.Errorf("polygon %d must have at least 1 linear ring", i)
+		}
+
+		for j, ring := range polygon {
+			if len(ring) < 4 {
+				return fmt.Errorf("polygon %d ring %d must have at least 4 positions", i, j)
+			}
+
+			if ring[0] != ring[len(ring)-1] {
+				return fmt.Errorf("polygon %d ring %d must be closed (the first and last positions must be the same)", i, j)
+			}
+
+			for _, pos := range ring {
+				if pos[0] < -180 || pos[0] > 180 || math.IsNaN(pos[0]) {
+					return fmt.Errorf("polygon %d ring %d has invalid longitude %v", i, j, pos[0])
+				}
+				if pos[1] < -90 || pos[1] > 90 || math.IsNaN(pos[1]) {
+					return fmt.Errorf("polygon %d ring %d has invalid latitude %v", i, j, pos[1])
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// BBox returns the bounding box of the GeoPolygon exterior rings.
+//
+// Returns zeros for zero GeoPolygon.
+func (p GeoPolygon) BBox() (minLon, minLat, maxLon, maxLat float64) {
+	if p.IsZero() {
+		return
+	}
+
+	minLon, minLat = math.Inf(1), math.Inf(1)
+	maxLon, maxLat = math.Inf(-1), math.Inf(-1)
+
+	for _, polygon := range p.Polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+		for _, pos := range polygon[0] {
+			minLon = math.Min(minLon, pos[0])
+			minLat = math.Min(minLat, pos[1])
+			maxLon = math.Max(maxLon, pos[0])
+			maxLat = math.Max(maxLat, pos[1])
+		}
+	}
+
+	return
+}
+
+// Contains checks whether the provided point is inside
+// any of the GeoPolygon polygons (excluding their holes).
+//
+// Note that the check is planar (aka. the edges are treated as
+// straight lines in the lon/lat space), which is accurate enough
+// for most regional polygons that doesn't cross the antimeridian.
+func (p GeoPolygon) Contains(point GeoPoint) bool {
+	for _, polygon := range p.Polygons {
+		if len(polygon) == 0 || !ringContains(polygon[0], point) {
+			continue
+		}
+
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if ringContains(hole, point) {
+				inHole = true
+				break
+			}
+		}
+
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ringContains checks whether point is inside ring using the even-odd ray casting rule.
+func ringContains(ring [][2]float64, point GeoPoint) bool {
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		if (yi > point.Lat) != (yj > point.Lat) &&
+			point.Lon < (xj-xi)*(point.Lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
@@ -0,0 +1,193 @@
+package types_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const testSquare = `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]]}`
+
+func TestGeoPolygonJSON(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		raw         string
+		expectError bool
+		expected    string
+	}{
+		{`null`, false, `null`},
+		{`{}`, false, `null`},
+		{`{"type":"Point","coordinates":[1,2]}`, true, `null`},
+		{`{"coordinates":[[[0,0]]]}`, true, `null`},
+		{`{"type":"Polygon","coordinates":"invalid"}`, true, `null`},
+		{`{"type":"Polygon","coordinates":[]}`, false, `null`},
+		{testSquare, false, `{"coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]],"type":"Polygon"}`},
+		{
+			`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]}`,
+			false,
+			`{"coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]],"type":"MultiPolygon"}`,
+		},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.raw), func(t *testing.T) {
+			p := types.GeoPolygon{}
+
+			err := json.Unmarshal([]byte(s.raw), &p)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if str := p.String(); str != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestGeoPolygonValue(t *testing.T) {
+	t.Parallel()
+
+	zero, err := types.GeoPolygon{}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zero != nil {
+		t.Fatalf("Expected nil value for zero polygon, got %v", zero)
+	}
+
+	p := types.GeoPolygon{}
+	if err := p.Scan(testSquare); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := p.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != p.String() {
+		t.Fatalf("Expected %q, got %v", p.String(), val)
+	}
+}
+
+func TestGeoPolygonScan(t *testing.T) {
+	t.Parallel()
+
+	polygon := types.GeoPolygon{Type: types.GeoPolygonTypePolygon, Polygons: [][][][2]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}}
+	expected := `{"coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"type":"Polygon"}`
+
+	scenarios := []struct {
+		value       any
+		expectError bool
+		expected    string
+	}{
+		{nil, false, `null`},
+		{"", false, `null`},
+		{[]byte{}, false, `null`},
+		{types.JSONRaw{}, false, `null`},
+		{"invalid", true, `null`},
+		{polygon, false, expected},
+		{&polygon, false, expected},
+		{expected, false, expected},
+		{[]byte(expected), false, expected},
+		{types.JSONRaw(expected), false, expected},
+		{map[string]any{"type": "Polygon", "coordinates": [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, false, expected},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.value), func(t *testing.T) {
+			p := types.GeoPolygon{}
+
+			err := p.Scan(s.value)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if str := p.String(); str != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestGeoPolygonValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name        string
+		polygons    [][][][2]float64
+		expectError bool
+	}{
+		{"zero", nil, false},
+		{"no rings", [][][][2]float64{{}}, true},
+		{"< 4 positions", [][][][2]float64{{{{0, 0}, {1, 0}, {0, 0}}}}, true},
+		{"not closed", [][][][2]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}}, true},
+		{"invalid lon", [][][][2]float64{{{{0, 0}, {181, 0}, {1, 1}, {0, 0}}}}, true},
+		{"invalid lat", [][][][2]float64{{{{0, 0}, {1, -91}, {1, 1}, {0, 0}}}}, true},
+		{"valid", [][][][2]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := types.GeoPolygon{Polygons: s.polygons}.Validate()
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestGeoPolygonBBox(t *testing.T) {
+	t.Parallel()
+
+	p := types.GeoPolygon{}
+	if err := p.Scan(`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,-5],[6,5],[6,6],[5,-5]]]]}`); err != nil {
+		t.Fatal(err)
+	}
+
+	minLon, minLat, maxLon, maxLat := p.BBox()
+	if minLon != 0 || minLat != -5 || maxLon != 6 || maxLat != 6 {
+		t.Fatalf("Expected bbox (0, -5, 6, 6), got (%v, %v, %v, %v)", minLon, minLat, maxLon, maxLat)
+	}
+}
+
+func TestGeoPolygonContains(t *testing.T) {
+	t.Parallel()
+
+	p := types.GeoPolygon{}
+	if err := p.Scan(testSquare); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		point    types.GeoPoint
+		expected bool
+	}{
+		{types.GeoPoint{Lon: 1, Lat: 1}, true},
+		{types.GeoPoint{Lon: 9.9, Lat: 5}, true},
+		{types.GeoPoint{Lon: 5, Lat: 5}, false}, // in the hole
+		{types.GeoPoint{Lon: -1, Lat: 5}, false},
+		{types.GeoPoint{Lon: 5, Lat: 11}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%v", s.point), func(t *testing.T) {
+			if v := p.Contains(s.point); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+
+	if (types.GeoPolygon{}).Contains(types.GeoPoint{}) {
+		t.Fatal("Expected zero polygon to not contain any point")
+	}
+}
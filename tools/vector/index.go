@@ -0,0 +1,32 @@
+package vector
+
+// DefaultIndexCandidates is the default number of the nearest
+// candidates that are looked up from an [Index].
+const DefaultIndexCandidates = 1000
+
+// Index defines an optional nearest neighbour index of a stored
+// vectors column (eg. an external HNSW or IVF index).
+//
+// It is used to limit the rows checked by the vector distance
+// functions only to the index candidates.
+type Index interface {
+	// Candidates returns the ids of up to k stored vectors that are
+	// nearest (or approximately nearest) to the query vector for
+	// the specified metric.
+	//
+	// A nil result means that all rows are candidates.
+	Candidates(metric string, query []float32, k int) ([]string, error)
+}
+
+var _ Index = BruteForce{}
+
+// BruteForce is the default [Index] implementation that doesn't
+// limit the candidates, aka. the distance is calculated for every checked row.
+type BruteForce struct{}
+
+// Candidates implements the [Index.Candidates] interface method.
+//
+// It always returns nil.
+func (BruteForce) Candidates(metric string, query []float32, k int) ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,212 @@
+// Package vector implements various helpers for storing and comparing
+// float32 vector embeddings.
+//
+// By default the vectors are compared by brute-force (aka. the distance
+// is calculated for every checked row) but an approximate nearest
+// neighbour index could be plugged in with the [Index] interface.
+package vector
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// SQLFunctionName is the name of the SQLite scalar function
+// that calculates the distance between 2 vectors.
+//
+// Its arguments are (metric, vectorA, vectorB) and each vector could
+// be either an encoded BLOB (see [Encode]) or a JSON array string.
+//
+// The function resolves to NULL if any of the vectors is empty, invalid
+// or if the vectors dimensions doesn't match.
+const SQLFunctionName = "vector_distance"
+
+// Supported distance metrics.
+const (
+	// MetricCosine represents the cosine distance (1 - cosine similarity) in the range [0, 2].
+	MetricCosine = "cosine"
+
+	// MetricDot represents the negative inner (dot) product
+	// (it is negated so that smaller values are always "closer").
+	MetricDot = "dot"
+
+	// MetricL2 represents the Euclidean distance.
+	MetricL2 = "l2"
+)
+
+// Metrics is a list with all supported distance metrics.
+var Metrics = []string{MetricCosine, MetricDot, MetricL2}
+
+var (
+	ErrDimensionsMismatch = errors.New("vectors dimensions mismatch")
+	ErrUnknownMetric      = errors.New("unknown vector distance metric")
+)
+
+// Encode encodes the provided vector as little-endian float32 bytes sequence.
+func Encode(v []float32) []byte {
+	result := make([]byte, 4*len(v))
+
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(result[i*4:], math.Float32bits(f))
+	}
+
+	return result
+}
+
+// Decode decodes the bytes sequence created with [Encode].
+func Decode(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, errors.New("invalid encoded vector length")
+	}
+
+	result := make([]float32, len(data)/4)
+
+	for i := range result {
+		result[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+
+	return result, nil
+}
+
+// Parse converts the provided raw value into a float32 vector.
+//
+// The raw value could be a JSON array string (eg. "[0.1, 0.2]"),
+// a numeric slice or a slice of numeric values (eg. []any{0.1, 0.2}).
+//
+// Empty string and nil are parsed as empty vector.
+func Parse(raw any) ([]float32, error) {
+	switch v := raw.(type) {
+	case nil:
+		return []float32{}, nil
+	case []float32:
+		return v, nil
+	case []float64:
+		result := make([]float32, len(v))
+		for i, f := range v {
+			result[i] = float32(f)
+		}
+		return result, nil
+	case []any:
+		result := make([]float32, len(v))
+		for i, item := range v {
+			f, err := cast.ToFloat32E(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid vector item %d: %w", i, err)
+			}
+			result[i] = f
+		}
+		return result, nil
+	case []byte:
+		return Parse(string(v))
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return []float32{}, nil
+		}
+
+		result := []float32{}
+		if err := json.Unmarshal([]byte(v), &result); err != nil {
+			return nil, fmt.Errorf("invalid vector JSON array: %w", err)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported vector value type %T", raw)
+	}
+}
+
+// Distance calculates the distance between vectors a and b using the specified metric.
+func Distance(metric string, a, b []float32) (float64, error) {
+	if len(a) != len(b) {
+		return 0, ErrDimensionsMismatch
+	}
+
+	switch metric {
+	case MetricCosine:
+		var dot, normA, normB float64
+		for i := range a {
+			dot += float64(a[i]) * float64(b[i])
+			normA += float64(a[i]) * float64(a[i])
+			normB += float64(b[i]) * float64(b[i])
+		}
+		if normA == 0 || normB == 0 {
+			return 1, nil // no direction
+		}
+		return 1 - dot/(math.Sqrt(normA)*math.Sqrt(normB)), nil
+	case MetricDot:
+		var dot float64
+		for i := range a {
+			dot += float64(a[i]) * float64(b[i])
+		}
+		return -dot, nil
+	case MetricL2:
+		var sum float64
+		for i := range a {
+			d := float64(a[i]) - float64(b[i])
+			sum += d * d
+		}
+		return math.Sqrt(sum), nil
+	default:
+		return 0, ErrUnknownMetric
+	}
+}
+
+// SQLFunction is the [SQLFunctionName] SQLite scalar function implementation.
+//
+// It could be used to register the function with a custom SQLite driver, eg.:
+//
+//	sql.Register("pb_sqlite3", &sqlite3.SQLiteDriver{
+//		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+//			return conn.RegisterFunc(vector.SQLFunctionName, func(metric string, a, b any) (any, error) {
+//				return vector.SQLFunction([]driver.Value{metric, a, b})
+//			}, true)
+//		},
+//	})
+func SQLFunction(args []driver.Value) (driver.Value, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("%s expects 3 arguments, got %d", SQLFunctionName, len(args))
+	}
+
+	metric := cast.ToString(args[0])
+
+	a, err := sqlArgVector(args[1])
+	if err != nil || len(a) == 0 {
+		return nil, nil
+	}
+
+	b, err := sqlArgVector(args[2])
+	if err != nil || len(b) == 0 {
+		return nil, nil
+	}
+
+	distance, err := Distance(metric, a, b)
+	if err != nil {
+		if errors.Is(err, ErrDimensionsMismatch) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return distance, nil
+}
+
+// sqlArgVector converts the provided SQL function argument into a float32 vector.
+//
+// note: the text arguments are parsed on every call so the constant vectors
+// (eg. the search vector) are expected to be passed as encoded BLOB.
+func sqlArgVector(arg driver.Value) ([]float32, error) {
+	switch v := arg.(type) {
+	case []byte:
+		return Decode(v)
+	case string:
+		return Parse(v)
+	default:
+		return nil, nil
+	}
+}
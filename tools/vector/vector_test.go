@@ -0,0 +1,157 @@
+package vector_test
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/vector"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	scenarios := [][]float32{
+		{},
+		{0},
+		{1.5, -2.25, 0.1, math.MaxFloat32},
+	}
+
+	for i, v := range scenarios {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			encoded := vector.Encode(v)
+
+			if len(encoded) != 4*len(v) {
+				t.Fatalf("Expected %d bytes, got %d", 4*len(v), len(encoded))
+			}
+
+			decoded, err := vector.Decode(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(decoded, v) {
+				t.Fatalf("Expected %v, got %v", v, decoded)
+			}
+		})
+	}
+
+	if _, err := vector.Decode([]byte{1, 2, 3}); err == nil {
+		t.Fatal("Expected decode error for invalid bytes length")
+	}
+}
+
+func TestParse(t *testing.T) {
+	scenarios := []struct {
+		raw         any
+		expected    []float32
+		expectError bool
+	}{
+		{nil, []float32{}, false},
+		{"", []float32{}, false},
+		{" [1, 2.5] ", []float32{1, 2.5}, false},
+		{[]byte("[3]"), []float32{3}, false},
+		{"[1, 'a']", nil, true},
+		{"{}", nil, true},
+		{[]float32{1, 2}, []float32{1, 2}, false},
+		{[]float64{1, 2}, []float32{1, 2}, false},
+		{[]any{1, "2", 3.5}, []float32{1, 2, 3.5}, false},
+		{[]any{1, "a"}, nil, true},
+		{123, nil, true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			result, err := vector.Parse(s.raw)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !slices.Equal(result, s.expected) {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	scenarios := []struct {
+		metric      string
+		a           []float32
+		b           []float32
+		expected    float64
+		expectError bool
+	}{
+		{"unknown", []float32{1}, []float32{1}, 0, true},
+		{vector.MetricCosine, []float32{1}, []float32{1, 2}, 0, true},
+		{vector.MetricCosine, []float32{1, 0}, []float32{2, 0}, 0, false},
+		{vector.MetricCosine, []float32{1, 0}, []float32{0, 1}, 1, false},
+		{vector.MetricCosine, []float32{1, 0}, []float32{-1, 0}, 2, false},
+		{vector.MetricCosine, []float32{0, 0}, []float32{1, 0}, 1, false},
+		{vector.MetricDot, []float32{1, 2}, []float32{3, 4}, -11, false},
+		{vector.MetricL2, []float32{0, 0}, []float32{3, 4}, 5, false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.metric), func(t *testing.T) {
+			result, err := vector.Distance(s.metric, s.a, s.b)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if math.Abs(result-s.expected) > 1e-9 {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestSQLFunction(t *testing.T) {
+	encoded := vector.Encode([]float32{3, 4})
+
+	scenarios := []struct {
+		name        string
+		args        []driver.Value
+		expected    driver.Value
+		expectError bool
+	}{
+		{"invalid number of args", []driver.Value{vector.MetricL2, encoded}, nil, true},
+		{"unknown metric", []driver.Value{"unknown", encoded, "[1,2]"}, nil, true},
+		{"empty vector", []driver.Value{vector.MetricL2, []byte{}, "[1,2]"}, nil, false},
+		{"invalid vector", []driver.Value{vector.MetricL2, encoded, "[1,"}, nil, false},
+		{"dimensions mismatch", []driver.Value{vector.MetricL2, encoded, "[1]"}, nil, false},
+		{"null vector", []driver.Value{vector.MetricL2, encoded, nil}, nil, false},
+		{"blob and text", []driver.Value{vector.MetricL2, encoded, "[0,0]"}, 5.0, false},
+		{"text and blob", []driver.Value{vector.MetricDot, "[1,1]", encoded}, -7.0, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := vector.SQLFunction(s.args)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestBruteForceCandidates(t *testing.T) {
+	ids, err := vector.BruteForce{}.Candidates(vector.MetricCosine, []float32{1, 2}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ids != nil {
+		t.Fatalf("Expected nil candidates (aka. all rows), got %v", ids)
+	}
+}